@@ -0,0 +1,6 @@
+-- fails when a custom role id out of the smallint range is in use, move those members and invites to a system role first.
+alter table team_members
+    alter column user_role type smallint;
+
+alter table invites
+    alter column user_role type smallint;
@@ -0,0 +1,6 @@
+-- custom role ids come from roles.id (bigserial), the role columns created as smallint can not hold them.
+alter table team_members
+    alter column user_role type bigint;
+
+alter table invites
+    alter column user_role type bigint;
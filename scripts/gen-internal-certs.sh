@@ -0,0 +1,33 @@
+#!/bin/bash
+
+# generate a self-signed CA, the internal server certificate and a client certificate for local mTLS testing.
+# usage: gen-internal-certs.sh [output dir] [client service name]
+# then set:
+#   KOZMO_INTERNAL_TLS_CERT_FILE=<output dir>/server.crt
+#   KOZMO_INTERNAL_TLS_KEY_FILE=<output dir>/server.key
+#   KOZMO_INTERNAL_TLS_CLIENT_CA_FILE=<output dir>/ca.crt
+#   KOZMO_INTERNAL_TLS_ALLOWED_CLIENTS=<client service name>=CN:<client service name>
+
+set -e
+
+OUT_DIR=${1:-./certs}
+CLIENT_NAME=${2:-kozmo-builder-backend}
+DAYS=365
+
+mkdir -p "$OUT_DIR"
+cd "$OUT_DIR"
+
+# CA
+openssl req -x509 -newkey rsa:2048 -nodes -days $DAYS -keyout ca.key -out ca.crt -subj "/CN=kozmo-internal-ca"
+
+# server
+openssl req -newkey rsa:2048 -nodes -keyout server.key -out server.csr -subj "/CN=kozmo-supervisor-backend-internal"
+printf "subjectAltName=DNS:localhost,IP:127.0.0.1\nextendedKeyUsage=serverAuth\n" > server.ext
+openssl x509 -req -in server.csr -CA ca.crt -CAkey ca.key -CAcreateserial -days $DAYS -extfile server.ext -out server.crt
+
+# client
+openssl req -newkey rsa:2048 -nodes -keyout client.key -out client.csr -subj "/CN=$CLIENT_NAME"
+printf "subjectAltName=DNS:$CLIENT_NAME\nextendedKeyUsage=clientAuth\n" > client.ext
+openssl x509 -req -in client.csr -CA ca.crt -CAkey ca.key -CAcreateserial -days $DAYS -extfile client.ext -out client.crt
+
+rm -f server.csr server.ext client.csr client.ext ca.srl
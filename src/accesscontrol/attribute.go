@@ -1,6 +1,10 @@
 package accesscontrol
 
-import "github.com/kozmoai/kozmo-supervisor-backend/src/model"
+import (
+	"errors"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+)
 
 // default
 const ANONYMOUS_AUTH_TOKEN = ""
@@ -64,6 +68,15 @@ var MadifyRoleToAttributeMap = map[int]int{
 	model.USER_ROLE_OWNER: ACTION_MANAGE_ROLE_TO_OWNER, model.USER_ROLE_ADMIN: ACTION_MANAGE_ROLE_TO_ADMIN, model.USER_ROLE_EDITOR: ACTION_MANAGE_ROLE_TO_EDITOR, model.USER_ROLE_VIEWER: ACTION_MANAGE_ROLE_TO_VIEWER,
 }
 
+// this config map domain category to target manage and delete domain attribute
+// e.g. you want manage a model.DOMAIN_CATEGORY_APP domain, so it's mapped attribute is ACTION_MANAGE_APP_DOMAIN
+var ManageDomainAttributeMap = map[int]int{
+	model.DOMAIN_CATEGORY_TEAM: ACTION_MANAGE_TEAM_DOMAIN, model.DOMAIN_CATEGORY_APP: ACTION_MANAGE_APP_DOMAIN,
+}
+var DeleteDomainAttributeMap = map[int]int{
+	model.DOMAIN_CATEGORY_TEAM: ACTION_DELETE_TEAM_DOMAIN, model.DOMAIN_CATEGORY_APP: ACTION_DELETE_APP_DOMAIN,
+}
+
 const (
 	ATTRIBUTE_CATEGORY_ACCESS  = 1
 	ATTRIBUTE_CATEGORY_DELETE  = 2
@@ -142,6 +155,18 @@ const (
 	ACTION_MANAGE_CREATE_FILE      // create file
 	ACTION_MANAGE_EDIT_FILE        // edit file
 	ACTION_MANAGE_CREATE_SHARELINK // create sharelink
+
+	// Team Member Attribute
+	ACTION_MANAGE_SUSPEND_MEMBER // suspend and reactivate team member
+	ACTION_MANAGE_APPROVE_MEMBER // approve pending team member
+	ACTION_MANAGE_SCIM           // manage SCIM provisioning token
+
+	// Role Attribute
+	ACTION_MANAGE_CUSTOM_ROLE        // create and update team custom role
+	ACTION_MANAGE_UNIT_ROLE_RELATION // grant or deny role on a single unit
+
+	// Team Attribute
+	ACTION_MANAGE_TEAM_WEBHOOK // manage team outgoing webhooks and their deliveries
 )
 
 // action delete
@@ -182,36 +207,41 @@ var AttributeConfigList = map[int]map[int]map[int]map[int]bool{
 			UNIT_TYPE_ACTION: {ACTION_ACCESS_VIEW: true}, // only should for public action
 		},
 		model.USER_ROLE_OWNER: {
-			UNIT_TYPE_TEAM:              {ACTION_ACCESS_VIEW: true},
-			UNIT_TYPE_TEAM_MEMBER:       {ACTION_ACCESS_VIEW: true},
-			UNIT_TYPE_USER:              {ACTION_ACCESS_VIEW: true},
-			UNIT_TYPE_INVITE:            {ACTION_ACCESS_VIEW: true, ACTION_ACCESS_INVITE_BY_LINK: true, ACTION_ACCESS_INVITE_BY_EMAIL: true, ACTION_ACCESS_INVITE_ADMIN: true, ACTION_ACCESS_INVITE_EDITOR: true, ACTION_ACCESS_INVITE_VIEWER: true},
-			UNIT_TYPE_DOMAIN:            {ACTION_ACCESS_VIEW: true},
-			UNIT_TYPE_BILLING:           {ACTION_ACCESS_VIEW: true},
-			UNIT_TYPE_BUILDER_DASHBOARD: {ACTION_ACCESS_VIEW: true},
-			UNIT_TYPE_APP:               {ACTION_ACCESS_VIEW: true},
-			UNIT_TYPE_COMPONENTS:        {ACTION_ACCESS_VIEW: true},
-			UNIT_TYPE_RESOURCE:          {ACTION_ACCESS_VIEW: true},
-			UNIT_TYPE_ACTION:            {ACTION_ACCESS_VIEW: true},
-			UNIT_TYPE_TRANSFORMER:       {ACTION_ACCESS_VIEW: true},
-			UNIT_TYPE_JOB:               {ACTION_ACCESS_VIEW: true},
+			UNIT_TYPE_TEAM:                {ACTION_ACCESS_VIEW: true},
+			UNIT_TYPE_TEAM_MEMBER:         {ACTION_ACCESS_VIEW: true},
+			UNIT_TYPE_ROLES:               {ACTION_ACCESS_VIEW: true},
+			UNIT_TYPE_UNIT_ROLE_RELATIONS: {ACTION_ACCESS_VIEW: true},
+			UNIT_TYPE_USER:                {ACTION_ACCESS_VIEW: true},
+			UNIT_TYPE_INVITE:              {ACTION_ACCESS_VIEW: true, ACTION_ACCESS_INVITE_BY_LINK: true, ACTION_ACCESS_INVITE_BY_EMAIL: true, ACTION_ACCESS_INVITE_ADMIN: true, ACTION_ACCESS_INVITE_EDITOR: true, ACTION_ACCESS_INVITE_VIEWER: true},
+			UNIT_TYPE_DOMAIN:              {ACTION_ACCESS_VIEW: true},
+			UNIT_TYPE_BILLING:             {ACTION_ACCESS_VIEW: true},
+			UNIT_TYPE_BUILDER_DASHBOARD:   {ACTION_ACCESS_VIEW: true},
+			UNIT_TYPE_APP:                 {ACTION_ACCESS_VIEW: true},
+			UNIT_TYPE_COMPONENTS:          {ACTION_ACCESS_VIEW: true},
+			UNIT_TYPE_RESOURCE:            {ACTION_ACCESS_VIEW: true},
+			UNIT_TYPE_ACTION:              {ACTION_ACCESS_VIEW: true},
+			UNIT_TYPE_TRANSFORMER:         {ACTION_ACCESS_VIEW: true},
+			UNIT_TYPE_JOB:                 {ACTION_ACCESS_VIEW: true},
 		},
 		model.USER_ROLE_ADMIN: {
-			UNIT_TYPE_TEAM:              {ACTION_ACCESS_VIEW: true},
-			UNIT_TYPE_TEAM_MEMBER:       {ACTION_ACCESS_VIEW: true},
-			UNIT_TYPE_USER:              {ACTION_ACCESS_VIEW: true},
-			UNIT_TYPE_INVITE:            {ACTION_ACCESS_VIEW: true, ACTION_ACCESS_INVITE_BY_LINK: true, ACTION_ACCESS_INVITE_BY_EMAIL: true, ACTION_ACCESS_INVITE_ADMIN: true, ACTION_ACCESS_INVITE_EDITOR: true, ACTION_ACCESS_INVITE_VIEWER: true},
-			UNIT_TYPE_DOMAIN:            {ACTION_ACCESS_VIEW: true},
-			UNIT_TYPE_BUILDER_DASHBOARD: {ACTION_ACCESS_VIEW: true},
-			UNIT_TYPE_APP:               {ACTION_ACCESS_VIEW: true},
-			UNIT_TYPE_COMPONENTS:        {ACTION_ACCESS_VIEW: true},
-			UNIT_TYPE_RESOURCE:          {ACTION_ACCESS_VIEW: true},
-			UNIT_TYPE_ACTION:            {ACTION_ACCESS_VIEW: true},
-			UNIT_TYPE_TRANSFORMER:       {ACTION_ACCESS_VIEW: true},
-			UNIT_TYPE_JOB:               {ACTION_ACCESS_VIEW: true},
+			UNIT_TYPE_TEAM:                {ACTION_ACCESS_VIEW: true},
+			UNIT_TYPE_TEAM_MEMBER:         {ACTION_ACCESS_VIEW: true},
+			UNIT_TYPE_ROLES:               {ACTION_ACCESS_VIEW: true},
+			UNIT_TYPE_UNIT_ROLE_RELATIONS: {ACTION_ACCESS_VIEW: true},
+			UNIT_TYPE_USER:                {ACTION_ACCESS_VIEW: true},
+			UNIT_TYPE_INVITE:              {ACTION_ACCESS_VIEW: true, ACTION_ACCESS_INVITE_BY_LINK: true, ACTION_ACCESS_INVITE_BY_EMAIL: true, ACTION_ACCESS_INVITE_ADMIN: true, ACTION_ACCESS_INVITE_EDITOR: true, ACTION_ACCESS_INVITE_VIEWER: true},
+			UNIT_TYPE_DOMAIN:              {ACTION_ACCESS_VIEW: true},
+			UNIT_TYPE_BUILDER_DASHBOARD:   {ACTION_ACCESS_VIEW: true},
+			UNIT_TYPE_APP:                 {ACTION_ACCESS_VIEW: true},
+			UNIT_TYPE_COMPONENTS:          {ACTION_ACCESS_VIEW: true},
+			UNIT_TYPE_RESOURCE:            {ACTION_ACCESS_VIEW: true},
+			UNIT_TYPE_ACTION:              {ACTION_ACCESS_VIEW: true},
+			UNIT_TYPE_TRANSFORMER:         {ACTION_ACCESS_VIEW: true},
+			UNIT_TYPE_JOB:                 {ACTION_ACCESS_VIEW: true},
 		},
 		model.USER_ROLE_EDITOR: {
 			UNIT_TYPE_TEAM_MEMBER:       {ACTION_ACCESS_VIEW: true},
+			UNIT_TYPE_ROLES:             {ACTION_ACCESS_VIEW: true},
 			UNIT_TYPE_USER:              {ACTION_ACCESS_VIEW: true},
 			UNIT_TYPE_INVITE:            {ACTION_ACCESS_VIEW: true, ACTION_ACCESS_INVITE_BY_LINK: true, ACTION_ACCESS_INVITE_BY_EMAIL: true, ACTION_ACCESS_INVITE_EDITOR: true, ACTION_ACCESS_INVITE_VIEWER: true},
 			UNIT_TYPE_BUILDER_DASHBOARD: {ACTION_ACCESS_VIEW: true},
@@ -224,6 +254,7 @@ var AttributeConfigList = map[int]map[int]map[int]map[int]bool{
 		},
 		model.USER_ROLE_VIEWER: {
 			UNIT_TYPE_TEAM_MEMBER:       {ACTION_ACCESS_VIEW: true},
+			UNIT_TYPE_ROLES:             {ACTION_ACCESS_VIEW: true},
 			UNIT_TYPE_USER:              {ACTION_ACCESS_VIEW: true},
 			UNIT_TYPE_INVITE:            {ACTION_ACCESS_VIEW: true, ACTION_ACCESS_INVITE_BY_LINK: true, ACTION_ACCESS_INVITE_BY_EMAIL: true, ACTION_ACCESS_INVITE_VIEWER: true},
 			UNIT_TYPE_BUILDER_DASHBOARD: {ACTION_ACCESS_VIEW: true},
@@ -237,32 +268,36 @@ var AttributeConfigList = map[int]map[int]map[int]map[int]bool{
 	},
 	ATTRIBUTE_CATEGORY_DELETE: {
 		model.USER_ROLE_OWNER: {
-			UNIT_TYPE_TEAM:              {ACTION_DELETE: true},
-			UNIT_TYPE_TEAM_MEMBER:       {ACTION_DELETE: true},
-			UNIT_TYPE_USER:              {ACTION_DELETE: true},
-			UNIT_TYPE_INVITE:            {ACTION_DELETE: true},
-			UNIT_TYPE_DOMAIN:            {ACTION_DELETE: true, ACTION_DELETE_TEAM_DOMAIN: true, ACTION_DELETE_APP_DOMAIN: true},
-			UNIT_TYPE_BILLING:           {ACTION_DELETE: true},
-			UNIT_TYPE_BUILDER_DASHBOARD: {ACTION_DELETE: true},
-			UNIT_TYPE_APP:               {ACTION_DELETE: true},
-			UNIT_TYPE_COMPONENTS:        {ACTION_DELETE: true},
-			UNIT_TYPE_RESOURCE:          {ACTION_DELETE: true},
-			UNIT_TYPE_ACTION:            {ACTION_DELETE: true},
-			UNIT_TYPE_TRANSFORMER:       {ACTION_DELETE: true},
-			UNIT_TYPE_JOB:               {ACTION_DELETE: true},
+			UNIT_TYPE_TEAM:                {ACTION_DELETE: true},
+			UNIT_TYPE_TEAM_MEMBER:         {ACTION_DELETE: true},
+			UNIT_TYPE_ROLES:               {ACTION_DELETE: true},
+			UNIT_TYPE_UNIT_ROLE_RELATIONS: {ACTION_DELETE: true},
+			UNIT_TYPE_USER:                {ACTION_DELETE: true},
+			UNIT_TYPE_INVITE:              {ACTION_DELETE: true},
+			UNIT_TYPE_DOMAIN:              {ACTION_DELETE: true, ACTION_DELETE_TEAM_DOMAIN: true, ACTION_DELETE_APP_DOMAIN: true},
+			UNIT_TYPE_BILLING:             {ACTION_DELETE: true},
+			UNIT_TYPE_BUILDER_DASHBOARD:   {ACTION_DELETE: true},
+			UNIT_TYPE_APP:                 {ACTION_DELETE: true},
+			UNIT_TYPE_COMPONENTS:          {ACTION_DELETE: true},
+			UNIT_TYPE_RESOURCE:            {ACTION_DELETE: true},
+			UNIT_TYPE_ACTION:              {ACTION_DELETE: true},
+			UNIT_TYPE_TRANSFORMER:         {ACTION_DELETE: true},
+			UNIT_TYPE_JOB:                 {ACTION_DELETE: true},
 		},
 		model.USER_ROLE_ADMIN: {
-			UNIT_TYPE_TEAM_MEMBER:       {ACTION_DELETE: true},
-			UNIT_TYPE_USER:              {ACTION_DELETE: true},
-			UNIT_TYPE_INVITE:            {ACTION_DELETE: true},
-			UNIT_TYPE_DOMAIN:            {ACTION_DELETE: true, ACTION_DELETE_TEAM_DOMAIN: true, ACTION_DELETE_APP_DOMAIN: true},
-			UNIT_TYPE_BUILDER_DASHBOARD: {ACTION_DELETE: true},
-			UNIT_TYPE_APP:               {ACTION_DELETE: true},
-			UNIT_TYPE_COMPONENTS:        {ACTION_DELETE: true},
-			UNIT_TYPE_RESOURCE:          {ACTION_DELETE: true},
-			UNIT_TYPE_ACTION:            {ACTION_DELETE: true},
-			UNIT_TYPE_TRANSFORMER:       {ACTION_DELETE: true},
-			UNIT_TYPE_JOB:               {ACTION_DELETE: true},
+			UNIT_TYPE_TEAM_MEMBER:         {ACTION_DELETE: true},
+			UNIT_TYPE_ROLES:               {ACTION_DELETE: true},
+			UNIT_TYPE_UNIT_ROLE_RELATIONS: {ACTION_DELETE: true},
+			UNIT_TYPE_USER:                {ACTION_DELETE: true},
+			UNIT_TYPE_INVITE:              {ACTION_DELETE: true},
+			UNIT_TYPE_DOMAIN:              {ACTION_DELETE: true, ACTION_DELETE_TEAM_DOMAIN: true, ACTION_DELETE_APP_DOMAIN: true},
+			UNIT_TYPE_BUILDER_DASHBOARD:   {ACTION_DELETE: true},
+			UNIT_TYPE_APP:                 {ACTION_DELETE: true},
+			UNIT_TYPE_COMPONENTS:          {ACTION_DELETE: true},
+			UNIT_TYPE_RESOURCE:            {ACTION_DELETE: true},
+			UNIT_TYPE_ACTION:              {ACTION_DELETE: true},
+			UNIT_TYPE_TRANSFORMER:         {ACTION_DELETE: true},
+			UNIT_TYPE_JOB:                 {ACTION_DELETE: true},
 		},
 		model.USER_ROLE_EDITOR: {
 			UNIT_TYPE_TEAM_MEMBER: {ACTION_DELETE: true},
@@ -285,34 +320,38 @@ var AttributeConfigList = map[int]map[int]map[int]map[int]bool{
 			UNIT_TYPE_APP: {ACTION_MANAGE_RUN_ACTION: true},
 		},
 		model.USER_ROLE_OWNER: {
-			UNIT_TYPE_TEAM:              {ACTION_MANAGE_TEAM_NAME: true, ACTION_MANAGE_TEAM_ICON: true, ACTION_MANAGE_TEAM_CONFIG: true, ACTION_MANAGE_UPDATE_TEAM_DOMAIN: true},
-			UNIT_TYPE_TEAM_MEMBER:       {ACTION_MANAGE_REMOVE_MEMBER: true, ACTION_MANAGE_ROLE: true, ACTION_MANAGE_ROLE_FROM_OWNER: true, ACTION_MANAGE_ROLE_FROM_ADMIN: true, ACTION_MANAGE_ROLE_FROM_EDITOR: true, ACTION_MANAGE_ROLE_FROM_VIEWER: true, ACTION_MANAGE_ROLE_TO_OWNER: true, ACTION_MANAGE_ROLE_TO_ADMIN: true, ACTION_MANAGE_ROLE_TO_EDITOR: true, ACTION_MANAGE_ROLE_TO_VIEWER: true},
-			UNIT_TYPE_USER:              {ACTION_MANAGE_RENAME_USER: true, ACTION_MANAGE_UPDATE_USER_AVATAR: true},
-			UNIT_TYPE_INVITE:            {ACTION_MANAGE_CONFIG_INVITE: true, ACTION_MANAGE_INVITE_LINK: true},
-			UNIT_TYPE_DOMAIN:            {ACTION_MANAGE_TEAM_DOMAIN: true, ACTION_MANAGE_APP_DOMAIN: true},
-			UNIT_TYPE_BILLING:           {ACTION_MANAGE_PAYMENT_INFO: true},
-			UNIT_TYPE_BUILDER_DASHBOARD: {ACTION_MANAGE_DASHBOARD_BROADCAST: true},
-			UNIT_TYPE_APP:               {ACTION_MANAGE_CREATE_APP: true, ACTION_MANAGE_EDIT_APP: true},
-			UNIT_TYPE_COMPONENTS:        {},
-			UNIT_TYPE_RESOURCE:          {ACTION_MANAGE_CREATE_RESOURCE: true, ACTION_MANAGE_EDIT_RESOURCE: true},
-			UNIT_TYPE_ACTION:            {ACTION_MANAGE_CREATE_ACTION: true, ACTION_MANAGE_EDIT_ACTION: true, ACTION_MANAGE_PREVIEW_ACTION: true, ACTION_MANAGE_RUN_ACTION: true},
-			UNIT_TYPE_TRANSFORMER:       {},
-			UNIT_TYPE_JOB:               {},
+			UNIT_TYPE_TEAM:                {ACTION_MANAGE_TEAM_NAME: true, ACTION_MANAGE_TEAM_ICON: true, ACTION_MANAGE_TEAM_CONFIG: true, ACTION_MANAGE_UPDATE_TEAM_DOMAIN: true, ACTION_MANAGE_SCIM: true, ACTION_MANAGE_TEAM_WEBHOOK: true},
+			UNIT_TYPE_TEAM_MEMBER:         {ACTION_MANAGE_REMOVE_MEMBER: true, ACTION_MANAGE_ROLE: true, ACTION_MANAGE_ROLE_FROM_OWNER: true, ACTION_MANAGE_ROLE_FROM_ADMIN: true, ACTION_MANAGE_ROLE_FROM_EDITOR: true, ACTION_MANAGE_ROLE_FROM_VIEWER: true, ACTION_MANAGE_ROLE_TO_OWNER: true, ACTION_MANAGE_ROLE_TO_ADMIN: true, ACTION_MANAGE_ROLE_TO_EDITOR: true, ACTION_MANAGE_ROLE_TO_VIEWER: true, ACTION_MANAGE_SUSPEND_MEMBER: true, ACTION_MANAGE_APPROVE_MEMBER: true},
+			UNIT_TYPE_ROLES:               {ACTION_MANAGE_CUSTOM_ROLE: true},
+			UNIT_TYPE_UNIT_ROLE_RELATIONS: {ACTION_MANAGE_UNIT_ROLE_RELATION: true},
+			UNIT_TYPE_USER:                {ACTION_MANAGE_RENAME_USER: true, ACTION_MANAGE_UPDATE_USER_AVATAR: true},
+			UNIT_TYPE_INVITE:              {ACTION_MANAGE_CONFIG_INVITE: true, ACTION_MANAGE_INVITE_LINK: true},
+			UNIT_TYPE_DOMAIN:              {ACTION_MANAGE_TEAM_DOMAIN: true, ACTION_MANAGE_APP_DOMAIN: true},
+			UNIT_TYPE_BILLING:             {ACTION_MANAGE_PAYMENT_INFO: true},
+			UNIT_TYPE_BUILDER_DASHBOARD:   {ACTION_MANAGE_DASHBOARD_BROADCAST: true},
+			UNIT_TYPE_APP:                 {ACTION_MANAGE_CREATE_APP: true, ACTION_MANAGE_EDIT_APP: true},
+			UNIT_TYPE_COMPONENTS:          {},
+			UNIT_TYPE_RESOURCE:            {ACTION_MANAGE_CREATE_RESOURCE: true, ACTION_MANAGE_EDIT_RESOURCE: true},
+			UNIT_TYPE_ACTION:              {ACTION_MANAGE_CREATE_ACTION: true, ACTION_MANAGE_EDIT_ACTION: true, ACTION_MANAGE_PREVIEW_ACTION: true, ACTION_MANAGE_RUN_ACTION: true},
+			UNIT_TYPE_TRANSFORMER:         {},
+			UNIT_TYPE_JOB:                 {},
 		},
 		model.USER_ROLE_ADMIN: {
-			UNIT_TYPE_TEAM:              {ACTION_MANAGE_TEAM_NAME: true, ACTION_MANAGE_TEAM_ICON: true, ACTION_MANAGE_UPDATE_TEAM_DOMAIN: true, ACTION_MANAGE_TEAM_CONFIG: true},
-			UNIT_TYPE_TEAM_MEMBER:       {ACTION_MANAGE_REMOVE_MEMBER: true, ACTION_MANAGE_ROLE: true, ACTION_MANAGE_ROLE_FROM_ADMIN: true, ACTION_MANAGE_ROLE_FROM_EDITOR: true, ACTION_MANAGE_ROLE_FROM_VIEWER: true, ACTION_MANAGE_ROLE_TO_ADMIN: true, ACTION_MANAGE_ROLE_TO_EDITOR: true, ACTION_MANAGE_ROLE_TO_VIEWER: true},
-			UNIT_TYPE_USER:              {ACTION_MANAGE_RENAME_USER: true, ACTION_MANAGE_UPDATE_USER_AVATAR: true},
-			UNIT_TYPE_INVITE:            {ACTION_MANAGE_CONFIG_INVITE: true, ACTION_MANAGE_INVITE_LINK: true},
-			UNIT_TYPE_DOMAIN:            {ACTION_MANAGE_TEAM_DOMAIN: true, ACTION_MANAGE_APP_DOMAIN: true},
-			UNIT_TYPE_BILLING:           {ACTION_MANAGE_PAYMENT_INFO: true},
-			UNIT_TYPE_BUILDER_DASHBOARD: {ACTION_MANAGE_DASHBOARD_BROADCAST: true},
-			UNIT_TYPE_APP:               {ACTION_MANAGE_CREATE_APP: true, ACTION_MANAGE_EDIT_APP: true},
-			UNIT_TYPE_COMPONENTS:        {},
-			UNIT_TYPE_RESOURCE:          {ACTION_MANAGE_CREATE_RESOURCE: true, ACTION_MANAGE_EDIT_RESOURCE: true},
-			UNIT_TYPE_ACTION:            {ACTION_MANAGE_CREATE_ACTION: true, ACTION_MANAGE_EDIT_ACTION: true, ACTION_MANAGE_PREVIEW_ACTION: true, ACTION_MANAGE_RUN_ACTION: true},
-			UNIT_TYPE_TRANSFORMER:       {},
-			UNIT_TYPE_JOB:               {},
+			UNIT_TYPE_TEAM:                {ACTION_MANAGE_TEAM_NAME: true, ACTION_MANAGE_TEAM_ICON: true, ACTION_MANAGE_UPDATE_TEAM_DOMAIN: true, ACTION_MANAGE_TEAM_CONFIG: true, ACTION_MANAGE_SCIM: true, ACTION_MANAGE_TEAM_WEBHOOK: true},
+			UNIT_TYPE_TEAM_MEMBER:         {ACTION_MANAGE_REMOVE_MEMBER: true, ACTION_MANAGE_ROLE: true, ACTION_MANAGE_ROLE_FROM_ADMIN: true, ACTION_MANAGE_ROLE_FROM_EDITOR: true, ACTION_MANAGE_ROLE_FROM_VIEWER: true, ACTION_MANAGE_ROLE_TO_ADMIN: true, ACTION_MANAGE_ROLE_TO_EDITOR: true, ACTION_MANAGE_ROLE_TO_VIEWER: true, ACTION_MANAGE_SUSPEND_MEMBER: true, ACTION_MANAGE_APPROVE_MEMBER: true},
+			UNIT_TYPE_ROLES:               {ACTION_MANAGE_CUSTOM_ROLE: true},
+			UNIT_TYPE_UNIT_ROLE_RELATIONS: {ACTION_MANAGE_UNIT_ROLE_RELATION: true},
+			UNIT_TYPE_USER:                {ACTION_MANAGE_RENAME_USER: true, ACTION_MANAGE_UPDATE_USER_AVATAR: true},
+			UNIT_TYPE_INVITE:              {ACTION_MANAGE_CONFIG_INVITE: true, ACTION_MANAGE_INVITE_LINK: true},
+			UNIT_TYPE_DOMAIN:              {ACTION_MANAGE_TEAM_DOMAIN: true, ACTION_MANAGE_APP_DOMAIN: true},
+			UNIT_TYPE_BILLING:             {ACTION_MANAGE_PAYMENT_INFO: true},
+			UNIT_TYPE_BUILDER_DASHBOARD:   {ACTION_MANAGE_DASHBOARD_BROADCAST: true},
+			UNIT_TYPE_APP:                 {ACTION_MANAGE_CREATE_APP: true, ACTION_MANAGE_EDIT_APP: true},
+			UNIT_TYPE_COMPONENTS:          {},
+			UNIT_TYPE_RESOURCE:            {ACTION_MANAGE_CREATE_RESOURCE: true, ACTION_MANAGE_EDIT_RESOURCE: true},
+			UNIT_TYPE_ACTION:              {ACTION_MANAGE_CREATE_ACTION: true, ACTION_MANAGE_EDIT_ACTION: true, ACTION_MANAGE_PREVIEW_ACTION: true, ACTION_MANAGE_RUN_ACTION: true},
+			UNIT_TYPE_TRANSFORMER:         {},
+			UNIT_TYPE_JOB:                 {},
 		},
 		model.USER_ROLE_EDITOR: {
 			UNIT_TYPE_TEAM_MEMBER:       {ACTION_MANAGE_REMOVE_MEMBER: true, ACTION_MANAGE_ROLE: true, ACTION_MANAGE_ROLE_FROM_EDITOR: true, ACTION_MANAGE_ROLE_FROM_VIEWER: true, ACTION_MANAGE_ROLE_TO_EDITOR: true, ACTION_MANAGE_ROLE_TO_VIEWER: true},
@@ -357,27 +396,49 @@ type Attribute struct {
 	Special map[int]bool
 }
 
+// NewAttribute resolve attribute set of the role from role provider.
 func NewAttribute(userRole int, unitType int) *Attribute {
+	permissions := RetrieveRolePermissions(userRole)
 	attr := &Attribute{
-		Access:  AttributeConfigList[ATTRIBUTE_CATEGORY_ACCESS][userRole][unitType],
-		Delete:  AttributeConfigList[ATTRIBUTE_CATEGORY_DELETE][userRole][unitType],
-		Manage:  AttributeConfigList[ATTRIBUTE_CATEGORY_MANAGE][userRole][unitType],
-		Special: AttributeConfigList[ATTRIBUTE_CATEGORY_SPECIAL][userRole][unitType],
+		Access:  permissions[ATTRIBUTE_CATEGORY_ACCESS][unitType],
+		Delete:  permissions[ATTRIBUTE_CATEGORY_DELETE][unitType],
+		Manage:  permissions[ATTRIBUTE_CATEGORY_MANAGE][unitType],
+		Special: permissions[ATTRIBUTE_CATEGORY_SPECIAL][unitType],
 	}
 	return attr
 }
 
 type AttributeGroup struct {
-	UserRole  int
-	UnitType  int
-	UnitID    int
-	Attribute *Attribute
+	UserRole       int
+	UserStatus     int
+	UnitType       int
+	UnitID         int
+	Attribute      *Attribute
+	UnitAttribute  *UnitAttribute        // unit-level grants, nil for team-wide check
+	TeamPermission *model.TeamPermission // team-level toggles, nil for no overlay
 }
 
 func (attrg *AttributeGroup) SetUserRole(userRole int) {
 	attrg.UserRole = userRole
 }
 
+func (attrg *AttributeGroup) SetUserStatus(userStatus int) {
+	attrg.UserStatus = userStatus
+}
+
+func (attrg *AttributeGroup) IsUserSuspended() bool {
+	return attrg.UserStatus == STATUS_SUSPEND
+}
+
+func (attrg *AttributeGroup) IsUserPending() bool {
+	return attrg.UserStatus == STATUS_PENDING
+}
+
+// suspended or pending approval team member can not pass any attribute check.
+func (attrg *AttributeGroup) IsUserUnavailable() bool {
+	return attrg.IsUserSuspended() || attrg.IsUserPending()
+}
+
 func (attrg *AttributeGroup) SetUnitType(unitType int) {
 	attrg.UnitType = unitType
 }
@@ -386,36 +447,84 @@ func (attrg *AttributeGroup) SetUnitID(unitID int) {
 	attrg.UnitID = unitID
 }
 
-func (attrg *AttributeGroup) CanAccess(attribute int) bool {
-	r, match := attrg.Attribute.Access[attribute]
-	if !match {
-		return false
+func (attrg *AttributeGroup) SetUnitRoleRelations(unitRoleRelations []*model.UnitRoleRelation) {
+	attrg.UnitAttribute = NewUnitAttribute(unitRoleRelations)
+}
+
+func (attrg *AttributeGroup) SetTeamPermission(tp *model.TeamPermission) {
+	attrg.TeamPermission = tp
+}
+
+func (attrg *AttributeGroup) categoryAttributes(category int) map[int]bool {
+	switch category {
+	case ATTRIBUTE_CATEGORY_ACCESS:
+		return attrg.Attribute.Access
+	case ATTRIBUTE_CATEGORY_DELETE:
+		return attrg.Attribute.Delete
+	case ATTRIBUTE_CATEGORY_MANAGE:
+		return attrg.Attribute.Manage
+	case ATTRIBUTE_CATEGORY_SPECIAL:
+		return attrg.Attribute.Special
 	}
-	return r
+	return nil
 }
 
-func (attrg *AttributeGroup) CanDelete(attribute int) bool {
-	r, match := attrg.Attribute.Delete[attribute]
-	if !match {
-		return false
+// check team permission toggles and unit-level grants first, then fallback to the team-wide role.
+// the rules are recorded to trace when it is not nil, so Explain follows the very same path.
+func (attrg *AttributeGroup) check(category int, attribute int, withUnitAttribute bool, trace *Decision) bool {
+	name := attributeName{category: category, unitType: attrg.UnitType, attribute: attribute}
+
+	// user status
+	trace.considerf(DECISION_RULE_SOURCE_USER_STATUS, attrg.IsUserUnavailable(), false, "team member status is %s", model.TeamMemberStatusNameMap[attrg.UserStatus])
+	if attrg.IsUserUnavailable() {
+		return trace.decide(false, DECISION_REASON_USER_UNAVAILABLE)
+	}
+
+	// switched off team permission toggle revoke the attribute, even it was granted on the unit.
+	if overlay := revokingTeamPermissionOverlay(attrg.UserRole, attrg.TeamPermission, category, attrg.UnitType, attribute); overlay != nil {
+		trace.considerf(DECISION_RULE_SOURCE_TEAM_PERMISSION, true, false, "toggle %s is off, %s is revoked when it is off", overlay.Name, name)
+		return trace.decide(false, DECISION_REASON_TEAM_PERMISSION_REVOKED)
+	}
+
+	// unit-level grants
+	if withUnitAttribute {
+		if attrg.UnitAttribute == nil {
+			trace.considerf(DECISION_RULE_SOURCE_UNIT_ROLE_RELATION, false, false, "team-wide check, no unit-level grant loaded")
+		} else if r, match := attrg.UnitAttribute.Lookup(category, attribute); match {
+			if r {
+				trace.considerf(DECISION_RULE_SOURCE_UNIT_ROLE_RELATION, true, true, "allow %s on unit %d", name, attrg.UnitID)
+				return trace.decide(true, DECISION_REASON_UNIT_ALLOWED)
+			}
+			trace.considerf(DECISION_RULE_SOURCE_UNIT_ROLE_RELATION, true, false, "deny %s on unit %d", name, attrg.UnitID)
+			return trace.decide(false, DECISION_REASON_UNIT_DENIED)
+		} else {
+			trace.considerf(DECISION_RULE_SOURCE_UNIT_ROLE_RELATION, false, false, "no grant of %s on unit %d", name, attrg.UnitID)
+		}
 	}
-	return r
+
+	// team-wide role
+	if attrg.categoryAttributes(category)[attribute] {
+		trace.considerf(DECISION_RULE_SOURCE_ROLE, true, true, "role %d grants %s", attrg.UserRole, name)
+		return trace.decide(true, DECISION_REASON_ROLE_GRANTED)
+	}
+	trace.considerf(DECISION_RULE_SOURCE_ROLE, true, false, "role %d does not grant %s", attrg.UserRole, name)
+	return trace.decide(false, DECISION_REASON_ROLE_NOT_GRANTED)
+}
+
+func (attrg *AttributeGroup) CanAccess(attribute int) bool {
+	return attrg.check(ATTRIBUTE_CATEGORY_ACCESS, attribute, true, nil)
+}
+
+func (attrg *AttributeGroup) CanDelete(attribute int) bool {
+	return attrg.check(ATTRIBUTE_CATEGORY_DELETE, attribute, true, nil)
 }
 
 func (attrg *AttributeGroup) CanManage(attribute int) bool {
-	r, match := attrg.Attribute.Manage[attribute]
-	if !match {
-		return false
-	}
-	return r
+	return attrg.check(ATTRIBUTE_CATEGORY_MANAGE, attribute, true, nil)
 }
 
 func (attrg *AttributeGroup) CanManageSpecial(attribute int) bool {
-	r, match := attrg.Attribute.Special[attribute]
-	if !match {
-		return false
-	}
-	return r
+	return attrg.check(ATTRIBUTE_CATEGORY_SPECIAL, attribute, true, nil)
 }
 
 func (attrg *AttributeGroup) CanModify(attribute, fromID, toID int) bool {
@@ -426,38 +535,70 @@ func (attrg *AttributeGroup) CanModify(attribute, fromID, toID int) bool {
 	return false
 }
 
+// Evaluate dispatch the check by category name of internal access control check, e.g. "canAccess".
+func (attrg *AttributeGroup) Evaluate(category string, attribute int, fromID int, toID int) (bool, error) {
+	switch category {
+	case model.ACCESS_CONTROL_CHECK_CATEGORY_ACCESS:
+		return attrg.CanAccess(attribute), nil
+	case model.ACCESS_CONTROL_CHECK_CATEGORY_DELETE:
+		return attrg.CanDelete(attribute), nil
+	case model.ACCESS_CONTROL_CHECK_CATEGORY_MANAGE:
+		return attrg.CanManage(attribute), nil
+	case model.ACCESS_CONTROL_CHECK_CATEGORY_MANAGE_SPECIAL:
+		return attrg.CanManageSpecial(attribute), nil
+	case model.ACCESS_CONTROL_CHECK_CATEGORY_MODIFY:
+		return attrg.CanModify(attribute, fromID, toID), nil
+	}
+	return false, errors.New("unknown check category")
+}
+
 func (attrg *AttributeGroup) CanInvite(userRole int) bool {
-	// convert to attribute
-	attribute, hit := InviteRoleAttributeMap[userRole]
+	// convert to attribute, custom role requires the same attribute as the built-in role of its level
+	attribute, hit := InviteRoleAttributeMap[ResolveRoleLevel(userRole)]
 	if !hit {
 		return false
 	}
-	// check attirbute
-	r, match := attrg.Attribute.Access[attribute]
-	if !match {
+	return attrg.check(ATTRIBUTE_CATEGORY_ACCESS, attribute, false, nil)
+}
+
+func (attrg *AttributeGroup) CanManageDomain(domainCategory int) bool {
+	// convert to attribute
+	attribute, hit := ManageDomainAttributeMap[domainCategory]
+	if !hit {
 		return false
 	}
-	return r
+	return attrg.CanManage(attribute)
 }
 
-func (attrg *AttributeGroup) CanModifyRoleFromTo(fromRole, toRole int) bool {
+func (attrg *AttributeGroup) CanDeleteDomain(domainCategory int) bool {
 	// convert to attribute
-	fromRoleAttribute, fromHit := ModifyRoleFromAttributeMap[fromRole]
-	toRoleAttribute, toHit := MadifyRoleToAttributeMap[toRole]
-	if !fromHit || !toHit {
+	attribute, hit := DeleteDomainAttributeMap[domainCategory]
+	if !hit {
 		return false
 	}
-	// check attirbute
-	fromResult, fromMatch := attrg.Attribute.Manage[fromRoleAttribute]
-	toResult, toMatch := attrg.Attribute.Manage[toRoleAttribute]
-	if !fromMatch || !toMatch {
-		return false
+	return attrg.CanDelete(attribute)
+}
+
+func (attrg *AttributeGroup) CanModifyRoleFromTo(fromRole, toRole int) bool {
+	return attrg.modifyRoleFromTo(fromRole, toRole, nil)
+}
+
+// modifyRoleFromTo check both of the from and to role attributes, unit-level grants are not considered.
+func (attrg *AttributeGroup) modifyRoleFromTo(fromRole, toRole int, trace *Decision) bool {
+	// convert to attribute, custom role requires the same attribute as the built-in role of its level
+	fromRoleAttribute, fromHit := ModifyRoleFromAttributeMap[ResolveRoleLevel(fromRole)]
+	toRoleAttribute, toHit := MadifyRoleToAttributeMap[ResolveRoleLevel(toRole)]
+	if !fromHit || !toHit {
+		trace.considerf(DECISION_RULE_SOURCE_ROLE, true, false, "role %d or role %d can not be assigned", fromRole, toRole)
+		return trace.decide(false, DECISION_REASON_INVALID_CHECK)
 	}
-	return fromResult && toResult
+	return attrg.check(ATTRIBUTE_CATEGORY_MANAGE, fromRoleAttribute, false, trace) && attrg.check(ATTRIBUTE_CATEGORY_MANAGE, toRoleAttribute, false, trace)
 }
 
+// DoesNowUserAreEditorOrViewer check the role level, a custom role is an editor or viewer by its attributes.
 func (attrg *AttributeGroup) DoesNowUserAreEditorOrViewer() bool {
-	if attrg.UserRole == model.USER_ROLE_EDITOR || attrg.UserRole == model.USER_ROLE_VIEWER {
+	roleLevel := ResolveRoleLevel(attrg.UserRole)
+	if roleLevel == model.USER_ROLE_EDITOR || roleLevel == model.USER_ROLE_VIEWER {
 		return true
 	}
 	return false
@@ -466,10 +607,17 @@ func (attrg *AttributeGroup) DoesNowUserAreEditorOrViewer() bool {
 func NewAttributeGroup(userRole int, unitType int) *AttributeGroup {
 	attr := NewAttribute(userRole, unitType)
 	attrg := &AttributeGroup{
-		UserRole:  userRole,
-		UnitType:  unitType,
-		UnitID:    0, // 0 for placeholder, this feature has not implemented.
-		Attribute: attr,
+		UserRole:   userRole,
+		UserStatus: STATUS_OK,
+		UnitType:   unitType,
+		UnitID:     DEFAULT_UNIT_ID, // team-wide check, use SetUnitID and SetUnitRoleRelations for unit-level check.
+		Attribute:  attr,
 	}
 	return attrg
 }
+
+func NewAttributeGroupByTeamMember(teamMember *model.TeamMember, unitType int) *AttributeGroup {
+	attrg := NewAttributeGroup(teamMember.ExportUserRole(), unitType)
+	attrg.SetUserStatus(teamMember.ExportStatus())
+	return attrg
+}
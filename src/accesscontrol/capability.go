@@ -0,0 +1,72 @@
+package accesscontrol
+
+import (
+	"sort"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+)
+
+var AttributeCategoryNameMap = map[int]string{
+	ATTRIBUTE_CATEGORY_ACCESS:  "access",
+	ATTRIBUTE_CATEGORY_DELETE:  "delete",
+	ATTRIBUTE_CATEGORY_MANAGE:  "manage",
+	ATTRIBUTE_CATEGORY_SPECIAL: "special",
+}
+
+// ExportCapabilities export granted attributes of the role after team permission toggles applied.
+// map[categoryName][unitType][]attribute, suspended and pending team member has no capability.
+func ExportCapabilities(userRole int, userStatus int, tp *model.TeamPermission) map[string]map[int][]int {
+	capabilities := make(map[string]map[int][]int, len(AttributeCategoryNameMap))
+	for _, categoryName := range AttributeCategoryNameMap {
+		capabilities[categoryName] = make(map[int][]int)
+	}
+	if userStatus == STATUS_SUSPEND || userStatus == STATUS_PENDING {
+		return capabilities
+	}
+	permissions := ApplyTeamPermission(userRole, tp, RetrieveRolePermissions(userRole))
+	for category, unitTypes := range permissions {
+		categoryName, hit := AttributeCategoryNameMap[category]
+		if !hit {
+			continue
+		}
+		for unitType, attributes := range unitTypes {
+			granted := make([]int, 0, len(attributes))
+			for attribute, status := range attributes {
+				if status {
+					granted = append(granted, attribute)
+				}
+			}
+			if len(granted) == 0 {
+				continue
+			}
+			sort.Ints(granted)
+			capabilities[categoryName][unitType] = granted
+		}
+	}
+	return capabilities
+}
+
+// ExportScopes export granted attributes in "<category>:<unit type>:<attribute>" policy names, like "manage:team:team_webhook".
+// the attributes which has no policy name are not exported, scopes are sorted.
+func ExportScopes(userRole int, userStatus int, tp *model.TeamPermission) []string {
+	unitTypeNames := reverseNameMap(PolicyUnitTypeNameMap)
+	scopes := make([]string, 0)
+	for categoryName, unitTypes := range ExportCapabilities(userRole, userStatus, tp) {
+		attributeNames := reverseNameMap(PolicyAttributeNameMap[categoryName])
+		for unitType, attributes := range unitTypes {
+			unitTypeName, hit := unitTypeNames[unitType]
+			if !hit {
+				continue
+			}
+			for _, attribute := range attributes {
+				attributeName, hit := attributeNames[attribute]
+				if !hit {
+					continue
+				}
+				scopes = append(scopes, categoryName+":"+unitTypeName+":"+attributeName)
+			}
+		}
+	}
+	sort.Strings(scopes)
+	return scopes
+}
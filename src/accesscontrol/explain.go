@@ -0,0 +1,138 @@
+package accesscontrol
+
+import (
+	"fmt"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+)
+
+// sources of rules in decision trace
+const (
+	DECISION_RULE_SOURCE_PRINCIPAL          = "principal"
+	DECISION_RULE_SOURCE_MEMBERSHIP         = "membership"
+	DECISION_RULE_SOURCE_USER_STATUS        = "userStatus"
+	DECISION_RULE_SOURCE_TEAM_PERMISSION    = "teamPermission"
+	DECISION_RULE_SOURCE_UNIT_ROLE_RELATION = "unitRoleRelation"
+	DECISION_RULE_SOURCE_ROLE               = "role"
+)
+
+// reasons of the final verdict
+const (
+	DECISION_REASON_INVALID_PRINCIPAL       = "invalidPrincipal"
+	DECISION_REASON_NOT_TEAM_MEMBER         = "notTeamMember"
+	DECISION_REASON_INVALID_CHECK           = "invalidCheck"
+	DECISION_REASON_USER_UNAVAILABLE        = "userUnavailable"
+	DECISION_REASON_TEAM_PERMISSION_REVOKED = "teamPermissionRevoked"
+	DECISION_REASON_UNIT_DENIED             = "unitDenied"
+	DECISION_REASON_UNIT_ALLOWED            = "unitAllowed"
+	DECISION_REASON_ROLE_GRANTED            = "roleGranted"
+	DECISION_REASON_ROLE_NOT_GRANTED        = "roleNotGranted"
+)
+
+// Decision is the trace of an attribute check, the rules are recorded by AttributeGroup.check in evaluation order.
+type Decision struct {
+	Rules   []*model.AccessControlDecisionRule
+	Allowed bool
+	Reason  string
+}
+
+func NewDecision() *Decision {
+	return &Decision{
+		Rules: make([]*model.AccessControlDecisionRule, 0),
+	}
+}
+
+// NewDeniedDecision is for the request which can not reach attribute check, e.g. invalid principal or missing membership.
+func NewDeniedDecision(source string, reason string, detail string) *Decision {
+	decision := NewDecision()
+	decision.consider(source, true, false, detail)
+	decision.decide(false, reason)
+	return decision
+}
+
+// consider record the rule, the nil decision is the untraced check and records nothing.
+func (decision *Decision) consider(source string, matched bool, allowed bool, detail string) {
+	if decision == nil {
+		return
+	}
+	decision.Rules = append(decision.Rules, &model.AccessControlDecisionRule{
+		Source:  source,
+		Matched: matched,
+		Allowed: allowed,
+		Detail:  detail,
+	})
+}
+
+// considerf format the detail only when the decision is traced.
+func (decision *Decision) considerf(source string, matched bool, allowed bool, format string, args ...interface{}) {
+	if decision == nil {
+		return
+	}
+	decision.consider(source, matched, allowed, fmt.Sprintf(format, args...))
+}
+
+// decide settle the verdict and returns it.
+func (decision *Decision) decide(allowed bool, reason string) bool {
+	if decision != nil {
+		decision.Allowed = allowed
+		decision.Reason = reason
+	}
+	return allowed
+}
+
+// attributeName formats as DescribeAttribute, it is resolved only when the trace detail is formatted.
+type attributeName struct {
+	category  int
+	unitType  int
+	attribute int
+}
+
+func (name attributeName) String() string {
+	return DescribeAttribute(name.category, name.unitType, name.attribute)
+}
+
+// DescribeAttribute export the symbolic name of attribute, e.g. "manage.app.edit_app".
+func DescribeAttribute(category int, unitType int, attribute int) string {
+	categoryName, hit := AttributeCategoryNameMap[category]
+	if !hit {
+		categoryName = fmt.Sprintf("#%d", category)
+	}
+	unitTypeName, hit := reverseNameMap(PolicyUnitTypeNameMap)[unitType]
+	if !hit {
+		unitTypeName = fmt.Sprintf("#%d", unitType)
+	}
+	attributeName, hit := reverseNameMap(PolicyAttributeNameMap[categoryName])[attribute]
+	if !hit {
+		attributeName = fmt.Sprintf("#%d", attribute)
+	}
+	return categoryName + "." + unitTypeName + "." + attributeName
+}
+
+// IsAttributeDefined check the attribute is known by the policy under the category.
+func IsAttributeDefined(category int, attribute int) bool {
+	for _, definedAttribute := range PolicyAttributeNameMap[AttributeCategoryNameMap[category]] {
+		if definedAttribute == attribute {
+			return true
+		}
+	}
+	return false
+}
+
+func IsUnitTypeDefined(unitType int) bool {
+	_, hit := reverseNameMap(PolicyUnitTypeNameMap)[unitType]
+	return hit
+}
+
+// Explain trace the decision of CanAccess, CanDelete, CanManage and CanManageSpecial.
+func (attrg *AttributeGroup) Explain(category int, attribute int) *Decision {
+	decision := NewDecision()
+	attrg.check(category, attribute, true, decision)
+	return decision
+}
+
+// ExplainModifyRoleFromTo trace the decision of CanModifyRoleFromTo, unit-level grants are not considered by it.
+func (attrg *AttributeGroup) ExplainModifyRoleFromTo(fromRole, toRole int) *Decision {
+	decision := NewDecision()
+	attrg.modifyRoleFromTo(fromRole, toRole, decision)
+	return decision
+}
@@ -0,0 +1,85 @@
+package accesscontrol
+
+import (
+	"testing"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+)
+
+func newTestAttributeGroups(userRole int, unitType int, attribute int) []*AttributeGroup {
+	allow := model.NewUnitRoleRelation(1, unitType, testUnitID, userRole)
+	allow.AllowPermissions(model.UnitPermissions{ATTRIBUTE_CATEGORY_MANAGE: {attribute: true}})
+	deny := model.NewUnitRoleRelation(1, unitType, testUnitID, userRole)
+	deny.Effect = model.UNIT_ROLE_RELATION_EFFECT_DENY
+
+	teamWide := NewAttributeGroup(userRole, unitType)
+	revoked := NewAttributeGroup(userRole, unitType)
+	revoked.SetTeamPermission(&model.TeamPermission{})
+	suspended := NewAttributeGroup(userRole, unitType)
+	suspended.SetUserStatus(STATUS_SUSPEND)
+	allowed := NewAttributeGroup(userRole, unitType)
+	allowed.SetUnitID(testUnitID)
+	allowed.SetUnitRoleRelations([]*model.UnitRoleRelation{allow})
+	denied := NewAttributeGroup(userRole, unitType)
+	denied.SetUnitID(testUnitID)
+	denied.SetUnitRoleRelations([]*model.UnitRoleRelation{allow, deny})
+	return []*AttributeGroup{teamWide, revoked, suspended, allowed, denied}
+}
+
+// the explained verdict is the verdict of the check, they share the evaluation.
+func TestExplainAgreesWithCheck(t *testing.T) {
+	userRoles := []int{model.USER_ROLE_OWNER, model.USER_ROLE_ADMIN, model.USER_ROLE_EDITOR, model.USER_ROLE_VIEWER}
+	unitTypes := []int{UNIT_TYPE_TEAM_MEMBER, UNIT_TYPE_INVITE, UNIT_TYPE_APP}
+	checks := map[int]func(attrg *AttributeGroup, attribute int) bool{
+		ATTRIBUTE_CATEGORY_ACCESS:  (*AttributeGroup).CanAccess,
+		ATTRIBUTE_CATEGORY_DELETE:  (*AttributeGroup).CanDelete,
+		ATTRIBUTE_CATEGORY_MANAGE:  (*AttributeGroup).CanManage,
+		ATTRIBUTE_CATEGORY_SPECIAL: (*AttributeGroup).CanManageSpecial,
+	}
+	attributes := []int{ACTION_ACCESS_VIEW, ACTION_ACCESS_INVITE_BY_LINK, ACTION_MANAGE_ROLE, ACTION_MANAGE_REMOVE_MEMBER, ACTION_MANAGE_EDIT_APP, ACTION_DELETE}
+	for _, userRole := range userRoles {
+		for _, unitType := range unitTypes {
+			for _, attribute := range attributes {
+				for _, attrg := range newTestAttributeGroups(userRole, unitType, attribute) {
+					for category, check := range checks {
+						decision := attrg.Explain(category, attribute)
+						if want := check(attrg, attribute); decision.Allowed != want {
+							t.Errorf("role %d %s: explained %v (%s), checked %v", userRole, DescribeAttribute(category, unitType, attribute), decision.Allowed, decision.Reason, want)
+						}
+						if len(decision.Rules) == 0 || decision.Reason == "" {
+							t.Errorf("role %d %s: decision without trace", userRole, DescribeAttribute(category, unitType, attribute))
+						}
+					}
+					for _, toRole := range userRoles {
+						decision := attrg.ExplainModifyRoleFromTo(model.USER_ROLE_VIEWER, toRole)
+						if want := attrg.CanModifyRoleFromTo(model.USER_ROLE_VIEWER, toRole); decision.Allowed != want {
+							t.Errorf("role %d modify viewer to %d: explained %v (%s), checked %v", userRole, toRole, decision.Allowed, decision.Reason, want)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestExplainReason(t *testing.T) {
+	groups := newTestAttributeGroups(model.USER_ROLE_VIEWER, UNIT_TYPE_TEAM_MEMBER, ACTION_MANAGE_REMOVE_MEMBER)
+	cases := []struct {
+		name   string
+		attrg  *AttributeGroup
+		reason string
+	}{
+		{"team-wide", groups[0], DECISION_REASON_ROLE_GRANTED},
+		{"revoked", groups[1], DECISION_REASON_TEAM_PERMISSION_REVOKED},
+		{"suspended", groups[2], DECISION_REASON_USER_UNAVAILABLE},
+		{"unit allowed", groups[3], DECISION_REASON_UNIT_ALLOWED},
+		{"unit denied", groups[4], DECISION_REASON_UNIT_DENIED},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if decision := c.attrg.Explain(ATTRIBUTE_CATEGORY_MANAGE, ACTION_MANAGE_REMOVE_MEMBER); decision.Reason != c.reason {
+				t.Errorf("reason = %s, want %s", decision.Reason, c.reason)
+			}
+		})
+	}
+}
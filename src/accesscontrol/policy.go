@@ -0,0 +1,320 @@
+package accesscontrol
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"gopkg.in/yaml.v2"
+)
+
+// the built-in policy version, AttributeConfigList is the default policy.
+const POLICY_VERSION_BUILT_IN = "built-in"
+
+// symbolic names in policy document
+var PolicyRoleNameMap = map[string]int{
+	"anonymous": model.USER_ROLE_ANONYMOUS,
+	"owner":     model.USER_ROLE_OWNER,
+	"admin":     model.USER_ROLE_ADMIN,
+	"editor":    model.USER_ROLE_EDITOR,
+	"viewer":    model.USER_ROLE_VIEWER,
+}
+
+var PolicyUnitTypeNameMap = map[string]int{
+	"team":                      UNIT_TYPE_TEAM,
+	"team_member":               UNIT_TYPE_TEAM_MEMBER,
+	"user":                      UNIT_TYPE_USER,
+	"invite":                    UNIT_TYPE_INVITE,
+	"domain":                    UNIT_TYPE_DOMAIN,
+	"billing":                   UNIT_TYPE_BILLING,
+	"builder_dashboard":         UNIT_TYPE_BUILDER_DASHBOARD,
+	"app":                       UNIT_TYPE_APP,
+	"components":                UNIT_TYPE_COMPONENTS,
+	"resource":                  UNIT_TYPE_RESOURCE,
+	"action":                    UNIT_TYPE_ACTION,
+	"transformer":               UNIT_TYPE_TRANSFORMER,
+	"job":                       UNIT_TYPE_JOB,
+	"tree_states":               UNIT_TYPE_TREE_STATES,
+	"kv_states":                 UNIT_TYPE_KV_STATES,
+	"set_states":                UNIT_TYPE_SET_STATES,
+	"promote_codes":             UNIT_TYPE_PROMOTE_CODES,
+	"promote_code_usages":       UNIT_TYPE_PROMOTE_CODE_USAGES,
+	"roles":                     UNIT_TYPE_ROLES,
+	"user_role_relations":       UNIT_TYPE_USER_ROLE_RELATIONS,
+	"unit_role_relations":       UNIT_TYPE_UNIT_ROLE_RELATIONS,
+	"compensating_transactions": UNIT_TYPE_COMPENSATING_TRANSACTIONS,
+	"transaction_serials":       UNIT_TYPE_TRANSACTION_SERIALS,
+	"capacities":                UNIT_TYPE_CAPACITIES,
+	"drive":                     UNIT_TYPE_DRIVE,
+	"peripheral_service":        UNIT_TYPE_PERIPHERAL_SERVICE,
+}
+
+// map[categoryName][attributeName]attribute
+var PolicyAttributeNameMap = map[string]map[string]int{
+	"access": {
+		"view":            ACTION_ACCESS_VIEW,
+		"invite_by_link":  ACTION_ACCESS_INVITE_BY_LINK,
+		"invite_by_email": ACTION_ACCESS_INVITE_BY_EMAIL,
+		"invite_owner":    ACTION_ACCESS_INVITE_OWNER,
+		"invite_admin":    ACTION_ACCESS_INVITE_ADMIN,
+		"invite_editor":   ACTION_ACCESS_INVITE_EDITOR,
+		"invite_viewer":   ACTION_ACCESS_INVITE_VIEWER,
+	},
+	"delete": {
+		"delete":      ACTION_DELETE,
+		"team_domain": ACTION_DELETE_TEAM_DOMAIN,
+		"app_domain":  ACTION_DELETE_APP_DOMAIN,
+	},
+	"manage": {
+		"team_name":           ACTION_MANAGE_TEAM_NAME,
+		"team_icon":           ACTION_MANAGE_TEAM_ICON,
+		"team_config":         ACTION_MANAGE_TEAM_CONFIG,
+		"update_team_domain":  ACTION_MANAGE_UPDATE_TEAM_DOMAIN,
+		"remove_member":       ACTION_MANAGE_REMOVE_MEMBER,
+		"role":                ACTION_MANAGE_ROLE,
+		"role_from_owner":     ACTION_MANAGE_ROLE_FROM_OWNER,
+		"role_from_admin":     ACTION_MANAGE_ROLE_FROM_ADMIN,
+		"role_from_editor":    ACTION_MANAGE_ROLE_FROM_EDITOR,
+		"role_from_viewer":    ACTION_MANAGE_ROLE_FROM_VIEWER,
+		"role_to_owner":       ACTION_MANAGE_ROLE_TO_OWNER,
+		"role_to_admin":       ACTION_MANAGE_ROLE_TO_ADMIN,
+		"role_to_editor":      ACTION_MANAGE_ROLE_TO_EDITOR,
+		"role_to_viewer":      ACTION_MANAGE_ROLE_TO_VIEWER,
+		"rename_user":         ACTION_MANAGE_RENAME_USER,
+		"update_user_avatar":  ACTION_MANAGE_UPDATE_USER_AVATAR,
+		"config_invite":       ACTION_MANAGE_CONFIG_INVITE,
+		"invite_link":         ACTION_MANAGE_INVITE_LINK,
+		"team_domain":         ACTION_MANAGE_TEAM_DOMAIN,
+		"app_domain":          ACTION_MANAGE_APP_DOMAIN,
+		"payment":             ACTION_MANAGE_PAYMENT,
+		"payment_info":        ACTION_MANAGE_PAYMENT_INFO,
+		"dashboard_broadcast": ACTION_MANAGE_DASHBOARD_BROADCAST,
+		"create_app":          ACTION_MANAGE_CREATE_APP,
+		"edit_app":            ACTION_MANAGE_EDIT_APP,
+		"create_resource":     ACTION_MANAGE_CREATE_RESOURCE,
+		"edit_resource":       ACTION_MANAGE_EDIT_RESOURCE,
+		"create_action":       ACTION_MANAGE_CREATE_ACTION,
+		"edit_action":         ACTION_MANAGE_EDIT_ACTION,
+		"preview_action":      ACTION_MANAGE_PREVIEW_ACTION,
+		"run_action":          ACTION_MANAGE_RUN_ACTION,
+		"create_file":         ACTION_MANAGE_CREATE_FILE,
+		"edit_file":           ACTION_MANAGE_EDIT_FILE,
+		"create_sharelink":    ACTION_MANAGE_CREATE_SHARELINK,
+		"suspend_member":      ACTION_MANAGE_SUSPEND_MEMBER,
+		"approve_member":      ACTION_MANAGE_APPROVE_MEMBER,
+		"scim":                ACTION_MANAGE_SCIM,
+		"custom_role":         ACTION_MANAGE_CUSTOM_ROLE,
+		"unit_role_relation":  ACTION_MANAGE_UNIT_ROLE_RELATION,
+		"team_webhook":        ACTION_MANAGE_TEAM_WEBHOOK,
+	},
+	"special": {
+		"editor_and_viewer_can_invite_by_link_sw": ACTION_SPECIAL_EDITOR_AND_VIEWER_CAN_INVITE_BY_LINK_SW,
+		"transfer_owner":    ACTION_SPECIAL_TRANSFER_OWNER,
+		"invite_link_renew": ACTION_SPECIAL_INVITE_LINK_RENEW,
+		"release_app":       ACTION_SPECIAL_RELEASE_APP,
+		"generate_sql":      ACTION_SPECIAL_GENERATE_SQL,
+		"take_snapshot":     ACTOIN_SPECIAL_TAKE_SNAPSHOT,
+		"recover_snapshot":  ACTOIN_SPECIAL_RECOVER_SNAPSHOT,
+	},
+}
+
+// built-in roles from high to low, lower role can not have any attribute which higher role does not have.
+var PolicyRoleHierarchy = []int{model.USER_ROLE_OWNER, model.USER_ROLE_ADMIN, model.USER_ROLE_EDITOR, model.USER_ROLE_VIEWER}
+
+// PolicyDocument is the declarative form of policy.
+// roles.{role}.{category}.{unitType}: [attribute, ...]
+type PolicyDocument struct {
+	Version string                                    `yaml:"version" json:"version"`
+	Roles   map[string]map[string]map[string][]string `yaml:"roles" json:"roles"`
+}
+
+// Policy is the compiled policy, the Config is same format as AttributeConfigList.
+type Policy struct {
+	Version string
+	Config  map[int]map[int]map[int]map[int]bool
+}
+
+var currentPolicy atomic.Value
+
+func init() {
+	currentPolicy.Store(NewBuiltInPolicy())
+}
+
+func NewBuiltInPolicy() *Policy {
+	return &Policy{
+		Version: POLICY_VERSION_BUILT_IN,
+		Config:  AttributeConfigList,
+	}
+}
+
+// CurrentPolicy returns the policy in use, the policy should be treated as read-only.
+func CurrentPolicy() *Policy {
+	return currentPolicy.Load().(*Policy)
+}
+
+// SetPolicy swap the policy in use atomically.
+func SetPolicy(policy *Policy) {
+	currentPolicy.Store(policy)
+}
+
+func reverseNameMap(nameMap map[string]int) map[int]string {
+	ret := make(map[int]string, len(nameMap))
+	for name, id := range nameMap {
+		ret[id] = name
+	}
+	return ret
+}
+
+func policyCategoryByName(categoryName string) (int, bool) {
+	for category, name := range AttributeCategoryNameMap {
+		if name == categoryName {
+			return category, true
+		}
+	}
+	return 0, false
+}
+
+// LoadPolicyFile read, compile and validate policy document, both YAML and JSON format are supported.
+// Unknown fields and duplicate keys are rejected, they are usually typos which silently drop attributes.
+func LoadPolicyFile(path string) (*Policy, error) {
+	payload, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	doc := &PolicyDocument{}
+	if err := yaml.UnmarshalStrict(payload, doc); err != nil {
+		return nil, errors.New("parse policy file failed: " + err.Error())
+	}
+	policy, err := doc.Compile()
+	if err != nil {
+		return nil, err
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// Compile convert symbolic names to attribute config, unknown role, category, unit type and attribute are reported together.
+// All of the built-in roles in PolicyRoleHierarchy should be declared, a missing role is usually a truncated file.
+func (doc *PolicyDocument) Compile() (*Policy, error) {
+	if doc.Version == "" {
+		return nil, errors.New("policy version is required")
+	}
+	config := make(map[int]map[int]map[int]map[int]bool)
+	problems := make([]string, 0)
+	roleNames := reverseNameMap(PolicyRoleNameMap)
+	for _, role := range PolicyRoleHierarchy {
+		if _, hit := doc.Roles[roleNames[role]]; !hit {
+			problems = append(problems, fmt.Sprintf("missing role %q", roleNames[role]))
+		}
+	}
+	for roleName, categories := range doc.Roles {
+		role, hit := PolicyRoleNameMap[roleName]
+		if !hit {
+			problems = append(problems, fmt.Sprintf("unknown role %q", roleName))
+			continue
+		}
+		for categoryName, unitTypes := range categories {
+			category, hit := policyCategoryByName(categoryName)
+			if !hit {
+				problems = append(problems, fmt.Sprintf("%s: unknown category %q", roleName, categoryName))
+				continue
+			}
+			for unitTypeName, attributeNames := range unitTypes {
+				unitType, hit := PolicyUnitTypeNameMap[unitTypeName]
+				if !hit {
+					problems = append(problems, fmt.Sprintf("%s.%s: unknown unit type %q", roleName, categoryName, unitTypeName))
+					continue
+				}
+				if _, hit := config[category]; !hit {
+					config[category] = make(map[int]map[int]map[int]bool)
+				}
+				if _, hit := config[category][role]; !hit {
+					config[category][role] = make(map[int]map[int]bool)
+				}
+				attributes := make(map[int]bool, len(attributeNames))
+				for _, attributeName := range attributeNames {
+					attribute, hit := PolicyAttributeNameMap[categoryName][attributeName]
+					if !hit {
+						problems = append(problems, fmt.Sprintf("%s.%s.%s: unknown attribute %q", roleName, categoryName, unitTypeName, attributeName))
+						continue
+					}
+					attributes[attribute] = true
+				}
+				config[category][role][unitType] = attributes
+			}
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, errors.New("invalid policy: " + strings.Join(problems, "; "))
+	}
+	return &Policy{Version: doc.Version, Config: config}, nil
+}
+
+// Validate check privilege inversion, e.g. viewer can do something but editor can not.
+func (policy *Policy) Validate() error {
+	problems := make([]string, 0)
+	roleNames := reverseNameMap(PolicyRoleNameMap)
+	unitTypeNames := reverseNameMap(PolicyUnitTypeNameMap)
+	for i := 1; i < len(PolicyRoleHierarchy); i++ {
+		higherRole, lowerRole := PolicyRoleHierarchy[i-1], PolicyRoleHierarchy[i]
+		for category, roles := range policy.Config {
+			attributeNames := reverseNameMap(PolicyAttributeNameMap[AttributeCategoryNameMap[category]])
+			for unitType, attributes := range roles[lowerRole] {
+				for attribute, status := range attributes {
+					if status && !roles[higherRole][unitType][attribute] {
+						problems = append(problems, fmt.Sprintf("%s has %s.%s.%s but %s does not", roleNames[lowerRole], AttributeCategoryNameMap[category], unitTypeNames[unitType], attributeNames[attribute], roleNames[higherRole]))
+					}
+				}
+			}
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return errors.New("privilege inversion in policy: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// ExportDocument convert policy to the declarative form, names are sorted for stable output.
+func (policy *Policy) ExportDocument() *PolicyDocument {
+	doc := &PolicyDocument{
+		Version: policy.Version,
+		Roles:   make(map[string]map[string]map[string][]string),
+	}
+	roleNames := reverseNameMap(PolicyRoleNameMap)
+	unitTypeNames := reverseNameMap(PolicyUnitTypeNameMap)
+	for category, roles := range policy.Config {
+		categoryName := AttributeCategoryNameMap[category]
+		attributeNames := reverseNameMap(PolicyAttributeNameMap[categoryName])
+		for role, unitTypes := range roles {
+			roleName := roleNames[role]
+			for unitType, attributes := range unitTypes {
+				names := make([]string, 0, len(attributes))
+				for attribute, status := range attributes {
+					if status {
+						names = append(names, attributeNames[attribute])
+					}
+				}
+				if len(names) == 0 {
+					continue
+				}
+				sort.Strings(names)
+				if _, hit := doc.Roles[roleName]; !hit {
+					doc.Roles[roleName] = make(map[string]map[string][]string)
+				}
+				if _, hit := doc.Roles[roleName][categoryName]; !hit {
+					doc.Roles[roleName][categoryName] = make(map[string][]string)
+				}
+				doc.Roles[roleName][categoryName][unitTypeNames[unitType]] = names
+			}
+		}
+	}
+	return doc
+}
@@ -0,0 +1,133 @@
+package accesscontrol
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+)
+
+// PolicyChange is an attribute added to or removed from a role on a unit type.
+// an added attribute is an escalation when the role is anonymous, or only higher roles had it in the old policy, e.g. viewer gaining delete.
+type PolicyChange struct {
+	Role       string `json:"role"`
+	UnitType   string `json:"unitType"`
+	Category   string `json:"category"`
+	Attribute  string `json:"attribute"`
+	Added      bool   `json:"added"`
+	Escalation bool   `json:"escalation"`
+	roleOrder  int
+}
+
+type PolicyDiff struct {
+	FromVersion string          `json:"fromVersion"`
+	ToVersion   string          `json:"toVersion"`
+	Changes     []*PolicyChange `json:"changes"`
+}
+
+// DiffPolicy compare two policies, changes are sorted by role from high to low, then unit type, category and attribute.
+func DiffPolicy(from *Policy, to *Policy) *PolicyDiff {
+	diff := &PolicyDiff{
+		FromVersion: from.Version,
+		ToVersion:   to.Version,
+		Changes:     make([]*PolicyChange, 0),
+	}
+	collect := func(base *Policy, other *Policy, added bool) {
+		for category, roles := range base.Config {
+			for role, unitTypes := range roles {
+				for unitType, attributes := range unitTypes {
+					for attribute, status := range attributes {
+						if !status || other.Config[category][role][unitType][attribute] {
+							continue
+						}
+						change := newPolicyChange(category, role, unitType, attribute, added)
+						if added {
+							change.Escalation = isPolicyEscalation(from, category, role, unitType, attribute)
+						}
+						diff.Changes = append(diff.Changes, change)
+					}
+				}
+			}
+		}
+	}
+	collect(to, from, true)
+	collect(from, to, false)
+	sort.Slice(diff.Changes, func(i, j int) bool {
+		a, b := diff.Changes[i], diff.Changes[j]
+		if a.roleOrder != b.roleOrder {
+			return a.roleOrder < b.roleOrder
+		}
+		if a.UnitType != b.UnitType {
+			return a.UnitType < b.UnitType
+		}
+		if a.Category != b.Category {
+			return a.Category < b.Category
+		}
+		if a.Attribute != b.Attribute {
+			return a.Attribute < b.Attribute
+		}
+		return a.Added && !b.Added
+	})
+	return diff
+}
+
+func (diff *PolicyDiff) IsEmpty() bool {
+	return len(diff.Changes) == 0
+}
+
+func (diff *PolicyDiff) CountChanges() (int, int, int) {
+	added, removed, escalations := 0, 0, 0
+	for _, change := range diff.Changes {
+		if change.Added {
+			added++
+		} else {
+			removed++
+		}
+		if change.Escalation {
+			escalations++
+		}
+	}
+	return added, removed, escalations
+}
+
+func newPolicyChange(category int, role int, unitType int, attribute int, added bool) *PolicyChange {
+	categoryName := AttributeCategoryNameMap[category]
+	return &PolicyChange{
+		Role:      nameOrNumber(reverseNameMap(PolicyRoleNameMap), role),
+		UnitType:  nameOrNumber(reverseNameMap(PolicyUnitTypeNameMap), unitType),
+		Category:  categoryName,
+		Attribute: nameOrNumber(reverseNameMap(PolicyAttributeNameMap[categoryName]), attribute),
+		Added:     added,
+		roleOrder: policyRoleOrder(role),
+	}
+}
+
+// policyRoleOrder is the index in role hierarchy, anonymous and unknown roles go last.
+func policyRoleOrder(role int) int {
+	for i, hierarchyRole := range PolicyRoleHierarchy {
+		if hierarchyRole == role {
+			return i
+		}
+	}
+	return len(PolicyRoleHierarchy)
+}
+
+func isPolicyEscalation(from *Policy, category int, role int, unitType int, attribute int) bool {
+	if role == model.USER_ROLE_ANONYMOUS {
+		return true
+	}
+	order := policyRoleOrder(role)
+	for i := 0; i < order && i < len(PolicyRoleHierarchy); i++ {
+		if from.Config[category][PolicyRoleHierarchy[i]][unitType][attribute] {
+			return true
+		}
+	}
+	return false
+}
+
+func nameOrNumber(names map[int]string, id int) string {
+	if name, hit := names[id]; hit {
+		return name
+	}
+	return fmt.Sprintf("#%d", id)
+}
@@ -0,0 +1,74 @@
+package accesscontrol
+
+import (
+	"fmt"
+	"testing"
+)
+
+// newTestPolicies feedback the policies before and after a change, which
+// grants the owner a new attribute, the admin an attribute of owner, the viewer the delete of editor,
+// the anonymous user a new attribute, and revokes the delete of editor.
+func newTestPolicies(t *testing.T) (*Policy, *Policy) {
+	t.Helper()
+	from, err := (&PolicyDocument{
+		Version: "1",
+		Roles: map[string]map[string]map[string][]string{
+			"owner":  {"manage": {"team": {"team_name", "team_icon"}}, "delete": {"app": {"delete"}}},
+			"admin":  {"manage": {"team": {"team_name"}}, "delete": {"app": {"delete"}}},
+			"editor": {"delete": {"app": {"delete"}}},
+			"viewer": {},
+		},
+	}).Compile()
+	if err != nil {
+		t.Fatalf("compile policy failed: %v", err)
+	}
+	to, err := (&PolicyDocument{
+		Version: "2",
+		Roles: map[string]map[string]map[string][]string{
+			"anonymous": {"access": {"app": {"view"}}},
+			"owner":     {"access": {"app": {"view"}}, "manage": {"team": {"team_name", "team_icon"}}, "delete": {"app": {"delete"}}},
+			"admin":     {"manage": {"team": {"team_name", "team_icon"}}, "delete": {"app": {"delete"}}},
+			"editor":    {},
+			"viewer":    {"delete": {"app": {"delete"}}},
+		},
+	}).Compile()
+	if err != nil {
+		t.Fatalf("compile policy failed: %v", err)
+	}
+	return from, to
+}
+
+func TestDiffPolicy(t *testing.T) {
+	from, to := newTestPolicies(t)
+	diff := DiffPolicy(from, to)
+	if diff.FromVersion != "1" || diff.ToVersion != "2" {
+		t.Errorf("versions = %s, %s, want 1, 2", diff.FromVersion, diff.ToVersion)
+	}
+	changes := make([]string, 0, len(diff.Changes))
+	for _, change := range diff.Changes {
+		changes = append(changes, fmt.Sprintf("%s %s.%s.%s added=%v escalation=%v", change.Role, change.Category, change.UnitType, change.Attribute, change.Added, change.Escalation))
+	}
+	// sorted by role from high to low, anonymous goes last
+	want := []string{
+		"owner access.app.view added=true escalation=false",
+		"admin manage.team.team_icon added=true escalation=true",
+		"editor delete.app.delete added=false escalation=false",
+		"viewer delete.app.delete added=true escalation=true",
+		"anonymous access.app.view added=true escalation=true",
+	}
+	if fmt.Sprint(changes) != fmt.Sprint(want) {
+		t.Errorf("changes = %q, want %q", changes, want)
+	}
+	if added, removed, escalations := diff.CountChanges(); added != 4 || removed != 1 || escalations != 3 {
+		t.Errorf("count = %d added, %d removed, %d escalations, want 4, 1, 3", added, removed, escalations)
+	}
+
+	// the reverse change grants the editor back the delete which higher roles have, it is an escalation too
+	reverse := DiffPolicy(to, from)
+	if added, removed, escalations := reverse.CountChanges(); added != 1 || removed != 4 || escalations != 1 {
+		t.Errorf("reverse count = %d added, %d removed, %d escalations, want 1, 4, 1", added, removed, escalations)
+	}
+	if !DiffPolicy(from, from).IsEmpty() {
+		t.Error("diff of the same policy is not empty")
+	}
+}
@@ -0,0 +1,124 @@
+package accesscontrol
+
+import (
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+)
+
+// DecisionReplay is a recorded decision whose verdict changed under the replayed policy.
+type DecisionReplay struct {
+	UserID     string                    `json:"userID"`
+	Role       string                    `json:"role"`
+	Check      *model.AccessControlCheck `json:"check"`
+	Attribute  string                    `json:"attribute"`
+	WasAllowed bool                      `json:"wasAllowed"`
+	NowAllowed bool                      `json:"nowAllowed"`
+}
+
+type PolicyReplayResult struct {
+	Replayed int               `json:"replayed"`
+	Skipped  int               `json:"skipped"`
+	Changed  []*DecisionReplay `json:"changed"`
+}
+
+// ReplayDecisions evaluate the recorded explain decisions under policy.
+// only the decisions made by built-in role are replayed, decisions made by membership, user status,
+// team permission toggles, unit role relations or custom roles do not depend on policy and are skipped.
+// the policy in use is swapped during replay, so it must not be called by a serving process.
+func ReplayDecisions(policy *Policy, decisions []*model.ExplainAccessControlResponse) *PolicyReplayResult {
+	previousPolicy := CurrentPolicy()
+	SetPolicy(policy)
+	defer SetPolicy(previousPolicy)
+
+	result := &PolicyReplayResult{
+		Changed: make([]*DecisionReplay, 0),
+	}
+	for _, decision := range decisions {
+		allowed, replayable := replayDecision(decision)
+		if !replayable {
+			result.Skipped++
+			continue
+		}
+		result.Replayed++
+		if allowed == decision.Allowed {
+			continue
+		}
+		replay := &DecisionReplay{
+			UserID:     decision.Principal.UserID,
+			Role:       nameOrNumber(reverseNameMap(PolicyRoleNameMap), decision.Membership.UserRole),
+			Check:      decision.Check,
+			WasAllowed: decision.Allowed,
+			NowAllowed: allowed,
+		}
+		if category, unitType, attribute, err := exportDecisionCheck(decision.Check); err == nil {
+			replay.Attribute = DescribeAttribute(category, unitType, attribute)
+		}
+		result.Changed = append(result.Changed, replay)
+	}
+	return result
+}
+
+func replayDecision(decision *model.ExplainAccessControlResponse) (bool, bool) {
+	if decision.Check == nil || decision.Membership == nil || decision.Principal == nil {
+		return false, false
+	}
+	if decision.Reason != DECISION_REASON_ROLE_GRANTED && decision.Reason != DECISION_REASON_ROLE_NOT_GRANTED {
+		return false, false
+	}
+	role := decision.Membership.UserRole
+	if role != model.USER_ROLE_ANONYMOUS && !model.IsSystemRoleID(role) {
+		return false, false
+	}
+	unitType, errInExportUnitType := decision.Check.ExportUnitType()
+	attributeID, errInExportAttributeID := decision.Check.ExportAttributeID()
+	if errInExportUnitType != nil || errInExportAttributeID != nil {
+		return false, false
+	}
+	fromID, toID := 0, 0
+	if decision.Check.IsModify() {
+		var err error
+		if fromID, toID, err = decision.Check.ExportFromIDAndToID(); err != nil {
+			return false, false
+		}
+	}
+	allowed, err := NewAttributeGroup(role, unitType).Evaluate(decision.Check.Category, attributeID, fromID, toID)
+	if err != nil {
+		return false, false
+	}
+	return allowed, true
+}
+
+// exportDecisionCheck resolve the attribute category of check for display, canModify is a manage check.
+func exportDecisionCheck(check *model.AccessControlCheck) (int, int, int, error) {
+	unitType, err := check.ExportUnitType()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	attribute, err := check.ExportAttributeID()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	category := ATTRIBUTE_CATEGORY_MANAGE
+	switch check.Category {
+	case model.ACCESS_CONTROL_CHECK_CATEGORY_ACCESS:
+		category = ATTRIBUTE_CATEGORY_ACCESS
+	case model.ACCESS_CONTROL_CHECK_CATEGORY_DELETE:
+		category = ATTRIBUTE_CATEGORY_DELETE
+	case model.ACCESS_CONTROL_CHECK_CATEGORY_MANAGE_SPECIAL:
+		category = ATTRIBUTE_CATEGORY_SPECIAL
+	}
+	return category, unitType, attribute, nil
+}
+
+// CountEscalations count the decisions which were denied but are allowed now.
+func (result *PolicyReplayResult) CountEscalations() int {
+	if result == nil {
+		return 0
+	}
+	escalations := 0
+	for _, changed := range result.Changed {
+		if changed.NowAllowed {
+			escalations++
+		}
+	}
+	return escalations
+}
@@ -0,0 +1,60 @@
+package accesscontrol
+
+import (
+	"testing"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/idconvertor"
+)
+
+func newTestDecision(userRole int, category string, unitType int, attribute int, allowed bool, reason string) *model.ExplainAccessControlResponse {
+	check := &model.AccessControlCheck{
+		Category:    category,
+		UnitType:    idconvertor.ConvertIntToString(unitType),
+		UnitID:      idconvertor.ConvertIntToString(testUnitID),
+		AttributeID: idconvertor.ConvertIntToString(attribute),
+	}
+	decision := model.NewExplainAccessControlResponse(model.TEAM_DEFAULT_ID, check)
+	decision.Principal.UserID = idconvertor.ConvertIntToString(userRole)
+	decision.Membership.UserRole = userRole
+	decision.Allowed = allowed
+	decision.Reason = reason
+	return decision
+}
+
+func TestReplayDecisions(t *testing.T) {
+	_, to := newTestPolicies(t)
+	malformed := newTestDecision(model.USER_ROLE_VIEWER, model.ACCESS_CONTROL_CHECK_CATEGORY_DELETE, UNIT_TYPE_APP, ACTION_DELETE, false, DECISION_REASON_ROLE_NOT_GRANTED)
+	malformed.Check.UnitType = "malformed"
+	decisions := []*model.ExplainAccessControlResponse{
+		newTestDecision(model.USER_ROLE_VIEWER, model.ACCESS_CONTROL_CHECK_CATEGORY_DELETE, UNIT_TYPE_APP, ACTION_DELETE, false, DECISION_REASON_ROLE_NOT_GRANTED),
+		newTestDecision(model.USER_ROLE_EDITOR, model.ACCESS_CONTROL_CHECK_CATEGORY_DELETE, UNIT_TYPE_APP, ACTION_DELETE, true, DECISION_REASON_ROLE_GRANTED),
+		newTestDecision(model.USER_ROLE_OWNER, model.ACCESS_CONTROL_CHECK_CATEGORY_MANAGE, UNIT_TYPE_TEAM, ACTION_MANAGE_TEAM_NAME, true, DECISION_REASON_ROLE_GRANTED),
+		// the decisions which do not depend on policy
+		newTestDecision(model.USER_ROLE_VIEWER, model.ACCESS_CONTROL_CHECK_CATEGORY_DELETE, UNIT_TYPE_APP, ACTION_DELETE, false, DECISION_REASON_UNIT_DENIED),
+		newTestDecision(model.ROLE_ID_SYSTEM_MAX+1, model.ACCESS_CONTROL_CHECK_CATEGORY_DELETE, UNIT_TYPE_APP, ACTION_DELETE, false, DECISION_REASON_ROLE_NOT_GRANTED),
+		malformed,
+	}
+
+	current := CurrentPolicy()
+	result := ReplayDecisions(to, decisions)
+	if CurrentPolicy() != current {
+		t.Error("the policy in use is not restored after replay")
+	}
+	if result.Replayed != 3 || result.Skipped != 3 {
+		t.Errorf("replayed %d, skipped %d, want 3, 3", result.Replayed, result.Skipped)
+	}
+	if len(result.Changed) != 2 {
+		t.Fatalf("changed = %d, want 2", len(result.Changed))
+	}
+	gained, lost := result.Changed[0], result.Changed[1]
+	if gained.Role != "viewer" || gained.WasAllowed || !gained.NowAllowed || gained.Attribute != "delete.app.delete" {
+		t.Errorf("viewer decision = %+v, want delete.app.delete gained", gained)
+	}
+	if lost.Role != "editor" || !lost.WasAllowed || lost.NowAllowed {
+		t.Errorf("editor decision = %+v, want delete.app.delete lost", lost)
+	}
+	if escalations := result.CountEscalations(); escalations != 1 {
+		t.Errorf("escalations = %d, want 1", escalations)
+	}
+}
@@ -0,0 +1,196 @@
+package accesscontrol
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"go.uber.org/zap"
+)
+
+const testPolicyRoles = `
+roles:
+  owner:
+    manage:
+      team: [team_name, team_icon]
+  admin:
+    manage:
+      team: [team_name]
+  editor: {}
+  viewer: {}
+`
+
+// writeTestPolicyFile write the policy file with a modification time after the previous one.
+func writeTestPolicyFile(t *testing.T, path string, content string, modifiedAt time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("write policy file failed: %v", err)
+	}
+	if err := os.Chtimes(path, modifiedAt, modifiedAt); err != nil {
+		t.Fatalf("touch policy file failed: %v", err)
+	}
+}
+
+func TestLoadPolicyFileExample(t *testing.T) {
+	policy, err := LoadPolicyFile(filepath.Join("..", "..", "DOCUMENTS", "access-control-policy.example.yaml"))
+	if err != nil {
+		t.Fatalf("load example policy failed: %v", err)
+	}
+	if diff := DiffPolicy(NewBuiltInPolicy(), policy); !diff.IsEmpty() {
+		t.Errorf("example policy differs from the built-in policy: %+v", diff)
+	}
+}
+
+func TestLoadPolicyFile(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"valid", `version: "2"` + testPolicyRoles, ""},
+		{"json", `{"version": "2", "roles": {"owner": {}, "admin": {}, "editor": {}, "viewer": {}}}`, ""},
+		{"unknown field", `version: "2"
+description: typo` + testPolicyRoles, "parse policy file failed"},
+		{"duplicate key", `version: "2"
+version: "3"` + testPolicyRoles, "parse policy file failed"},
+		{"missing roles", `version: "2"
+roles:
+  owner: {}
+  admin: {}`, `missing role "editor"; missing role "viewer"`},
+		{"empty", ``, "policy version is required"},
+		{"privilege inversion", `version: "2"
+roles:
+  owner: {}
+  admin: {}
+  editor: {}
+  viewer:
+    manage:
+      team: [team_name]`, `privilege inversion in policy: viewer has manage.team.team_name but editor does not`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "policy.yaml")
+			writeTestPolicyFile(t, path, c.content, time.Now())
+			policy, err := LoadPolicyFile(path)
+			if c.wantErr == "" {
+				if err != nil {
+					t.Fatalf("load policy failed: %v", err)
+				}
+				if policy.Version != "2" {
+					t.Errorf("version = %q, want 2", policy.Version)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+				t.Errorf("err = %v, want %q", err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicyDocumentCompile(t *testing.T) {
+	doc := &PolicyDocument{
+		Version: "2",
+		Roles: map[string]map[string]map[string][]string{
+			"owner":     {"manage": {"team": {"team_name", "fly"}}, "dance": {"team": {"team_name"}}},
+			"admin":     {"manage": {"spaceship": {"team_name"}}},
+			"editor":    {},
+			"viewer":    {},
+			"superuser": {},
+		},
+	}
+	_, err := doc.Compile()
+	want := `invalid policy: admin.manage: unknown unit type "spaceship"; owner.manage.team: unknown attribute "fly"; owner: unknown category "dance"; unknown role "superuser"`
+	if err == nil || err.Error() != want {
+		t.Fatalf("err = %v, want %s", err, want)
+	}
+
+	doc = &PolicyDocument{
+		Version: "2",
+		Roles: map[string]map[string]map[string][]string{
+			"owner":  {"manage": {"team": {"team_name", "team_icon"}}},
+			"admin":  {"manage": {"team": {"team_name"}}},
+			"editor": {},
+			"viewer": {},
+		},
+	}
+	policy, err := doc.Compile()
+	if err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+	manage := policy.Config[ATTRIBUTE_CATEGORY_MANAGE]
+	if !manage[model.USER_ROLE_OWNER][UNIT_TYPE_TEAM][ACTION_MANAGE_TEAM_ICON] || manage[model.USER_ROLE_ADMIN][UNIT_TYPE_TEAM][ACTION_MANAGE_TEAM_ICON] {
+		t.Errorf("compiled config = %v", manage)
+	}
+	if err := policy.Validate(); err != nil {
+		t.Errorf("validate failed: %v", err)
+	}
+}
+
+func TestBuiltInPolicyRoundTrip(t *testing.T) {
+	builtIn := NewBuiltInPolicy()
+	if err := builtIn.Validate(); err != nil {
+		t.Fatalf("built-in policy is invalid: %v", err)
+	}
+	policy, err := builtIn.ExportDocument().Compile()
+	if err != nil {
+		t.Fatalf("compile exported built-in policy failed: %v", err)
+	}
+	if diff := DiffPolicy(builtIn, policy); !diff.IsEmpty() {
+		t.Errorf("round trip changed the policy: %+v", diff)
+	}
+}
+
+func TestPolicyWatcherReload(t *testing.T) {
+	t.Cleanup(func() { SetPolicy(NewBuiltInPolicy()) })
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	modifiedAt := time.Now().Add(-time.Minute)
+	writeTestPolicyFile(t, path, `version: "2"`+testPolicyRoles, modifiedAt)
+	reloaded := make([]string, 0)
+	watcher := NewPolicyWatcher(path, time.Second, func(policy *Policy) { reloaded = append(reloaded, policy.Version) }, zap.NewNop().Sugar())
+
+	if err := watcher.Load(); err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if CurrentPolicy().Version != "2" {
+		t.Fatalf("version = %q, want 2", CurrentPolicy().Version)
+	}
+
+	// not modified, nothing to reload
+	watcher.Reload()
+	if len(reloaded) != 1 {
+		t.Errorf("reloaded = %v, want only the first load", reloaded)
+	}
+
+	// the broken and the incomplete policy files are ignored
+	for _, content := range []string{"version: [", `version: "3"` + "\nroles:\n  owner: {}\n"} {
+		modifiedAt = modifiedAt.Add(time.Second)
+		writeTestPolicyFile(t, path, content, modifiedAt)
+		watcher.Reload()
+		if CurrentPolicy().Version != "2" {
+			t.Errorf("version = %q after broken reload, want 2", CurrentPolicy().Version)
+		}
+	}
+
+	// the deleted policy file is ignored too
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("remove policy file failed: %v", err)
+	}
+	watcher.Reload()
+	if CurrentPolicy().Version != "2" {
+		t.Errorf("version = %q after policy file removed, want 2", CurrentPolicy().Version)
+	}
+
+	modifiedAt = modifiedAt.Add(time.Second)
+	writeTestPolicyFile(t, path, `version: "4"`+testPolicyRoles, modifiedAt)
+	watcher.Reload()
+	if CurrentPolicy().Version != "4" {
+		t.Errorf("version = %q, want 4", CurrentPolicy().Version)
+	}
+	if strings.Join(reloaded, ",") != "2,4" {
+		t.Errorf("reloaded = %v, want [2 4]", reloaded)
+	}
+}
@@ -0,0 +1,77 @@
+package accesscontrol
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// PolicyWatcher reload the policy file when it has been modified, the invalid policy file will be ignored and the policy in use will be kept.
+type PolicyWatcher struct {
+	logger         *zap.SugaredLogger
+	Path           string
+	ReloadInterval time.Duration
+	OnReload       func(policy *Policy)
+	lastModifiedAt time.Time
+}
+
+func NewPolicyWatcher(path string, reloadInterval time.Duration, onReload func(policy *Policy), logger *zap.SugaredLogger) *PolicyWatcher {
+	return &PolicyWatcher{
+		logger:         logger,
+		Path:           path,
+		ReloadInterval: reloadInterval,
+		OnReload:       onReload,
+	}
+}
+
+// Load load the policy file and swap the policy in use.
+func (w *PolicyWatcher) Load() error {
+	fileInfo, errInStat := os.Stat(w.Path)
+	if errInStat != nil {
+		return errInStat
+	}
+	policy, errInLoad := LoadPolicyFile(w.Path)
+	if errInLoad != nil {
+		return errInLoad
+	}
+	w.lastModifiedAt = fileInfo.ModTime()
+	SetPolicy(policy)
+	if w.OnReload != nil {
+		w.OnReload(policy)
+	}
+	w.logger.Infow("access control policy loaded", "path", w.Path, "version", policy.Version)
+	return nil
+}
+
+// Reload load the policy file only when it has been modified since last load.
+func (w *PolicyWatcher) Reload() {
+	fileInfo, err := os.Stat(w.Path)
+	if err != nil {
+		w.logger.Errorw("stat access control policy file failed", "path", w.Path, "err", err)
+		return
+	}
+	if fileInfo.ModTime().Equal(w.lastModifiedAt) {
+		return
+	}
+	if err := w.Load(); err != nil {
+		// avoid logging the same broken file every tick
+		w.lastModifiedAt = fileInfo.ModTime()
+		w.logger.Errorw("reload access control policy failed, keep using the previous policy", "path", w.Path, "err", err)
+	}
+}
+
+// Run reload policy file periodically, it blocks until ctx is done.
+func (w *PolicyWatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.ReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.Reload()
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package accesscontrol
+
+import (
+	"errors"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+)
+
+// RoleProvider resolves the attribute set of a role by role id.
+type RoleProvider interface {
+	RetrieveRolePermissions(roleID int) (model.RolePermissions, error)
+}
+
+// the default provider only knows built-in roles, it will be replaced by the role store in startup.
+var roleProvider RoleProvider = NewStaticRoleProvider()
+
+// SetRoleProvider should be called in startup before serving any request.
+func SetRoleProvider(provider RoleProvider) {
+	roleProvider = provider
+}
+
+// InvalidateRole drop the cached attribute set of the role if the role provider has a cache.
+func InvalidateRole(roleID int) {
+	if cachedProvider, ok := roleProvider.(interface{ Invalidate(roleID int) }); ok {
+		cachedProvider.Invalidate(roleID)
+	}
+}
+
+// RetrieveRolePermissions resolve the attribute set of a role.
+// anonymous is not a role of any team, it always use the built-in config.
+// system roles fallback to the built-in config when the role provider failed, and custom roles got nothing.
+func RetrieveRolePermissions(roleID int) model.RolePermissions {
+	if roleID == model.USER_ROLE_ANONYMOUS {
+		return ExportBuiltInRolePermissions(roleID)
+	}
+	permissions, err := roleProvider.RetrieveRolePermissions(roleID)
+	if err != nil {
+		if model.IsSystemRoleID(roleID) {
+			return ExportBuiltInRolePermissions(roleID)
+		}
+		return model.RolePermissions{}
+	}
+	return permissions
+}
+
+// ExportBuiltInRolePermissions export attribute set of the built-in role from the policy in use.
+func ExportBuiltInRolePermissions(roleID int) model.RolePermissions {
+	permissions := model.RolePermissions{}
+	for category, roles := range CurrentPolicy().Config {
+		unitTypes, hit := roles[roleID]
+		if !hit {
+			continue
+		}
+		permissions[category] = unitTypes
+	}
+	return permissions
+}
+
+// ExportSystemRoles export the built-in roles for seeding role store.
+func ExportSystemRoles() []*model.Role {
+	roles := make([]*model.Role, 0, model.ROLE_ID_SYSTEM_MAX)
+	for roleID := model.ROLE_ID_SYSTEM_MIN; roleID <= model.ROLE_ID_SYSTEM_MAX; roleID++ {
+		roles = append(roles, model.NewSystemRole(roleID, ExportBuiltInRolePermissions(roleID)))
+	}
+	return roles
+}
+
+// CanGrantRolePermissions check all granted attributes in permissions are also granted to the grantor role,
+// so nobody can create a role which has more permission than the grantor.
+func CanGrantRolePermissions(grantorRole int, permissions model.RolePermissions) bool {
+	grantorPermissions := RetrieveRolePermissions(grantorRole)
+	for category, unitTypes := range permissions {
+		for unitType, attributes := range unitTypes {
+			for attribute, status := range attributes {
+				if status && !grantorPermissions[category][unitType][attribute] {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// ResolveRoleLevel returns the built-in role a role is treated as when it is invited or assigned.
+// a custom role takes the lowest built-in role which covers its attributes, owner when none covers it.
+func ResolveRoleLevel(roleID int) int {
+	if roleID <= model.ROLE_ID_SYSTEM_MAX {
+		return roleID
+	}
+	permissions := RetrieveRolePermissions(roleID)
+	for level := model.ROLE_ID_SYSTEM_MAX; level > model.ROLE_ID_SYSTEM_MIN; level-- {
+		if CanGrantRolePermissions(level, permissions) {
+			return level
+		}
+	}
+	return model.ROLE_ID_SYSTEM_MIN
+}
+
+type StaticRoleProvider struct{}
+
+func NewStaticRoleProvider() *StaticRoleProvider {
+	return &StaticRoleProvider{}
+}
+
+func (p *StaticRoleProvider) RetrieveRolePermissions(roleID int) (model.RolePermissions, error) {
+	if !model.IsSystemRoleID(roleID) {
+		return nil, errors.New("custom role is not supported by static role provider")
+	}
+	return ExportBuiltInRolePermissions(roleID), nil
+}
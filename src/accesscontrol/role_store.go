@@ -0,0 +1,84 @@
+package accesscontrol
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// role changes made by other instance will take effect after the cache expired.
+const ROLE_STORE_CACHE_TTL = 30 * time.Second
+
+type roleStoreEntry struct {
+	permissions model.RolePermissions
+	expiredAt   time.Time
+}
+
+// RoleStore is a database backed RoleProvider with in-memory cache.
+type RoleStore struct {
+	logger      *zap.SugaredLogger
+	roleStorage *model.RoleStorage
+	ttl         time.Duration
+	mutex       sync.RWMutex
+	cache       map[int]*roleStoreEntry
+}
+
+func NewRoleStore(roleStorage *model.RoleStorage, logger *zap.SugaredLogger) *RoleStore {
+	return &RoleStore{
+		logger:      logger,
+		roleStorage: roleStorage,
+		ttl:         ROLE_STORE_CACHE_TTL,
+		cache:       make(map[int]*roleStoreEntry),
+	}
+}
+
+// SeedSystemRoles write the built-in roles to database, system roles always follow the policy in use.
+func SeedSystemRoles(roleStorage *model.RoleStorage) error {
+	return roleStorage.UpsertSystemRoles(ExportSystemRoles())
+}
+
+// RetrieveRolePermissions resolve system roles from the policy in use, so the reloaded policy takes effect immediately.
+func (s *RoleStore) RetrieveRolePermissions(roleID int) (model.RolePermissions, error) {
+	if model.IsSystemRoleID(roleID) {
+		return ExportBuiltInRolePermissions(roleID), nil
+	}
+	s.mutex.RLock()
+	entry, hit := s.cache[roleID]
+	s.mutex.RUnlock()
+	if hit && time.Now().Before(entry.expiredAt) {
+		return entry.permissions, nil
+	}
+
+	// retrieve from database
+	role, err := s.roleStorage.RetrieveByID(roleID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.Invalidate(roleID)
+			return nil, err
+		}
+		// database unavailable, keep the expired attribute set working
+		if hit {
+			s.logger.Warnw("retrieve role failed, use expired role cache", "roleID", roleID, "err", err)
+			return entry.permissions, nil
+		}
+		return nil, err
+	}
+	permissions := role.ExportPermissions()
+	s.mutex.Lock()
+	s.cache[roleID] = &roleStoreEntry{
+		permissions: permissions,
+		expiredAt:   time.Now().Add(s.ttl),
+	}
+	s.mutex.Unlock()
+	return permissions, nil
+}
+
+func (s *RoleStore) Invalidate(roleID int) {
+	s.mutex.Lock()
+	delete(s.cache, roleID)
+	s.mutex.Unlock()
+}
@@ -0,0 +1,47 @@
+package accesscontrol
+
+import (
+	"testing"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/internal/testdb"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"go.uber.org/zap"
+)
+
+func TestSeedSystemRolesAndCustomRole(t *testing.T) {
+	storage := testdb.NewStorage(t)
+	// seeding again on restart keeps the fixed ids
+	for i := 0; i < 2; i++ {
+		if err := SeedSystemRoles(storage.RoleStorage); err != nil {
+			t.Fatalf("seed system roles failed: %v", err)
+		}
+	}
+	for roleID := model.ROLE_ID_SYSTEM_MIN; roleID <= model.ROLE_ID_SYSTEM_MAX; roleID++ {
+		role, err := storage.RoleStorage.RetrieveByID(roleID)
+		if err != nil {
+			t.Fatalf("get system role %d failed: %v", roleID, err)
+		}
+		if role.Name != model.SystemRoleNameMap[roleID] {
+			t.Errorf("name of role %d = %s, want %s", roleID, role.Name, model.SystemRoleNameMap[roleID])
+		}
+	}
+
+	// custom role ids start after the system roles
+	req := &model.CreateRoleRequest{Name: "Auditor", Permissions: ExportBuiltInRolePermissions(model.USER_ROLE_VIEWER)}
+	roleID, err := storage.RoleStorage.Create(model.NewRoleByCreateRoleRequest(model.TEAM_DEFAULT_ID, req))
+	if err != nil {
+		t.Fatalf("create custom role failed: %v", err)
+	}
+	if roleID <= model.ROLE_ID_SYSTEM_MAX {
+		t.Fatalf("custom role id = %d, want after %d", roleID, model.ROLE_ID_SYSTEM_MAX)
+	}
+
+	// the custom role is a viewer by its attributes
+	SetRoleProvider(NewRoleStore(storage.RoleStorage, zap.NewNop().Sugar()))
+	t.Cleanup(func() {
+		SetRoleProvider(NewStaticRoleProvider())
+	})
+	if level := ResolveRoleLevel(roleID); level != model.USER_ROLE_VIEWER {
+		t.Errorf("level = %d, want %d", level, model.USER_ROLE_VIEWER)
+	}
+}
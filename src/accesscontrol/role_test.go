@@ -0,0 +1,102 @@
+package accesscontrol
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+)
+
+type testRoleProvider map[int]model.RolePermissions
+
+func (p testRoleProvider) RetrieveRolePermissions(roleID int) (model.RolePermissions, error) {
+	if model.IsSystemRoleID(roleID) {
+		return ExportBuiltInRolePermissions(roleID), nil
+	}
+	permissions, hit := p[roleID]
+	if !hit {
+		return nil, errors.New("role not found")
+	}
+	return permissions, nil
+}
+
+const (
+	testViewerLikeRoleID = 100 + iota
+	testAdminLikeRoleID
+	testOwnerLikeRoleID
+)
+
+func setTestRoleProvider(t *testing.T) {
+	SetRoleProvider(testRoleProvider{
+		testViewerLikeRoleID: ExportBuiltInRolePermissions(model.USER_ROLE_VIEWER),
+		testAdminLikeRoleID: model.RolePermissions{
+			ATTRIBUTE_CATEGORY_MANAGE: {UNIT_TYPE_ROLES: {ACTION_MANAGE_CUSTOM_ROLE: true}},
+		},
+		testOwnerLikeRoleID: ExportBuiltInRolePermissions(model.USER_ROLE_OWNER),
+	})
+	t.Cleanup(func() {
+		SetRoleProvider(NewStaticRoleProvider())
+	})
+}
+
+func TestResolveRoleLevel(t *testing.T) {
+	setTestRoleProvider(t)
+	cases := []struct {
+		name   string
+		roleID int
+		want   int
+	}{
+		{"system role", model.USER_ROLE_EDITOR, model.USER_ROLE_EDITOR},
+		{"anonymous", model.USER_ROLE_ANONYMOUS, model.USER_ROLE_ANONYMOUS},
+		{"custom role with viewer attributes", testViewerLikeRoleID, model.USER_ROLE_VIEWER},
+		{"custom role with admin attribute", testAdminLikeRoleID, model.USER_ROLE_ADMIN},
+		{"custom role with owner attributes", testOwnerLikeRoleID, model.USER_ROLE_OWNER},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := ResolveRoleLevel(c.roleID); got != c.want {
+				t.Errorf("level = %d, want %d", got, c.want)
+			}
+		})
+	}
+}
+
+func TestCustomRoleInviteAndModifyByLevel(t *testing.T) {
+	setTestRoleProvider(t)
+	editor := NewAttributeGroup(model.USER_ROLE_EDITOR, UNIT_TYPE_INVITE)
+	if !editor.CanInvite(testViewerLikeRoleID) {
+		t.Error("editor should invite a custom role of viewer level")
+	}
+	if editor.CanInvite(testAdminLikeRoleID) {
+		t.Error("editor should not invite a custom role of admin level")
+	}
+	admin := NewAttributeGroup(model.USER_ROLE_ADMIN, UNIT_TYPE_TEAM_MEMBER)
+	if !admin.CanModifyRoleFromTo(model.USER_ROLE_VIEWER, testAdminLikeRoleID) {
+		t.Error("admin should assign a custom role of admin level")
+	}
+	if admin.CanModifyRoleFromTo(model.USER_ROLE_VIEWER, testOwnerLikeRoleID) {
+		t.Error("admin should not assign a custom role of owner level")
+	}
+}
+
+func TestDoesNowUserAreEditorOrViewerByLevel(t *testing.T) {
+	setTestRoleProvider(t)
+	cases := []struct {
+		name   string
+		roleID int
+		want   bool
+	}{
+		{"editor", model.USER_ROLE_EDITOR, true},
+		{"admin", model.USER_ROLE_ADMIN, false},
+		{"custom role of viewer level", testViewerLikeRoleID, true},
+		{"custom role of admin level", testAdminLikeRoleID, false},
+		{"custom role of owner level", testOwnerLikeRoleID, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := NewAttributeGroup(c.roleID, UNIT_TYPE_TEAM).DoesNowUserAreEditorOrViewer(); got != c.want {
+				t.Errorf("editor or viewer = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
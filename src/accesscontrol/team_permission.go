@@ -0,0 +1,133 @@
+package accesscontrol
+
+import "github.com/kozmoai/kozmo-supervisor-backend/src/model"
+
+// TeamPermissionOverlay revoke attributes from roles when the team permission toggle is off.
+type TeamPermissionOverlay struct {
+	Name       string
+	IsOn       func(tp *model.TeamPermission) bool
+	UserRoles  []int // affected roles, nil for all roles
+	Category   int
+	UnitType   int
+	Attributes []int
+}
+
+var inviteAttributes = []int{ACTION_ACCESS_INVITE_BY_LINK, ACTION_ACCESS_INVITE_BY_EMAIL, ACTION_ACCESS_INVITE_OWNER, ACTION_ACCESS_INVITE_ADMIN, ACTION_ACCESS_INVITE_EDITOR, ACTION_ACCESS_INVITE_VIEWER}
+var manageTeamMemberAttributes = []int{ACTION_MANAGE_REMOVE_MEMBER, ACTION_MANAGE_ROLE, ACTION_MANAGE_ROLE_FROM_OWNER, ACTION_MANAGE_ROLE_FROM_ADMIN, ACTION_MANAGE_ROLE_FROM_EDITOR, ACTION_MANAGE_ROLE_FROM_VIEWER, ACTION_MANAGE_ROLE_TO_OWNER, ACTION_MANAGE_ROLE_TO_ADMIN, ACTION_MANAGE_ROLE_TO_EDITOR, ACTION_MANAGE_ROLE_TO_VIEWER}
+
+// Team Permission Overlay List
+// the toggles only restrict built-in roles listed here, custom roles are controlled by their own attribute set.
+var TeamPermissionOverlayList = []*TeamPermissionOverlay{
+	{
+		Name:       "allowEditorInvite",
+		IsOn:       func(tp *model.TeamPermission) bool { return tp.AllowEditorInvite },
+		UserRoles:  []int{model.USER_ROLE_EDITOR},
+		Category:   ATTRIBUTE_CATEGORY_ACCESS,
+		UnitType:   UNIT_TYPE_INVITE,
+		Attributes: inviteAttributes,
+	},
+	{
+		Name:       "allowViewerInvite",
+		IsOn:       func(tp *model.TeamPermission) bool { return tp.AllowViewerInvite },
+		UserRoles:  []int{model.USER_ROLE_VIEWER},
+		Category:   ATTRIBUTE_CATEGORY_ACCESS,
+		UnitType:   UNIT_TYPE_INVITE,
+		Attributes: inviteAttributes,
+	},
+	{
+		Name:       "inviteLinkEnabled",
+		IsOn:       func(tp *model.TeamPermission) bool { return tp.InviteLinkEnabled },
+		UserRoles:  nil,
+		Category:   ATTRIBUTE_CATEGORY_ACCESS,
+		UnitType:   UNIT_TYPE_INVITE,
+		Attributes: []int{ACTION_ACCESS_INVITE_BY_LINK},
+	},
+	{
+		Name:       "allowEditorManageTeamMember",
+		IsOn:       func(tp *model.TeamPermission) bool { return tp.AllowEditorManageTeamMember },
+		UserRoles:  []int{model.USER_ROLE_EDITOR},
+		Category:   ATTRIBUTE_CATEGORY_MANAGE,
+		UnitType:   UNIT_TYPE_TEAM_MEMBER,
+		Attributes: manageTeamMemberAttributes,
+	},
+	{
+		Name:       "allowViewerManageTeamMember",
+		IsOn:       func(tp *model.TeamPermission) bool { return tp.AllowViewerManageTeamMember },
+		UserRoles:  []int{model.USER_ROLE_VIEWER},
+		Category:   ATTRIBUTE_CATEGORY_MANAGE,
+		UnitType:   UNIT_TYPE_TEAM_MEMBER,
+		Attributes: manageTeamMemberAttributes,
+	},
+}
+
+func (overlay *TeamPermissionOverlay) DoesAffectUserRole(userRole int) bool {
+	if overlay.UserRoles == nil {
+		return userRole != model.USER_ROLE_ANONYMOUS
+	}
+	for _, affectedUserRole := range overlay.UserRoles {
+		if affectedUserRole == userRole {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAffectedByTeamPermission returns true when any toggle may revoke attribute of the role on the unit type,
+// the caller can skip loading team permission when it returns false.
+func IsAffectedByTeamPermission(userRole int, unitType int) bool {
+	for _, overlay := range TeamPermissionOverlayList {
+		if overlay.UnitType == unitType && overlay.DoesAffectUserRole(userRole) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRevokedByTeamPermission returns true when the attribute is revoked by a switched off team permission toggle.
+func IsRevokedByTeamPermission(userRole int, tp *model.TeamPermission, category int, unitType int, attribute int) bool {
+	return revokingTeamPermissionOverlay(userRole, tp, category, unitType, attribute) != nil
+}
+
+// revokingTeamPermissionOverlay returns the switched off toggle which revoke the attribute, nil when not revoked.
+func revokingTeamPermissionOverlay(userRole int, tp *model.TeamPermission, category int, unitType int, attribute int) *TeamPermissionOverlay {
+	if tp == nil {
+		return nil
+	}
+	for _, overlay := range TeamPermissionOverlayList {
+		if overlay.Category != category || overlay.UnitType != unitType || overlay.IsOn(tp) || !overlay.DoesAffectUserRole(userRole) {
+			continue
+		}
+		for _, revokedAttribute := range overlay.Attributes {
+			if revokedAttribute == attribute {
+				return overlay
+			}
+		}
+	}
+	return nil
+}
+
+// ApplyTeamPermission export a copy of permissions with attributes revoked by the switched off team permission toggles.
+func ApplyTeamPermission(userRole int, tp *model.TeamPermission, permissions model.RolePermissions) model.RolePermissions {
+	ret := make(model.RolePermissions, len(permissions))
+	for category, unitTypes := range permissions {
+		ret[category] = make(map[int]map[int]bool, len(unitTypes))
+		for unitType, attributes := range unitTypes {
+			ret[category][unitType] = make(map[int]bool, len(attributes))
+			for attribute, status := range attributes {
+				ret[category][unitType][attribute] = status
+			}
+		}
+	}
+	if tp == nil {
+		return ret
+	}
+	for _, overlay := range TeamPermissionOverlayList {
+		if overlay.IsOn(tp) || !overlay.DoesAffectUserRole(userRole) {
+			continue
+		}
+		for _, attribute := range overlay.Attributes {
+			delete(ret[overlay.Category][overlay.UnitType], attribute)
+		}
+	}
+	return ret
+}
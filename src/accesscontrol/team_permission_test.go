@@ -0,0 +1,79 @@
+package accesscontrol
+
+import (
+	"testing"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+)
+
+const testUnitID = 42
+
+func TestTeamPermissionOverlay(t *testing.T) {
+	allOn := &model.TeamPermission{
+		AllowEditorInvite:           true,
+		AllowViewerInvite:           true,
+		AllowEditorManageTeamMember: true,
+		AllowViewerManageTeamMember: true,
+		InviteLinkEnabled:           true,
+	}
+	allOff := &model.TeamPermission{}
+	cases := []struct {
+		name           string
+		userRole       int
+		unitType       int
+		category       int
+		attribute      int
+		teamPermission *model.TeamPermission
+		unitGrant      bool // grant the attribute by an allow relation on the unit
+		want           bool
+	}{
+		{"role grant kept when toggle on", model.USER_ROLE_EDITOR, UNIT_TYPE_INVITE, ATTRIBUTE_CATEGORY_ACCESS, ACTION_ACCESS_INVITE_BY_EMAIL, allOn, false, true},
+		{"role grant revoked by editor invite toggle", model.USER_ROLE_EDITOR, UNIT_TYPE_INVITE, ATTRIBUTE_CATEGORY_ACCESS, ACTION_ACCESS_INVITE_BY_EMAIL, allOff, false, false},
+		{"role grant revoked by viewer invite toggle", model.USER_ROLE_VIEWER, UNIT_TYPE_INVITE, ATTRIBUTE_CATEGORY_ACCESS, ACTION_ACCESS_INVITE_VIEWER, allOff, false, false},
+		{"unit grant kept when toggle on", model.USER_ROLE_VIEWER, UNIT_TYPE_TEAM_MEMBER, ATTRIBUTE_CATEGORY_MANAGE, ACTION_MANAGE_REMOVE_MEMBER, allOn, true, true},
+		{"unit grant revoked by viewer manage toggle", model.USER_ROLE_VIEWER, UNIT_TYPE_TEAM_MEMBER, ATTRIBUTE_CATEGORY_MANAGE, ACTION_MANAGE_REMOVE_MEMBER, allOff, true, false},
+		{"unit grant revoked by editor manage toggle", model.USER_ROLE_EDITOR, UNIT_TYPE_TEAM_MEMBER, ATTRIBUTE_CATEGORY_MANAGE, ACTION_MANAGE_ROLE, allOff, true, false},
+		{"unit and role grant revoked by invite link toggle", model.USER_ROLE_OWNER, UNIT_TYPE_INVITE, ATTRIBUTE_CATEGORY_ACCESS, ACTION_ACCESS_INVITE_BY_LINK, allOff, true, false},
+		{"admin not restricted by editor toggles", model.USER_ROLE_ADMIN, UNIT_TYPE_INVITE, ATTRIBUTE_CATEGORY_ACCESS, ACTION_ACCESS_INVITE_BY_EMAIL, allOff, false, true},
+		{"other unit type not restricted", model.USER_ROLE_VIEWER, UNIT_TYPE_APP, ATTRIBUTE_CATEGORY_ACCESS, ACTION_ACCESS_VIEW, allOff, true, true},
+		{"no team permission loaded", model.USER_ROLE_EDITOR, UNIT_TYPE_INVITE, ATTRIBUTE_CATEGORY_ACCESS, ACTION_ACCESS_INVITE_BY_EMAIL, nil, false, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			attrg := NewAttributeGroup(c.userRole, c.unitType)
+			if c.unitGrant {
+				unitRoleRelation := model.NewUnitRoleRelation(1, c.unitType, testUnitID, c.userRole)
+				unitRoleRelation.AllowPermissions(model.UnitPermissions{c.category: {c.attribute: true}})
+				attrg.SetUnitID(testUnitID)
+				attrg.SetUnitRoleRelations([]*model.UnitRoleRelation{unitRoleRelation})
+			}
+			attrg.SetTeamPermission(c.teamPermission)
+
+			var got bool
+			switch c.category {
+			case ATTRIBUTE_CATEGORY_ACCESS:
+				got = attrg.CanAccess(c.attribute)
+			case ATTRIBUTE_CATEGORY_MANAGE:
+				got = attrg.CanManage(c.attribute)
+			}
+			if got != c.want {
+				t.Errorf("check = %v, want %v", got, c.want)
+			}
+			if revoked := IsRevokedByTeamPermission(c.userRole, c.teamPermission, c.category, c.unitType, c.attribute); revoked == c.want {
+				t.Errorf("revoked = %v, want %v", revoked, !c.want)
+			}
+		})
+	}
+}
+
+func TestTeamPermissionOverlayRevokesModifyRole(t *testing.T) {
+	attrg := NewAttributeGroup(model.USER_ROLE_EDITOR, UNIT_TYPE_TEAM_MEMBER)
+	attrg.SetTeamPermission(&model.TeamPermission{AllowEditorManageTeamMember: true})
+	if !attrg.CanModifyRoleFromTo(model.USER_ROLE_VIEWER, model.USER_ROLE_EDITOR) {
+		t.Fatal("editor should modify viewer to editor when the toggle is on")
+	}
+	attrg.SetTeamPermission(&model.TeamPermission{})
+	if attrg.CanModifyRoleFromTo(model.USER_ROLE_VIEWER, model.USER_ROLE_EDITOR) {
+		t.Error("editor should not modify role when the toggle is off")
+	}
+}
@@ -0,0 +1,51 @@
+package accesscontrol
+
+import "github.com/kozmoai/kozmo-supervisor-backend/src/model"
+
+// UnitAttribute holds unit-level grants of a role on a single unit.
+// deny wins over allow, attributes not mentioned fallback to the team-wide role.
+type UnitAttribute struct {
+	DenyAll bool
+	Allow   map[int]map[int]bool // map[AttributeCategory][Attribute]status
+	Deny    map[int]map[int]bool
+}
+
+func NewUnitAttribute(unitRoleRelations []*model.UnitRoleRelation) *UnitAttribute {
+	unitAttr := &UnitAttribute{
+		Allow: make(map[int]map[int]bool),
+		Deny:  make(map[int]map[int]bool),
+	}
+	for _, unitRoleRelation := range unitRoleRelations {
+		permissions := unitRoleRelation.ExportPermissions()
+		target := unitAttr.Allow
+		if unitRoleRelation.IsDeny() {
+			target = unitAttr.Deny
+			// empty deny relation means the role can not touch the unit at all
+			if len(permissions) == 0 {
+				unitAttr.DenyAll = true
+			}
+		}
+		for category, attributes := range permissions {
+			if _, hit := target[category]; !hit {
+				target[category] = make(map[int]bool)
+			}
+			for attribute, status := range attributes {
+				if status {
+					target[category][attribute] = true
+				}
+			}
+		}
+	}
+	return unitAttr
+}
+
+// Lookup returns the unit-level decision of the attribute, the second value is false when no unit-level grant matched.
+func (unitAttr *UnitAttribute) Lookup(category int, attribute int) (bool, bool) {
+	if unitAttr.DenyAll || unitAttr.Deny[category][attribute] {
+		return false, true
+	}
+	if unitAttr.Allow[category][attribute] {
+		return true, true
+	}
+	return false, false
+}
@@ -1,21 +1,29 @@
 package authenticator
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"math/rand"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 
 	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/config"
 )
 
+const SCIM_AUTHORIZATION_SCHEME = "Bearer "
+
+const ACCESS_TOKEN_ISSUER = "KOZMO"
+
 type AuthClaims struct {
 	User   int       `json:"user"`
 	UUID   uuid.UUID `json:"uuid"`
@@ -24,12 +32,14 @@ type AuthClaims struct {
 }
 
 type Authenticator struct {
+	logger  *zap.SugaredLogger
 	Storage *model.Storage
 	Cache   *model.Cache
 }
 
-func NewAuthenticator(storage *model.Storage, cache *model.Cache) *Authenticator {
+func NewAuthenticator(storage *model.Storage, cache *model.Cache, logger *zap.SugaredLogger) *Authenticator {
 	a := &Authenticator{
+		logger:  logger,
 		Storage: storage,
 		Cache:   cache,
 	}
@@ -102,7 +112,7 @@ func CreateAccessToken(id int, uid uuid.UUID) (string, error) {
 		UUID:   uid,
 		Random: vCode,
 		RegisteredClaims: jwt.RegisteredClaims{
-			Issuer: "KOZMO",
+			Issuer: ACCESS_TOKEN_ISSUER,
 			ExpiresAt: &jwt.NumericDate{
 				Time: time.Now().Add(time.Hour * 24 * 7),
 			},
@@ -149,6 +159,32 @@ func (a *Authenticator) JWTAuth() gin.HandlerFunc {
 	}
 }
 
+// SCIMAuth validate the SCIM bearer token, and set the team ID which the token belongs to.
+func (a *Authenticator) SCIMAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authorization := c.GetHeader("Authorization")
+		if len(authorization) <= len(SCIM_AUTHORIZATION_SCHEME) || !strings.EqualFold(authorization[:len(SCIM_AUTHORIZATION_SCHEME)], SCIM_AUTHORIZATION_SCHEME) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, model.NewSCIMError(strconv.Itoa(http.StatusUnauthorized), "", "bearer token required."))
+			return
+		}
+		token := strings.TrimSpace(authorization[len(SCIM_AUTHORIZATION_SCHEME):])
+
+		// fetch token
+		scimToken, err := a.Storage.SCIMTokenStorage.RetrieveByTokenDigest(model.DigestSCIMToken(token))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, model.NewSCIMError(strconv.Itoa(http.StatusUnauthorized), "", "invalid bearer token."))
+			return
+		}
+		// the last used time is informational, the request goes on when it is not recorded
+		if err := a.Storage.SCIMTokenStorage.UpdateLastUsedAtByID(scimToken.ID, time.Now().UTC()); err != nil {
+			a.logger.Errorw("update SCIM token last used at failed", "scimTokenID", scimToken.ID, "teamID", scimToken.ExportTeamID(), "err", err)
+		}
+
+		c.Set("scimTeamID", scimToken.ExportTeamID())
+		c.Next()
+	}
+}
+
 func (a *Authenticator) ManualAuth(accessToken string) (bool, error) {
 	// fetch user
 	userID, userUID, extractErr := ExtractUserIDFromToken(accessToken)
@@ -188,3 +224,10 @@ func (a *Authenticator) DoesAccessTokenExpiredAtAvaliable(user *model.User, acce
 	// get history data
 	return a.Cache.JWTCache.DoesUserJWTTokenAvaliable(user, expiresAt)
 }
+
+// ExportSessionID feedback an opaque id of the sign in session of token.
+// the jwt cache keeps the expiresAt of the signed in token per user, so user uid and expiresAt identify the session.
+func ExportSessionID(userUID uuid.UUID, expiresAt string) string {
+	digest := sha256.Sum256([]byte(userUID.String() + ":" + expiresAt))
+	return hex.EncodeToString(digest[:16])
+}
@@ -1,38 +1,57 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"net/http"
 	"os"
+	"strings"
 
+	"github.com/kozmoai/kozmo-supervisor-backend/src/accesscontrol"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/authenticator"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/controller"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/domainverifier"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/driver/minio"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/driver/postgres"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/driver/redis"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/internalrouter"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/internalrpc"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/config"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/cors"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/dnsresolver"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/logger"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/mtls"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/recovery"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/tokenvalidator"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/webhook"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
 )
 
 type Server struct {
-	engine *gin.Engine
-	router *internalrouter.Router
-	logger *zap.SugaredLogger
-	config *config.Config
+	engine     *gin.Engine
+	router     *internalrouter.Router
+	grpcServer *grpc.Server
+	tlsConfig  *tls.Config
+	allowlist  *mtls.Allowlist
+	logger     *zap.SugaredLogger
+	config     *config.Config
 }
 
-func NewServer(config *config.Config, engine *gin.Engine, router *internalrouter.Router, logger *zap.SugaredLogger) *Server {
+func NewServer(config *config.Config, engine *gin.Engine, router *internalrouter.Router, grpcServer *grpc.Server, tlsConfig *tls.Config, allowlist *mtls.Allowlist, logger *zap.SugaredLogger) *Server {
 	return &Server{
-		engine: engine,
-		config: config,
-		router: router,
-		logger: logger,
+		engine:     engine,
+		config:     config,
+		router:     router,
+		grpcServer: grpcServer,
+		tlsConfig:  tlsConfig,
+		allowlist:  allowlist,
+		logger:     logger,
 	}
 }
 
@@ -44,6 +63,53 @@ func initStorage(globalConfig *config.Config, logger *zap.SugaredLogger) *model.
 	return model.NewStorage(postgresDriver, logger)
 }
 
+func initAccessControlPolicy(globalConfig *config.Config, storage *model.Storage, logger *zap.SugaredLogger) {
+	if globalConfig.GetAccessControlPolicyFile() == "" {
+		return
+	}
+	// system roles in database follow the reloaded policy
+	onReload := func(policy *accesscontrol.Policy) {
+		if err := accesscontrol.SeedSystemRoles(storage.RoleStorage); err != nil {
+			logger.Errorw("seed system roles failed after access control policy reloaded.", "err", err)
+		}
+	}
+	watcher := accesscontrol.NewPolicyWatcher(globalConfig.GetAccessControlPolicyFile(), globalConfig.GetAccessControlPolicyReloadInterval(), onReload, logger)
+	if err := watcher.Load(); err != nil {
+		logger.Errorw("Error in startup, load access control policy failed, use the built-in policy.", "err", err)
+	}
+	if globalConfig.GetAccessControlPolicyReloadInterval() > 0 {
+		go watcher.Run(context.Background())
+	}
+}
+
+func initRoleStore(storage *model.Storage, logger *zap.SugaredLogger) {
+	if err := accesscontrol.SeedSystemRoles(storage.RoleStorage); err != nil {
+		logger.Errorw("Error in startup, seed system roles failed.", "err", err)
+	}
+	accesscontrol.SetRoleProvider(accesscontrol.NewRoleStore(storage.RoleStorage, logger))
+}
+
+// initInternalTLS feedback nil config when mTLS is disabled, a broken mTLS config stops the startup instead of falling back to plaintext.
+func initInternalTLS(globalConfig *config.Config, logger *zap.SugaredLogger) (*tls.Config, *mtls.Allowlist) {
+	if !globalConfig.IsInternalTLSEnabled() {
+		return nil, nil
+	}
+	allowlist, errInParseAllowlist := mtls.ParseAllowlist(globalConfig.GetInternalTLSAllowedClients())
+	if errInParseAllowlist != nil {
+		logger.Errorw("Error in startup, parse internal TLS allowed clients failed.", "err", errInParseAllowlist)
+		os.Exit(2)
+	}
+	reloader := mtls.NewCertificateReloader(globalConfig.GetInternalTLSCertFile(), globalConfig.GetInternalTLSKeyFile(), globalConfig.GetInternalTLSClientCAFile(), globalConfig.GetInternalTLSReloadInterval(), logger)
+	if err := reloader.Load(); err != nil {
+		logger.Errorw("Error in startup, load internal TLS certificates failed.", "err", err)
+		os.Exit(2)
+	}
+	if globalConfig.GetInternalTLSReloadInterval() > 0 {
+		go reloader.Run(context.Background())
+	}
+	return reloader.TLSConfig(allowlist), allowlist
+}
+
 func initCache(globalConfig *config.Config, logger *zap.SugaredLogger) *model.Cache {
 	redisDriver, err := redis.NewRedisConnectionByGlobalConfig(globalConfig, logger)
 	if err != nil {
@@ -67,18 +133,33 @@ func initServer() (*Server, error) {
 	engine := gin.New()
 	sugaredLogger := logger.NewSugardLogger()
 
-	// init validator
-	validator := tokenvalidator.NewRequestTokenValidator()
-
 	// init driver
 	storage := initStorage(globalConfig, sugaredLogger)
 	cache := initCache(globalConfig, sugaredLogger)
 	drive := initDrive(globalConfig, sugaredLogger)
 
-	a := authenticator.NewAuthenticator(storage, cache)
-	c := controller.NewController(storage, cache, drive, validator, a)
+	// init validator
+	validator := tokenvalidator.NewRequestTokenValidator(cache.RequestNonceCache)
+
+	// init role store
+	initAccessControlPolicy(globalConfig, storage, sugaredLogger)
+	initRoleStore(storage, sugaredLogger)
+
+	// init notifier, notices are delivered by the team webhooks which subscribed them
+	notifier := webhook.NewNotifier(storage, sugaredLogger)
+
+	// init domain verifier
+	domainVerifier := domainverifier.NewDomainVerifierByGlobalConfig(globalConfig, storage, dnsresolver.NewNetResolver(), sugaredLogger)
+
+	// init team webhook deliverer, the delivery job runs in the public server
+	webhookDeliverer := webhook.NewDelivererByGlobalConfig(globalConfig, storage, sugaredLogger)
+
+	a := authenticator.NewAuthenticator(storage, cache, sugaredLogger)
+	c := controller.NewController(storage, cache, drive, validator, a, domainVerifier, notifier, webhookDeliverer, sugaredLogger)
 	router := internalrouter.NewRouter(c, a)
-	server := NewServer(globalConfig, engine, router, sugaredLogger)
+	grpcServer := internalrpc.NewGRPCServer(c, sugaredLogger)
+	tlsConfig, allowlist := initInternalTLS(globalConfig, sugaredLogger)
+	server := NewServer(globalConfig, engine, router, grpcServer, tlsConfig, allowlist, sugaredLogger)
 	return server, nil
 
 }
@@ -93,7 +174,30 @@ func (server *Server) Start() {
 	server.engine.Use(cors.Cors())
 	server.router.RegisterRouters(server.engine)
 
-	err := server.engine.Run(server.config.ServerHost + ":" + server.config.InternalServerPort)
+	// gRPC and REST share the internal port, HTTP/2 requests with grpc content type go to the gRPC server
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+			server.grpcServer.ServeHTTP(w, r)
+			return
+		}
+		server.engine.ServeHTTP(w, r)
+	}))
+	address := server.config.ServerHost + ":" + server.config.InternalServerPort
+	var err error
+	if server.tlsConfig != nil {
+		// mTLS, HTTP/2 is negotiated by ALPN
+		httpServer := &http.Server{
+			Addr:      address,
+			Handler:   mtls.IdentityHandler(server.allowlist, server.logger, handler),
+			TLSConfig: server.tlsConfig,
+		}
+		server.logger.Infow("Listening internal REST and gRPC services with mutual TLS", "address", address)
+		err = httpServer.ListenAndServeTLS("", "")
+	} else {
+		// plaintext, HTTP/2 is served as h2c
+		server.logger.Infow("Listening internal REST and gRPC services", "address", address)
+		err = http.ListenAndServe(address, h2c.NewHandler(handler, &http2.Server{}))
+	}
 	if err != nil {
 		server.logger.Errorw("Error in startup", "err", err)
 		os.Exit(2)
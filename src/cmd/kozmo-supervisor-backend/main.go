@@ -1,38 +1,53 @@
 package main
 
 import (
+	"context"
 	"os"
 
+	"github.com/kozmoai/kozmo-supervisor-backend/src/accesscontrol"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/authenticator"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/controller"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/domainverifier"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/driver/minio"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/driver/postgres"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/driver/redis"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/outbox"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/rolegrantexpirer"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/router"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/config"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/cors"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/dnsresolver"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/logger"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/recovery"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/tokenvalidator"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/webhook"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
 type Server struct {
-	engine *gin.Engine
-	router *router.Router
-	logger *zap.SugaredLogger
-	config *config.Config
+	engine           *gin.Engine
+	router           *router.Router
+	domainVerifier   *domainverifier.DomainVerifier
+	roleGrantExpirer *rolegrantexpirer.RoleGrantExpirer
+	outboxRelay      *outbox.Relay
+	webhookDeliverer *webhook.Deliverer
+	logger           *zap.SugaredLogger
+	config           *config.Config
 }
 
-func NewServer(config *config.Config, engine *gin.Engine, router *router.Router, logger *zap.SugaredLogger) *Server {
+func NewServer(config *config.Config, engine *gin.Engine, router *router.Router, domainVerifier *domainverifier.DomainVerifier, roleGrantExpirer *rolegrantexpirer.RoleGrantExpirer, outboxRelay *outbox.Relay, webhookDeliverer *webhook.Deliverer, logger *zap.SugaredLogger) *Server {
 	return &Server{
-		engine: engine,
-		config: config,
-		router: router,
-		logger: logger,
+		engine:           engine,
+		config:           config,
+		router:           router,
+		domainVerifier:   domainVerifier,
+		roleGrantExpirer: roleGrantExpirer,
+		outboxRelay:      outboxRelay,
+		webhookDeliverer: webhookDeliverer,
+		logger:           logger,
 	}
 }
 
@@ -44,6 +59,32 @@ func initStorage(globalConfig *config.Config, logger *zap.SugaredLogger) *model.
 	return model.NewStorage(postgresDriver, logger)
 }
 
+func initAccessControlPolicy(globalConfig *config.Config, storage *model.Storage, logger *zap.SugaredLogger) {
+	if globalConfig.GetAccessControlPolicyFile() == "" {
+		return
+	}
+	// system roles in database follow the reloaded policy
+	onReload := func(policy *accesscontrol.Policy) {
+		if err := accesscontrol.SeedSystemRoles(storage.RoleStorage); err != nil {
+			logger.Errorw("seed system roles failed after access control policy reloaded.", "err", err)
+		}
+	}
+	watcher := accesscontrol.NewPolicyWatcher(globalConfig.GetAccessControlPolicyFile(), globalConfig.GetAccessControlPolicyReloadInterval(), onReload, logger)
+	if err := watcher.Load(); err != nil {
+		logger.Errorw("Error in startup, load access control policy failed, use the built-in policy.", "err", err)
+	}
+	if globalConfig.GetAccessControlPolicyReloadInterval() > 0 {
+		go watcher.Run(context.Background())
+	}
+}
+
+func initRoleStore(storage *model.Storage, logger *zap.SugaredLogger) {
+	if err := accesscontrol.SeedSystemRoles(storage.RoleStorage); err != nil {
+		logger.Errorw("Error in startup, seed system roles failed.", "err", err)
+	}
+	accesscontrol.SetRoleProvider(accesscontrol.NewRoleStore(storage.RoleStorage, logger))
+}
+
 func initCache(globalConfig *config.Config, logger *zap.SugaredLogger) *model.Cache {
 	redisDriver, err := redis.NewRedisConnectionByGlobalConfig(globalConfig, logger)
 	if err != nil {
@@ -53,6 +94,15 @@ func initCache(globalConfig *config.Config, logger *zap.SugaredLogger) *model.Ca
 
 }
 
+func initOutboxRelay(globalConfig *config.Config, storage *model.Storage, cache *model.Cache, logger *zap.SugaredLogger) *outbox.Relay {
+	relay, err := outbox.NewRelayByGlobalConfig(globalConfig, storage, cache, logger)
+	if err != nil {
+		logger.Errorw("Error in startup, outbox relay init failed.", "err", err)
+		os.Exit(1)
+	}
+	return relay
+}
+
 func initDrive(globalConfig *config.Config, logger *zap.SugaredLogger) *model.Drive {
 	systemMINIOConfig := minio.NewSystemMINIOConfigByGlobalConfig(globalConfig)
 	teamMINIOConfig := minio.NewTeamMINIOConfigByGlobalConfig(globalConfig)
@@ -67,19 +117,42 @@ func initServer() (*Server, error) {
 	engine := gin.New()
 	sugaredLogger := logger.NewSugardLogger()
 
-	// init validator
-	validator := tokenvalidator.NewRequestTokenValidator()
-
 	// init driver
 	storage := initStorage(globalConfig, sugaredLogger)
 	cache := initCache(globalConfig, sugaredLogger)
 	drive := initDrive(globalConfig, sugaredLogger)
 
+	// init validator
+	validator := tokenvalidator.NewRequestTokenValidator(cache.RequestNonceCache)
+
+	// init role store
+	initAccessControlPolicy(globalConfig, storage, sugaredLogger)
+	initRoleStore(storage, sugaredLogger)
+
+	// init notifier, notices are delivered by the team webhooks which subscribed them
+	notifier := webhook.NewNotifier(storage, sugaredLogger)
+
+	// init domain verifier
+	domainVerifier := domainverifier.NewDomainVerifierByGlobalConfig(globalConfig, storage, dnsresolver.NewNetResolver(), sugaredLogger)
+
+	// init role grant expirer
+	roleGrantExpirer := rolegrantexpirer.NewRoleGrantExpirerByGlobalConfig(globalConfig, storage, notifier, sugaredLogger)
+
+	// init outbox relay
+	outboxRelay := initOutboxRelay(globalConfig, storage, cache, sugaredLogger)
+
+	// init team webhook dispatcher & deliverer
+	outboxRelay.AddSink(webhook.SINK_NAME, webhook.NewDispatcher(storage, sugaredLogger))
+	webhookDeliverer := webhook.NewDelivererByGlobalConfig(globalConfig, storage, sugaredLogger)
+
+	// init team drive cleaner
+	outboxRelay.AddSink(outbox.SINK_TEAM_DRIVE_CLEANER, outbox.NewTeamDriveCleaner(drive))
+
 	// init controller
-	a := authenticator.NewAuthenticator(storage, cache)
-	c := controller.NewController(storage, cache, drive, validator, a)
+	a := authenticator.NewAuthenticator(storage, cache, sugaredLogger)
+	c := controller.NewController(storage, cache, drive, validator, a, domainVerifier, notifier, webhookDeliverer, sugaredLogger)
 	router := router.NewRouter(c, a)
-	server := NewServer(globalConfig, engine, router, sugaredLogger)
+	server := NewServer(globalConfig, engine, router, domainVerifier, roleGrantExpirer, outboxRelay, webhookDeliverer, sugaredLogger)
 	return server, nil
 
 }
@@ -94,6 +167,18 @@ func (server *Server) Start() {
 	server.engine.Use(cors.Cors())
 	server.router.RegisterRouters(server.engine)
 
+	// start domain reverify job
+	go server.domainVerifier.Run(context.Background())
+
+	// start time-bound role expiry job
+	go server.roleGrantExpirer.Run(context.Background())
+
+	// start domain event outbox relay
+	go server.outboxRelay.Run(context.Background())
+
+	// start team webhook delivery job
+	go server.webhookDeliverer.Run(context.Background())
+
 	err := server.engine.Run(server.config.ServerHost + ":" + server.config.ServerPort)
 	if err != nil {
 		server.logger.Errorw("Error in startup", "err", err)
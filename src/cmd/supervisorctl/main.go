@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/accesscontrol"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+)
+
+const usage = `usage: supervisorctl <command> [arguments]
+
+commands:
+  policy diff    compare two access control policies and report added, removed and escalated attributes
+
+run "supervisorctl policy diff -h" for arguments.
+`
+
+// exit codes
+const (
+	EXIT_CODE_OK         = 0
+	EXIT_CODE_ERROR      = 1
+	EXIT_CODE_USAGE      = 2
+	EXIT_CODE_ESCALATION = 3
+)
+
+func main() {
+	if len(os.Args) < 3 || os.Args[1] != "policy" || os.Args[2] != "diff" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(EXIT_CODE_USAGE)
+	}
+	os.Exit(runPolicyDiff(os.Args[3:], os.Stdout, os.Stderr))
+}
+
+// policyDiffResult is the JSON output of policy diff.
+type policyDiffResult struct {
+	Diff   *accesscontrol.PolicyDiff         `json:"diff"`
+	Replay *accesscontrol.PolicyReplayResult `json:"replay,omitempty"`
+}
+
+func runPolicyDiff(args []string, stdout io.Writer, stderr io.Writer) int {
+	flags := flag.NewFlagSet("supervisorctl policy diff", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	fromPath := flags.String("from", "", "current policy file, the built-in policy (AttributeConfigList) is used when empty")
+	toPath := flags.String("to", "", "proposed policy file (required)")
+	decisionsPath := flags.String("decisions", "", "JSON lines file of recorded explain responses to replay against the proposed policy")
+	sampleSize := flags.Int("sample", 100, "number of recorded decisions to replay, 0 means all")
+	seed := flags.Int64("seed", 1, "random seed of decision sampling")
+	outputJSON := flags.Bool("json", false, "print result in JSON")
+	failOnEscalation := flags.Bool("fail-on-escalation", false, "exit with code 3 when any privilege escalation is found")
+	if err := flags.Parse(args); err != nil {
+		return EXIT_CODE_USAGE
+	}
+	if *toPath == "" {
+		fmt.Fprintln(stderr, "-to is required")
+		flags.Usage()
+		return EXIT_CODE_USAGE
+	}
+
+	// load policies
+	from := accesscontrol.NewBuiltInPolicy()
+	if *fromPath != "" {
+		var err error
+		if from, err = accesscontrol.LoadPolicyFile(*fromPath); err != nil {
+			fmt.Fprintln(stderr, "load current policy failed: "+err.Error())
+			return EXIT_CODE_ERROR
+		}
+	}
+	to, err := accesscontrol.LoadPolicyFile(*toPath)
+	if err != nil {
+		fmt.Fprintln(stderr, "load proposed policy failed: "+err.Error())
+		return EXIT_CODE_ERROR
+	}
+
+	// diff and replay
+	result := &policyDiffResult{
+		Diff: accesscontrol.DiffPolicy(from, to),
+	}
+	if *decisionsPath != "" {
+		decisions, err := loadDecisions(*decisionsPath)
+		if err != nil {
+			fmt.Fprintln(stderr, "load recorded decisions failed: "+err.Error())
+			return EXIT_CODE_ERROR
+		}
+		decisions = sampleDecisions(decisions, *sampleSize, *seed)
+		result.Replay = accesscontrol.ReplayDecisions(to, decisions)
+	}
+
+	// feedback
+	if *outputJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(result)
+	} else {
+		printPolicyDiff(stdout, result)
+	}
+	_, _, escalations := result.Diff.CountChanges()
+	if *failOnEscalation && (escalations > 0 || result.Replay.CountEscalations() > 0) {
+		return EXIT_CODE_ESCALATION
+	}
+	return EXIT_CODE_OK
+}
+
+func printPolicyDiff(w io.Writer, result *policyDiffResult) {
+	diff := result.Diff
+	fmt.Fprintf(w, "policy diff: %s -> %s\n", diff.FromVersion, diff.ToVersion)
+	if diff.IsEmpty() {
+		fmt.Fprintln(w, "no attribute changed.")
+	}
+	role, unitType := "", ""
+	for _, change := range diff.Changes {
+		if change.Role != role {
+			role, unitType = change.Role, ""
+			fmt.Fprintf(w, "\nrole %s\n", role)
+		}
+		if change.UnitType != unitType {
+			unitType = change.UnitType
+			fmt.Fprintf(w, "  %s\n", unitType)
+		}
+		mark, note := "-", ""
+		if change.Added {
+			mark = "+"
+		}
+		if change.Escalation {
+			note = "  [ESCALATION]"
+		}
+		fmt.Fprintf(w, "    %s %s.%s%s\n", mark, change.Category, change.Attribute, note)
+	}
+	added, removed, escalations := diff.CountChanges()
+	fmt.Fprintf(w, "\n%d added, %d removed, %d escalation(s)\n", added, removed, escalations)
+
+	if result.Replay == nil {
+		return
+	}
+	replay := result.Replay
+	fmt.Fprintf(w, "\nreplay: %d replayed, %d skipped (not decided by built-in role), %d changed\n", replay.Replayed, replay.Skipped, len(replay.Changed))
+	for _, changed := range replay.Changed {
+		verdict := "allow -> deny"
+		if changed.NowAllowed {
+			verdict = "deny -> allow"
+		}
+		fmt.Fprintf(w, "  %s  %s %s %s (user %s, unit %s)\n", verdict, changed.Role, changed.Check.Category, changed.Attribute, changed.UserID, changed.Check.UnitID)
+	}
+}
+
+// loadDecisions read explain responses, one JSON object per line, blank lines are ignored.
+func loadDecisions(path string) ([]*model.ExplainAccessControlResponse, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	decisions := make([]*model.ExplainAccessControlResponse, 0)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		decision := &model.ExplainAccessControlResponse{}
+		if err := json.Unmarshal(scanner.Bytes(), decision); err != nil {
+			return nil, errors.New(fmt.Sprintf("line %d: %s", line, err.Error()))
+		}
+		decisions = append(decisions, decision)
+	}
+	return decisions, scanner.Err()
+}
+
+// sampleDecisions pick size decisions uniformly by reservoir sampling, the order of picked decisions is kept.
+func sampleDecisions(decisions []*model.ExplainAccessControlResponse, size int, seed int64) []*model.ExplainAccessControlResponse {
+	if size <= 0 || len(decisions) <= size {
+		return decisions
+	}
+	random := rand.New(rand.NewSource(seed))
+	picked := make([]int, size)
+	for i := range picked {
+		picked[i] = i
+	}
+	for i := size; i < len(decisions); i++ {
+		if j := random.Intn(i + 1); j < size {
+			picked[j] = i
+		}
+	}
+	hits := make([]bool, len(decisions))
+	for _, i := range picked {
+		hits[i] = true
+	}
+	ret := make([]*model.ExplainAccessControlResponse, 0, size)
+	for i, hit := range hits {
+		if hit {
+			ret = append(ret, decisions[i])
+		}
+	}
+	return ret
+}
@@ -1,7 +1,12 @@
 package controller
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
+
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 
 	"github.com/kozmoai/kozmo-supervisor-backend/src/accesscontrol"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/authenticator"
@@ -21,9 +26,8 @@ func (controller *Controller) ValidateAccount(c *gin.Context) {
 	}
 
 	// validate account
-	a := controller.Authenticator
-	if _, err := a.ManualAuth(authorizationToken); err != nil {
-		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_ACCOUNT_FAILED, "validate account failed: "+err.Error())
+	if err := controller.ValidateAccountByToken(authorizationToken); err != nil {
+		controller.FeedbackBadRequest(c, err.Flag, err.Message)
 		return
 	}
 
@@ -32,6 +36,16 @@ func (controller *Controller) ValidateAccount(c *gin.Context) {
 	return
 }
 
+// IntrospectToken feedback everything about the token in the request body in one call, see RFC 7662.
+// the request is signed by the raw body, the token to introspect is in the body instead of the authorization header.
+func (controller *Controller) IntrospectToken(c *gin.Context) {
+	teamID := model.TEAM_DEFAULT_ID
+	req := model.NewIntrospectTokenRequest()
+	controller.serveSignedInternalLookup(c, req, func() (model.Response, *FeedbackError) {
+		return controller.IntrospectAccessToken(req.Token, teamID, req.HasTeam())
+	})
+}
+
 func (controller *Controller) GetTeamPermission(c *gin.Context) {
 	authorizationToken, errInGetAuthorizationToken := controller.GetStringParamFromHeader(c, PARAM_AUTHORIZATION_TOKEN)
 	teamID := model.TEAM_DEFAULT_ID
@@ -89,21 +103,13 @@ func (controller *Controller) CanAccess(c *gin.Context) {
 		return
 	}
 
-	// validate user
-	teamMemberRole := model.USER_ROLE_ANONYMOUS
-	if userID != model.USER_ROLE_ANONYMOUS {
-		teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
-		if errInRetrieveTeamMember != nil {
-			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve team member error: "+errInRetrieveTeamMember.Error())
-			return
-		}
-		teamMemberRole = teamMember.ExportUserRole()
-	}
-
 	// check attribute
-	attrg := accesscontrol.NewAttributeGroup(teamMemberRole, unitType)
-	attrg.SetUnitID(unitID)
-	if !attrg.CanAccess(attributeID) {
+	allowed, errInCheck := controller.CheckAccessControl(teamID, userID, model.ACCESS_CONTROL_CHECK_CATEGORY_ACCESS, unitType, unitID, attributeID, 0, 0)
+	if errInCheck != nil {
+		controller.FeedbackBadRequest(c, errInCheck.Flag, errInCheck.Message)
+		return
+	}
+	if !allowed {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
 		return
 	}
@@ -143,21 +149,13 @@ func (controller *Controller) CanManage(c *gin.Context) {
 		return
 	}
 
-	// validate user
-	teamMemberRole := model.USER_ROLE_ANONYMOUS
-	if userID != model.USER_ROLE_ANONYMOUS {
-		teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
-		if errInRetrieveTeamMember != nil {
-			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve team member error: "+errInRetrieveTeamMember.Error())
-			return
-		}
-		teamMemberRole = teamMember.ExportUserRole()
-	}
-
 	// check attribute
-	attrg := accesscontrol.NewAttributeGroup(teamMemberRole, unitType)
-	attrg.SetUnitID(unitID)
-	if !attrg.CanManage(attributeID) {
+	allowed, errInCheck := controller.CheckAccessControl(teamID, userID, model.ACCESS_CONTROL_CHECK_CATEGORY_MANAGE, unitType, unitID, attributeID, 0, 0)
+	if errInCheck != nil {
+		controller.FeedbackBadRequest(c, errInCheck.Flag, errInCheck.Message)
+		return
+	}
+	if !allowed {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
 		return
 	}
@@ -197,21 +195,13 @@ func (controller *Controller) CanManageSpecial(c *gin.Context) {
 		return
 	}
 
-	// validate user
-	teamMemberRole := model.USER_ROLE_ANONYMOUS
-	if userID != model.USER_ROLE_ANONYMOUS {
-		teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
-		if errInRetrieveTeamMember != nil {
-			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve team member error: "+errInRetrieveTeamMember.Error())
-			return
-		}
-		teamMemberRole = teamMember.ExportUserRole()
-	}
-
 	// check attribute
-	attrg := accesscontrol.NewAttributeGroup(teamMemberRole, unitType)
-	attrg.SetUnitID(unitID)
-	if !attrg.CanManageSpecial(attributeID) {
+	allowed, errInCheck := controller.CheckAccessControl(teamID, userID, model.ACCESS_CONTROL_CHECK_CATEGORY_MANAGE_SPECIAL, unitType, unitID, attributeID, 0, 0)
+	if errInCheck != nil {
+		controller.FeedbackBadRequest(c, errInCheck.Flag, errInCheck.Message)
+		return
+	}
+	if !allowed {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
 		return
 	}
@@ -255,21 +245,13 @@ func (controller *Controller) CanModify(c *gin.Context) {
 		return
 	}
 
-	// validate user
-	teamMemberRole := model.USER_ROLE_ANONYMOUS
-	if userID != model.USER_ROLE_ANONYMOUS {
-		teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
-		if errInRetrieveTeamMember != nil {
-			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve team member error: "+errInRetrieveTeamMember.Error())
-			return
-		}
-		teamMemberRole = teamMember.ExportUserRole()
-	}
-
 	// check attribute
-	attrg := accesscontrol.NewAttributeGroup(teamMemberRole, unitType)
-	attrg.SetUnitID(unitID)
-	if !attrg.CanModify(attributeID, fromID, toID) {
+	allowed, errInCheck := controller.CheckAccessControl(teamID, userID, model.ACCESS_CONTROL_CHECK_CATEGORY_MODIFY, unitType, unitID, attributeID, fromID, toID)
+	if errInCheck != nil {
+		controller.FeedbackBadRequest(c, errInCheck.Flag, errInCheck.Message)
+		return
+	}
+	if !allowed {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
 		return
 	}
@@ -309,21 +291,13 @@ func (controller *Controller) CanDelete(c *gin.Context) {
 		return
 	}
 
-	// validate user
-	teamMemberRole := model.USER_ROLE_ANONYMOUS
-	if userID != model.USER_ROLE_ANONYMOUS {
-		teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
-		if errInRetrieveTeamMember != nil {
-			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve team member error: "+errInRetrieveTeamMember.Error())
-			return
-		}
-		teamMemberRole = teamMember.ExportUserRole()
-	}
-
 	// check attribute
-	attrg := accesscontrol.NewAttributeGroup(teamMemberRole, unitType)
-	attrg.SetUnitID(unitID)
-	if !attrg.CanDelete(attributeID) {
+	allowed, errInCheck := controller.CheckAccessControl(teamID, userID, model.ACCESS_CONTROL_CHECK_CATEGORY_DELETE, unitType, unitID, attributeID, 0, 0)
+	if errInCheck != nil {
+		controller.FeedbackBadRequest(c, errInCheck.Flag, errInCheck.Message)
+		return
+	}
+	if !allowed {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
 		return
 	}
@@ -332,3 +306,138 @@ func (controller *Controller) CanDelete(c *gin.Context) {
 	controller.FeedbackOK(c, nil)
 	return
 }
+
+// BatchCheckAccessControl evaluate a list of checks for one principal with a single membership lookup.
+// the request token is signed by the authorization token and the raw request body.
+func (controller *Controller) BatchCheckAccessControl(c *gin.Context) {
+	authorizationToken, errInGetAuthorizationToken := controller.GetStringParamFromHeader(c, PARAM_AUTHORIZATION_TOKEN)
+	teamID := model.TEAM_DEFAULT_ID
+	userID := model.USER_ROLE_ANONYMOUS
+	var errInGetUserID error
+	if authorizationToken != accesscontrol.ANONYMOUS_AUTH_TOKEN {
+		userID, _, errInGetUserID = authenticator.ExtractUserIDFromToken(authorizationToken)
+	}
+	if errInGetAuthorizationToken != nil || errInGetUserID != nil {
+		return
+	}
+
+	// get request body
+	rawBody, errInReadBody := io.ReadAll(c.Request.Body)
+	if errInReadBody != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "read request body error: "+errInReadBody.Error())
+		return
+	}
+
+	// validate request data
+	validated, errInValidate := controller.ValidateRequestTokenFromHeader(c, authorizationToken, string(rawBody))
+	if !validated && errInValidate != nil {
+		return
+	}
+
+	req := model.NewBatchAccessControlRequest()
+	if err := json.Unmarshal(rawBody, &req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// check attributes
+	resp, errInCheck := controller.CheckAccessControlBatch(teamID, userID, req.Checks)
+	if errInCheck != nil {
+		controller.FeedbackBadRequest(c, errInCheck.Flag, errInCheck.Message)
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, resp)
+	return
+}
+
+func (controller *Controller) RetrieveUnitRoleRelationsForChecks(teamID int, userID int, userRole int, checks []*model.AccessControlCheck) (map[model.UnitRoleRelationKey][]*model.UnitRoleRelation, error) {
+	if userRole == model.USER_ROLE_ANONYMOUS {
+		return nil, nil
+	}
+	unitIDs := make([]int, 0, len(checks))
+	for _, check := range checks {
+		unitID, err := check.ExportUnitID()
+		if err != nil || unitID == accesscontrol.DEFAULT_UNIT_ID {
+			continue
+		}
+		unitIDs = append(unitIDs, unitID)
+	}
+	if len(unitIDs) == 0 {
+		return nil, nil
+	}
+	unitRoleRelations, err := controller.Storage.UnitRoleRelationStorage.RetrieveByTeamMemberAndUnitIDs(teamID, userRole, userID, unitIDs)
+	if err != nil {
+		return nil, err
+	}
+	return model.GroupUnitRoleRelationsByUnit(unitRoleRelations), nil
+}
+
+func EvaluateAccessControlCheck(userRole int, userStatus int, tp *model.TeamPermission, check *model.AccessControlCheck, unitRoleRelationsMap map[model.UnitRoleRelationKey][]*model.UnitRoleRelation) (bool, error) {
+	unitType, errInExportUnitType := check.ExportUnitType()
+	unitID, errInExportUnitID := check.ExportUnitID()
+	attributeID, errInExportAttributeID := check.ExportAttributeID()
+	if errInExportUnitType != nil || errInExportUnitID != nil || errInExportAttributeID != nil {
+		return false, errors.New("invalid unit type, unit id or attribute id format")
+	}
+	attrg := accesscontrol.NewAttributeGroup(userRole, unitType)
+	attrg.SetUserStatus(userStatus)
+	attrg.SetUnitID(unitID)
+	attrg.SetTeamPermission(tp)
+	if unitID != accesscontrol.DEFAULT_UNIT_ID && userRole != model.USER_ROLE_ANONYMOUS {
+		attrg.SetUnitRoleRelations(unitRoleRelationsMap[model.UnitRoleRelationKey{UnitType: unitType, UnitID: unitID}])
+	}
+	fromID, toID := 0, 0
+	if check.IsModify() {
+		var err error
+		if fromID, toID, err = check.ExportFromIDAndToID(); err != nil {
+			return false, err
+		}
+	}
+	return EvaluateAttributeGroup(attrg, check.Category, attributeID, fromID, toID)
+}
+
+// ApplyUnitRoleRelations load unit-level grants of the role and of the user on the unit, unit id 0 and anonymous user only use team-wide role.
+func (controller *Controller) ApplyUnitRoleRelations(attrg *accesscontrol.AttributeGroup, teamID int, userID int) error {
+	if attrg.UnitID == accesscontrol.DEFAULT_UNIT_ID || attrg.UserRole == model.USER_ROLE_ANONYMOUS {
+		return nil
+	}
+	unitRoleRelations, err := controller.Storage.UnitRoleRelationStorage.RetrieveByUnitForTeamMember(teamID, attrg.UnitType, attrg.UnitID, attrg.UserRole, userID)
+	if err != nil {
+		return err
+	}
+	attrg.SetUnitRoleRelations(unitRoleRelations)
+	return nil
+}
+
+// NewTeamMemberAttributeGroup build the attribute group of the team member on the unit type with the team permission applied,
+// it feedback the error when the team can not be fetched.
+func (controller *Controller) NewTeamMemberAttributeGroup(c *gin.Context, teamMember *model.TeamMember, teamID int, unitType int) (*accesscontrol.AttributeGroup, error) {
+	attrg := accesscontrol.NewAttributeGroupByTeamMember(teamMember, unitType)
+	if err := controller.ApplyTeamPermission(attrg, teamID); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+err.Error())
+		return nil, err
+	}
+	return attrg, nil
+}
+
+// ApplyTeamPermission load team permission toggles, skipped when no toggle can revoke attribute of the role on the unit type.
+func (controller *Controller) ApplyTeamPermission(attrg *accesscontrol.AttributeGroup, teamID int) error {
+	if !accesscontrol.IsAffectedByTeamPermission(attrg.UserRole, attrg.UnitType) {
+		return nil
+	}
+	team, err := controller.Storage.TeamStorage.RetrieveByID(teamID)
+	if err != nil {
+		return err
+	}
+	attrg.SetTeamPermission(team.ExportTeamPermission())
+	return nil
+}
@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/accesscontrol"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/authenticator"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+)
+
+// ExplainAccessControl evaluate a single check like the check endpoints, and feedback the decision trace instead of the verdict only.
+// the request token is signed by the authorization token and the raw request body.
+func (controller *Controller) ExplainAccessControl(c *gin.Context) {
+	authorizationToken, errInGetAuthorizationToken := controller.GetStringParamFromHeader(c, PARAM_AUTHORIZATION_TOKEN)
+	teamID := model.TEAM_DEFAULT_ID
+	if errInGetAuthorizationToken != nil {
+		return
+	}
+
+	// get request body
+	rawBody, errInReadBody := io.ReadAll(c.Request.Body)
+	if errInReadBody != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "read request body error: "+errInReadBody.Error())
+		return
+	}
+
+	// validate request data
+	validated, errInValidate := controller.ValidateRequestTokenFromHeader(c, authorizationToken, string(rawBody))
+	if !validated && errInValidate != nil {
+		return
+	}
+
+	req := model.NewAccessControlCheck()
+	if err := json.Unmarshal(rawBody, &req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+	resp := model.NewExplainAccessControlResponse(teamID, req)
+
+	// resolve principal
+	userID := model.USER_ROLE_ANONYMOUS
+	var errInGetUserID error
+	if authorizationToken != accesscontrol.ANONYMOUS_AUTH_TOKEN {
+		userID, _, errInGetUserID = authenticator.ExtractUserIDFromToken(authorizationToken)
+	}
+	resp.SetPrincipal(userID, errInGetUserID)
+	if errInGetUserID != nil {
+		decision := accesscontrol.NewDeniedDecision(accesscontrol.DECISION_RULE_SOURCE_PRINCIPAL, accesscontrol.DECISION_REASON_INVALID_PRINCIPAL, "authorization token can not be resolved")
+		resp.SetDecision(decision.Rules, decision.Allowed, decision.Reason)
+		controller.FeedbackOK(c, resp)
+		return
+	}
+
+	// resolve team membership
+	teamMemberRole := model.USER_ROLE_ANONYMOUS
+	teamMemberStatus := accesscontrol.STATUS_OK
+	if userID != model.USER_ROLE_ANONYMOUS {
+		teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+		if errInRetrieveTeamMember != nil {
+			resp.SetMembershipError(errInRetrieveTeamMember)
+			decision := accesscontrol.NewDeniedDecision(accesscontrol.DECISION_RULE_SOURCE_MEMBERSHIP, accesscontrol.DECISION_REASON_NOT_TEAM_MEMBER, "user is not a member of the team")
+			resp.SetDecision(decision.Rules, decision.Allowed, decision.Reason)
+			controller.FeedbackOK(c, resp)
+			return
+		}
+		teamMemberRole = teamMember.ExportUserRole()
+		teamMemberStatus = teamMember.ExportStatus()
+		resp.SetMembership(teamMember, controller.RetrieveRoleName(teamMemberRole))
+	}
+
+	// parse check
+	unitType, errInExportUnitType := req.ExportUnitType()
+	unitID, errInExportUnitID := req.ExportUnitID()
+	attributeID, errInExportAttributeID := req.ExportAttributeID()
+	if errInExportUnitType != nil || errInExportUnitID != nil || errInExportAttributeID != nil {
+		decision := accesscontrol.NewDeniedDecision(accesscontrol.DECISION_RULE_SOURCE_ROLE, accesscontrol.DECISION_REASON_INVALID_CHECK, "invalid unit type, unit id or attribute id format")
+		resp.SetDecision(decision.Rules, decision.Allowed, decision.Reason)
+		controller.FeedbackOK(c, resp)
+		return
+	}
+
+	// load everything the check endpoints consider
+	attrg := accesscontrol.NewAttributeGroup(teamMemberRole, unitType)
+	attrg.SetUserStatus(teamMemberStatus)
+	attrg.SetUnitID(unitID)
+	if err := controller.ApplyUnitRoleRelations(attrg, teamID, userID); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_UNIT_ROLE_RELATION, "retrieve unit role relation error: "+err.Error())
+		return
+	}
+	if err := controller.ApplyTeamPermission(attrg, teamID); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+err.Error())
+		return
+	}
+
+	// explain
+	decision := ExplainAccessControlCheck(attrg, req, attributeID)
+	resp.SetDecision(decision.Rules, decision.Allowed, decision.Reason)
+
+	// feedback
+	controller.FeedbackOK(c, resp)
+	return
+}
+
+func ExplainAccessControlCheck(attrg *accesscontrol.AttributeGroup, check *model.AccessControlCheck, attributeID int) *accesscontrol.Decision {
+	switch check.Category {
+	case model.ACCESS_CONTROL_CHECK_CATEGORY_ACCESS:
+		return attrg.Explain(accesscontrol.ATTRIBUTE_CATEGORY_ACCESS, attributeID)
+	case model.ACCESS_CONTROL_CHECK_CATEGORY_DELETE:
+		return attrg.Explain(accesscontrol.ATTRIBUTE_CATEGORY_DELETE, attributeID)
+	case model.ACCESS_CONTROL_CHECK_CATEGORY_MANAGE:
+		return attrg.Explain(accesscontrol.ATTRIBUTE_CATEGORY_MANAGE, attributeID)
+	case model.ACCESS_CONTROL_CHECK_CATEGORY_MANAGE_SPECIAL:
+		return attrg.Explain(accesscontrol.ATTRIBUTE_CATEGORY_SPECIAL, attributeID)
+	case model.ACCESS_CONTROL_CHECK_CATEGORY_MODIFY:
+		fromID, toID, err := check.ExportFromIDAndToID()
+		if err != nil {
+			return accesscontrol.NewDeniedDecision(accesscontrol.DECISION_RULE_SOURCE_ROLE, accesscontrol.DECISION_REASON_INVALID_CHECK, err.Error())
+		}
+		if attributeID != accesscontrol.ACTION_MANAGE_ROLE {
+			return accesscontrol.NewDeniedDecision(accesscontrol.DECISION_RULE_SOURCE_ROLE, accesscontrol.DECISION_REASON_INVALID_CHECK, "only role modification is supported")
+		}
+		return attrg.ExplainModifyRoleFromTo(fromID, toID)
+	}
+	return accesscontrol.NewDeniedDecision(accesscontrol.DECISION_RULE_SOURCE_ROLE, accesscontrol.DECISION_REASON_INVALID_CHECK, "unknown check category")
+}
+
+// RetrieveRoleName export name of system role or custom role, empty for unknown role.
+func (controller *Controller) RetrieveRoleName(roleID int) string {
+	if name, hit := model.SystemRoleNameMap[roleID]; hit {
+		return name
+	}
+	role, err := controller.Storage.RoleStorage.RetrieveByID(roleID)
+	if err != nil {
+		return ""
+	}
+	return role.Name
+}
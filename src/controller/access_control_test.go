@@ -0,0 +1,291 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/accesscontrol"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/authenticator"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/internal/testdb"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/idconvertor"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/tokenvalidator"
+	"go.uber.org/zap"
+)
+
+// newTestInternalController serve the internal requests of the self-host team, which are signed by the request token.
+func newTestInternalController(t *testing.T) *Controller {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	controller := newTestSelfHostController(t)
+	controller.Cache = testdb.NewCache(t)
+	controller.Authenticator = authenticator.NewAuthenticator(controller.Storage, controller.Cache, zap.NewNop().Sugar())
+	controller.RequestTokenValidator = tokenvalidator.NewRequestTokenValidator(controller.Cache.RequestNonceCache)
+	return controller
+}
+
+// serveSignedRequest sign the raw body as the only param of request, as the json body internal endpoints do.
+func serveSignedRequest(t *testing.T, controller *Controller, path string, handler gin.HandlerFunc, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	engine := gin.New()
+	engine.POST(path, handler)
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce := uuid.NewString()
+	req.Header.Set(PARAM_REQUEST_TIMESTAMP, timestamp)
+	req.Header.Set(PARAM_REQUEST_NONCE, nonce)
+	req.Header.Set(PARAM_REQUEST_TOKEN, controller.RequestTokenValidator.GenerateSignedToken(http.MethodPost, path, []string{body}, timestamp, nonce))
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// signInTestUser create the user and feedback the access token of its sign in session.
+func signInTestUser(t *testing.T, controller *Controller, email string) (*model.User, string) {
+	t.Helper()
+	user := &model.User{Nickname: strings.Split(email, "@")[0], Email: email}
+	user.InitUID()
+	if _, err := controller.Storage.UserStorage.Create(user); err != nil {
+		t.Fatalf("create user failed: %v", err)
+	}
+	accessToken, err := authenticator.CreateAccessToken(user.ID, user.UID)
+	if err != nil {
+		t.Fatalf("create access token failed: %v", err)
+	}
+	expiresAt, err := authenticator.ExtractExpiresAtFromTokenInString(accessToken)
+	if err != nil {
+		t.Fatalf("extract expires at failed: %v", err)
+	}
+	if err := controller.Cache.JWTCache.InitUserJWTTokenExpiredAt(user, expiresAt); err != nil {
+		t.Fatalf("sign in failed: %v", err)
+	}
+	return user, accessToken
+}
+
+func TestIntrospectToken(t *testing.T) {
+	controller := newTestInternalController(t)
+	member, memberToken := signInTestUser(t, controller, "member@acme.com")
+	outsider, outsiderToken := signInTestUser(t, controller, "outsider@acme.com")
+	createTestTeamMember(t, controller.Storage, model.TEAM_DEFAULT_ID, member.ID, model.USER_ROLE_EDITOR, model.TEAM_MEMBER_STATUS_OK)
+	signedOut, signedOutToken := signInTestUser(t, controller, "signed-out@acme.com")
+	if err := controller.Cache.JWTCache.CleanUserJWTTokenExpiredAt(signedOut); err != nil {
+		t.Fatalf("sign out failed: %v", err)
+	}
+	deletedUserToken, err := authenticator.CreateAccessToken(member.ID+100, uuid.New())
+	if err != nil {
+		t.Fatalf("create access token failed: %v", err)
+	}
+	team, err := controller.Storage.TeamStorage.RetrieveByID(model.TEAM_DEFAULT_ID)
+	if err != nil {
+		t.Fatalf("get team failed: %v", err)
+	}
+	introspect := func(body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		recorder := serveSignedRequest(t, controller, "/account/introspect", controller.IntrospectToken, body)
+		var resp map[string]interface{}
+		if recorder.Code == http.StatusOK {
+			if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response failed: %v", err)
+			}
+		}
+		return recorder, resp
+	}
+
+	t.Run("without team", func(t *testing.T) {
+		_, resp := introspect(`{"token":"` + memberToken + `"}`)
+		if resp["active"] != true || resp["token_type"] != model.INTROSPECT_TOKEN_TYPE || resp["iss"] != authenticator.ACCESS_TOKEN_ISSUER {
+			t.Errorf("resp = %v, want an active access token", resp)
+		}
+		if resp["sub"] != member.UID.String() || resp["userID"] != idconvertor.ConvertIntToString(member.ID) || resp["email"] != member.Email || resp["sessionID"] == "" {
+			t.Errorf("resp = %v, want the user of token", resp)
+		}
+		if _, hit := resp["membership"]; hit {
+			t.Errorf("membership feedback without team: %v", resp["membership"])
+		}
+		if _, hit := resp["scope"]; hit {
+			t.Errorf("scope feedback without team: %v", resp["scope"])
+		}
+	})
+
+	// the team id is not resolved, the membership is in the default team
+	for _, teamID := range []string{idconvertor.ConvertIntToString(model.TEAM_DEFAULT_ID), "malformed-team"} {
+		t.Run("member with team "+teamID, func(t *testing.T) {
+			_, resp := introspect(`{"token":"` + memberToken + `","teamID":"` + teamID + `"}`)
+			membership, _ := resp["membership"].(map[string]interface{})
+			if membership == nil || membership["teamID"] != idconvertor.ConvertIntToString(model.TEAM_DEFAULT_ID) || membership["userRole"] != float64(model.USER_ROLE_EDITOR) {
+				t.Fatalf("membership = %v, want editor of the default team", resp["membership"])
+			}
+			scopes := strings.Fields(resp["scope"].(string))
+			wantScopes := accesscontrol.ExportScopes(model.USER_ROLE_EDITOR, model.TEAM_MEMBER_STATUS_OK, team.ExportTeamPermission())
+			sort.Strings(scopes)
+			sort.Strings(wantScopes)
+			if len(scopes) == 0 || strings.Join(scopes, " ") != strings.Join(wantScopes, " ") {
+				t.Errorf("scopes = %v, want %v", scopes, wantScopes)
+			}
+		})
+	}
+
+	t.Run("outsider with team", func(t *testing.T) {
+		_, resp := introspect(`{"token":"` + outsiderToken + `","teamID":"` + idconvertor.ConvertIntToString(model.TEAM_DEFAULT_ID) + `"}`)
+		if resp["active"] != true || resp["sub"] != outsider.UID.String() {
+			t.Errorf("resp = %v, want the active token of outsider", resp)
+		}
+		if _, hit := resp["membership"]; hit {
+			t.Errorf("membership of outsider = %v", resp["membership"])
+		}
+	})
+
+	// an inactive token only feedback active false, the reason is not exposed
+	inactiveTokens := []struct {
+		name  string
+		token string
+	}{
+		{"malformed", "not-a-jwt"},
+		{"signed out", signedOutToken},
+		{"user not found", deletedUserToken},
+	}
+	for _, c := range inactiveTokens {
+		t.Run("inactive "+c.name, func(t *testing.T) {
+			recorder, _ := introspect(`{"token":"` + c.token + `","teamID":"` + idconvertor.ConvertIntToString(model.TEAM_DEFAULT_ID) + `"}`)
+			if recorder.Code != http.StatusOK || strings.TrimSpace(recorder.Body.String()) != `{"active":false}` {
+				t.Errorf("response = %d %s, want {\"active\":false}", recorder.Code, recorder.Body.String())
+			}
+		})
+	}
+
+	// the anonymous token is empty, it is rejected by validation
+	t.Run("missing token", func(t *testing.T) {
+		if recorder, _ := introspect(`{"teamID":"` + idconvertor.ConvertIntToString(model.TEAM_DEFAULT_ID) + `"}`); recorder.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want %d", recorder.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestBatchCheckAccessControl(t *testing.T) {
+	controller := newTestInternalController(t)
+	editor, editorToken := signInTestUser(t, controller, "editor@acme.com")
+	createTestTeamMember(t, controller.Storage, model.TEAM_DEFAULT_ID, editor.ID, model.USER_ROLE_EDITOR, model.TEAM_MEMBER_STATUS_OK)
+	_, outsiderToken := signInTestUser(t, controller, "outsider@acme.com")
+	// deny the editors to edit the test app only
+	unitRoleRelation := model.NewUnitRoleRelation(model.TEAM_DEFAULT_ID, accesscontrol.UNIT_TYPE_APP, testAppID, model.USER_ROLE_EDITOR)
+	unitRoleRelation.UpdateByCreateUnitRoleRelationRequest(&model.CreateUnitRoleRelationRequest{
+		Effect:      model.UNIT_ROLE_RELATION_EFFECT_DENY,
+		Permissions: model.UnitPermissions{accesscontrol.ATTRIBUTE_CATEGORY_MANAGE: {accesscontrol.ACTION_MANAGE_EDIT_APP: true}},
+	})
+	if _, err := controller.Storage.UnitRoleRelationStorage.Create(unitRoleRelation); err != nil {
+		t.Fatalf("create unit role relation failed: %v", err)
+	}
+
+	// the request token is signed for the signedBody, which is the body unless it is forged
+	const path = "/accessControl/batch"
+	serveBatchCheck := func(authorizationToken string, body string, signedBody string) *httptest.ResponseRecorder {
+		engine := gin.New()
+		engine.POST(path, controller.BatchCheckAccessControl)
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		nonce := uuid.NewString()
+		req.Header.Set(PARAM_AUTHORIZATION_TOKEN, authorizationToken)
+		req.Header.Set(PARAM_REQUEST_TIMESTAMP, timestamp)
+		req.Header.Set(PARAM_REQUEST_NONCE, nonce)
+		req.Header.Set(PARAM_REQUEST_TOKEN, controller.RequestTokenValidator.GenerateSignedToken(http.MethodPost, path, []string{authorizationToken, signedBody}, timestamp, nonce))
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, req)
+		return recorder
+	}
+	batchCheck := func(authorizationToken string, body string) *httptest.ResponseRecorder {
+		return serveBatchCheck(authorizationToken, body, body)
+	}
+	newCheck := func(category string, unitType int, unitID int, attributeID int) *model.AccessControlCheck {
+		return &model.AccessControlCheck{
+			Category:    category,
+			UnitType:    idconvertor.ConvertIntToString(unitType),
+			UnitID:      idconvertor.ConvertIntToString(unitID),
+			AttributeID: idconvertor.ConvertIntToString(attributeID),
+		}
+	}
+	modifyViewerToEditor := newCheck(model.ACCESS_CONTROL_CHECK_CATEGORY_MODIFY, accesscontrol.UNIT_TYPE_TEAM_MEMBER, 0, accesscontrol.ACTION_MANAGE_ROLE)
+	modifyViewerToEditor.FromID = idconvertor.ConvertIntToString(model.USER_ROLE_VIEWER)
+	modifyViewerToEditor.ToID = idconvertor.ConvertIntToString(model.USER_ROLE_EDITOR)
+	malformedUnitID := newCheck(model.ACCESS_CONTROL_CHECK_CATEGORY_ACCESS, accesscontrol.UNIT_TYPE_APP, testAppID, accesscontrol.ACTION_ACCESS_VIEW)
+	malformedUnitID.UnitID = "malformed"
+	checks := []*model.AccessControlCheck{
+		newCheck(model.ACCESS_CONTROL_CHECK_CATEGORY_MANAGE, accesscontrol.UNIT_TYPE_APP, testAppID, accesscontrol.ACTION_MANAGE_EDIT_APP),
+		newCheck(model.ACCESS_CONTROL_CHECK_CATEGORY_MANAGE, accesscontrol.UNIT_TYPE_APP, testAppID+1, accesscontrol.ACTION_MANAGE_EDIT_APP),
+		newCheck(model.ACCESS_CONTROL_CHECK_CATEGORY_ACCESS, accesscontrol.UNIT_TYPE_APP, testAppID, accesscontrol.ACTION_ACCESS_VIEW),
+		newCheck(model.ACCESS_CONTROL_CHECK_CATEGORY_MANAGE, accesscontrol.UNIT_TYPE_TEAM, 0, accesscontrol.ACTION_MANAGE_TEAM_NAME),
+		modifyViewerToEditor,
+		malformedUnitID,
+	}
+	body, _ := json.Marshal(&model.BatchAccessControlRequest{Checks: checks})
+
+	recorder := batchCheck(editorToken, string(body))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", recorder.Code, recorder.Body.String())
+	}
+	resp := &model.BatchAccessControlResponse{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), resp); err != nil {
+		t.Fatalf("decode response failed: %v", err)
+	}
+	if len(resp.Results) != len(checks) {
+		t.Fatalf("results = %d, want %d", len(resp.Results), len(checks))
+	}
+	// every result is the same as the single check, in the order of checks
+	for i, check := range checks[:len(checks)-1] {
+		unitType, _ := check.ExportUnitType()
+		unitID, _ := check.ExportUnitID()
+		attributeID, _ := check.ExportAttributeID()
+		fromID, toID := 0, 0
+		if check.IsModify() {
+			fromID, toID, _ = check.ExportFromIDAndToID()
+		}
+		allowed, errInCheck := controller.CheckAccessControl(model.TEAM_DEFAULT_ID, editor.ID, check.Category, unitType, unitID, attributeID, fromID, toID)
+		if errInCheck != nil {
+			t.Fatalf("check %d failed: %v", i, errInCheck.Message)
+		}
+		if resp.Results[i].Allowed != allowed || resp.Results[i].Error != "" {
+			t.Errorf("result %d = %+v, want allowed %v", i, resp.Results[i], allowed)
+		}
+	}
+	if resp.Results[0].Allowed || !resp.Results[1].Allowed {
+		t.Errorf("edit app results = %+v, %+v, want denied on the test app only", resp.Results[0], resp.Results[1])
+	}
+	if malformed := resp.Results[len(checks)-1]; malformed.Allowed || malformed.Error == "" {
+		t.Errorf("malformed check = %+v, want an error result", malformed)
+	}
+
+	tooManyChecks := make([]*model.AccessControlCheck, 201)
+	for i := range tooManyChecks {
+		tooManyChecks[i] = checks[2]
+	}
+	tooManyBody, _ := json.Marshal(&model.BatchAccessControlRequest{Checks: tooManyChecks})
+	cases := []struct {
+		name               string
+		authorizationToken string
+		body               string
+	}{
+		{"outsider", outsiderToken, string(body)},
+		{"no check", editorToken, `{"checks":[]}`},
+		{"too many checks", editorToken, string(tooManyBody)},
+		{"unknown category", editorToken, `{"checks":[{"category":"canFly","unitType":"a","unitID":"b","attributeID":"c"}]}`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if recorder := batchCheck(c.authorizationToken, c.body); recorder.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d: %s", recorder.Code, http.StatusBadRequest, recorder.Body.String())
+			}
+		})
+	}
+	t.Run("request token of another body", func(t *testing.T) {
+		recorder := serveBatchCheck(editorToken, string(body), `{"checks":[]}`)
+		if recorder.Code != http.StatusBadRequest || !strings.Contains(recorder.Body.String(), ERROR_FLAG_VALIDATE_REQUEST_TOKEN_FAILED) {
+			t.Errorf("status = %d, want %s: %s", recorder.Code, ERROR_FLAG_VALIDATE_REQUEST_TOKEN_FAILED, recorder.Body.String())
+		}
+	})
+}
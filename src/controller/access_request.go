@@ -0,0 +1,524 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/accesscontrol"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/notification"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/idconvertor"
+)
+
+var errAccessRequestIsNotPending = errors.New("access request is not pending")
+var errAccessRequestDeniedByUnitRoleRelation = errors.New("the requester or its role is denied on the unit")
+
+// CreateAccessRequest ask for a target role, or a single attribute on a unit, the team members who can grant it will be notified.
+func (controller *Controller) CreateAccessRequest(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewCreateAccessRequestRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+	if err := req.ValidateTarget(); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+	if err := req.ValidateValidUntil(); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_INVALID_ROLE_VALID_UNTIL, "validate validUntil error: "+err.Error())
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+	if !teamMember.IsStatusOK() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// validate target
+	accessRequest := model.NewAccessRequestByCreateAccessRequestRequest(teamMember, req)
+	if accessRequest.IsRoleRequest() {
+		if req.TargetRole == model.USER_ROLE_OWNER {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_OWNER_ROLE_MUST_BE_TRANSFERED, "owner role can not be requested.")
+			return
+		}
+		if teamMember.IsOwner() || req.TargetRole == teamMember.ExportUserRole() {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_REQUEST_IS_NOT_NEEDED, "you already have the target role.")
+			return
+		}
+		if _, err := controller.Storage.RoleStorage.RetrieveByTeamIDAndID(teamID, req.TargetRole); err != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ROLE, "get role error: "+err.Error())
+			return
+		}
+	} else {
+		if !accesscontrol.IsUnitTypeDefined(req.UnitType) || !accesscontrol.IsAttributeDefined(req.AttributeCategory, req.Attribute) {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: unknown unit type or attribute.")
+			return
+		}
+	}
+
+	// one pending request for the same access at a time
+	duplicates, errInCount := controller.Storage.AccessRequestStorage.CountPendingDuplicates(accessRequest)
+	if errInCount != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ACCESS_REQUEST, "get access request error: "+errInCount.Error())
+		return
+	}
+	if duplicates > 0 {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_REQUEST_ALREADY_PENDING, "the same access request is pending.")
+		return
+	}
+
+	// create
+	var events []*model.AccessRequestEvent
+	errInCreate := controller.Storage.Transaction(func(txStorage *model.Storage) error {
+		if _, err := txStorage.AccessRequestStorage.Create(accessRequest); err != nil {
+			return err
+		}
+		event := model.NewAccessRequestEvent(accessRequest, userID, model.ACCESS_REQUEST_ACTION_CREATE, accessRequest.Justification)
+		if _, err := txStorage.AccessRequestEventStorage.Create(event); err != nil {
+			return err
+		}
+		events = append(events, event)
+		return nil
+	})
+	if errInCreate != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_CREATE_ACCESS_REQUEST, "create access request error: "+errInCreate.Error())
+		return
+	}
+
+	// notify reviewers, the request is saved already so notify failure does not fail the request
+	controller.NotifyAccessRequestReviewers(teamMember, accessRequest)
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetAccessRequestResponse(accessRequest, events))
+	return
+}
+
+// GetAllAccessRequests list access requests of the team for members who can manage roles, and the requests of their own for others.
+func (controller *Controller) GetAllAccessRequests(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// build filter
+	statusRaw, _ := controller.TestFirstStringParamValueFromURI(c, PARAM_ACCESS_REQUEST_STATUS)
+	status, errInParseStatus := model.ParseAccessRequestStatus(statusRaw)
+	if errInParseStatus != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_PARAM_FAILED, "validate request param error: "+errInParseStatus.Error())
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// validate user role
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_TEAM_MEMBER)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+
+	// fetch
+	var accessRequests []*model.AccessRequest
+	var errInRetrieve error
+	if attrg.CanManage(accesscontrol.ACTION_MANAGE_ROLE) {
+		accessRequests, errInRetrieve = controller.Storage.AccessRequestStorage.RetrieveByTeamID(teamID, status)
+	} else {
+		accessRequests, errInRetrieve = controller.Storage.AccessRequestStorage.RetrieveByTeamIDAndTeamMemberID(teamID, teamMember.ExportID(), status)
+	}
+	if errInRetrieve != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ACCESS_REQUEST, "get access request error: "+errInRetrieve.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetAllAccessRequestsResponse(accessRequests))
+	return
+}
+
+// GetAccessRequest feedback the access request with its audit history.
+func (controller *Controller) GetAccessRequest(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	accessRequestID, errInGetAccessRequestID := controller.GetMagicIntParamFromRequest(c, PARAM_ACCESS_REQUEST_ID)
+	if errInGetUserID != nil || errInGetAccessRequestID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// get access request
+	accessRequest, errInRetrieveAccessRequest := controller.Storage.AccessRequestStorage.RetrieveByTeamIDAndID(teamID, accessRequestID)
+	if errInRetrieveAccessRequest != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ACCESS_REQUEST, "get access request error: "+errInRetrieveAccessRequest.Error())
+		return
+	}
+
+	// validate user role
+	if !accessRequest.IsRequestedBy(teamMember) {
+		attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_TEAM_MEMBER)
+		if errInApplyTeamPermission != nil {
+			return
+		}
+		if !attrg.CanManage(accesscontrol.ACTION_MANAGE_ROLE) {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+			return
+		}
+	}
+
+	// get history
+	events, errInRetrieveEvents := controller.Storage.AccessRequestEventStorage.RetrieveByTeamIDAndAccessRequestID(teamID, accessRequestID)
+	if errInRetrieveEvents != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ACCESS_REQUEST, "get access request history error: "+errInRetrieveEvents.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetAccessRequestResponse(accessRequest, events))
+	return
+}
+
+// ApproveAccessRequest grant the requested access and close the request.
+// unit attribute is granted to the requester only by a unit role relation of the user, the other members of the role are untouched.
+func (controller *Controller) ApproveAccessRequest(c *gin.Context) {
+	controller.reviewAccessRequest(c, model.ACCESS_REQUEST_ACTION_APPROVE)
+}
+
+// DenyAccessRequest close the request without granting anything.
+func (controller *Controller) DenyAccessRequest(c *gin.Context) {
+	controller.reviewAccessRequest(c, model.ACCESS_REQUEST_ACTION_DENY)
+}
+
+func (controller *Controller) reviewAccessRequest(c *gin.Context, action int) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	accessRequestID, errInGetAccessRequestID := controller.GetMagicIntParamFromRequest(c, PARAM_ACCESS_REQUEST_ID)
+	if errInGetUserID != nil || errInGetAccessRequestID != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewReviewAccessRequestRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// get access request
+	accessRequest, errInRetrieveAccessRequest := controller.Storage.AccessRequestStorage.RetrieveByTeamIDAndID(teamID, accessRequestID)
+	if errInRetrieveAccessRequest != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ACCESS_REQUEST, "get access request error: "+errInRetrieveAccessRequest.Error())
+		return
+	}
+	if !accessRequest.IsPending() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_REQUEST_IS_NOT_PENDING, "access request is not pending.")
+		return
+	}
+	if accessRequest.IsRequestedBy(teamMember) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_REVIEW_YOUR_OWN_ACCESS_REQUEST, "can not review your own access request.")
+		return
+	}
+
+	// get requester
+	requester, errInRetrieveRequester := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndID(teamID, accessRequest.ExportTeamMemberID())
+	if errInRetrieveRequester != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve requester team member error: "+errInRetrieveRequester.Error())
+		return
+	}
+
+	// validate user role, only who can grant the access can review it
+	canReview, errInCheck := controller.CanReviewAccessRequest(teamMember, requester, accessRequest)
+	if errInCheck != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+errInCheck.Error())
+		return
+	}
+	if !canReview {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// review and apply in one transaction
+	var errFlag string
+	errInReview := controller.Storage.Transaction(func(txStorage *model.Storage) error {
+		if action == model.ACCESS_REQUEST_ACTION_APPROVE {
+			accessRequest.Approve(userID, req.ExportComment())
+		} else {
+			accessRequest.Deny(userID, req.ExportComment())
+		}
+		updated, err := txStorage.AccessRequestStorage.UpdateStatusFromPending(accessRequest)
+		if err != nil {
+			errFlag = ERROR_FLAG_CAN_NOT_UPDATE_ACCESS_REQUEST
+			return err
+		}
+		if !updated {
+			errFlag = ERROR_FLAG_ACCESS_REQUEST_IS_NOT_PENDING
+			return errAccessRequestIsNotPending
+		}
+		if _, err := txStorage.AccessRequestEventStorage.Create(model.NewAccessRequestEvent(accessRequest, userID, action, req.ExportComment())); err != nil {
+			errFlag = ERROR_FLAG_CAN_NOT_UPDATE_ACCESS_REQUEST
+			return err
+		}
+		if action != model.ACCESS_REQUEST_ACTION_APPROVE {
+			return nil
+		}
+		errFlag, err = applyAccessRequest(txStorage, requester, accessRequest)
+		return err
+	})
+	if errInReview != nil {
+		controller.FeedbackBadRequest(c, errFlag, "review access request error: "+errInReview.Error())
+		return
+	}
+
+	// notify requester
+	category := notification.NOTICE_CATEGORY_ACCESS_REQUEST_DENIED
+	if action == model.ACCESS_REQUEST_ACTION_APPROVE {
+		category = notification.NOTICE_CATEGORY_ACCESS_REQUEST_APPROVED
+	}
+	controller.notifyAccessRequest(category, accessRequest, []int{accessRequest.ExportUserID()})
+
+	// feedback
+	events, errInRetrieveEvents := controller.Storage.AccessRequestEventStorage.RetrieveByTeamIDAndAccessRequestID(teamID, accessRequestID)
+	if errInRetrieveEvents != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ACCESS_REQUEST, "get access request history error: "+errInRetrieveEvents.Error())
+		return
+	}
+	controller.FeedbackOK(c, model.NewGetAccessRequestResponse(accessRequest, events))
+	return
+}
+
+// CancelAccessRequest withdraw a pending access request by the requester.
+func (controller *Controller) CancelAccessRequest(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	accessRequestID, errInGetAccessRequestID := controller.GetMagicIntParamFromRequest(c, PARAM_ACCESS_REQUEST_ID)
+	if errInGetUserID != nil || errInGetAccessRequestID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// get access request
+	accessRequest, errInRetrieveAccessRequest := controller.Storage.AccessRequestStorage.RetrieveByTeamIDAndID(teamID, accessRequestID)
+	if errInRetrieveAccessRequest != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ACCESS_REQUEST, "get access request error: "+errInRetrieveAccessRequest.Error())
+		return
+	}
+	if !accessRequest.IsRequestedBy(teamMember) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+	if !accessRequest.IsPending() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_REQUEST_IS_NOT_PENDING, "access request is not pending.")
+		return
+	}
+
+	// cancel
+	var errFlag string
+	errInCancel := controller.Storage.Transaction(func(txStorage *model.Storage) error {
+		accessRequest.Cancel()
+		updated, err := txStorage.AccessRequestStorage.UpdateStatusFromPending(accessRequest)
+		if err != nil {
+			errFlag = ERROR_FLAG_CAN_NOT_UPDATE_ACCESS_REQUEST
+			return err
+		}
+		if !updated {
+			errFlag = ERROR_FLAG_ACCESS_REQUEST_IS_NOT_PENDING
+			return errAccessRequestIsNotPending
+		}
+		if _, err := txStorage.AccessRequestEventStorage.Create(model.NewAccessRequestEvent(accessRequest, userID, model.ACCESS_REQUEST_ACTION_CANCEL, "")); err != nil {
+			errFlag = ERROR_FLAG_CAN_NOT_UPDATE_ACCESS_REQUEST
+			return err
+		}
+		return nil
+	})
+	if errInCancel != nil {
+		controller.FeedbackBadRequest(c, errFlag, "cancel access request error: "+errInCancel.Error())
+		return
+	}
+
+	// feedback
+	events, errInRetrieveEvents := controller.Storage.AccessRequestEventStorage.RetrieveByTeamIDAndAccessRequestID(teamID, accessRequestID)
+	if errInRetrieveEvents != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ACCESS_REQUEST, "get access request history error: "+errInRetrieveEvents.Error())
+		return
+	}
+	controller.FeedbackOK(c, model.NewGetAccessRequestResponse(accessRequest, events))
+	return
+}
+
+// CanReviewAccessRequest check the reviewer can grant the access by hand, with the same checks as the role and unit role relation endpoints.
+func (controller *Controller) CanReviewAccessRequest(reviewer *model.TeamMember, requester *model.TeamMember, accessRequest *model.AccessRequest) (bool, error) {
+	if reviewer.ExportID() == requester.ExportID() {
+		return false, nil
+	}
+	memberAttrg := accesscontrol.NewAttributeGroupByTeamMember(reviewer, accesscontrol.UNIT_TYPE_TEAM_MEMBER)
+	if err := controller.ApplyTeamPermission(memberAttrg, accessRequest.TeamID); err != nil {
+		return false, err
+	}
+	if accessRequest.IsRoleRequest() {
+		return memberAttrg.CanManage(accesscontrol.ACTION_MANAGE_ROLE) && memberAttrg.CanModifyRoleFromTo(requester.ExportUserRole(), accessRequest.TargetRole), nil
+	}
+	attrg := accesscontrol.NewAttributeGroupByTeamMember(reviewer, accesscontrol.UNIT_TYPE_UNIT_ROLE_RELATIONS)
+	if err := controller.ApplyTeamPermission(attrg, accessRequest.TeamID); err != nil {
+		return false, err
+	}
+	if !attrg.CanManage(accesscontrol.ACTION_MANAGE_UNIT_ROLE_RELATION) || !memberAttrg.CanModifyRoleFromTo(requester.ExportUserRole(), requester.ExportUserRole()) {
+		return false, nil
+	}
+	unitRoleRelation := model.NewUnitRoleRelation(accessRequest.TeamID, accessRequest.UnitType, accessRequest.UnitID, requester.ExportUserRole())
+	unitRoleRelation.AllowPermissions(accessRequest.ExportUnitPermissions())
+	return accesscontrol.CanGrantRolePermissions(reviewer.ExportUserRole(), unitRoleRelation.ExportRolePermissions()), nil
+}
+
+// NotifyAccessRequestReviewers notify the team members who can review the access request.
+// the review permission only depends on the role, so it is checked once per role held in the team.
+func (controller *Controller) NotifyAccessRequestReviewers(requester *model.TeamMember, accessRequest *model.AccessRequest) {
+	userRoles, err := controller.Storage.TeamMemberStorage.RetrieveUserRolesByTeamIDAndStatus(accessRequest.TeamID, model.TEAM_MEMBER_STATUS_OK)
+	if err != nil {
+		controller.Logger.Errorw("retrieve access request reviewer roles failed", "accessRequestID", accessRequest.ExportID(), "err", err)
+		return
+	}
+	reviewerRoles := make([]int, 0)
+	for _, userRole := range userRoles {
+		roleHolder := &model.TeamMember{TeamID: accessRequest.TeamID, UserRole: userRole, Status: model.TEAM_MEMBER_STATUS_OK}
+		if canReview, err := controller.CanReviewAccessRequest(roleHolder, requester, accessRequest); err == nil && canReview {
+			reviewerRoles = append(reviewerRoles, userRole)
+		}
+	}
+	if len(reviewerRoles) == 0 {
+		return
+	}
+	teamMembers, err := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserRolesAndStatus(accessRequest.TeamID, reviewerRoles, model.TEAM_MEMBER_STATUS_OK)
+	if err != nil {
+		controller.Logger.Errorw("retrieve access request reviewers failed", "accessRequestID", accessRequest.ExportID(), "err", err)
+		return
+	}
+	reviewers := make([]int, 0, len(teamMembers))
+	for _, teamMember := range teamMembers {
+		if teamMember.ExportID() == requester.ExportID() {
+			continue
+		}
+		reviewers = append(reviewers, teamMember.ExportUserID())
+	}
+	if len(reviewers) == 0 {
+		return
+	}
+	controller.notifyAccessRequest(notification.NOTICE_CATEGORY_ACCESS_REQUEST_CREATED, accessRequest, reviewers)
+}
+
+func (controller *Controller) notifyAccessRequest(category string, accessRequest *model.AccessRequest, recipientUserIDs []int) {
+	if controller.Notifier == nil {
+		return
+	}
+	notice := notification.NewNotice(category, accessRequest.TeamID, recipientUserIDs, map[string]interface{}{
+		"accessRequestID": idconvertor.ConvertIntToString(accessRequest.ExportID()),
+		"accessRequest":   accessRequest.Export(),
+	})
+	if err := controller.Notifier.Notify(notice); err != nil {
+		controller.Logger.Errorw("notify access request failed", "category", category, "accessRequestID", accessRequest.ExportID(), "err", err)
+	}
+}
+
+// applyAccessRequest grant the approved access, it feedback the error flag for the failure.
+func applyAccessRequest(txStorage *model.Storage, requester *model.TeamMember, accessRequest *model.AccessRequest) (string, error) {
+	if accessRequest.IsRoleRequest() {
+		previousUserRole := requester.ExportUserRole()
+		if accessRequest.IsTimeBound() {
+			requester.GrantTimeBoundRole(accessRequest.TargetRole, accessRequest.ValidUntil)
+		} else {
+			requester.UpdateTeamMemberRole(accessRequest.TargetRole)
+		}
+		if err := txStorage.TeamMemberStorage.UpdateRole(requester); err != nil {
+			return ERROR_FLAG_CAN_NOT_UPDATE_TEAM_MEMBER, err
+		}
+		if _, err := txStorage.OutboxEventStorage.Create(model.NewTeamMemberRoleChangedEvent(requester, previousUserRole, accessRequest.ReviewerUserID)); err != nil {
+			return ERROR_FLAG_CAN_NOT_UPDATE_TEAM_MEMBER, err
+		}
+		return "", nil
+	}
+
+	// grant the unit to the requester only, the other members of the role are untouched.
+	// a deny relation of the role or the requester wins over any grant so it has to be removed by hand.
+	existingUnitRoleRelations, err := txStorage.UnitRoleRelationStorage.RetrieveByUnitForTeamMember(accessRequest.TeamID, accessRequest.UnitType, accessRequest.UnitID, requester.ExportUserRole(), requester.ExportUserID())
+	if err != nil {
+		return ERROR_FLAG_CAN_NOT_GET_UNIT_ROLE_RELATION, err
+	}
+	var memberUnitRoleRelation *model.UnitRoleRelation
+	for _, existingUnitRoleRelation := range existingUnitRoleRelations {
+		if existingUnitRoleRelation.IsDeny() {
+			return ERROR_FLAG_ACCESS_REQUEST_DENIED_BY_UNIT_ROLE_RELATION, errAccessRequestDeniedByUnitRoleRelation
+		}
+		if existingUnitRoleRelation.UserID == requester.ExportUserID() && memberUnitRoleRelation == nil {
+			memberUnitRoleRelation = existingUnitRoleRelation
+		}
+	}
+	if memberUnitRoleRelation != nil {
+		memberUnitRoleRelation.AllowPermissions(accessRequest.ExportUnitPermissions())
+		if err := txStorage.UnitRoleRelationStorage.UpdateByID(memberUnitRoleRelation); err != nil {
+			return ERROR_FLAG_CAN_NOT_CREATE_UNIT_ROLE_RELATION, err
+		}
+		return "", nil
+	}
+	unitRoleRelation := model.NewUnitRoleRelationForUser(accessRequest.TeamID, accessRequest.UnitType, accessRequest.UnitID, requester.ExportUserID())
+	unitRoleRelation.AllowPermissions(accessRequest.ExportUnitPermissions())
+	if _, err := txStorage.UnitRoleRelationStorage.Create(unitRoleRelation); err != nil {
+		return ERROR_FLAG_CAN_NOT_CREATE_UNIT_ROLE_RELATION, err
+	}
+	return "", nil
+}
@@ -0,0 +1,226 @@
+package controller
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/accesscontrol"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/internal/testdb"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/notification"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/idconvertor"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/webhook"
+	"go.uber.org/zap"
+)
+
+const testAppID = 42
+
+type recordingNotifier struct {
+	notices []*notification.Notice
+}
+
+func (n *recordingNotifier) Notify(notice *notification.Notice) error {
+	n.notices = append(n.notices, notice)
+	return nil
+}
+
+func newTestController(t *testing.T) (*Controller, *recordingNotifier) {
+	t.Helper()
+	notifier := &recordingNotifier{}
+	controller := &Controller{Storage: testdb.NewStorage(t), Notifier: notifier, Logger: zap.NewNop().Sugar()}
+	return controller, notifier
+}
+
+func createTestTeam(t *testing.T, storage *model.Storage) int {
+	t.Helper()
+	teamID, err := storage.TeamStorage.Create(&model.Team{Name: "acme", Identifier: "acme", Permission: "{}"})
+	if err != nil {
+		t.Fatalf("create team failed: %v", err)
+	}
+	return teamID
+}
+
+func createTestTeamMember(t *testing.T, storage *model.Storage, teamID int, userID int, userRole int, status int) *model.TeamMember {
+	t.Helper()
+	teamMember := model.NewTeamMember()
+	teamMember.TeamID = teamID
+	teamMember.UserID = userID
+	teamMember.UserRole = userRole
+	teamMember.Status = status
+	teamMember.InitCreatedAt()
+	teamMember.InitUpdatedAt()
+	id, err := storage.TeamMemberStorage.Create(teamMember)
+	if err != nil {
+		t.Fatalf("create team member failed: %v", err)
+	}
+	teamMember.SetID(id)
+	return teamMember
+}
+
+func newTestUnitAttributeRequest(requester *model.TeamMember) *model.AccessRequest {
+	return model.NewAccessRequestByCreateAccessRequestRequest(requester, &model.CreateAccessRequestRequest{
+		Category:          model.ACCESS_REQUEST_CATEGORY_UNIT_ATTRIBUTE,
+		UnitType:          accesscontrol.UNIT_TYPE_APP,
+		UnitID:            idconvertor.ConvertIntToString(testAppID),
+		AttributeCategory: accesscontrol.ATTRIBUTE_CATEGORY_MANAGE,
+		Attribute:         accesscontrol.ACTION_MANAGE_EDIT_APP,
+		Justification:     "fix the release",
+	})
+}
+
+func canEditTestApp(t *testing.T, controller *Controller, teamMember *model.TeamMember) bool {
+	t.Helper()
+	attrg := accesscontrol.NewAttributeGroupByTeamMember(teamMember, accesscontrol.UNIT_TYPE_APP)
+	attrg.SetUnitID(testAppID)
+	if err := controller.ApplyUnitRoleRelations(attrg, teamMember.TeamID, teamMember.ExportUserID()); err != nil {
+		t.Fatalf("apply unit role relations failed: %v", err)
+	}
+	return attrg.CanManage(accesscontrol.ACTION_MANAGE_EDIT_APP)
+}
+
+func TestApplyAccessRequestGrantsRequesterOnly(t *testing.T) {
+	controller, _ := newTestController(t)
+	teamID := createTestTeam(t, controller.Storage)
+	requester := createTestTeamMember(t, controller.Storage, teamID, 1, model.USER_ROLE_VIEWER, model.TEAM_MEMBER_STATUS_OK)
+	sameRoleMember := createTestTeamMember(t, controller.Storage, teamID, 2, model.USER_ROLE_VIEWER, model.TEAM_MEMBER_STATUS_OK)
+
+	// approve twice, the second grant merges into the requester relation
+	for i := 0; i < 2; i++ {
+		if errorFlag, err := applyAccessRequest(controller.Storage, requester, newTestUnitAttributeRequest(requester)); err != nil {
+			t.Fatalf("apply access request failed: %s %v", errorFlag, err)
+		}
+	}
+
+	if !canEditTestApp(t, controller, requester) {
+		t.Error("requester should be granted")
+	}
+	if canEditTestApp(t, controller, sameRoleMember) {
+		t.Error("the other member of the role should not be granted")
+	}
+	roleRelations, err := controller.Storage.UnitRoleRelationStorage.RetrieveByUnitAndRoleID(teamID, accesscontrol.UNIT_TYPE_APP, testAppID, model.USER_ROLE_VIEWER)
+	if err != nil {
+		t.Fatalf("retrieve unit role relations failed: %v", err)
+	}
+	if len(roleRelations) != 0 {
+		t.Errorf("role relations = %d, want 0", len(roleRelations))
+	}
+	memberRelations, err := controller.Storage.UnitRoleRelationStorage.RetrieveByUnitAndUserID(teamID, accesscontrol.UNIT_TYPE_APP, testAppID, requester.ExportUserID())
+	if err != nil {
+		t.Fatalf("retrieve unit role relations failed: %v", err)
+	}
+	if len(memberRelations) != 1 {
+		t.Errorf("member relations = %d, want 1", len(memberRelations))
+	}
+}
+
+func TestApplyAccessRequestDeniedByRoleRelation(t *testing.T) {
+	controller, _ := newTestController(t)
+	teamID := createTestTeam(t, controller.Storage)
+	requester := createTestTeamMember(t, controller.Storage, teamID, 1, model.USER_ROLE_VIEWER, model.TEAM_MEMBER_STATUS_OK)
+	deny := model.NewUnitRoleRelation(teamID, accesscontrol.UNIT_TYPE_APP, testAppID, model.USER_ROLE_VIEWER)
+	deny.Effect = model.UNIT_ROLE_RELATION_EFFECT_DENY
+	if _, err := controller.Storage.UnitRoleRelationStorage.Create(deny); err != nil {
+		t.Fatalf("create unit role relation failed: %v", err)
+	}
+
+	errorFlag, err := applyAccessRequest(controller.Storage, requester, newTestUnitAttributeRequest(requester))
+	if err != errAccessRequestDeniedByUnitRoleRelation || errorFlag != ERROR_FLAG_ACCESS_REQUEST_DENIED_BY_UNIT_ROLE_RELATION {
+		t.Errorf("apply access request = %s %v, want denied", errorFlag, err)
+	}
+}
+
+func TestNotifyAccessRequestReviewers(t *testing.T) {
+	controller, notifier := newTestController(t)
+	teamID := createTestTeam(t, controller.Storage)
+	requester := createTestTeamMember(t, controller.Storage, teamID, 1, model.USER_ROLE_VIEWER, model.TEAM_MEMBER_STATUS_OK)
+	teamMembers := []*model.TeamMember{
+		requester,
+		createTestTeamMember(t, controller.Storage, teamID, 2, model.USER_ROLE_OWNER, model.TEAM_MEMBER_STATUS_OK),
+		createTestTeamMember(t, controller.Storage, teamID, 3, model.USER_ROLE_ADMIN, model.TEAM_MEMBER_STATUS_OK),
+		createTestTeamMember(t, controller.Storage, teamID, 4, model.USER_ROLE_ADMIN, model.TEAM_MEMBER_STATUS_SUSPEND),
+		createTestTeamMember(t, controller.Storage, teamID, 5, model.USER_ROLE_EDITOR, model.TEAM_MEMBER_STATUS_OK),
+		createTestTeamMember(t, controller.Storage, teamID, 6, model.USER_ROLE_VIEWER, model.TEAM_MEMBER_STATUS_OK),
+	}
+	accessRequest := newTestUnitAttributeRequest(requester)
+
+	// the reviewers checked one by one
+	want := make([]int, 0)
+	for _, teamMember := range teamMembers {
+		if !teamMember.IsStatusOK() {
+			continue
+		}
+		if canReview, err := controller.CanReviewAccessRequest(teamMember, requester, accessRequest); err == nil && canReview {
+			want = append(want, teamMember.ExportUserID())
+		}
+	}
+	if len(want) == 0 {
+		t.Fatal("no reviewer in the team")
+	}
+
+	controller.NotifyAccessRequestReviewers(requester, accessRequest)
+
+	if len(notifier.notices) != 1 {
+		t.Fatalf("notices = %d, want 1", len(notifier.notices))
+	}
+	got := append([]int{}, notifier.notices[0].RecipientUserIDs...)
+	sort.Ints(got)
+	sort.Ints(want)
+	if len(got) != len(want) {
+		t.Fatalf("recipients = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("recipients = %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestNotifyAccessRequestReviewersByWebhook(t *testing.T) {
+	controller, _ := newTestController(t)
+	controller.Notifier = webhook.NewNotifier(controller.Storage, zap.NewNop().Sugar())
+	teamID := createTestTeam(t, controller.Storage)
+	for _, user := range []*model.User{
+		{ID: 1, Nickname: "requester", Email: "requester@acme.com"},
+		{ID: 2, Nickname: "owner", Email: "owner@acme.com"},
+	} {
+		if _, err := controller.Storage.UserStorage.Create(user); err != nil {
+			t.Fatalf("create user failed: %v", err)
+		}
+	}
+	requester := createTestTeamMember(t, controller.Storage, teamID, 1, model.USER_ROLE_VIEWER, model.TEAM_MEMBER_STATUS_OK)
+	createTestTeamMember(t, controller.Storage, teamID, 2, model.USER_ROLE_OWNER, model.TEAM_MEMBER_STATUS_OK)
+	relay := &model.Webhook{TeamID: teamID, Name: "relay", URL: "https://hooks.example.com/relay", Format: model.WEBHOOK_FORMAT_JSON, Status: model.WEBHOOK_STATUS_ENABLED}
+	relay.SetEventTypes([]string{model.WEBHOOK_EVENT_TYPE_ACCESS_REQUEST_CREATED})
+	relay.InitUID()
+	relay.InitSecret()
+	if _, err := controller.Storage.WebhookStorage.Create(relay); err != nil {
+		t.Fatalf("create webhook failed: %v", err)
+	}
+	accessRequest := newTestUnitAttributeRequest(requester)
+	if _, err := controller.Storage.AccessRequestStorage.Create(accessRequest); err != nil {
+		t.Fatalf("create access request failed: %v", err)
+	}
+
+	controller.NotifyAccessRequestReviewers(requester, accessRequest)
+
+	deliveries, err := controller.Storage.WebhookDeliveryStorage.RetrieveDueBefore(time.Now().UTC(), 10)
+	if err != nil {
+		t.Fatalf("retrieve deliveries failed: %v", err)
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("deliveries = %d, want 1", len(deliveries))
+	}
+	body := &webhook.NoticeBody{}
+	if err := json.Unmarshal([]byte(deliveries[0].Body), body); err != nil {
+		t.Fatalf("decode body failed: %v", err)
+	}
+	if body.Type != model.WEBHOOK_EVENT_TYPE_ACCESS_REQUEST_CREATED || len(body.Recipients) != 1 || body.Recipients[0].Email != "owner@acme.com" {
+		t.Errorf("body = %s", deliveries[0].Body)
+	}
+	if body.Payload["accessRequestID"] != idconvertor.ConvertIntToString(accessRequest.ExportID()) {
+		t.Errorf("access request id = %v", body.Payload["accessRequestID"])
+	}
+}
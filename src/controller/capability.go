@@ -0,0 +1,46 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/accesscontrol"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+)
+
+func (controller *Controller) GetCapabilities(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// get team permission
+	team, errInRetrieveTeam := controller.Storage.TeamStorage.RetrieveByID(teamID)
+	if errInRetrieveTeam != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+errInRetrieveTeam.Error())
+		return
+	}
+
+	// export capabilities
+	capabilities := accesscontrol.ExportCapabilities(teamMember.ExportUserRole(), teamMember.ExportStatus(), team.ExportTeamPermission())
+	resp := model.NewGetCapabilitiesResponse(teamID, teamMember.ExportUserRole(), capabilities)
+
+	// feedback, client cached capabilities still fresh
+	c.Header(HEADER_ETAG, resp.ExportETag())
+	if controller.IsETagMatched(c, resp.ExportETag()) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	controller.FeedbackOK(c, resp)
+	return
+}
@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/accesscontrol"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+)
+
+// serveGetCapabilities request the capabilities of user with the If-None-Match headers.
+func serveGetCapabilities(controller *Controller, userID int, ifNoneMatch ...string) *httptest.ResponseRecorder {
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) { c.Set("userID", userID) })
+	engine.GET("/capabilities", controller.GetCapabilities)
+	req := httptest.NewRequest(http.MethodGet, "/capabilities", nil)
+	for _, value := range ifNoneMatch {
+		req.Header.Add(HEADER_IF_NONE_MATCH, value)
+	}
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestGetCapabilities(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	controller := newTestSelfHostController(t)
+	const editorID = 1
+	createTestTeamMember(t, controller.Storage, model.TEAM_DEFAULT_ID, editorID, model.USER_ROLE_EDITOR, model.TEAM_MEMBER_STATUS_OK)
+	getCapabilities := func() *model.GetCapabilitiesResponse {
+		recorder := serveGetCapabilities(controller, editorID)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", recorder.Code, recorder.Body.String())
+		}
+		resp := &model.GetCapabilitiesResponse{}
+		if err := json.Unmarshal(recorder.Body.Bytes(), resp); err != nil {
+			t.Fatalf("decode response failed: %v", err)
+		}
+		if etag := recorder.Header().Get(HEADER_ETAG); etag != resp.ExportETag() {
+			t.Errorf("etag = %s, want %s", etag, resp.ExportETag())
+		}
+		return resp
+	}
+	canInviteByEmail := func(resp *model.GetCapabilitiesResponse) bool {
+		for _, attribute := range resp.Capabilities["access"][accesscontrol.UNIT_TYPE_INVITE] {
+			if attribute == accesscontrol.ACTION_ACCESS_INVITE_BY_EMAIL {
+				return true
+			}
+		}
+		return false
+	}
+	setAllowEditorInvite := func(allow bool) {
+		team, err := controller.Storage.TeamStorage.RetrieveByID(model.TEAM_DEFAULT_ID)
+		if err != nil {
+			t.Fatalf("get team failed: %v", err)
+		}
+		tp := team.ExportTeamPermission()
+		tp.AllowEditorInvite = allow
+		team.Permission = tp.ExportForTeam()
+		if err := controller.Storage.TeamStorage.UpdateByID(team); err != nil {
+			t.Fatalf("update team failed: %v", err)
+		}
+	}
+
+	// the version follows the team permission toggles
+	setAllowEditorInvite(true)
+	allowed := getCapabilities()
+	if allowed.UserRole != model.USER_ROLE_EDITOR || !canInviteByEmail(allowed) {
+		t.Errorf("capabilities = %+v, want the editor invite by email", allowed)
+	}
+	if again := getCapabilities(); again.Version != allowed.Version {
+		t.Errorf("version = %s, want the same version %s", again.Version, allowed.Version)
+	}
+	setAllowEditorInvite(false)
+	revoked := getCapabilities()
+	if canInviteByEmail(revoked) {
+		t.Errorf("capabilities = %+v, want the editor invite revoked", revoked)
+	}
+	if revoked.Version == allowed.Version {
+		t.Errorf("version %s is not changed by the team permission", revoked.Version)
+	}
+
+	if recorder := serveGetCapabilities(controller, editorID+1); recorder.Code != http.StatusBadRequest {
+		t.Errorf("outsider status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+}
+
+func TestGetCapabilitiesIfNoneMatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	controller := newTestSelfHostController(t)
+	const editorID = 1
+	createTestTeamMember(t, controller.Storage, model.TEAM_DEFAULT_ID, editorID, model.USER_ROLE_EDITOR, model.TEAM_MEMBER_STATUS_OK)
+	etag := serveGetCapabilities(controller, editorID).Header().Get(HEADER_ETAG)
+
+	cases := []struct {
+		name        string
+		ifNoneMatch []string
+		status      int
+	}{
+		{"no header", nil, http.StatusOK},
+		{"same etag", []string{etag}, http.StatusNotModified},
+		{"weak etag", []string{"W/" + etag}, http.StatusNotModified},
+		{"in list", []string{`"stale", ` + etag}, http.StatusNotModified},
+		{"in list without space", []string{`W/"stale",` + etag}, http.StatusNotModified},
+		{"repeated header", []string{`"stale"`, etag}, http.StatusNotModified},
+		{"any", []string{"*"}, http.StatusNotModified},
+		{"comma in stale etag", []string{`"a,` + etag[1:]}, http.StatusOK},
+		{"stale etag", []string{`"stale"`}, http.StatusOK},
+		{"unquoted etag", []string{etag[1 : len(etag)-1]}, http.StatusOK},
+		{"after malformed etag", []string{`stale, ` + etag}, http.StatusOK},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			recorder := serveGetCapabilities(controller, editorID, c.ifNoneMatch...)
+			if recorder.Code != c.status {
+				t.Errorf("status = %d, want %d", recorder.Code, c.status)
+			}
+			if got := recorder.Header().Get(HEADER_ETAG); got != etag {
+				t.Errorf("etag = %s, want %s", got, etag)
+			}
+		})
+	}
+}
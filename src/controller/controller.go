@@ -2,8 +2,12 @@ package controller
 
 import (
 	"github.com/kozmoai/kozmo-supervisor-backend/src/authenticator"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/domainverifier"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/notification"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/tokenvalidator"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/webhook"
+	"go.uber.org/zap"
 )
 
 type Controller struct {
@@ -12,14 +16,22 @@ type Controller struct {
 	Drive                 *model.Drive
 	RequestTokenValidator *tokenvalidator.RequestTokenValidator
 	Authenticator         *authenticator.Authenticator
+	DomainVerifier        *domainverifier.DomainVerifier
+	Notifier              notification.Notifier
+	WebhookDeliverer      *webhook.Deliverer
+	Logger                *zap.SugaredLogger
 }
 
-func NewController(storage *model.Storage, cache *model.Cache, drive *model.Drive, validator *tokenvalidator.RequestTokenValidator, auth *authenticator.Authenticator) *Controller {
+func NewController(storage *model.Storage, cache *model.Cache, drive *model.Drive, validator *tokenvalidator.RequestTokenValidator, auth *authenticator.Authenticator, domainVerifier *domainverifier.DomainVerifier, notifier notification.Notifier, webhookDeliverer *webhook.Deliverer, logger *zap.SugaredLogger) *Controller {
 	return &Controller{
 		Storage:               storage,
 		Cache:                 cache,
 		Drive:                 drive,
 		RequestTokenValidator: validator,
 		Authenticator:         auth,
+		DomainVerifier:        domainVerifier,
+		Notifier:              notifier,
+		WebhookDeliverer:      webhookDeliverer,
+		Logger:                logger,
 	}
 }
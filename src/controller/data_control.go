@@ -1,11 +1,15 @@
 package controller
 
 import (
+	"encoding/json"
+	"io"
 	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/dnsresolver"
 )
 
 func (controller *Controller) GetTargetUserByInternalRequest(c *gin.Context) {
@@ -22,14 +26,14 @@ func (controller *Controller) GetTargetUserByInternalRequest(c *gin.Context) {
 	}
 
 	// fetch target user info
-	user, err := controller.Storage.UserStorage.RetrieveByID(targetUserID)
+	resp, err := controller.RetrieveTargetUser(targetUserID)
 	if err != nil {
-		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_USER, "get user error: "+err.Error())
+		controller.FeedbackBadRequest(c, err.Flag, err.Message)
 		return
 	}
 
 	// feedback
-	controller.FeedbackOK(c, model.NewGetTargetUserByInternalRequestResponse(user))
+	controller.FeedbackOK(c, resp)
 	return
 }
 
@@ -53,15 +57,21 @@ func (controller *Controller) GetTargetUsersByInternalRequest(c *gin.Context) {
 		targetUserIDsInInt = append(targetUserIDsInInt, idInInt)
 	}
 
+	// get pagination, all target users are feedback without cursor and limit
+	pagination, errInGetPagination := controller.GetOptionalPaginationFromRequest(c, model.PAGINATION_SORT_KEY_ID, model.PAGINATION_DIRECTION_ASC)
+	if errInGetPagination != nil {
+		return
+	}
+
 	// fetch target user info
-	users, err := controller.Storage.UserStorage.RetrieveByIDs(targetUserIDsInInt)
+	resp, err := controller.RetrieveTargetUsers(targetUserIDsInInt, pagination)
 	if err != nil {
-		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_USER, "get user error: "+err.Error())
+		controller.FeedbackBadRequest(c, err.Flag, err.Message)
 		return
 	}
 
 	// feedback
-	controller.FeedbackOK(c, model.NewGetTargetUsersByInternalRequestResponse(users))
+	controller.FeedbackOK(c, resp)
 	return
 }
 
@@ -78,7 +88,39 @@ func (controller *Controller) GetTargetTeamByIdentifier(c *gin.Context) {
 	}
 
 	// fetch target team info
-	team, err := controller.Storage.TeamStorage.RetrieveByIdentifier(teamIdentifier)
+	resp, err := controller.RetrieveTargetTeamByIdentifier(teamIdentifier)
+	if err != nil {
+		controller.FeedbackBadRequest(c, err.Flag, err.Message)
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, resp)
+	return
+}
+
+func (controller *Controller) GetTargetTeamByHostname(c *gin.Context) {
+	hostname, errInGetHostname := controller.GetStringParamFromRequest(c, PARAM_HOSTNAME)
+	if errInGetHostname != nil {
+		return
+	}
+
+	// validate request data
+	validated, errInValidate := controller.ValidateRequestTokenFromHeader(c, hostname)
+	if !validated && errInValidate != nil {
+		return
+	}
+
+	// fetch verified domain, the port of host will be ignored
+	userDomain := dnsresolver.NormalizeHost(strings.Split(hostname, ":")[0])
+	domain, errInRetrieveDomain := controller.Storage.DomainStorage.RetrieveVerifiedByUserDomain(userDomain)
+	if errInRetrieveDomain != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_DOMAIN, "get domain error: "+errInRetrieveDomain.Error())
+		return
+	}
+
+	// fetch target team info
+	team, err := controller.Storage.TeamStorage.RetrieveByID(domain.ExportTeamID())
 	if err != nil {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+err.Error())
 		return
@@ -88,3 +130,84 @@ func (controller *Controller) GetTargetTeamByIdentifier(c *gin.Context) {
 	controller.FeedbackOK(c, model.NewGetTargetTeamByInternalRequestResponse(team))
 	return
 }
+
+// the batch lookup endpoints feedback the found ones keyed by the requested keys, and the keys which not found.
+func (controller *Controller) BatchGetTargetUsersByIDs(c *gin.Context) {
+	req := model.NewBatchGetTargetUsersByIDsRequest()
+	controller.serveSignedInternalLookup(c, req, func() (model.Response, *FeedbackError) {
+		return controller.BatchRetrieveTargetUsersByIDs(req.ExportIDs())
+	})
+}
+
+func (controller *Controller) BatchGetTargetUsersByUIDs(c *gin.Context) {
+	req := model.NewBatchGetTargetUsersByUIDsRequest()
+	controller.serveSignedInternalLookup(c, req, func() (model.Response, *FeedbackError) {
+		return controller.BatchRetrieveTargetUsersByUIDs(req.ExportUIDs())
+	})
+}
+
+func (controller *Controller) BatchGetTargetUsersByEmails(c *gin.Context) {
+	req := model.NewBatchGetTargetUsersByEmailsRequest()
+	controller.serveSignedInternalLookup(c, req, func() (model.Response, *FeedbackError) {
+		return controller.BatchRetrieveTargetUsersByEmails(req.Emails)
+	})
+}
+
+func (controller *Controller) BatchGetTargetTeamsByIDs(c *gin.Context) {
+	req := model.NewBatchGetTargetTeamsByIDsRequest()
+	controller.serveSignedInternalLookup(c, req, func() (model.Response, *FeedbackError) {
+		return controller.BatchRetrieveTargetTeamsByIDs(req.ExportIDs())
+	})
+}
+
+func (controller *Controller) BatchGetTargetTeamsByUIDs(c *gin.Context) {
+	req := model.NewBatchGetTargetTeamsByUIDsRequest()
+	controller.serveSignedInternalLookup(c, req, func() (model.Response, *FeedbackError) {
+		return controller.BatchRetrieveTargetTeamsByUIDs(req.ExportUIDs())
+	})
+}
+
+func (controller *Controller) GetTargetUserTeams(c *gin.Context) {
+	req := model.NewGetTargetUserTeamsRequest()
+	controller.serveSignedInternalLookup(c, req, func() (model.Response, *FeedbackError) {
+		return controller.RetrieveTargetUserTeams(req.ExportUserID())
+	})
+}
+
+// serveSignedInternalLookup parse the request body signed by the request token into req, then feedback the result of lookup.
+func (controller *Controller) serveSignedInternalLookup(c *gin.Context, req interface{}, lookup func() (model.Response, *FeedbackError)) {
+	// get request body
+	rawBody, errInReadBody := io.ReadAll(c.Request.Body)
+	if errInReadBody != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "read request body error: "+errInReadBody.Error())
+		return
+	}
+
+	// validate request data
+	validated, errInValidate := controller.ValidateRequestTokenFromHeader(c, string(rawBody))
+	if !validated && errInValidate != nil {
+		return
+	}
+
+	if err := json.Unmarshal(rawBody, req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// lookup
+	resp, err := lookup()
+	if err != nil {
+		controller.FeedbackBadRequest(c, err.Flag, err.Message)
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, resp)
+}
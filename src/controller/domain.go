@@ -0,0 +1,260 @@
+package controller
+
+import (
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/accesscontrol"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/dnsresolver"
+)
+
+func (controller *Controller) GetAllDomains(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// validate user role
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_DOMAIN)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+	if !attrg.CanAccess(accesscontrol.ACTION_ACCESS_VIEW) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// retrieve
+	domains, err := controller.Storage.DomainStorage.RetrieveByTeamID(teamID)
+	if err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_DOMAIN, "get domains error: "+err.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetAllDomainsResponse(domains, controller.DomainVerifier.SystemDomain))
+	return
+}
+
+func (controller *Controller) CreateDomain(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewCreateDomainRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// validate user role
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_DOMAIN)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+	if !attrg.CanManageDomain(req.Category) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// check domain
+	domain := model.NewDomainByCreateDomainRequest(teamID, req)
+	if controller.Storage.DomainStorage.IsUserDomainExists(domain.UserDomain) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_DOMAIN_HAS_BEEN_TAKEN, "the domain has been taken.")
+		return
+	}
+
+	// create
+	if _, err := controller.Storage.DomainStorage.Create(domain); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_CREATE_DOMAIN, "create domain error: "+err.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetDomainResponse(domain, controller.DomainVerifier.SystemDomain))
+	return
+}
+
+func (controller *Controller) UpdateDomain(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	domainID, errInGetDomainID := controller.GetMagicIntParamFromRequest(c, PARAM_DOMAIN_ID)
+	if errInGetUserID != nil || errInGetDomainID != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewUpdateDomainRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// get domain
+	domain, errInRetrieveDomain := controller.Storage.DomainStorage.RetrieveByTeamIDAndID(teamID, domainID)
+	if errInRetrieveDomain != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_DOMAIN, "get domain error: "+errInRetrieveDomain.Error())
+		return
+	}
+
+	// validate user role, both of now category and target category should be manageable
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_DOMAIN)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+	if !attrg.CanManageDomain(domain.ExportCategory()) || (req.Category != 0 && !attrg.CanManageDomain(req.Category)) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// check new user domain
+	newUserDomain := dnsresolver.NormalizeHost(req.UserDomain)
+	if newUserDomain != "" && newUserDomain != domain.UserDomain && controller.Storage.DomainStorage.IsUserDomainExists(newUserDomain) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_DOMAIN_HAS_BEEN_TAKEN, "the domain has been taken.")
+		return
+	}
+
+	// update
+	domain.UpdateByUpdateDomainRequest(req)
+	if err := controller.Storage.DomainStorage.UpdateByID(domain); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_DOMAIN, "update domain error: "+err.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetDomainResponse(domain, controller.DomainVerifier.SystemDomain))
+	return
+}
+
+func (controller *Controller) VerifyDomain(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	domainID, errInGetDomainID := controller.GetMagicIntParamFromRequest(c, PARAM_DOMAIN_ID)
+	if errInGetUserID != nil || errInGetDomainID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// get domain
+	domain, errInRetrieveDomain := controller.Storage.DomainStorage.RetrieveByTeamIDAndID(teamID, domainID)
+	if errInRetrieveDomain != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_DOMAIN, "get domain error: "+errInRetrieveDomain.Error())
+		return
+	}
+
+	// validate user role
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_DOMAIN)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+	if !attrg.CanManageDomain(domain.ExportCategory()) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// verify
+	if err := controller.DomainVerifier.VerifyDomain(domain); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_DOMAIN, "update domain resolve status error: "+err.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetDomainResponse(domain, controller.DomainVerifier.SystemDomain))
+	return
+}
+
+func (controller *Controller) DeleteDomain(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	domainID, errInGetDomainID := controller.GetMagicIntParamFromRequest(c, PARAM_DOMAIN_ID)
+	if errInGetUserID != nil || errInGetDomainID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// get domain
+	domain, errInRetrieveDomain := controller.Storage.DomainStorage.RetrieveByTeamIDAndID(teamID, domainID)
+	if errInRetrieveDomain != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_DOMAIN, "get domain error: "+errInRetrieveDomain.Error())
+		return
+	}
+
+	// validate user role
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_DOMAIN)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+	if !attrg.CanDeleteDomain(domain.ExportCategory()) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// delete
+	if err := controller.Storage.DomainStorage.DeleteByTeamIDAndID(teamID, domainID); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_DELETE_DOMAIN, "delete domain error: "+err.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, nil)
+	return
+}
@@ -0,0 +1,307 @@
+package controller
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/accesscontrol"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/authenticator"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+)
+
+// shared logic of the internal services, the REST handlers and the gRPC server feedback its result in their own way.
+
+// FeedbackError carry the error flag and message which the REST handler feedback with FeedbackBadRequest.
+type FeedbackError struct {
+	Flag    string
+	Message string
+}
+
+func NewFeedbackError(flag string, message string) *FeedbackError {
+	return &FeedbackError{
+		Flag:    flag,
+		Message: message,
+	}
+}
+
+func (e *FeedbackError) Error() string {
+	return e.Message
+}
+
+// ResolvePrincipal extract user id from authorization token, the anonymous token resolves to anonymous user.
+func ResolvePrincipal(authorizationToken string) (int, error) {
+	if authorizationToken == accesscontrol.ANONYMOUS_AUTH_TOKEN {
+		return model.USER_ROLE_ANONYMOUS, nil
+	}
+	userID, _, err := authenticator.ExtractUserIDFromToken(authorizationToken)
+	return userID, err
+}
+
+func (controller *Controller) ValidateAccountByToken(authorizationToken string) *FeedbackError {
+	if _, err := controller.Authenticator.ManualAuth(authorizationToken); err != nil {
+		return NewFeedbackError(ERROR_FLAG_VALIDATE_ACCOUNT_FAILED, "validate account failed: "+err.Error())
+	}
+	return nil
+}
+
+// IntrospectAccessToken feedback the user, session and expiry of token, and the membership and scopes of user in team when withTeam.
+// an invalid, expired or signed out token feedback inactive, the errors are only for failed lookups.
+func (controller *Controller) IntrospectAccessToken(authorizationToken string, teamID int, withTeam bool) (*model.IntrospectTokenResponse, *FeedbackError) {
+	// validate token
+	if authorizationToken == accesscontrol.ANONYMOUS_AUTH_TOKEN {
+		return model.NewInactiveIntrospectTokenResponse(), nil
+	}
+	if _, err := controller.Authenticator.ManualAuth(authorizationToken); err != nil {
+		return model.NewInactiveIntrospectTokenResponse(), nil
+	}
+	userID, userUID, errInExtractUserID := authenticator.ExtractUserIDFromToken(authorizationToken)
+	expiresAt, errInExtractExpiresAt := authenticator.ExtractExpiresAtFromToken(authorizationToken)
+	if errInExtractUserID != nil || errInExtractExpiresAt != nil || expiresAt == nil {
+		return model.NewInactiveIntrospectTokenResponse(), nil
+	}
+
+	// retrieve user
+	user, errInRetrieveUser := controller.Storage.UserStorage.RetrieveByIDAndUID(userID, userUID)
+	if errInRetrieveUser != nil {
+		return model.NewInactiveIntrospectTokenResponse(), nil
+	}
+	expiresAtInString, _ := authenticator.ExtractExpiresAtFromTokenInString(authorizationToken)
+	resp := model.NewIntrospectTokenResponse(user, authenticator.ACCESS_TOKEN_ISSUER, authenticator.ExportSessionID(user.UID, expiresAtInString), expiresAt.Time)
+	if !withTeam {
+		return resp, nil
+	}
+
+	// retrieve membership, the user who is not a team member has no membership and scope
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errors.Is(errInRetrieveTeamMember, gorm.ErrRecordNotFound) {
+		return resp, nil
+	}
+	if errInRetrieveTeamMember != nil {
+		return nil, NewFeedbackError(ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve team member error: "+errInRetrieveTeamMember.Error())
+	}
+	team, errInRetrieveTeam := controller.Storage.TeamStorage.RetrieveByID(teamID)
+	if errInRetrieveTeam != nil {
+		return nil, NewFeedbackError(ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+errInRetrieveTeam.Error())
+	}
+	resp.SetMembership(teamMember, accesscontrol.ExportScopes(teamMember.ExportUserRole(), teamMember.ExportStatus(), team.ExportTeamPermission()))
+	return resp, nil
+}
+
+// RetrieveMembership feedback role and status of user in team, anonymous user is not a team member.
+func (controller *Controller) RetrieveMembership(teamID int, userID int) (int, int, *FeedbackError) {
+	if userID == model.USER_ROLE_ANONYMOUS {
+		return model.USER_ROLE_ANONYMOUS, accesscontrol.STATUS_OK, nil
+	}
+	teamMember, err := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if err != nil {
+		return 0, 0, NewFeedbackError(ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve team member error: "+err.Error())
+	}
+	return teamMember.ExportUserRole(), teamMember.ExportStatus(), nil
+}
+
+// CheckAccessControl evaluate a single check of user, it is the shared logic of canAccess, canManage, canManageSpecial, canModify and canDelete.
+// fromID and toID are only used by canModify.
+func (controller *Controller) CheckAccessControl(teamID int, userID int, category string, unitType int, unitID int, attributeID int, fromID int, toID int) (bool, *FeedbackError) {
+	// validate user
+	teamMemberRole, teamMemberStatus, errInRetrieveMembership := controller.RetrieveMembership(teamID, userID)
+	if errInRetrieveMembership != nil {
+		return false, errInRetrieveMembership
+	}
+
+	// check attribute
+	attrg := accesscontrol.NewAttributeGroup(teamMemberRole, unitType)
+	attrg.SetUserStatus(teamMemberStatus)
+	attrg.SetUnitID(unitID)
+	if err := controller.ApplyUnitRoleRelations(attrg, teamID, userID); err != nil {
+		return false, NewFeedbackError(ERROR_FLAG_CAN_NOT_GET_UNIT_ROLE_RELATION, "retrieve unit role relation error: "+err.Error())
+	}
+	if err := controller.ApplyTeamPermission(attrg, teamID); err != nil {
+		return false, NewFeedbackError(ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+err.Error())
+	}
+	allowed, err := EvaluateAttributeGroup(attrg, category, attributeID, fromID, toID)
+	if err != nil {
+		return false, NewFeedbackError(ERROR_FLAG_VALIDATE_REQUEST_PARAM_FAILED, "validate request param error: "+err.Error())
+	}
+	return allowed, nil
+}
+
+// CheckAccessControlBatch evaluate a list of checks for user with a single membership lookup.
+func (controller *Controller) CheckAccessControlBatch(teamID int, userID int, checks []*model.AccessControlCheck) (*model.BatchAccessControlResponse, *FeedbackError) {
+	// validate user
+	teamMemberRole, teamMemberStatus, errInRetrieveMembership := controller.RetrieveMembership(teamID, userID)
+	if errInRetrieveMembership != nil {
+		return nil, errInRetrieveMembership
+	}
+
+	// retrieve unit-level grants of all checked units at once
+	unitRoleRelationsMap, errInRetrieveUnitRoleRelations := controller.RetrieveUnitRoleRelationsForChecks(teamID, userID, teamMemberRole, checks)
+	if errInRetrieveUnitRoleRelations != nil {
+		return nil, NewFeedbackError(ERROR_FLAG_CAN_NOT_GET_UNIT_ROLE_RELATION, "retrieve unit role relation error: "+errInRetrieveUnitRoleRelations.Error())
+	}
+
+	// retrieve team permission toggles
+	team, errInRetrieveTeam := controller.Storage.TeamStorage.RetrieveByID(teamID)
+	if errInRetrieveTeam != nil {
+		return nil, NewFeedbackError(ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+errInRetrieveTeam.Error())
+	}
+	tp := team.ExportTeamPermission()
+
+	// check attributes
+	resp := model.NewBatchAccessControlResponse(len(checks))
+	for _, check := range checks {
+		allowed, err := EvaluateAccessControlCheck(teamMemberRole, teamMemberStatus, tp, check, unitRoleRelationsMap)
+		if err != nil {
+			resp.AppendError(err)
+			continue
+		}
+		resp.AppendResult(allowed)
+	}
+	return resp, nil
+}
+
+func EvaluateAttributeGroup(attrg *accesscontrol.AttributeGroup, category string, attributeID int, fromID int, toID int) (bool, error) {
+	return attrg.Evaluate(category, attributeID, fromID, toID)
+}
+
+func (controller *Controller) RetrieveTargetUser(targetUserID int) (*model.GetTargetUserByInternalRequestResponse, *FeedbackError) {
+	user, err := controller.Storage.UserStorage.RetrieveByID(targetUserID)
+	if err != nil {
+		return nil, NewFeedbackError(ERROR_FLAG_CAN_NOT_GET_USER, "get user error: "+err.Error())
+	}
+	return model.NewGetTargetUserByInternalRequestResponse(user), nil
+}
+
+func (controller *Controller) RetrieveTargetUsers(targetUserIDs []int, pagination *model.Pagination) (*model.GetTargetUsersByInternalRequestResponse, *FeedbackError) {
+	users, pageInfo, err := controller.Storage.UserStorage.RetrieveByIDsByPage(targetUserIDs, pagination)
+	if err != nil {
+		return nil, NewFeedbackError(ERROR_FLAG_CAN_NOT_GET_USER, "get user error: "+err.Error())
+	}
+	return model.NewGetTargetUsersByInternalRequestResponse(users, pageInfo), nil
+}
+
+func (controller *Controller) RetrieveTargetTeamByIdentifier(teamIdentifier string) (*model.GetTargetTeamByInternalRequestResponse, *FeedbackError) {
+	team, err := controller.Storage.TeamStorage.RetrieveByIdentifier(teamIdentifier)
+	if err != nil {
+		return nil, NewFeedbackError(ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+err.Error())
+	}
+	return model.NewGetTargetTeamByInternalRequestResponse(team), nil
+}
+
+// the malformed ids are reported as not found.
+func (controller *Controller) BatchRetrieveTargetUsersByIDs(ids map[int]string, malformedIDs []string) (*model.BatchGetTargetUsersResponse, *FeedbackError) {
+	idsInInt := make([]int, 0, len(ids))
+	keys := make([]string, 0, len(ids)+len(malformedIDs))
+	keys = append(keys, malformedIDs...)
+	for id, magicID := range ids {
+		idsInInt = append(idsInInt, id)
+		keys = append(keys, magicID)
+	}
+	users, err := controller.Storage.UserStorage.RetrieveByIDs(idsInInt)
+	if err != nil {
+		return nil, NewFeedbackError(ERROR_FLAG_CAN_NOT_GET_USER, "get user error: "+err.Error())
+	}
+	resp := model.NewBatchGetTargetUsersResponse()
+	for _, user := range users {
+		resp.SetUser(ids[user.ID], user)
+	}
+	resp.SetNotFound(keys)
+	return resp, nil
+}
+
+func (controller *Controller) BatchRetrieveTargetUsersByUIDs(uids map[uuid.UUID][]string) (*model.BatchGetTargetUsersResponse, *FeedbackError) {
+	uidsInUUID := make([]uuid.UUID, 0, len(uids))
+	keys := make([]string, 0, len(uids))
+	for uid, uidsInString := range uids {
+		uidsInUUID = append(uidsInUUID, uid)
+		keys = append(keys, uidsInString...)
+	}
+	users, err := controller.Storage.UserStorage.RetrieveByUIDs(uidsInUUID)
+	if err != nil {
+		return nil, NewFeedbackError(ERROR_FLAG_CAN_NOT_GET_USER, "get user error: "+err.Error())
+	}
+	resp := model.NewBatchGetTargetUsersResponse()
+	for _, user := range users {
+		for _, key := range uids[user.UID] {
+			resp.SetUser(key, user)
+		}
+	}
+	resp.SetNotFound(keys)
+	return resp, nil
+}
+
+func (controller *Controller) BatchRetrieveTargetUsersByEmails(emails []string) (*model.BatchGetTargetUsersResponse, *FeedbackError) {
+	users, err := controller.Storage.UserStorage.RetrieveByEmails(emails)
+	if err != nil {
+		return nil, NewFeedbackError(ERROR_FLAG_CAN_NOT_GET_USER, "get user error: "+err.Error())
+	}
+	resp := model.NewBatchGetTargetUsersResponse()
+	for _, user := range users {
+		resp.SetUser(user.Email, user)
+	}
+	resp.SetNotFound(emails)
+	return resp, nil
+}
+
+// the malformed ids are reported as not found.
+func (controller *Controller) BatchRetrieveTargetTeamsByIDs(ids map[int]string, malformedIDs []string) (*model.BatchGetTargetTeamsResponse, *FeedbackError) {
+	idsInInt := make([]int, 0, len(ids))
+	keys := make([]string, 0, len(ids)+len(malformedIDs))
+	keys = append(keys, malformedIDs...)
+	for id, magicID := range ids {
+		idsInInt = append(idsInInt, id)
+		keys = append(keys, magicID)
+	}
+	teams, err := controller.Storage.TeamStorage.RetrieveByIDs(idsInInt)
+	if err != nil {
+		return nil, NewFeedbackError(ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+err.Error())
+	}
+	resp := model.NewBatchGetTargetTeamsResponse()
+	for _, team := range teams {
+		resp.SetTeam(ids[team.ID], team)
+	}
+	resp.SetNotFound(keys)
+	return resp, nil
+}
+
+func (controller *Controller) BatchRetrieveTargetTeamsByUIDs(uids map[uuid.UUID][]string) (*model.BatchGetTargetTeamsResponse, *FeedbackError) {
+	uidsInUUID := make([]uuid.UUID, 0, len(uids))
+	keys := make([]string, 0, len(uids))
+	for uid, uidsInString := range uids {
+		uidsInUUID = append(uidsInUUID, uid)
+		keys = append(keys, uidsInString...)
+	}
+	teams, err := controller.Storage.TeamStorage.RetrieveByUIDs(uidsInUUID)
+	if err != nil {
+		return nil, NewFeedbackError(ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+err.Error())
+	}
+	resp := model.NewBatchGetTargetTeamsResponse()
+	for _, team := range teams {
+		for _, key := range uids[team.UID] {
+			resp.SetTeam(key, team)
+		}
+	}
+	resp.SetNotFound(keys)
+	return resp, nil
+}
+
+// RetrieveTargetUserTeams feedback all teams which user joined, with the role and status of user in each team.
+func (controller *Controller) RetrieveTargetUserTeams(userID int) (*model.GetTargetUserTeamsResponse, *FeedbackError) {
+	if _, err := controller.Storage.UserStorage.RetrieveByID(userID); err != nil {
+		return nil, NewFeedbackError(ERROR_FLAG_CAN_NOT_GET_USER, "get user error: "+err.Error())
+	}
+	teamMembers, errInRetrieveTeamMembers := controller.Storage.TeamMemberStorage.RetrieveByUserID(userID)
+	if errInRetrieveTeamMembers != nil {
+		return nil, NewFeedbackError(ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve team member error: "+errInRetrieveTeamMembers.Error())
+	}
+	teamIDs := make([]int, 0, len(teamMembers))
+	for _, teamMember := range teamMembers {
+		teamIDs = append(teamIDs, teamMember.TeamID)
+	}
+	teams, errInRetrieveTeams := controller.Storage.TeamStorage.RetrieveByIDs(teamIDs)
+	if errInRetrieveTeams != nil {
+		return nil, NewFeedbackError(ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+errInRetrieveTeams.Error())
+	}
+	return model.NewGetTargetUserTeamsResponse(userID, teamMembers, teams), nil
+}
@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/idconvertor"
+)
+
+func TestBatchRetrieveTargetUsersByIDsReportsMalformedIDs(t *testing.T) {
+	controller, _ := newTestController(t)
+	user := &model.User{Nickname: "alice", Email: "alice@acme.com"}
+	user.InitUID()
+	if _, err := controller.Storage.UserStorage.Create(user); err != nil {
+		t.Fatalf("create user failed: %v", err)
+	}
+	magicID := idconvertor.ConvertIntToString(user.ID)
+	missingID := idconvertor.ConvertIntToString(user.ID + 100)
+	// same length as a magic id, but does not convert back to itself
+	malformedID := "x" + magicID[1:]
+
+	req := &model.BatchGetTargetUsersByIDsRequest{IDs: []string{magicID, magicID, malformedID, malformedID, missingID}}
+	resp, err := controller.BatchRetrieveTargetUsersByIDs(req.ExportIDs())
+	if err != nil {
+		t.Fatalf("batch retrieve failed: %v", err)
+	}
+	if len(resp.Users) != 1 || resp.Users[magicID] == nil || resp.Users[magicID].Email != user.Email {
+		t.Errorf("users = %v, want %s only", resp.Users, magicID)
+	}
+	wantNotFound := []string{malformedID, missingID}
+	sort.Strings(wantNotFound)
+	if fmt.Sprint(resp.NotFound) != fmt.Sprint(wantNotFound) {
+		t.Errorf("not found = %v, want %v", resp.NotFound, wantNotFound)
+	}
+}
+
+func TestBatchRetrieveTargetTeamsByUIDsKeepsEveryRequestedForm(t *testing.T) {
+	controller, _ := newTestController(t)
+	team := &model.Team{Name: "acme", Identifier: "acme", Permission: "{}"}
+	team.InitUID()
+	if _, err := controller.Storage.TeamStorage.Create(team); err != nil {
+		t.Fatalf("create team failed: %v", err)
+	}
+	lower := team.UID.String()
+	upper := strings.ToUpper(lower)
+	missing := "00000000-0000-0000-0000-000000000001"
+
+	req := &model.BatchGetTargetTeamsByUIDsRequest{UIDs: []string{lower, upper, lower, missing}}
+	resp, err := controller.BatchRetrieveTargetTeamsByUIDs(req.ExportUIDs())
+	if err != nil {
+		t.Fatalf("batch retrieve failed: %v", err)
+	}
+	for _, key := range []string{lower, upper} {
+		if resp.Teams[key] == nil {
+			t.Errorf("team of %s not found", key)
+		}
+	}
+	if len(resp.Teams) != 2 || fmt.Sprint(resp.NotFound) != fmt.Sprint([]string{missing}) {
+		t.Errorf("teams = %d, not found = %v, want 2 teams and %s not found", len(resp.Teams), resp.NotFound, missing)
+	}
+}
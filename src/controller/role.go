@@ -0,0 +1,242 @@
+package controller
+
+import (
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/accesscontrol"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+)
+
+func (controller *Controller) GetAllRoles(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// validate user role
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_ROLES)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+	if !attrg.CanAccess(accesscontrol.ACTION_ACCESS_VIEW) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// retrieve
+	roles, err := controller.Storage.RoleStorage.RetrieveByTeamID(teamID)
+	if err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ROLE, "get roles error: "+err.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetAllRolesResponse(roles))
+	return
+}
+
+func (controller *Controller) CreateRole(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewCreateRoleRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// validate user role, the role can not have any attribute which operator does not have
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_ROLES)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+	if !attrg.CanManage(accesscontrol.ACTION_MANAGE_CUSTOM_ROLE) || !accesscontrol.CanGrantRolePermissions(teamMember.ExportUserRole(), req.Permissions) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// check role name
+	role := model.NewRoleByCreateRoleRequest(teamID, req)
+	if controller.Storage.RoleStorage.IsRoleNameExists(teamID, role.Name) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ROLE_NAME_HAS_BEEN_TAKEN, "the role name has been taken.")
+		return
+	}
+
+	// create
+	if _, err := controller.Storage.RoleStorage.Create(role); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_CREATE_ROLE, "create role error: "+err.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetRoleResponse(role))
+	return
+}
+
+func (controller *Controller) UpdateRole(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	roleID, errInGetRoleID := controller.GetMagicIntParamFromRequest(c, PARAM_ROLE_ID)
+	if errInGetUserID != nil || errInGetRoleID != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewUpdateRoleRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// get role
+	role, errInRetrieveRole := controller.Storage.RoleStorage.RetrieveByTeamIDAndID(teamID, roleID)
+	if errInRetrieveRole != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ROLE, "get role error: "+errInRetrieveRole.Error())
+		return
+	}
+	if role.IsSystemRole() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_MODIFY_SYSTEM_ROLE, "system role can not be modified.")
+		return
+	}
+
+	// validate user role, both of now permissions and target permissions should be grantable
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_ROLES)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+	if !attrg.CanManage(accesscontrol.ACTION_MANAGE_CUSTOM_ROLE) || !accesscontrol.CanGrantRolePermissions(teamMember.ExportUserRole(), role.ExportPermissions()) || !accesscontrol.CanGrantRolePermissions(teamMember.ExportUserRole(), req.Permissions) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// check new role name
+	previousName := role.Name
+	role.UpdateByUpdateRoleRequest(req)
+	if role.Name != previousName && controller.Storage.RoleStorage.IsRoleNameExists(teamID, role.Name) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ROLE_NAME_HAS_BEEN_TAKEN, "the role name has been taken.")
+		return
+	}
+
+	// update
+	if err := controller.Storage.RoleStorage.UpdateByID(role); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_ROLE, "update role error: "+err.Error())
+		return
+	}
+	accesscontrol.InvalidateRole(role.ExportID())
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetRoleResponse(role))
+	return
+}
+
+func (controller *Controller) DeleteRole(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	roleID, errInGetRoleID := controller.GetMagicIntParamFromRequest(c, PARAM_ROLE_ID)
+	if errInGetUserID != nil || errInGetRoleID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// get role
+	role, errInRetrieveRole := controller.Storage.RoleStorage.RetrieveByTeamIDAndID(teamID, roleID)
+	if errInRetrieveRole != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ROLE, "get role error: "+errInRetrieveRole.Error())
+		return
+	}
+	if role.IsSystemRole() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_MODIFY_SYSTEM_ROLE, "system role can not be deleted.")
+		return
+	}
+
+	// validate user role
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_ROLES)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+	if !attrg.CanDelete(accesscontrol.ACTION_DELETE) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// role assigned to team member can not be deleted
+	count, errInCountTeamMember := controller.Storage.TeamMemberStorage.CountByTeamIDAndUserRole(teamID, roleID)
+	if errInCountTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "count team member error: "+errInCountTeamMember.Error())
+		return
+	}
+	if count > 0 {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_DELETE_ROLE_IN_USE, "the role has been assigned to team members, please change their role first.")
+		return
+	}
+
+	// delete role and its unit-level grants
+	errInDeleteRole := controller.Storage.Transaction(func(txStorage *model.Storage) error {
+		if err := txStorage.UnitRoleRelationStorage.DeleteByTeamIDAndRoleID(teamID, roleID); err != nil {
+			return err
+		}
+		return txStorage.RoleStorage.DeleteByTeamIDAndID(teamID, roleID)
+	})
+	if errInDeleteRole != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_DELETE_ROLE, "delete role error: "+errInDeleteRole.Error())
+		return
+	}
+	accesscontrol.InvalidateRole(roleID)
+
+	// feedback
+	controller.FeedbackOK(c, nil)
+	return
+}
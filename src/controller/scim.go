@@ -0,0 +1,699 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+)
+
+// SCIM 2.0 provisioning endpoints, authorized by the team SCIM bearer token (see authenticator.SCIMAuth()).
+// Users are mapped to User and TeamMember, Groups are mapped to the built-in user roles.
+// Deactivating a user suspends the team member, deleting a user removes the team member, the User itself is kept.
+
+func (controller *Controller) SCIMGetUsers(c *gin.Context) {
+	teamID, errInGetTeamID := controller.GetSCIMTeamIDFromAuth(c)
+	if errInGetTeamID != nil {
+		return
+	}
+	startIndex, count, errInGetIndex := controller.GetSCIMStartIndexAndCount(c)
+	if errInGetIndex != nil {
+		return
+	}
+
+	// parse filter
+	filterRaw, _ := controller.TestFirstStringParamValueFromURI(c, PARAM_SCIM_FILTER)
+	filter, errInParseFilter := model.ParseSCIMFilter(filterRaw)
+	if errInParseFilter != nil {
+		controller.FeedbackSCIMError(c, http.StatusBadRequest, model.SCIM_ERROR_TYPE_INVALID_FILTER, errInParseFilter.Error())
+		return
+	}
+
+	// retrieve
+	teamMembers, total, errInRetrieve := controller.Storage.TeamMemberStorage.RetrieveBySCIMFilter(teamID, filter, startIndex, count)
+	if errors.Is(errInRetrieve, model.ErrSCIMFilterUnsupportedAttribute) {
+		controller.FeedbackSCIMError(c, http.StatusBadRequest, model.SCIM_ERROR_TYPE_INVALID_FILTER, errInRetrieve.Error())
+		return
+	}
+	if errInRetrieve != nil {
+		controller.FeedbackSCIMError(c, http.StatusInternalServerError, "", "retrieve team members error: "+errInRetrieve.Error())
+		return
+	}
+	users, errInRetrieveUsers := controller.Storage.UserStorage.RetrieveByIDs(model.PickUpUserIDsInTeamMembers(teamMembers))
+	if errInRetrieveUsers != nil {
+		controller.FeedbackSCIMError(c, http.StatusInternalServerError, "", "get users error: "+errInRetrieveUsers.Error())
+		return
+	}
+	usersLT := make(map[int]*model.User, len(users))
+	for _, user := range users {
+		usersLT[user.ID] = user
+	}
+
+	// feedback
+	resp := model.NewSCIMListResponse(int(total), startIndex)
+	for _, teamMember := range teamMembers {
+		if user, hit := usersLT[teamMember.ExportUserID()]; hit {
+			resp.AppendResource(model.NewSCIMUserByTeamMember(user, teamMember))
+		}
+	}
+	controller.FeedbackSCIM(c, http.StatusOK, resp)
+	return
+}
+
+func (controller *Controller) SCIMGetUser(c *gin.Context) {
+	teamID, errInGetTeamID := controller.GetSCIMTeamIDFromAuth(c)
+	if errInGetTeamID != nil {
+		return
+	}
+	user, teamMember, errInRetrieve := controller.RetrieveSCIMUser(c, teamID)
+	if errInRetrieve != nil {
+		return
+	}
+
+	// feedback
+	controller.FeedbackSCIM(c, http.StatusOK, model.NewSCIMUserByTeamMember(user, teamMember))
+	return
+}
+
+func (controller *Controller) SCIMCreateUser(c *gin.Context) {
+	teamID, errInGetTeamID := controller.GetSCIMTeamIDFromAuth(c)
+	if errInGetTeamID != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewSCIMUser()
+	if err := json.NewDecoder(c.Request.Body).Decode(req); err != nil {
+		controller.FeedbackSCIMError(c, http.StatusBadRequest, model.SCIM_ERROR_TYPE_INVALID_SYNTAX, "parse request body error: "+err.Error())
+		return
+	}
+	if err := req.Validate(); err != nil {
+		controller.FeedbackSCIMError(c, http.StatusBadRequest, model.SCIM_ERROR_TYPE_INVALID_VALUE, err.Error())
+		return
+	}
+
+	// create user or reuse the existing user, then join the team
+	var user *model.User
+	var teamMember *model.TeamMember
+	errInCreate := controller.Storage.Transaction(func(txStorage *model.Storage) error {
+		var errInRetrieveUser error
+		user, errInRetrieveUser = txStorage.UserStorage.RetrieveByEmail(req.ExportEmail())
+		if errors.Is(errInRetrieveUser, gorm.ErrRecordNotFound) {
+			var errInNewUser error
+			user, errInNewUser = model.NewUserBySCIMUser(req)
+			if errInNewUser != nil {
+				return errInNewUser
+			}
+			if _, err := txStorage.UserStorage.Create(user); err != nil {
+				return err
+			}
+		} else if errInRetrieveUser != nil {
+			return errInRetrieveUser
+		} else {
+			joined, err := txStorage.TeamMemberStorage.DoesTeamIncludedTargetUser(teamID, user.ID)
+			if err != nil {
+				return err
+			}
+			if joined {
+				return errSCIMUserExists
+			}
+			user.SetSCIMExternalID(req.ExternalID)
+			if err := txStorage.UserStorage.UpdateByID(user); err != nil {
+				return err
+			}
+		}
+		teamMember = model.NewTeamMemberBySCIMUser(teamID, user, req)
+		if _, err := txStorage.TeamMemberStorage.Create(teamMember); err != nil {
+			return err
+		}
+		_, err := txStorage.OutboxEventStorage.Create(model.NewTeamMemberJoinedEvent(teamMember, model.SYSTEM_OPERATOR_USER_ID))
+		return err
+	})
+	if errors.Is(errInCreate, errSCIMUserExists) {
+		controller.FeedbackSCIMError(c, http.StatusConflict, model.SCIM_ERROR_TYPE_UNIQUENESS, "user already exists in this team.")
+		return
+	}
+	if errInCreate != nil {
+		controller.FeedbackSCIMError(c, http.StatusInternalServerError, "", "create user error: "+errInCreate.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackSCIM(c, http.StatusCreated, model.NewSCIMUserByTeamMember(user, teamMember))
+	return
+}
+
+func (controller *Controller) SCIMReplaceUser(c *gin.Context) {
+	teamID, errInGetTeamID := controller.GetSCIMTeamIDFromAuth(c)
+	if errInGetTeamID != nil {
+		return
+	}
+	user, teamMember, errInRetrieve := controller.RetrieveSCIMUser(c, teamID)
+	if errInRetrieve != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewSCIMUser()
+	if err := json.NewDecoder(c.Request.Body).Decode(req); err != nil {
+		controller.FeedbackSCIMError(c, http.StatusBadRequest, model.SCIM_ERROR_TYPE_INVALID_SYNTAX, "parse request body error: "+err.Error())
+		return
+	}
+	if err := req.Validate(); err != nil {
+		controller.FeedbackSCIMError(c, http.StatusBadRequest, model.SCIM_ERROR_TYPE_INVALID_VALUE, err.Error())
+		return
+	}
+
+	// update
+	controller.UpdateSCIMUser(c, user, teamMember, req)
+	return
+}
+
+func (controller *Controller) SCIMPatchUser(c *gin.Context) {
+	teamID, errInGetTeamID := controller.GetSCIMTeamIDFromAuth(c)
+	if errInGetTeamID != nil {
+		return
+	}
+	user, teamMember, errInRetrieve := controller.RetrieveSCIMUser(c, teamID)
+	if errInRetrieve != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewSCIMPatchRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(req); err != nil {
+		controller.FeedbackSCIMError(c, http.StatusBadRequest, model.SCIM_ERROR_TYPE_INVALID_SYNTAX, "parse request body error: "+err.Error())
+		return
+	}
+	if err := req.Validate(); err != nil {
+		controller.FeedbackSCIMError(c, http.StatusBadRequest, model.SCIM_ERROR_TYPE_INVALID_SYNTAX, err.Error())
+		return
+	}
+
+	// apply patch to the current resource
+	scimUser := model.NewSCIMUserByTeamMember(user, teamMember)
+	if err := req.ApplyToSCIMUser(scimUser); err != nil {
+		controller.FeedbackSCIMError(c, http.StatusBadRequest, model.SCIM_ERROR_TYPE_INVALID_VALUE, err.Error())
+		return
+	}
+
+	// update
+	controller.UpdateSCIMUser(c, user, teamMember, scimUser)
+	return
+}
+
+func (controller *Controller) SCIMDeleteUser(c *gin.Context) {
+	teamID, errInGetTeamID := controller.GetSCIMTeamIDFromAuth(c)
+	if errInGetTeamID != nil {
+		return
+	}
+	user, teamMember, errInRetrieve := controller.RetrieveSCIMUser(c, teamID)
+	if errInRetrieve != nil {
+		return
+	}
+	if teamMember.IsOwner() {
+		controller.FeedbackSCIMError(c, http.StatusBadRequest, model.SCIM_ERROR_TYPE_MUTABILITY, "team owner can not be deprovisioned.")
+		return
+	}
+
+	// remove team member, the user itself is kept, and recorded so the user will not join the team by email domain again
+	errInDelete := controller.Storage.Transaction(func(txStorage *model.Storage) error {
+		if err := txStorage.TeamMemberStorage.DeleteByIDAndTeamID(teamMember.ExportID(), teamID); err != nil {
+			return err
+		}
+		if _, err := txStorage.RemovedTeamMemberStorage.Create(model.NewRemovedTeamMember(teamMember, user, model.REMOVED_TEAM_MEMBER_REASON_DEPROVISIONED)); err != nil {
+			return err
+		}
+		if err := txStorage.UnitRoleRelationStorage.DeleteByTeamIDAndUserID(teamID, teamMember.ExportUserID()); err != nil {
+			return err
+		}
+		_, err := txStorage.OutboxEventStorage.Create(model.NewTeamMemberRemovedEvent(teamMember, model.SYSTEM_OPERATOR_USER_ID))
+		return err
+	})
+	if errInDelete != nil {
+		controller.FeedbackSCIMError(c, http.StatusInternalServerError, "", "delete team member error: "+errInDelete.Error())
+		return
+	}
+	if err := controller.Cache.JWTCache.CleanUserJWTTokenExpiredAt(user); err != nil {
+		controller.FeedbackSCIMError(c, http.StatusInternalServerError, "", "clean user token expired at cache failed: "+err.Error())
+		return
+	}
+
+	// feedback
+	c.Status(http.StatusNoContent)
+	return
+}
+
+func (controller *Controller) SCIMGetGroups(c *gin.Context) {
+	teamID, errInGetTeamID := controller.GetSCIMTeamIDFromAuth(c)
+	if errInGetTeamID != nil {
+		return
+	}
+	startIndex, count, errInGetIndex := controller.GetSCIMStartIndexAndCount(c)
+	if errInGetIndex != nil {
+		return
+	}
+
+	// parse filter
+	filterRaw, _ := controller.TestFirstStringParamValueFromURI(c, PARAM_SCIM_FILTER)
+	filter, errInParseFilter := model.ParseSCIMFilter(filterRaw)
+	if errInParseFilter != nil {
+		controller.FeedbackSCIMError(c, http.StatusBadRequest, model.SCIM_ERROR_TYPE_INVALID_FILTER, errInParseFilter.Error())
+		return
+	}
+	excludeMembers := controller.DoesSCIMMembersExcluded(c)
+
+	// filter groups in memory, there are only built-in role groups
+	matchedGroups := make([]*model.SCIMGroup, 0)
+	for _, group := range model.NewAllSCIMGroups() {
+		members, errInRetrieveMembers := controller.RetrieveSCIMGroupMembers(teamID, group)
+		if errInRetrieveMembers != nil {
+			controller.FeedbackSCIMError(c, http.StatusInternalServerError, "", "get group members error: "+errInRetrieveMembers.Error())
+			return
+		}
+		matched, errInMatch := matchSCIMGroup(group, members, filter)
+		if errInMatch != nil {
+			controller.FeedbackSCIMError(c, http.StatusBadRequest, model.SCIM_ERROR_TYPE_INVALID_FILTER, errInMatch.Error())
+			return
+		}
+		if !matched {
+			continue
+		}
+		if !excludeMembers {
+			group.SetMembers(members)
+		}
+		matchedGroups = append(matchedGroups, group)
+	}
+
+	// feedback
+	resp := model.NewSCIMListResponse(len(matchedGroups), startIndex)
+	for index, group := range matchedGroups {
+		if index+1 >= startIndex && len(resp.Resources) < count {
+			resp.AppendResource(group)
+		}
+	}
+	controller.FeedbackSCIM(c, http.StatusOK, resp)
+	return
+}
+
+func (controller *Controller) SCIMGetGroup(c *gin.Context) {
+	teamID, errInGetTeamID := controller.GetSCIMTeamIDFromAuth(c)
+	if errInGetTeamID != nil {
+		return
+	}
+	group, errInGetGroup := controller.GetSCIMGroupFromRequest(c)
+	if errInGetGroup != nil {
+		return
+	}
+
+	// fetch members
+	if !controller.DoesSCIMMembersExcluded(c) {
+		members, err := controller.RetrieveSCIMGroupMembers(teamID, group)
+		if err != nil {
+			controller.FeedbackSCIMError(c, http.StatusInternalServerError, "", "get group members error: "+err.Error())
+			return
+		}
+		group.SetMembers(members)
+	}
+
+	// feedback
+	controller.FeedbackSCIM(c, http.StatusOK, group)
+	return
+}
+
+func (controller *Controller) SCIMPatchGroup(c *gin.Context) {
+	teamID, errInGetTeamID := controller.GetSCIMTeamIDFromAuth(c)
+	if errInGetTeamID != nil {
+		return
+	}
+	group, errInGetGroup := controller.GetSCIMGroupFromRequest(c)
+	if errInGetGroup != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewSCIMPatchRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(req); err != nil {
+		controller.FeedbackSCIMError(c, http.StatusBadRequest, model.SCIM_ERROR_TYPE_INVALID_SYNTAX, "parse request body error: "+err.Error())
+		return
+	}
+	if err := req.Validate(); err != nil {
+		controller.FeedbackSCIMError(c, http.StatusBadRequest, model.SCIM_ERROR_TYPE_INVALID_SYNTAX, err.Error())
+		return
+	}
+
+	// apply patch to members
+	members, errInRetrieveMembers := controller.RetrieveSCIMGroupMembers(teamID, group)
+	if errInRetrieveMembers != nil {
+		controller.FeedbackSCIMError(c, http.StatusInternalServerError, "", "get group members error: "+errInRetrieveMembers.Error())
+		return
+	}
+	memberIDs := make([]string, 0, len(members))
+	for _, member := range members {
+		memberIDs = append(memberIDs, member.GetUIDInString())
+	}
+	newMemberIDs, errInApply := req.ApplyToGroupMembers(group, memberIDs)
+	if errInApply != nil {
+		controller.FeedbackSCIMError(c, http.StatusBadRequest, model.SCIM_ERROR_TYPE_INVALID_VALUE, errInApply.Error())
+		return
+	}
+
+	// update
+	controller.UpdateSCIMGroupMembers(c, teamID, group, memberIDs, newMemberIDs)
+	return
+}
+
+func (controller *Controller) SCIMReplaceGroup(c *gin.Context) {
+	teamID, errInGetTeamID := controller.GetSCIMTeamIDFromAuth(c)
+	if errInGetTeamID != nil {
+		return
+	}
+	group, errInGetGroup := controller.GetSCIMGroupFromRequest(c)
+	if errInGetGroup != nil {
+		return
+	}
+
+	// get request body
+	req := &model.SCIMGroup{}
+	if err := json.NewDecoder(c.Request.Body).Decode(req); err != nil {
+		controller.FeedbackSCIMError(c, http.StatusBadRequest, model.SCIM_ERROR_TYPE_INVALID_SYNTAX, "parse request body error: "+err.Error())
+		return
+	}
+	if req.DisplayName != "" && !strings.EqualFold(req.DisplayName, group.DisplayName) {
+		controller.FeedbackSCIMError(c, http.StatusBadRequest, model.SCIM_ERROR_TYPE_MUTABILITY, "group displayName is mapped to role and can not be changed.")
+		return
+	}
+
+	// replace members
+	members, errInRetrieveMembers := controller.RetrieveSCIMGroupMembers(teamID, group)
+	if errInRetrieveMembers != nil {
+		controller.FeedbackSCIMError(c, http.StatusInternalServerError, "", "get group members error: "+errInRetrieveMembers.Error())
+		return
+	}
+	memberIDs := make([]string, 0, len(members))
+	for _, member := range members {
+		memberIDs = append(memberIDs, member.GetUIDInString())
+	}
+	newMemberIDs := make([]string, 0, len(req.Members))
+	for _, member := range req.Members {
+		newMemberIDs = append(newMemberIDs, member.Value)
+	}
+
+	// update
+	controller.UpdateSCIMGroupMembers(c, teamID, group, memberIDs, newMemberIDs)
+	return
+}
+
+var errSCIMUserExists = errors.New("scim user exists")
+
+// @note: this param was setted by authenticator.SCIMAuth() method
+func (controller *Controller) GetSCIMTeamIDFromAuth(c *gin.Context) (int, error) {
+	teamID, ok := c.Get("scimTeamID")
+	if !ok {
+		controller.FeedbackSCIMError(c, http.StatusUnauthorized, "", "bearer token invalid, can not fetch team ID in it.")
+		return 0, errors.New("input missing scimTeamID field.")
+	}
+	teamIDInt, okAssert := teamID.(int)
+	if !okAssert {
+		controller.FeedbackSCIMError(c, http.StatusUnauthorized, "", "bearer token invalid, team ID is not int type in it.")
+		return 0, errors.New("input scimTeamID in wrong format.")
+	}
+	return teamIDInt, nil
+}
+
+// GetSCIMStartIndexAndCount get the 1-based startIndex and count of SCIM pagination.
+func (controller *Controller) GetSCIMStartIndexAndCount(c *gin.Context) (int, int, error) {
+	startIndex := 1
+	count := model.SCIM_DEFAULT_COUNT
+	if startIndexRaw, err := controller.TestFirstStringParamValueFromURI(c, PARAM_SCIM_START_INDEX); err == nil {
+		startIndexInt, errInConvert := strconv.Atoi(startIndexRaw)
+		if errInConvert != nil {
+			controller.FeedbackSCIMError(c, http.StatusBadRequest, model.SCIM_ERROR_TYPE_INVALID_VALUE, "startIndex should be integer.")
+			return 0, 0, errInConvert
+		}
+		if startIndexInt > 1 {
+			startIndex = startIndexInt
+		}
+	}
+	if countRaw, err := controller.TestFirstStringParamValueFromURI(c, PARAM_SCIM_COUNT); err == nil {
+		countInt, errInConvert := strconv.Atoi(countRaw)
+		if errInConvert != nil {
+			controller.FeedbackSCIMError(c, http.StatusBadRequest, model.SCIM_ERROR_TYPE_INVALID_VALUE, "count should be integer.")
+			return 0, 0, errInConvert
+		}
+		count = countInt
+	}
+	if count < 0 {
+		count = 0
+	}
+	if count > model.SCIM_MAX_COUNT {
+		count = model.SCIM_MAX_COUNT
+	}
+	return startIndex, count, nil
+}
+
+func (controller *Controller) DoesSCIMMembersExcluded(c *gin.Context) bool {
+	excludedAttributes, _ := controller.TestFirstStringParamValueFromURI(c, PARAM_SCIM_EXCLUDED_ATTRIBUTES)
+	for _, attribute := range strings.Split(excludedAttributes, ",") {
+		if strings.EqualFold(strings.TrimSpace(attribute), "members") {
+			return true
+		}
+	}
+	return false
+}
+
+// RetrieveSCIMUser retrieve user and team member by the SCIM user id (the UID of user), feedback 404 when not found.
+func (controller *Controller) RetrieveSCIMUser(c *gin.Context, teamID int) (*model.User, *model.TeamMember, error) {
+	scimUserID, _ := controller.TestStringParamFromRequest(c, PARAM_SCIM_USER_ID)
+	userUID, errInParseUID := uuid.Parse(scimUserID)
+	if errInParseUID != nil {
+		controller.FeedbackSCIMError(c, http.StatusNotFound, "", "user "+scimUserID+" not found.")
+		return nil, nil, errInParseUID
+	}
+	user, errInRetrieveUser := controller.Storage.UserStorage.RetrieveByUID(userUID)
+	if errInRetrieveUser != nil {
+		controller.FeedbackSCIMError(c, http.StatusNotFound, "", "user "+scimUserID+" not found.")
+		return nil, nil, errInRetrieveUser
+	}
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, user.ID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackSCIMError(c, http.StatusNotFound, "", "user "+scimUserID+" not found.")
+		return nil, nil, errInRetrieveTeamMember
+	}
+	return user, teamMember, nil
+}
+
+func (controller *Controller) GetSCIMGroupFromRequest(c *gin.Context) (*model.SCIMGroup, error) {
+	scimGroupID, _ := controller.TestStringParamFromRequest(c, PARAM_SCIM_GROUP_ID)
+	group, hit := model.NewSCIMGroupByID(scimGroupID)
+	if !hit {
+		controller.FeedbackSCIMError(c, http.StatusNotFound, "", "group "+scimGroupID+" not found.")
+		return nil, errors.New("group not found")
+	}
+	return group, nil
+}
+
+func (controller *Controller) RetrieveSCIMGroupMembers(teamID int, group *model.SCIMGroup) ([]*model.User, error) {
+	teamMembers, err := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndEffectiveUserRole(teamID, group.ExportUserRole(), time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	return controller.Storage.UserStorage.RetrieveByIDs(model.PickUpUserIDsInTeamMembers(teamMembers))
+}
+
+// UpdateSCIMUser save the SCIM user attributes to user and team member, and feedback the updated resource.
+func (controller *Controller) UpdateSCIMUser(c *gin.Context, user *model.User, teamMember *model.TeamMember, scimUser *model.SCIMUser) {
+	if err := user.UpdateBySCIMUser(scimUser); err != nil {
+		controller.FeedbackSCIMError(c, http.StatusBadRequest, model.SCIM_ERROR_TYPE_MUTABILITY, err.Error())
+		return
+	}
+	if teamMember.IsOwner() && !scimUser.IsActive() {
+		controller.FeedbackSCIMError(c, http.StatusBadRequest, model.SCIM_ERROR_TYPE_MUTABILITY, "team owner can not be deactivated.")
+		return
+	}
+
+	// deactivate suspends the team member, activate reactivates suspended or pending member
+	revokeSessions := false
+	previousStatus := teamMember.ExportStatus()
+	if scimUser.IsActive() && !teamMember.IsStatusOK() {
+		teamMember.ReactivateUser()
+	} else if !scimUser.IsActive() && !teamMember.IsStatusSuspend() {
+		teamMember.SuspendUser()
+		revokeSessions = true
+	}
+	errInUpdate := controller.Storage.Transaction(func(txStorage *model.Storage) error {
+		if err := txStorage.UserStorage.UpdateByID(user); err != nil {
+			return err
+		}
+		if err := txStorage.TeamMemberStorage.Update(teamMember); err != nil {
+			return err
+		}
+		if _, err := txStorage.OutboxEventStorage.Create(model.NewUserUpdatedEvent(user)); err != nil {
+			return err
+		}
+		if teamMember.ExportStatus() == previousStatus {
+			return nil
+		}
+		_, err := txStorage.OutboxEventStorage.Create(model.NewTeamMemberStatusChangedEvent(teamMember, model.SYSTEM_OPERATOR_USER_ID))
+		return err
+	})
+	if errInUpdate != nil {
+		controller.FeedbackSCIMError(c, http.StatusInternalServerError, "", "update user error: "+errInUpdate.Error())
+		return
+	}
+	if revokeSessions {
+		if err := controller.Cache.JWTCache.CleanUserJWTTokenExpiredAt(user); err != nil {
+			controller.FeedbackSCIMError(c, http.StatusInternalServerError, "", "clean user token expired at cache failed: "+err.Error())
+			return
+		}
+	}
+
+	// feedback
+	controller.FeedbackSCIM(c, http.StatusOK, model.NewSCIMUserByTeamMember(user, teamMember))
+}
+
+// UpdateSCIMGroupMembers assign the role of group to added members, and fallback removed members to viewer.
+func (controller *Controller) UpdateSCIMGroupMembers(c *gin.Context, teamID int, group *model.SCIMGroup, memberIDs []string, newMemberIDs []string) {
+	added, removed := diffSCIMMemberIDs(memberIDs, newMemberIDs)
+	if len(added) == 0 && len(removed) == 0 {
+		c.Status(http.StatusNoContent)
+		return
+	}
+	if group.IsReadOnly() {
+		controller.FeedbackSCIMError(c, http.StatusBadRequest, model.SCIM_ERROR_TYPE_MUTABILITY, "members of "+group.DisplayName+" group can not be changed.")
+		return
+	}
+
+	// retrieve team members of changed users
+	changedUIDs := make([]uuid.UUID, 0, len(added)+len(removed))
+	for _, memberID := range append(append([]string{}, added...), removed...) {
+		memberUID, err := uuid.Parse(memberID)
+		if err != nil {
+			controller.FeedbackSCIMError(c, http.StatusBadRequest, model.SCIM_ERROR_TYPE_INVALID_VALUE, "member "+memberID+" not found.")
+			return
+		}
+		changedUIDs = append(changedUIDs, memberUID)
+	}
+	users, errInRetrieveUsers := controller.Storage.UserStorage.RetrieveByUIDs(changedUIDs)
+	if errInRetrieveUsers != nil {
+		controller.FeedbackSCIMError(c, http.StatusInternalServerError, "", "get users error: "+errInRetrieveUsers.Error())
+		return
+	}
+	usersLT := make(map[string]*model.User, len(users))
+	for _, user := range users {
+		usersLT[user.GetUIDInString()] = user
+	}
+
+	errInUpdate := controller.Storage.Transaction(func(txStorage *model.Storage) error {
+		updateRole := func(memberID string, userRole int) error {
+			user, hit := usersLT[memberID]
+			if !hit {
+				return errors.New("member " + memberID + " not found.")
+			}
+			teamMember, err := txStorage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, user.ID)
+			if err != nil {
+				return errors.New("member " + memberID + " not found.")
+			}
+			if teamMember.IsOwner() {
+				return errors.New("role of team owner can not be changed.")
+			}
+			previousUserRole := teamMember.ExportUserRole()
+			teamMember.UpdateTeamMemberRole(userRole)
+			if err := txStorage.TeamMemberStorage.UpdateRole(teamMember); err != nil {
+				return err
+			}
+			if previousUserRole == userRole {
+				return nil
+			}
+			_, err = txStorage.OutboxEventStorage.Create(model.NewTeamMemberRoleChangedEvent(teamMember, previousUserRole, model.SYSTEM_OPERATOR_USER_ID))
+			return err
+		}
+		for _, memberID := range added {
+			if err := updateRole(memberID, group.ExportUserRole()); err != nil {
+				return err
+			}
+		}
+		for _, memberID := range removed {
+			if err := updateRole(memberID, model.USER_ROLE_VIEWER); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errInUpdate != nil {
+		controller.FeedbackSCIMError(c, http.StatusBadRequest, model.SCIM_ERROR_TYPE_INVALID_VALUE, errInUpdate.Error())
+		return
+	}
+
+	// feedback
+	c.Status(http.StatusNoContent)
+}
+
+func (controller *Controller) FeedbackSCIM(c *gin.Context, status int, resp model.Response) {
+	c.Header("Content-Type", model.SCIM_CONTENT_TYPE)
+	c.JSON(status, resp.ExportForFeedback())
+}
+
+func (controller *Controller) FeedbackSCIMError(c *gin.Context, status int, scimType string, detail string) {
+	c.Header("Content-Type", model.SCIM_CONTENT_TYPE)
+	c.AbortWithStatusJSON(status, model.NewSCIMError(strconv.Itoa(status), scimType, detail))
+}
+
+func matchSCIMGroup(group *model.SCIMGroup, members []*model.User, filter *model.SCIMFilter) (bool, error) {
+	for _, condition := range filter.Conditions {
+		switch condition.Attribute {
+		case "id":
+			if !condition.Match(group.ID) {
+				return false, nil
+			}
+		case "displayname":
+			if !condition.Match(group.DisplayName) {
+				return false, nil
+			}
+		case "members", "members.value":
+			hit := false
+			for _, member := range members {
+				if condition.Match(member.GetUIDInString()) {
+					hit = true
+					break
+				}
+			}
+			if !hit {
+				return false, nil
+			}
+		default:
+			return false, errors.New("unsupported filter attribute: " + condition.Attribute)
+		}
+	}
+	return true, nil
+}
+
+func diffSCIMMemberIDs(memberIDs []string, newMemberIDs []string) ([]string, []string) {
+	current := make(map[string]bool, len(memberIDs))
+	for _, memberID := range memberIDs {
+		current[memberID] = true
+	}
+	next := make(map[string]bool, len(newMemberIDs))
+	added := make([]string, 0)
+	for _, memberID := range newMemberIDs {
+		next[memberID] = true
+		if !current[memberID] {
+			added = append(added, memberID)
+		}
+	}
+	removed := make([]string, 0)
+	for _, memberID := range memberIDs {
+		if !next[memberID] {
+			removed = append(removed, memberID)
+		}
+	}
+	return added, removed
+}
@@ -0,0 +1,384 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/internal/testdb"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"go.uber.org/zap"
+)
+
+// the payloads below are replayed from the Okta and Entra ID provisioning requests.
+
+const oktaCreateUserPayload = `{
+	"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+	"userName": "isaiah.berlin@acme.com",
+	"name": {"givenName": "Isaiah", "familyName": "Berlin"},
+	"emails": [{"primary": true, "value": "isaiah.berlin@acme.com", "type": "work"}],
+	"displayName": "Isaiah Berlin",
+	"locale": "en-US",
+	"externalId": "00ujl29u0le5T6Aj10h7",
+	"groups": [],
+	"password": "1mz050nq",
+	"active": true
+}`
+
+const entraCreateUserPayload = `{
+	"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User", "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"],
+	"externalId": "0a21f0f2-8d2a-4f8e-bf98-7363c4aed4ef",
+	"userName": "Adele.Vance@contoso.com",
+	"active": true,
+	"emails": [{"primary": true, "type": "work", "value": "Adele.Vance@contoso.com"}],
+	"meta": {"resourceType": "User"},
+	"name": {"formatted": "Adele Vance", "familyName": "Vance", "givenName": "Adele"},
+	"roles": []
+}`
+
+func newTestSCIMRouter(t *testing.T) (*Controller, *gin.Engine, int) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	controller, _ := newTestController(t)
+	controller.Cache = testdb.NewCache(t)
+	teamID := createTestTeam(t, controller.Storage)
+
+	// the team id is set by authenticator.SCIMAuth() in the router
+	engine := gin.New()
+	scimRouter := engine.Group(model.SCIM_BASE_PATH)
+	scimRouter.Use(func(c *gin.Context) { c.Set("scimTeamID", teamID) })
+	scimRouter.GET("/Users", controller.SCIMGetUsers)
+	scimRouter.POST("/Users", controller.SCIMCreateUser)
+	scimRouter.GET("/Users/:scimUserID", controller.SCIMGetUser)
+	scimRouter.PUT("/Users/:scimUserID", controller.SCIMReplaceUser)
+	scimRouter.PATCH("/Users/:scimUserID", controller.SCIMPatchUser)
+	scimRouter.DELETE("/Users/:scimUserID", controller.SCIMDeleteUser)
+	scimRouter.GET("/Groups", controller.SCIMGetGroups)
+	scimRouter.GET("/Groups/:scimGroupID", controller.SCIMGetGroup)
+	scimRouter.PUT("/Groups/:scimGroupID", controller.SCIMReplaceGroup)
+	scimRouter.PATCH("/Groups/:scimGroupID", controller.SCIMPatchGroup)
+	return controller, engine, teamID
+}
+
+func serveSCIM(t *testing.T, engine *gin.Engine, method string, path string, payload string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, model.SCIM_BASE_PATH+path, strings.NewReader(payload))
+	req.Header.Set("Content-Type", model.SCIM_CONTENT_TYPE)
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func decodeSCIMResponse(t *testing.T, recorder *httptest.ResponseRecorder, status int, resp interface{}) {
+	t.Helper()
+	if recorder.Code != status {
+		t.Fatalf("status = %d, want %d: %s", recorder.Code, status, recorder.Body.String())
+	}
+	if resp == nil {
+		return
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), resp); err != nil {
+		t.Fatalf("decode response failed: %v: %s", err, recorder.Body.String())
+	}
+}
+
+func createSCIMUser(t *testing.T, engine *gin.Engine, payload string) *model.SCIMUser {
+	t.Helper()
+	scimUser := model.NewSCIMUser()
+	decodeSCIMResponse(t, serveSCIM(t, engine, http.MethodPost, "/Users", payload), http.StatusCreated, scimUser)
+	return scimUser
+}
+
+func retrieveSCIMTeamMember(t *testing.T, controller *Controller, teamID int, scimUser *model.SCIMUser) *model.TeamMember {
+	t.Helper()
+	user, err := controller.Storage.UserStorage.RetrieveByEmail(scimUser.UserName)
+	if err != nil {
+		t.Fatalf("retrieve user failed: %v", err)
+	}
+	teamMember, err := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, user.ID)
+	if err != nil {
+		t.Fatalf("retrieve team member failed: %v", err)
+	}
+	return teamMember
+}
+
+func filterSCIMUsers(t *testing.T, engine *gin.Engine, filter string) *model.SCIMListResponse {
+	t.Helper()
+	resp := &model.SCIMListResponse{}
+	query := url.Values{"filter": {filter}, "startIndex": {"1"}, "count": {"100"}}
+	decodeSCIMResponse(t, serveSCIM(t, engine, http.MethodGet, "/Users?"+query.Encode(), ""), http.StatusOK, resp)
+	return resp
+}
+
+func TestSCIMOktaUserLifecycle(t *testing.T) {
+	controller, engine, teamID := newTestSCIMRouter(t)
+
+	created := createSCIMUser(t, engine, oktaCreateUserPayload)
+	if created.ID == "" || created.UserName != "isaiah.berlin@acme.com" || created.ExternalID != "00ujl29u0le5T6Aj10h7" || !created.IsActive() {
+		t.Fatalf("unexpected created user: %+v", created)
+	}
+	if created.Groups[0].Value != "viewer" {
+		t.Errorf("group = %s, want viewer", created.Groups[0].Value)
+	}
+
+	// Okta looks up the user by userName before push
+	if resp := filterSCIMUsers(t, engine, `userName eq "Isaiah.Berlin@acme.com"`); resp.TotalResults != 1 {
+		t.Errorf("filtered users = %d, want 1", resp.TotalResults)
+	}
+	if resp := filterSCIMUsers(t, engine, `userName eq "someone@acme.com"`); resp.TotalResults != 0 || len(resp.Resources) != 0 {
+		t.Errorf("filtered users = %d, want 0", resp.TotalResults)
+	}
+
+	// Okta push the profile by PUT
+	replaced := model.NewSCIMUser()
+	replacePayload := strings.Replace(oktaCreateUserPayload, `"displayName": "Isaiah Berlin"`, `"displayName": "Isaiah B."`, 1)
+	decodeSCIMResponse(t, serveSCIM(t, engine, http.MethodPut, "/Users/"+created.ID, replacePayload), http.StatusOK, replaced)
+	if replaced.DisplayName != "Isaiah B." {
+		t.Errorf("display name = %s, want Isaiah B.", replaced.DisplayName)
+	}
+
+	// Okta deactivate and reactivate by PATCH without path
+	deactivated := model.NewSCIMUser()
+	deactivatePayload := `{"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"], "Operations": [{"op": "replace", "value": {"active": false}}]}`
+	decodeSCIMResponse(t, serveSCIM(t, engine, http.MethodPatch, "/Users/"+created.ID, deactivatePayload), http.StatusOK, deactivated)
+	if deactivated.IsActive() || !retrieveSCIMTeamMember(t, controller, teamID, created).IsStatusSuspend() {
+		t.Error("deactivated user should suspend the team member")
+	}
+	reactivatePayload := `{"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"], "Operations": [{"op": "replace", "value": {"active": true}}]}`
+	decodeSCIMResponse(t, serveSCIM(t, engine, http.MethodPatch, "/Users/"+created.ID, reactivatePayload), http.StatusOK, nil)
+	if !retrieveSCIMTeamMember(t, controller, teamID, created).IsStatusOK() {
+		t.Error("reactivated user should be ok")
+	}
+
+	// deprovision removes the team member and keeps the user
+	decodeSCIMResponse(t, serveSCIM(t, engine, http.MethodDelete, "/Users/"+created.ID, ""), http.StatusNoContent, nil)
+	decodeSCIMResponse(t, serveSCIM(t, engine, http.MethodGet, "/Users/"+created.ID, ""), http.StatusNotFound, nil)
+	user, err := controller.Storage.UserStorage.RetrieveByEmail(created.UserName)
+	if err != nil {
+		t.Fatalf("user should be kept: %v", err)
+	}
+	if removed, err := controller.Storage.RemovedTeamMemberStorage.DoesTeamRemovedTargetUser(teamID, user.ID, user.Email); err != nil || !removed {
+		t.Errorf("deprovisioned user should be recorded: %v %v", removed, err)
+	}
+}
+
+func TestSCIMEntraUserLifecycle(t *testing.T) {
+	controller, engine, teamID := newTestSCIMRouter(t)
+
+	created := createSCIMUser(t, engine, entraCreateUserPayload)
+	if created.UserName != "Adele.Vance@contoso.com" || created.DisplayName != "Adele Vance" {
+		t.Fatalf("unexpected created user: %+v", created)
+	}
+	if resp := filterSCIMUsers(t, engine, `userName eq "adele.vance@contoso.com"`); resp.TotalResults != 1 {
+		t.Errorf("filtered users = %d, want 1", resp.TotalResults)
+	}
+
+	// Entra ID send capitalized ops with path, and the extension attributes are ignored
+	patched := model.NewSCIMUser()
+	patchPayload := `{
+		"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+		"Operations": [
+			{"op": "Replace", "path": "displayName", "value": "Adele V."},
+			{"op": "Add", "path": "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:employeeNumber", "value": "1024"},
+			{"op": "Replace", "path": "externalId", "value": "60a3b0a8-4fbb-4a45-a3b0-4ad1a7c0e5f2"},
+			{"op": "Remove", "path": "name.familyName"}
+		]
+	}`
+	decodeSCIMResponse(t, serveSCIM(t, engine, http.MethodPatch, "/Users/"+created.ID, patchPayload), http.StatusOK, patched)
+	if patched.DisplayName != "Adele V." || patched.ExternalID != "60a3b0a8-4fbb-4a45-a3b0-4ad1a7c0e5f2" {
+		t.Errorf("unexpected patched user: %+v", patched)
+	}
+
+	// Entra ID send the boolean as string
+	deactivated := model.NewSCIMUser()
+	deactivatePayload := `{"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"], "Operations": [{"op": "Replace", "path": "active", "value": "False"}]}`
+	decodeSCIMResponse(t, serveSCIM(t, engine, http.MethodPatch, "/Users/"+created.ID, deactivatePayload), http.StatusOK, deactivated)
+	if deactivated.IsActive() || !retrieveSCIMTeamMember(t, controller, teamID, created).IsStatusSuspend() {
+		t.Error("deactivated user should suspend the team member")
+	}
+
+	// the suspended member is still listed as inactive
+	resp := filterSCIMUsers(t, engine, `externalId eq "60a3b0a8-4fbb-4a45-a3b0-4ad1a7c0e5f2"`)
+	if resp.TotalResults != 1 {
+		t.Fatalf("filtered users = %d, want 1", resp.TotalResults)
+	}
+	if resource := resp.Resources[0].(map[string]interface{}); resource["active"] != false {
+		t.Errorf("listed active = %v, want false", resource["active"])
+	}
+}
+
+func TestSCIMGroupMembers(t *testing.T) {
+	controller, engine, teamID := newTestSCIMRouter(t)
+	okta := createSCIMUser(t, engine, oktaCreateUserPayload)
+	entra := createSCIMUser(t, engine, entraCreateUserPayload)
+	userRole := func(scimUser *model.SCIMUser) int {
+		return retrieveSCIMTeamMember(t, controller, teamID, scimUser).ExportUserRole()
+	}
+
+	cases := []struct {
+		name    string
+		method  string
+		group   string
+		payload string
+		want    map[*model.SCIMUser]int
+	}{
+		{
+			name:    "okta add members",
+			method:  http.MethodPatch,
+			group:   "editor",
+			payload: `{"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"], "Operations": [{"op": "add", "path": "members", "value": [{"value": "` + okta.ID + `", "display": "isaiah.berlin@acme.com"}]}]}`,
+			want:    map[*model.SCIMUser]int{okta: model.USER_ROLE_EDITOR, entra: model.USER_ROLE_VIEWER},
+		},
+		{
+			name:    "okta remove member by value path",
+			method:  http.MethodPatch,
+			group:   "editor",
+			payload: `{"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"], "Operations": [{"op": "remove", "path": "members[value eq \"` + okta.ID + `\"]"}]}`,
+			want:    map[*model.SCIMUser]int{okta: model.USER_ROLE_VIEWER, entra: model.USER_ROLE_VIEWER},
+		},
+		{
+			name:    "entra add members",
+			method:  http.MethodPatch,
+			group:   "admin",
+			payload: `{"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"], "Operations": [{"op": "Add", "path": "members", "value": [{"value": "` + okta.ID + `"}, {"value": "` + entra.ID + `"}]}]}`,
+			want:    map[*model.SCIMUser]int{okta: model.USER_ROLE_ADMIN, entra: model.USER_ROLE_ADMIN},
+		},
+		{
+			name:    "entra remove members",
+			method:  http.MethodPatch,
+			group:   "admin",
+			payload: `{"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"], "Operations": [{"op": "Remove", "path": "members", "value": [{"value": "` + entra.ID + `"}]}]}`,
+			want:    map[*model.SCIMUser]int{okta: model.USER_ROLE_ADMIN, entra: model.USER_ROLE_VIEWER},
+		},
+		{
+			name:    "okta replace members without path",
+			method:  http.MethodPatch,
+			group:   "admin",
+			payload: `{"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"], "Operations": [{"op": "replace", "value": {"id": "admin", "displayName": "Admin", "members": [{"value": "` + entra.ID + `"}]}}]}`,
+			want:    map[*model.SCIMUser]int{okta: model.USER_ROLE_VIEWER, entra: model.USER_ROLE_ADMIN},
+		},
+		{
+			name:    "okta push group by PUT",
+			method:  http.MethodPut,
+			group:   "editor",
+			payload: `{"schemas": ["urn:ietf:params:scim:schemas:core:2.0:Group"], "id": "editor", "displayName": "Editor", "members": [{"value": "` + okta.ID + `"}, {"value": "` + entra.ID + `"}]}`,
+			want:    map[*model.SCIMUser]int{okta: model.USER_ROLE_EDITOR, entra: model.USER_ROLE_EDITOR},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			decodeSCIMResponse(t, serveSCIM(t, engine, c.method, "/Groups/"+c.group, c.payload), http.StatusNoContent, nil)
+			for scimUser, want := range c.want {
+				if got := userRole(scimUser); got != want {
+					t.Errorf("role of %s = %d, want %d", scimUser.UserName, got, want)
+				}
+			}
+		})
+	}
+
+	// the group lists the members by role
+	group := &model.SCIMGroup{}
+	decodeSCIMResponse(t, serveSCIM(t, engine, http.MethodGet, "/Groups/editor", ""), http.StatusOK, group)
+	if len(group.Members) != 2 {
+		t.Errorf("members of editor group = %d, want 2", len(group.Members))
+	}
+
+	// the members are listed by the role they hold now, an expired time-bound role is not held even before the expirer restored it
+	grants := map[*model.SCIMUser]time.Time{okta: time.Now().Add(-time.Hour), entra: time.Now().Add(time.Hour)}
+	for scimUser, validUntil := range grants {
+		teamMember := retrieveSCIMTeamMember(t, controller, teamID, scimUser)
+		teamMember.GrantTimeBoundRole(model.USER_ROLE_ADMIN, validUntil)
+		if err := controller.Storage.TeamMemberStorage.UpdateRole(teamMember); err != nil {
+			t.Fatalf("grant time-bound role failed: %v", err)
+		}
+	}
+	for groupID, want := range map[string]*model.SCIMUser{"editor": okta, "admin": entra} {
+		group := &model.SCIMGroup{}
+		decodeSCIMResponse(t, serveSCIM(t, engine, http.MethodGet, "/Groups/"+groupID, ""), http.StatusOK, group)
+		if len(group.Members) != 1 || group.Members[0].Value != want.ID {
+			t.Errorf("members of %s group = %+v, want %s only", groupID, group.Members, want.UserName)
+		}
+	}
+}
+
+func TestSCIMErrors(t *testing.T) {
+	controller, engine, teamID := newTestSCIMRouter(t)
+	created := createSCIMUser(t, engine, oktaCreateUserPayload)
+	owner := createSCIMUser(t, engine, entraCreateUserPayload)
+	ownerMember := retrieveSCIMTeamMember(t, controller, teamID, owner)
+	ownerMember.UpdateTeamMemberRole(model.USER_ROLE_OWNER)
+	if err := controller.Storage.TeamMemberStorage.Update(ownerMember); err != nil {
+		t.Fatalf("update team member failed: %v", err)
+	}
+
+	cases := []struct {
+		name     string
+		method   string
+		path     string
+		payload  string
+		status   int
+		scimType string
+	}{
+		{"duplicated user", http.MethodPost, "/Users", oktaCreateUserPayload, http.StatusConflict, model.SCIM_ERROR_TYPE_UNIQUENESS},
+		{"malformed body", http.MethodPost, "/Users", `{"userName": `, http.StatusBadRequest, model.SCIM_ERROR_TYPE_INVALID_SYNTAX},
+		{"missing email", http.MethodPost, "/Users", `{"userName": "isaiah"}`, http.StatusBadRequest, model.SCIM_ERROR_TYPE_INVALID_VALUE},
+		{"unsupported filter attribute", http.MethodGet, "/Users?filter=" + url.QueryEscape(`title eq "engineer"`), "", http.StatusBadRequest, model.SCIM_ERROR_TYPE_INVALID_FILTER},
+		{"invalid filter", http.MethodGet, "/Users?filter=" + url.QueryEscape(`userName eq "a" or userName eq "b"`), "", http.StatusBadRequest, model.SCIM_ERROR_TYPE_INVALID_FILTER},
+		{"unknown user", http.MethodGet, "/Users/2819c223-7f76-453a-919d-413861904646", "", http.StatusNotFound, ""},
+		{"unsupported patch op", http.MethodPatch, "/Users/" + created.ID, `{"Operations": [{"op": "move", "path": "active"}]}`, http.StatusBadRequest, model.SCIM_ERROR_TYPE_INVALID_SYNTAX},
+		{"change email", http.MethodPatch, "/Users/" + created.ID, `{"Operations": [{"op": "replace", "path": "emails[type eq \"work\"].value", "value": "isaiah@acme.com"}]}`, http.StatusBadRequest, model.SCIM_ERROR_TYPE_MUTABILITY},
+		{"deactivate owner", http.MethodPatch, "/Users/" + owner.ID, `{"Operations": [{"op": "Replace", "path": "active", "value": "False"}]}`, http.StatusBadRequest, model.SCIM_ERROR_TYPE_MUTABILITY},
+		{"delete owner", http.MethodDelete, "/Users/" + owner.ID, "", http.StatusBadRequest, model.SCIM_ERROR_TYPE_MUTABILITY},
+		{"unknown group", http.MethodGet, "/Groups/auditor", "", http.StatusNotFound, ""},
+		{"change owner group", http.MethodPatch, "/Groups/owner", `{"Operations": [{"op": "add", "path": "members", "value": [{"value": "` + created.ID + `"}]}]}`, http.StatusBadRequest, model.SCIM_ERROR_TYPE_MUTABILITY},
+		{"rename group", http.MethodPut, "/Groups/admin", `{"displayName": "Auditor"}`, http.StatusBadRequest, model.SCIM_ERROR_TYPE_MUTABILITY},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			recorder := serveSCIM(t, engine, c.method, c.path, c.payload)
+			scimError := &model.SCIMError{}
+			decodeSCIMResponse(t, recorder, c.status, scimError)
+			if got := recorder.Header().Get("Content-Type"); got != model.SCIM_CONTENT_TYPE {
+				t.Errorf("content type = %s, want %s", got, model.SCIM_CONTENT_TYPE)
+			}
+			if len(scimError.Schemas) != 1 || scimError.Schemas[0] != model.SCIM_SCHEMA_ERROR {
+				t.Errorf("schemas = %v, want %s", scimError.Schemas, model.SCIM_SCHEMA_ERROR)
+			}
+			if scimError.Status != strconv.Itoa(c.status) {
+				t.Errorf("status = %s, want %d", scimError.Status, c.status)
+			}
+			if scimError.ScimType != c.scimType {
+				t.Errorf("scimType = %s, want %s", scimError.ScimType, c.scimType)
+			}
+			if scimError.Detail == "" {
+				t.Error("detail should not be empty")
+			}
+		})
+	}
+}
+
+func TestSCIMGetUsersStorageError(t *testing.T) {
+	controller, engine, _ := newTestSCIMRouter(t)
+	createSCIMUser(t, engine, oktaCreateUserPayload)
+	brokenDB := testdb.NewDB(t)
+	sqlDB, err := brokenDB.DB()
+	if err != nil {
+		t.Fatalf("get sql db failed: %v", err)
+	}
+	sqlDB.Close()
+	controller.Storage.TeamMemberStorage = model.NewTeamMemberStorage(brokenDB, zap.NewNop().Sugar())
+
+	// a failed lookup is a server error, not an invalid filter
+	recorder := serveSCIM(t, engine, http.MethodGet, "/Users?filter="+url.QueryEscape(`userName eq "Isaiah.Berlin@acme.com"`), "")
+	scimError := &model.SCIMError{}
+	decodeSCIMResponse(t, recorder, http.StatusInternalServerError, scimError)
+	if scimError.ScimType != "" {
+		t.Errorf("scimType = %s, want none", scimError.ScimType)
+	}
+}
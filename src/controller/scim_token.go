@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/accesscontrol"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+)
+
+func (controller *Controller) GetAllSCIMTokens(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// validate user role
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_TEAM)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+	if !attrg.CanManage(accesscontrol.ACTION_MANAGE_SCIM) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// retrieve
+	scimTokens, err := controller.Storage.SCIMTokenStorage.RetrieveByTeamID(teamID)
+	if err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_SCIM_TOKEN, "get scim tokens error: "+err.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetAllSCIMTokensResponse(scimTokens))
+	return
+}
+
+func (controller *Controller) CreateSCIMToken(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// validate user role
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_TEAM)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+	if !attrg.CanManage(accesscontrol.ACTION_MANAGE_SCIM) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// create, the plain token only feedback once
+	scimToken, token := model.NewSCIMToken(teamID, userID)
+	if _, err := controller.Storage.SCIMTokenStorage.Create(scimToken); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_CREATE_SCIM_TOKEN, "create scim token error: "+err.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, scimToken.ExportWithToken(token))
+	return
+}
+
+func (controller *Controller) DeleteSCIMToken(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	scimTokenID, errInGetSCIMTokenID := controller.GetMagicIntParamFromRequest(c, PARAM_SCIM_TOKEN_ID)
+	if errInGetUserID != nil || errInGetSCIMTokenID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// validate user role
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_TEAM)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+	if !attrg.CanManage(accesscontrol.ACTION_MANAGE_SCIM) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// delete
+	if err := controller.Storage.SCIMTokenStorage.DeleteByTeamIDAndID(teamID, scimTokenID); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_DELETE_SCIM_TOKEN, "delete scim token error: "+err.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, nil)
+	return
+}
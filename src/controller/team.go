@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 
 	"github.com/kozmoai/kozmo-supervisor-backend/src/accesscontrol"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
@@ -15,8 +16,14 @@ func (controller *Controller) GetMyTeams(c *gin.Context) {
 		return
 	}
 
+	// get pagination, sort by joined time in default, all teams are feedback without cursor and limit
+	pagination, errInGetPagination := controller.GetOptionalPaginationFromRequest(c, model.PAGINATION_SORT_KEY_CREATED_AT, model.PAGINATION_DIRECTION_DESC)
+	if errInGetPagination != nil {
+		return
+	}
+
 	// retrieve
-	teamMembers, errInGetTeamMember := controller.Storage.TeamMemberStorage.RetrieveByUserID(userID)
+	teamMembers, pageInfo, errInGetTeamMember := controller.Storage.TeamMemberStorage.RetrieveByUserIDByPage(userID, pagination)
 	if errInGetTeamMember != nil {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve team by id error: "+errInGetTeamMember.Error())
 		return
@@ -28,11 +35,9 @@ func (controller *Controller) GetMyTeams(c *gin.Context) {
 		return
 	}
 
-	// build lookup table for feedback
-	tmlt := model.BuildTeamIDLookUpTableForTeamMemberExport(teamMembers)
-
-	// feedback
-	controller.FeedbackOK(c, model.NewGetMyTeamsResponse(teams, tmlt))
+	// feedback, the body is an array, so the page info goes to header
+	controller.SetPageInfoHeader(c, pageInfo)
+	controller.FeedbackOK(c, model.NewGetMyTeamsResponse(teamMembers, teams))
 	return
 }
 
@@ -59,7 +64,10 @@ func (controller *Controller) UpdateTeamConfig(c *gin.Context) {
 	}
 
 	// validate user role
-	attrg := accesscontrol.NewAttributeGroup(teamMember.ExportUserRole(), accesscontrol.UNIT_TYPE_TEAM)
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_TEAM)
+	if errInApplyTeamPermission != nil {
+		return
+	}
 	if !attrg.CanManage(accesscontrol.ACTION_MANAGE_TEAM_CONFIG) {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
 		return
@@ -80,8 +88,15 @@ func (controller *Controller) UpdateTeamConfig(c *gin.Context) {
 	}
 
 	// update
-	if err := controller.Storage.TeamStorage.UpdateByID(team); err != nil {
-		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_TEAM, "update team error: "+err.Error())
+	errInUpdateTeam := controller.Storage.Transaction(func(txStorage *model.Storage) error {
+		if err := txStorage.TeamStorage.UpdateByID(team); err != nil {
+			return err
+		}
+		_, err := txStorage.OutboxEventStorage.Create(model.NewTeamUpdatedEvent(team, userID))
+		return err
+	})
+	if errInUpdateTeam != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_TEAM, "update team error: "+errInUpdateTeam.Error())
 		return
 	}
 
@@ -113,7 +128,10 @@ func (controller *Controller) UpdateTeamPermission(c *gin.Context) {
 	}
 
 	// validate user role
-	attrg := accesscontrol.NewAttributeGroup(teamMember.ExportUserRole(), accesscontrol.UNIT_TYPE_TEAM)
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_TEAM)
+	if errInApplyTeamPermission != nil {
+		return
+	}
 	if !attrg.CanManage(accesscontrol.ACTION_SPECIAL_EDITOR_AND_VIEWER_CAN_INVITE_BY_LINK_SW) {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
 		return
@@ -127,13 +145,144 @@ func (controller *Controller) UpdateTeamPermission(c *gin.Context) {
 	}
 
 	// update team permission
+	previousTeamPermission := team.ExportTeamPermission()
 	errInParseRawReq := team.UpdateByUpdateTeamPermissionRawRequest(rawRequest)
 	if errInParseRawReq != nil {
 		controller.FeedbackBadRequest(c, ERROR_FLAG_BUILD_TEAM_PERMISSION_FAILED, "build team permission error: "+errInParseRawReq.Error())
 		return
 	}
-	if err := controller.Storage.TeamStorage.UpdateByID(team); err != nil {
-		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_TEAM, "update team error: "+err.Error())
+
+	// new claimed auto join domains should be proven
+	newAutoJoinDomains := team.ExportTeamPermission().ExportNewAutoJoinDomains(previousTeamPermission)
+	for _, domain := range newAutoJoinDomains {
+		if model.IsPublicEmailDomain(domain) {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_AUTO_JOIN_DOMAIN_IS_PUBLIC, "auto join domain "+domain+" is a public mail domain.")
+			return
+		}
+		if !controller.DoesAutoJoinDomainProven(team, domain) {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_AUTO_JOIN_DOMAIN_NOT_CLAIMED, "auto join domain "+domain+" should be verified by DNS.")
+			return
+		}
+	}
+	errInUpdateTeam := controller.Storage.Transaction(func(txStorage *model.Storage) error {
+		if err := txStorage.TeamStorage.UpdateByID(team); err != nil {
+			return err
+		}
+		_, err := txStorage.OutboxEventStorage.Create(model.NewTeamUpdatedEvent(team, userID))
+		return err
+	})
+	if errInUpdateTeam != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_TEAM, "update team error: "+errInUpdateTeam.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, nil)
+	return
+}
+
+func (controller *Controller) DeleteTeam(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewDeleteTeamRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// validate user role
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_TEAM)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+	if !attrg.CanDelete(accesscontrol.ACTION_DELETE) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// get team by id
+	team, err := controller.Storage.TeamStorage.RetrieveByID(teamID)
+	if err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM, "get team error: "+err.Error())
+		return
+	}
+
+	// confirm by team identifier
+	if !req.DoesConfirmed(team) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_TEAM_IDENTIFIER_MISMATCH, "team identifier mismatch, please type the team identifier to confirm.")
+		return
+	}
+
+	// delete invites, team members, domains and team (with it's settings) in one transaction,
+	// other kozmo services drop units of this team by the TeamDeleted event, and the team drive folder is removed
+	// by outbox.TeamDriveCleaner, which retries until it succeeded.
+	errInDeleteTeam := controller.Storage.Transaction(func(txStorage *model.Storage) error {
+		revokedInvites, errInRetrievePendingInvites := txStorage.InviteStorage.RetrievePendingByTeamID(teamID)
+		if errInRetrievePendingInvites != nil {
+			return errInRetrievePendingInvites
+		}
+		// pending invites are revoked by removing them, the invite link and email can not be accepted anymore
+		if err := txStorage.InviteStorage.DeleteByTeamID(teamID); err != nil {
+			return err
+		}
+		if err := txStorage.TeamMemberStorage.DeleteByTeamID(teamID); err != nil {
+			return err
+		}
+		if err := txStorage.RemovedTeamMemberStorage.DeleteByTeamID(teamID); err != nil {
+			return err
+		}
+		if err := txStorage.DomainStorage.DeleteByTeamID(teamID); err != nil {
+			return err
+		}
+		if err := txStorage.SCIMTokenStorage.DeleteByTeamID(teamID); err != nil {
+			return err
+		}
+		if err := txStorage.RoleStorage.DeleteByTeamID(teamID); err != nil {
+			return err
+		}
+		if err := txStorage.UnitRoleRelationStorage.DeleteByTeamID(teamID); err != nil {
+			return err
+		}
+		if err := txStorage.AccessRequestStorage.DeleteByTeamID(teamID); err != nil {
+			return err
+		}
+		if err := txStorage.AccessRequestEventStorage.DeleteByTeamID(teamID); err != nil {
+			return err
+		}
+		if err := txStorage.WebhookDeliveryStorage.DeleteByTeamID(teamID); err != nil {
+			return err
+		}
+		if err := txStorage.WebhookStorage.DeleteByTeamID(teamID); err != nil {
+			return err
+		}
+		if err := txStorage.TeamStorage.DeleteByID(teamID); err != nil {
+			return err
+		}
+		_, err := txStorage.OutboxEventStorage.Create(model.NewTeamDeletedEvent(team, revokedInvites, userID))
+		return err
+	})
+	if errInDeleteTeam != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_DELETE_TEAM, "delete team error: "+errInDeleteTeam.Error())
 		return
 	}
 
@@ -0,0 +1,540 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"gorm.io/gorm"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/accesscontrol"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+)
+
+func (controller *Controller) SearchTeamMembers(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// build search filter
+	keyword, _ := controller.TestFirstStringParamValueFromURI(c, PARAM_SEARCH_KEYWORD)
+	userRoleRaw, _ := controller.TestFirstStringParamValueFromURI(c, PARAM_USER_ROLE)
+	userStatusRaw, _ := controller.TestFirstStringParamValueFromURI(c, PARAM_USER_STATUS)
+	limitRaw, _ := controller.TestFirstStringParamValueFromURI(c, PARAM_LIMIT)
+	filter := model.NewTeamMemberSearchFilter()
+	filter.SetKeyword(keyword)
+	if err := filter.SetUserRoleByString(userRoleRaw); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_PARAM_FAILED, "validate request param error: "+err.Error())
+		return
+	}
+	if err := filter.SetStatusByString(userStatusRaw); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_PARAM_FAILED, "validate request param error: "+err.Error())
+		return
+	}
+	if err := filter.SetLimitByString(limitRaw); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_PARAM_FAILED, "validate request param error: "+err.Error())
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_TEAM_MEMBER)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+	if !attrg.CanAccess(accesscontrol.ACTION_ACCESS_VIEW) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// search by keyword in rank order, or list all team members page by page
+	var teamMembers []*model.TeamMember
+	if filter.HasKeyword() {
+		var errInSearchTeamMembers error
+		teamMembers, errInSearchTeamMembers = controller.Storage.TeamMemberStorage.SearchByTeamID(teamID, filter)
+		if errInSearchTeamMembers != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "search team members error: "+errInSearchTeamMembers.Error())
+			return
+		}
+	} else {
+		pagination, errInGetPagination := controller.GetPaginationFromRequest(c, model.PAGINATION_SORT_KEY_ID, model.PAGINATION_DIRECTION_ASC)
+		if errInGetPagination != nil {
+			return
+		}
+		var pageInfo *model.PageInfo
+		var errInRetrieveTeamMembers error
+		teamMembers, pageInfo, errInRetrieveTeamMembers = controller.Storage.TeamMemberStorage.RetrieveByTeamIDByPage(teamID, filter, pagination)
+		if errInRetrieveTeamMembers != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve team members error: "+errInRetrieveTeamMembers.Error())
+			return
+		}
+		controller.SetPageInfoHeader(c, pageInfo)
+	}
+
+	// fetch user info
+	userIDs := make([]int, 0, len(teamMembers))
+	for _, targetTeamMember := range teamMembers {
+		userIDs = append(userIDs, targetTeamMember.ExportUserID())
+	}
+	users, errInRetrieveUsers := controller.Storage.UserStorage.RetrieveByIDs(userIDs)
+	if errInRetrieveUsers != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_USER, "get users error: "+errInRetrieveUsers.Error())
+		return
+	}
+	usersLT := make(map[int]*model.User, len(users))
+	for _, user := range users {
+		usersLT[user.ID] = user
+	}
+
+	// build result, keep the ranking order of search result
+	teamMembersWithUserInfo := make([]*model.TeamMemberWithUserInfoForExport, 0, len(teamMembers))
+	for _, targetTeamMember := range teamMembers {
+		user, hit := usersLT[targetTeamMember.ExportUserID()]
+		if !hit {
+			continue
+		}
+		userForExport := user.Export()
+		userForExport.SetTeamMemberID(targetTeamMember.ExportID())
+		teamMembersWithUserInfo = append(teamMembersWithUserInfo, targetTeamMember.ExportWithUserInfo(userForExport))
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetAllTeamMembersResponse(teamMembersWithUserInfo))
+	return
+}
+
+func (controller *Controller) SuspendTeamMember(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	targetTeamMemberID, errInGetTargetTeamMemberID := controller.GetMagicIntParamFromRequest(c, PARAM_TARGET_TEAM_MEMBER_ID)
+	if errInGetUserID != nil || errInGetTargetTeamMemberID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// get target team member
+	targetTeamMember, errInRetrieveTargetTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndID(teamID, targetTeamMemberID)
+	if errInRetrieveTargetTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve target team member error: "+errInRetrieveTargetTeamMember.Error())
+		return
+	}
+
+	// check target team member
+	if targetTeamMember.IsOwner() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_SUSPEND_OWNER, "can not suspend team owner.")
+		return
+	}
+	if targetTeamMember.ExportID() == teamMember.ExportID() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_SUSPEND_YOURSELF, "can not suspend yourself.")
+		return
+	}
+	if targetTeamMember.IsStatusPending() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_SUSPEND_PENDING_USER, "can not suspend pending user, please remove the invite instead.")
+		return
+	}
+
+	// validate user role, the target role should be manageable by now user
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_TEAM_MEMBER)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+	if !attrg.CanManage(accesscontrol.ACTION_MANAGE_SUSPEND_MEMBER) || !attrg.CanModifyRoleFromTo(targetTeamMember.ExportUserRole(), targetTeamMember.ExportUserRole()) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// get target user
+	targetUser, errInRetrieveTargetUser := controller.Storage.UserStorage.RetrieveByID(targetTeamMember.ExportUserID())
+	if errInRetrieveTargetUser != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_USER, "get target user error: "+errInRetrieveTargetUser.Error())
+		return
+	}
+
+	// suspend, the team member record will be kept for history
+	targetTeamMember.SuspendUser()
+	errInUpdateTeamMember := controller.Storage.Transaction(func(txStorage *model.Storage) error {
+		if err := txStorage.TeamMemberStorage.Update(targetTeamMember); err != nil {
+			return err
+		}
+		_, err := txStorage.OutboxEventStorage.Create(model.NewTeamMemberStatusChangedEvent(targetTeamMember, userID))
+		return err
+	})
+	if errInUpdateTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_TEAM_MEMBER, "update team member error: "+errInUpdateTeamMember.Error())
+		return
+	}
+
+	// revoke sessions of target user, the self-host deploy only have one team, so the sessions are team wide.
+	if err := controller.Cache.JWTCache.CleanUserJWTTokenExpiredAt(targetUser); err != nil {
+		controller.FeedbackInternalServerError(c, ERROR_FLAG_CAHCE_JWT_TOKEN_FAILED, "clean target user token expired at cache failed: "+err.Error())
+		return
+	}
+
+	// feedback
+	targetUserForExport := targetUser.Export()
+	targetUserForExport.SetTeamMemberID(targetTeamMember.ExportID())
+	controller.FeedbackOK(c, model.NewGetTeamMemberResponse(targetTeamMember.ExportWithUserInfo(targetUserForExport)))
+	return
+}
+
+func (controller *Controller) ReactivateTeamMember(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	targetTeamMemberID, errInGetTargetTeamMemberID := controller.GetMagicIntParamFromRequest(c, PARAM_TARGET_TEAM_MEMBER_ID)
+	if errInGetUserID != nil || errInGetTargetTeamMemberID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// get target team member
+	targetTeamMember, errInRetrieveTargetTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndID(teamID, targetTeamMemberID)
+	if errInRetrieveTargetTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve target team member error: "+errInRetrieveTargetTeamMember.Error())
+		return
+	}
+
+	// validate user role, the target role should be manageable by now user
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_TEAM_MEMBER)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+	if !attrg.CanManage(accesscontrol.ACTION_MANAGE_SUSPEND_MEMBER) || !attrg.CanModifyRoleFromTo(targetTeamMember.ExportUserRole(), targetTeamMember.ExportUserRole()) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// get target user
+	targetUser, errInRetrieveTargetUser := controller.Storage.UserStorage.RetrieveByID(targetTeamMember.ExportUserID())
+	if errInRetrieveTargetUser != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_USER, "get target user error: "+errInRetrieveTargetUser.Error())
+		return
+	}
+
+	// reactivate, only suspended team member can be reactivated
+	if targetTeamMember.IsStatusSuspend() {
+		targetTeamMember.ReactivateUser()
+		errInUpdateTeamMember := controller.Storage.Transaction(func(txStorage *model.Storage) error {
+			if err := txStorage.TeamMemberStorage.Update(targetTeamMember); err != nil {
+				return err
+			}
+			_, err := txStorage.OutboxEventStorage.Create(model.NewTeamMemberStatusChangedEvent(targetTeamMember, userID))
+			return err
+		})
+		if errInUpdateTeamMember != nil {
+			controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_TEAM_MEMBER, "update team member error: "+errInUpdateTeamMember.Error())
+			return
+		}
+	}
+
+	// feedback
+	targetUserForExport := targetUser.Export()
+	targetUserForExport.SetTeamMemberID(targetTeamMember.ExportID())
+	controller.FeedbackOK(c, model.NewGetTeamMemberResponse(targetTeamMember.ExportWithUserInfo(targetUserForExport)))
+	return
+}
+
+func (controller *Controller) ApproveTeamMember(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	targetTeamMemberID, errInGetTargetTeamMemberID := controller.GetMagicIntParamFromRequest(c, PARAM_TARGET_TEAM_MEMBER_ID)
+	if errInGetUserID != nil || errInGetTargetTeamMemberID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// get target team member
+	targetTeamMember, errInRetrieveTargetTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndID(teamID, targetTeamMemberID)
+	if errInRetrieveTargetTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve target team member error: "+errInRetrieveTargetTeamMember.Error())
+		return
+	}
+	if !targetTeamMember.IsStatusPending() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_TEAM_MEMBER_IS_NOT_PENDING, "target team member is not pending approval.")
+		return
+	}
+
+	// validate user role
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_TEAM_MEMBER)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+	if !attrg.CanManage(accesscontrol.ACTION_MANAGE_APPROVE_MEMBER) || !attrg.CanModifyRoleFromTo(targetTeamMember.ExportUserRole(), targetTeamMember.ExportUserRole()) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// get target user
+	targetUser, errInRetrieveTargetUser := controller.Storage.UserStorage.RetrieveByID(targetTeamMember.ExportUserID())
+	if errInRetrieveTargetUser != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_USER, "get target user error: "+errInRetrieveTargetUser.Error())
+		return
+	}
+
+	// approve
+	targetTeamMember.ApproveUser()
+	errInUpdateTeamMember := controller.Storage.Transaction(func(txStorage *model.Storage) error {
+		if err := txStorage.TeamMemberStorage.Update(targetTeamMember); err != nil {
+			return err
+		}
+		_, err := txStorage.OutboxEventStorage.Create(model.NewTeamMemberStatusChangedEvent(targetTeamMember, userID))
+		return err
+	})
+	if errInUpdateTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_TEAM_MEMBER, "update team member error: "+errInUpdateTeamMember.Error())
+		return
+	}
+
+	// feedback
+	targetUserForExport := targetUser.Export()
+	targetUserForExport.SetTeamMemberID(targetTeamMember.ExportID())
+	controller.FeedbackOK(c, model.NewGetTeamMemberResponse(targetTeamMember.ExportWithUserInfo(targetUserForExport)))
+	return
+}
+
+// DoesAutoJoinDomainProven tells if the team owns the email domain.
+// The domain should be verified by the team in DNS, public mail domains are never proven.
+func (controller *Controller) DoesAutoJoinDomainProven(team *model.Team, domain string) bool {
+	if model.IsPublicEmailDomain(domain) {
+		return false
+	}
+	verifiedDomain, err := controller.Storage.DomainStorage.RetrieveVerifiedByUserDomain(domain)
+	if err != nil {
+		return false
+	}
+	return verifiedDomain.TeamID == team.ExportID()
+}
+
+// AutoJoinTeamByEmailDomain add user to the team which claimed the email domain of user.
+// The self-host deploy only have one team, so only the default team will be checked.
+func (controller *Controller) AutoJoinTeamByEmailDomain(user *model.User) error {
+	teamID := model.TEAM_DEFAULT_ID
+	team, errInRetrieveTeam := controller.Storage.TeamStorage.RetrieveByID(teamID)
+	if errors.Is(errInRetrieveTeam, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if errInRetrieveTeam != nil {
+		return errInRetrieveTeam
+	}
+	emailDomain := user.ExportEmailDomain()
+	if !team.ExportTeamPermission().DoesAutoJoinDomainClaimed(emailDomain) {
+		return nil
+	}
+	// the domain verification may fail after it was claimed
+	if !controller.DoesAutoJoinDomainProven(team, emailDomain) {
+		return nil
+	}
+	joined, errInCheckMember := controller.Storage.TeamMemberStorage.DoesTeamIncludedTargetUser(teamID, user.ID)
+	if errInCheckMember != nil {
+		return errInCheckMember
+	}
+	if joined {
+		return nil
+	}
+	// the removed and deprovisioned users can only join by invite
+	removed, errInCheckRemoved := controller.Storage.RemovedTeamMemberStorage.DoesTeamRemovedTargetUser(teamID, user.ID, user.Email)
+	if errInCheckRemoved != nil {
+		return errInCheckRemoved
+	}
+	if removed {
+		return nil
+	}
+	return controller.Storage.Transaction(func(txStorage *model.Storage) error {
+		teamMember := model.NewTeamMemberByAutoJoin(team, user)
+		if _, err := txStorage.TeamMemberStorage.Create(teamMember); err != nil {
+			return err
+		}
+		_, err := txStorage.OutboxEventStorage.Create(model.NewTeamMemberJoinedEvent(teamMember, model.SYSTEM_OPERATOR_USER_ID))
+		return err
+	})
+}
+
+// GrantTimeBoundRole elevate the target team member until validUntil, the base role is restored by role grant expirer after that.
+func (controller *Controller) GrantTimeBoundRole(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	targetTeamMemberID, errInGetTargetTeamMemberID := controller.GetMagicIntParamFromRequest(c, PARAM_TARGET_TEAM_MEMBER_ID)
+	if errInGetUserID != nil || errInGetTargetTeamMemberID != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewGrantTimeBoundRoleRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+	if err := req.ValidateValidUntil(); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_INVALID_ROLE_VALID_UNTIL, "validate validUntil error: "+err.Error())
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// get target team member
+	targetTeamMember, errInRetrieveTargetTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndID(teamID, targetTeamMemberID)
+	if errInRetrieveTargetTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve target team member error: "+errInRetrieveTargetTeamMember.Error())
+		return
+	}
+
+	// check target team member and role, owner role can only be transferred
+	if targetTeamMember.IsOwner() || req.ExportUserRole() == model.USER_ROLE_OWNER {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GRANT_TIME_BOUND_OWNER, "owner role can not be time-bound.")
+		return
+	}
+	if targetTeamMember.ExportID() == teamMember.ExportID() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GRANT_ROLE_TO_YOURSELF, "can not grant role to yourself.")
+		return
+	}
+	if _, err := controller.Storage.RoleStorage.RetrieveByTeamIDAndID(teamID, req.ExportUserRole()); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ROLE, "get role error: "+err.Error())
+		return
+	}
+
+	// validate user role
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_TEAM_MEMBER)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+	if !attrg.CanManage(accesscontrol.ACTION_MANAGE_ROLE) || !attrg.CanModifyRoleFromTo(targetTeamMember.ExportUserRole(), req.ExportUserRole()) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// get target user
+	targetUser, errInRetrieveTargetUser := controller.Storage.UserStorage.RetrieveByID(targetTeamMember.ExportUserID())
+	if errInRetrieveTargetUser != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_USER, "get target user error: "+errInRetrieveTargetUser.Error())
+		return
+	}
+
+	// grant
+	previousUserRole := targetTeamMember.ExportUserRole()
+	targetTeamMember.GrantTimeBoundRole(req.ExportUserRole(), req.ExportValidUntil())
+	errInUpdateTeamMember := controller.Storage.Transaction(func(txStorage *model.Storage) error {
+		if err := txStorage.TeamMemberStorage.UpdateRole(targetTeamMember); err != nil {
+			return err
+		}
+		_, err := txStorage.OutboxEventStorage.Create(model.NewTeamMemberRoleChangedEvent(targetTeamMember, previousUserRole, userID))
+		return err
+	})
+	if errInUpdateTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_TEAM_MEMBER, "update team member error: "+errInUpdateTeamMember.Error())
+		return
+	}
+
+	// feedback
+	targetUserForExport := targetUser.Export()
+	targetUserForExport.SetTeamMemberID(targetTeamMember.ExportID())
+	controller.FeedbackOK(c, model.NewGetTeamMemberResponse(targetTeamMember.ExportWithUserInfo(targetUserForExport)))
+	return
+}
+
+// RevokeTimeBoundRole restore the base role of target team member before the time-bound role expired.
+func (controller *Controller) RevokeTimeBoundRole(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	targetTeamMemberID, errInGetTargetTeamMemberID := controller.GetMagicIntParamFromRequest(c, PARAM_TARGET_TEAM_MEMBER_ID)
+	if errInGetUserID != nil || errInGetTargetTeamMemberID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// get target team member
+	targetTeamMember, errInRetrieveTargetTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndID(teamID, targetTeamMemberID)
+	if errInRetrieveTargetTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "retrieve target team member error: "+errInRetrieveTargetTeamMember.Error())
+		return
+	}
+	if !targetTeamMember.IsRoleTimeBound() {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_TEAM_MEMBER_ROLE_IS_NOT_TIME_BOUND, "target team member role is not time-bound.")
+		return
+	}
+
+	// validate user role
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_TEAM_MEMBER)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+	if !attrg.CanManage(accesscontrol.ACTION_MANAGE_ROLE) || !attrg.CanModifyRoleFromTo(targetTeamMember.ExportUserRole(), targetTeamMember.ExportBaseUserRole()) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// get target user
+	targetUser, errInRetrieveTargetUser := controller.Storage.UserStorage.RetrieveByID(targetTeamMember.ExportUserID())
+	if errInRetrieveTargetUser != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_USER, "get target user error: "+errInRetrieveTargetUser.Error())
+		return
+	}
+
+	// revoke
+	previousUserRole := targetTeamMember.ExportUserRole()
+	targetTeamMember.ExpireTimeBoundRole()
+	errInUpdateTeamMember := controller.Storage.Transaction(func(txStorage *model.Storage) error {
+		if err := txStorage.TeamMemberStorage.UpdateRole(targetTeamMember); err != nil {
+			return err
+		}
+		_, err := txStorage.OutboxEventStorage.Create(model.NewTeamMemberRoleChangedEvent(targetTeamMember, previousUserRole, userID))
+		return err
+	})
+	if errInUpdateTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_TEAM_MEMBER, "update team member error: "+errInUpdateTeamMember.Error())
+		return
+	}
+
+	// feedback
+	targetUserForExport := targetUser.Export()
+	targetUserForExport.SetTeamMemberID(targetTeamMember.ExportID())
+	controller.FeedbackOK(c, model.NewGetTeamMemberResponse(targetTeamMember.ExportWithUserInfo(targetUserForExport)))
+	return
+}
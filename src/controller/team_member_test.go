@@ -0,0 +1,211 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/internal/testdb"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"go.uber.org/zap"
+)
+
+// newTestSelfHostController create the default team, which claims the "acme.com" email domain for auto join.
+// The "acme.com" domain is verified by the team, the "unverified.com" and "gmail.com" domains are only claimed.
+func newTestSelfHostController(t *testing.T) *Controller {
+	t.Helper()
+	db := testdb.NewDB(t)
+	tp := model.NewTeamPermission()
+	tp.AutoJoinDomains = []string{"acme.com", "unverified.com", "gmail.com"}
+	tp.AutoJoinRequireApproval = false
+	team := &model.Team{Name: "my-team", Identifier: "0", Permission: tp.ExportForTeam()}
+	if err := db.Create(team).Error; err != nil {
+		t.Fatalf("create team failed: %v", err)
+	}
+	// the generated id starts from 1, move it to the default team id
+	if err := db.Model(&model.Team{}).Where("id = ?", team.ID).Update("id", model.TEAM_DEFAULT_ID).Error; err != nil {
+		t.Fatalf("update team id failed: %v", err)
+	}
+	for _, domain := range []*model.Domain{
+		{TeamID: model.TEAM_DEFAULT_ID, UserDomain: "acme.com", ResolveStatus: model.DOMAIN_RESOLVE_STATUS_VERIFIED, Category: model.DOMAIN_CATEGORY_TEAM},
+		{TeamID: model.TEAM_DEFAULT_ID, UserDomain: "unverified.com", ResolveStatus: model.DOMAIN_RESOLVE_STATUS_FAILED, Category: model.DOMAIN_CATEGORY_TEAM},
+		{TeamID: model.TEAM_DEFAULT_ID, UserDomain: "gmail.com", ResolveStatus: model.DOMAIN_RESOLVE_STATUS_VERIFIED, Category: model.DOMAIN_CATEGORY_TEAM},
+	} {
+		domain.InitUID()
+		if err := db.Create(domain).Error; err != nil {
+			t.Fatalf("create domain failed: %v", err)
+		}
+	}
+	return &Controller{Storage: model.NewStorage(db, zap.NewNop().Sugar()), Notifier: &recordingNotifier{}, Logger: zap.NewNop().Sugar()}
+}
+
+func TestAutoJoinTeamByEmailDomainSkipsRemovedUsers(t *testing.T) {
+	controller := newTestSelfHostController(t)
+	teamID := model.TEAM_DEFAULT_ID
+	deprovisioned := &model.User{ID: 2, Email: "deprovisioned@acme.com"}
+	deleted := &model.User{ID: 3, Email: "deleted@acme.com"}
+	for _, removedUser := range []struct {
+		user   *model.User
+		reason int
+	}{
+		{deprovisioned, model.REMOVED_TEAM_MEMBER_REASON_DEPROVISIONED},
+		{deleted, model.REMOVED_TEAM_MEMBER_REASON_USER_DELETED},
+	} {
+		teamMember := &model.TeamMember{TeamID: teamID, UserID: removedUser.user.ID}
+		if _, err := controller.Storage.RemovedTeamMemberStorage.Create(model.NewRemovedTeamMember(teamMember, removedUser.user, removedUser.reason)); err != nil {
+			t.Fatalf("create removed team member failed: %v", err)
+		}
+	}
+
+	cases := []struct {
+		name   string
+		user   *model.User
+		joined bool
+	}{
+		{"claimed domain", &model.User{ID: 1, Email: "alice@acme.com"}, true},
+		{"deprovisioned user", deprovisioned, false},
+		{"deleted user signed up again", &model.User{ID: 4, Email: "Deleted@Acme.com"}, false},
+		{"unclaimed domain", &model.User{ID: 5, Email: "bob@example.com"}, false},
+		{"unverified domain", &model.User{ID: 6, Email: "carol@unverified.com"}, false},
+		{"public domain", &model.User{ID: 7, Email: "dave@gmail.com"}, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := controller.AutoJoinTeamByEmailDomain(c.user); err != nil {
+				t.Fatalf("auto join failed: %v", err)
+			}
+			joined, err := controller.Storage.TeamMemberStorage.DoesTeamIncludedTargetUser(teamID, c.user.ID)
+			if err != nil {
+				t.Fatalf("check team member failed: %v", err)
+			}
+			if joined != c.joined {
+				t.Errorf("joined = %v, want %v", joined, c.joined)
+			}
+		})
+	}
+}
+
+func TestDoesAutoJoinDomainProven(t *testing.T) {
+	controller := newTestSelfHostController(t)
+	team, err := controller.Storage.TeamStorage.RetrieveByID(model.TEAM_DEFAULT_ID)
+	if err != nil {
+		t.Fatalf("get team failed: %v", err)
+	}
+	otherTeam := &model.Team{ID: 42}
+	cases := []struct {
+		name   string
+		team   *model.Team
+		domain string
+		proven bool
+	}{
+		{"verified domain", team, "acme.com", true},
+		{"verified by another team", otherTeam, "acme.com", false},
+		{"verification failed", team, "unverified.com", false},
+		{"not verified", team, "example.com", false},
+		{"public domain verified", team, "gmail.com", false},
+		{"public domain", team, "Outlook.com", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if proven := controller.DoesAutoJoinDomainProven(c.team, c.domain); proven != c.proven {
+				t.Errorf("proven = %v, want %v", proven, c.proven)
+			}
+		})
+	}
+}
+
+// the keyword search relies on pg_trgm and fulltext, its statement is tested in the model package.
+func TestSearchTeamMembersWithoutKeyword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	controller := newTestSelfHostController(t)
+	members := make(map[string]*model.User)
+	for _, member := range []struct {
+		nickname string
+		userRole int
+		status   int
+	}{
+		{"alice", model.USER_ROLE_ADMIN, model.TEAM_MEMBER_STATUS_OK},
+		{"bob", model.USER_ROLE_VIEWER, model.TEAM_MEMBER_STATUS_OK},
+		{"carol", model.USER_ROLE_VIEWER, model.TEAM_MEMBER_STATUS_SUSPEND},
+		{"dave", model.USER_ROLE_EDITOR, model.TEAM_MEMBER_STATUS_OK},
+	} {
+		user := &model.User{Nickname: member.nickname, Email: member.nickname + "@acme.com"}
+		user.InitUID()
+		if _, err := controller.Storage.UserStorage.Create(user); err != nil {
+			t.Fatalf("create user failed: %v", err)
+		}
+		createTestTeamMember(t, controller.Storage, model.TEAM_DEFAULT_ID, user.ID, member.userRole, member.status)
+		members[member.nickname] = user
+	}
+
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) { c.Set("userID", members["alice"].ID) })
+	engine.GET("/members", controller.SearchTeamMembers)
+	searchTeamMembers := func(query string) (*httptest.ResponseRecorder, []string) {
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/members"+query, nil))
+		var teamMembers []*model.TeamMemberWithUserInfoForExportConverted
+		if recorder.Code == http.StatusOK {
+			if err := json.Unmarshal(recorder.Body.Bytes(), &teamMembers); err != nil {
+				t.Fatalf("decode response failed: %v", err)
+			}
+		}
+		nicknames := make([]string, 0, len(teamMembers))
+		for _, teamMember := range teamMembers {
+			nicknames = append(nicknames, teamMember.Nickname)
+		}
+		return recorder, nicknames
+	}
+
+	cases := []struct {
+		name      string
+		query     string
+		nicknames string
+	}{
+		{"all", "", "[alice bob carol dave]"},
+		{"role", "?userRole=4", "[bob carol]"},
+		{"status", "?userStatus=3", "[carol]"},
+		{"role and status", "?userRole=4&userStatus=1", "[bob]"},
+		{"blank keyword", "?q=%20&userRole=3", "[dave]"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			recorder, nicknames := searchTeamMembers(c.query)
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", recorder.Code, recorder.Body.String())
+			}
+			if fmt.Sprint(nicknames) != c.nicknames {
+				t.Errorf("team members = %v, want %s", nicknames, c.nicknames)
+			}
+		})
+	}
+
+	// page by page in the id order
+	recorder, nicknames := searchTeamMembers("?limit=3")
+	if fmt.Sprint(nicknames) != "[alice bob carol]" || recorder.Header().Get(HEADER_HAS_MORE) != "true" {
+		t.Fatalf("first page = %v, has more %q", nicknames, recorder.Header().Get(HEADER_HAS_MORE))
+	}
+	recorder, nicknames = searchTeamMembers("?limit=3&cursor=" + recorder.Header().Get(HEADER_NEXT_CURSOR))
+	if fmt.Sprint(nicknames) != "[dave]" || recorder.Header().Get(HEADER_HAS_MORE) != "false" {
+		t.Errorf("last page = %v, has more %q", nicknames, recorder.Header().Get(HEADER_HAS_MORE))
+	}
+
+	for _, query := range []string{"?userRole=0", "?userRole=admin", "?userStatus=9", "?limit=0", "?limit=ten"} {
+		t.Run("invalid "+query, func(t *testing.T) {
+			if recorder, _ := searchTeamMembers(query); recorder.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", recorder.Code, http.StatusBadRequest)
+			}
+		})
+	}
+	outsider := gin.New()
+	outsider.Use(func(c *gin.Context) { c.Set("userID", members["dave"].ID+100) })
+	outsider.GET("/members", controller.SearchTeamMembers)
+	recorder = httptest.NewRecorder()
+	outsider.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/members", nil))
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("outsider status = %d, want %d", recorder.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+)
+
+func TestGetMyTeamsPagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	controller, _ := newTestController(t)
+	const userID = 1
+	teamCount := model.PAGINATION_DEFAULT_LIMIT + 5
+	for i := 0; i < teamCount; i++ {
+		identifier := "team-" + strconv.Itoa(i)
+		teamID, err := controller.Storage.TeamStorage.Create(&model.Team{Name: identifier, Identifier: identifier, Permission: "{}"})
+		if err != nil {
+			t.Fatalf("create team failed: %v", err)
+		}
+		createTestTeamMember(t, controller.Storage, teamID, userID, model.USER_ROLE_VIEWER, model.TEAM_MEMBER_STATUS_OK)
+	}
+
+	// the user id is set by authenticator.JWTAuth() in the router
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) { c.Set("userID", userID) })
+	engine.GET("/teams/my", controller.GetMyTeams)
+	getMyTeams := func(query string) (*httptest.ResponseRecorder, []*model.MyTeamResponse) {
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/teams/my"+query, nil))
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", recorder.Code, recorder.Body.String())
+		}
+		var myTeams []*model.MyTeamResponse
+		if err := json.Unmarshal(recorder.Body.Bytes(), &myTeams); err != nil {
+			t.Fatalf("decode response failed: %v", err)
+		}
+		return recorder, myTeams
+	}
+
+	// without cursor and limit, all teams in one response
+	recorder, myTeams := getMyTeams("")
+	if len(myTeams) != teamCount {
+		t.Errorf("teams = %d, want %d", len(myTeams), teamCount)
+	}
+	if recorder.Header().Get(HEADER_HAS_MORE) != "" {
+		t.Errorf("unpaginated response has page header %q", recorder.Header().Get(HEADER_HAS_MORE))
+	}
+
+	// page by page with limit
+	seen := 0
+	query := "?limit=10"
+	for pages := 1; ; pages++ {
+		recorder, myTeams := getMyTeams(query)
+		seen += len(myTeams)
+		if recorder.Header().Get(HEADER_HAS_MORE) != "true" {
+			if pages != 3 {
+				t.Errorf("pages = %d, want 3", pages)
+			}
+			break
+		}
+		query = "?limit=10&cursor=" + recorder.Header().Get(HEADER_NEXT_CURSOR)
+	}
+	if seen != teamCount {
+		t.Errorf("paginated teams = %d, want %d", seen, teamCount)
+	}
+}
+
+func TestDeleteTeam(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	// the controller has no drive, the team drive folder is removed by the outbox relay after the deletion
+	controller := newTestSelfHostController(t)
+	const ownerID, viewerID = 1, 2
+	createTestTeamMember(t, controller.Storage, model.TEAM_DEFAULT_ID, ownerID, model.USER_ROLE_OWNER, model.TEAM_MEMBER_STATUS_OK)
+	createTestTeamMember(t, controller.Storage, model.TEAM_DEFAULT_ID, viewerID, model.USER_ROLE_VIEWER, model.TEAM_MEMBER_STATUS_OK)
+
+	deleteTeam := func(userID int, body string) *httptest.ResponseRecorder {
+		engine := gin.New()
+		engine.Use(func(c *gin.Context) { c.Set("userID", userID) })
+		engine.DELETE("/teams", controller.DeleteTeam)
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, "/teams", strings.NewReader(body)))
+		return recorder
+	}
+	cases := []struct {
+		name   string
+		userID int
+		body   string
+	}{
+		{"viewer", viewerID, `{"teamIdentifier":"0"}`},
+		{"identifier mismatch", ownerID, `{"teamIdentifier":"my-team"}`},
+		{"missing identifier", ownerID, `{}`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if recorder := deleteTeam(c.userID, c.body); recorder.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d: %s", recorder.Code, http.StatusBadRequest, recorder.Body.String())
+			}
+			if _, err := controller.Storage.TeamStorage.RetrieveByID(model.TEAM_DEFAULT_ID); err != nil {
+				t.Errorf("team deleted: %v", err)
+			}
+		})
+	}
+
+	if recorder := deleteTeam(ownerID, `{"teamIdentifier":"0"}`); recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", recorder.Code, recorder.Body.String())
+	}
+	if _, err := controller.Storage.TeamStorage.RetrieveByID(model.TEAM_DEFAULT_ID); err == nil {
+		t.Error("team is not deleted")
+	}
+	if joined, err := controller.Storage.TeamMemberStorage.DoesTeamIncludedTargetUser(model.TEAM_DEFAULT_ID, ownerID); err != nil || joined {
+		t.Errorf("team member is not deleted: %v", err)
+	}
+	outboxEvents, err := controller.Storage.OutboxEventStorage.RetrieveDue(time.Now().UTC(), 10)
+	if err != nil {
+		t.Fatalf("retrieve outbox events failed: %v", err)
+	}
+	if len(outboxEvents) != 1 || outboxEvents[0].Type != model.EVENT_TYPE_TEAM_DELETED {
+		t.Errorf("outbox events = %+v, want one TeamDeleted event", outboxEvents)
+	}
+}
@@ -0,0 +1,188 @@
+package controller
+
+import (
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/accesscontrol"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+)
+
+func (controller *Controller) GetUnitRoleRelations(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	unitType, errInGetUnitType := controller.GetMagicIntParamFromRequest(c, PARAM_UNIT_TYPE)
+	unitID, errInGetUnitID := controller.GetMagicIntParamFromRequest(c, PARAM_UNIT_ID)
+	if errInGetUserID != nil || errInGetUnitType != nil || errInGetUnitID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// validate user role
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_UNIT_ROLE_RELATIONS)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+	if !attrg.CanAccess(accesscontrol.ACTION_ACCESS_VIEW) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// retrieve
+	unitRoleRelations, err := controller.Storage.UnitRoleRelationStorage.RetrieveByUnit(teamID, unitType, unitID)
+	if err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_UNIT_ROLE_RELATION, "get unit role relations error: "+err.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetAllUnitRoleRelationsResponse(unitRoleRelations))
+	return
+}
+
+// CreateUnitRoleRelation grant or deny a role on the unit, the existing relation of the role on the unit will be replaced.
+func (controller *Controller) CreateUnitRoleRelation(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	unitType, errInGetUnitType := controller.GetMagicIntParamFromRequest(c, PARAM_UNIT_TYPE)
+	unitID, errInGetUnitID := controller.GetMagicIntParamFromRequest(c, PARAM_UNIT_ID)
+	if errInGetUserID != nil || errInGetUnitType != nil || errInGetUnitID != nil {
+		return
+	}
+	if !accesscontrol.IsUnitTypeDefined(unitType) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_PARAM_FAILED, "validate request param error: unknown unit type.")
+		return
+	}
+
+	// get request body
+	req := model.NewCreateUnitRoleRelationRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// get role
+	role, errInRetrieveRole := controller.Storage.RoleStorage.RetrieveByTeamIDAndID(teamID, req.ExportRoleID())
+	if errInRetrieveRole != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_ROLE, "get role error: "+errInRetrieveRole.Error())
+		return
+	}
+
+	// validate user role, operator should be able to manage the target role, and can not allow any attribute which operator does not have
+	unitRoleRelation := model.NewUnitRoleRelation(teamID, unitType, unitID, role.ExportID())
+	unitRoleRelation.UpdateByCreateUnitRoleRelationRequest(req)
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_UNIT_ROLE_RELATIONS)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+	memberAttrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_TEAM_MEMBER)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+	if !attrg.CanManage(accesscontrol.ACTION_MANAGE_UNIT_ROLE_RELATION) || !memberAttrg.CanModifyRoleFromTo(role.ExportID(), role.ExportID()) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+	if !unitRoleRelation.IsDeny() && !accesscontrol.CanGrantRolePermissions(teamMember.ExportUserRole(), unitRoleRelation.ExportRolePermissions()) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// replace existing relation
+	existingUnitRoleRelations, errInRetrieveExisting := controller.Storage.UnitRoleRelationStorage.RetrieveByUnitAndRoleID(teamID, unitType, unitID, role.ExportID())
+	if errInRetrieveExisting != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_UNIT_ROLE_RELATION, "get unit role relation error: "+errInRetrieveExisting.Error())
+		return
+	}
+	errInSave := controller.Storage.Transaction(func(txStorage *model.Storage) error {
+		for _, existingUnitRoleRelation := range existingUnitRoleRelations {
+			if err := txStorage.UnitRoleRelationStorage.DeleteByTeamIDAndID(teamID, existingUnitRoleRelation.ExportID()); err != nil {
+				return err
+			}
+		}
+		_, err := txStorage.UnitRoleRelationStorage.Create(unitRoleRelation)
+		return err
+	})
+	if errInSave != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_CREATE_UNIT_ROLE_RELATION, "create unit role relation error: "+errInSave.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetUnitRoleRelationResponse(unitRoleRelation))
+	return
+}
+
+func (controller *Controller) DeleteUnitRoleRelation(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	unitType, errInGetUnitType := controller.GetMagicIntParamFromRequest(c, PARAM_UNIT_TYPE)
+	unitID, errInGetUnitID := controller.GetMagicIntParamFromRequest(c, PARAM_UNIT_ID)
+	unitRoleRelationID, errInGetUnitRoleRelationID := controller.GetMagicIntParamFromRequest(c, PARAM_UNIT_ROLE_RELATION_ID)
+	if errInGetUserID != nil || errInGetUnitType != nil || errInGetUnitID != nil || errInGetUnitRoleRelationID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// get unit role relation
+	unitRoleRelation, errInRetrieveUnitRoleRelation := controller.Storage.UnitRoleRelationStorage.RetrieveByUnitAndID(teamID, unitType, unitID, unitRoleRelationID)
+	if errInRetrieveUnitRoleRelation != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_UNIT_ROLE_RELATION, "get unit role relation error: "+errInRetrieveUnitRoleRelation.Error())
+		return
+	}
+
+	// validate user role
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_UNIT_ROLE_RELATIONS)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+	memberAttrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_TEAM_MEMBER)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+	if !attrg.CanDelete(accesscontrol.ACTION_DELETE) || !memberAttrg.CanModifyRoleFromTo(unitRoleRelation.RoleID, unitRoleRelation.RoleID) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// delete
+	if err := controller.Storage.UnitRoleRelationStorage.DeleteByTeamIDAndID(teamID, unitRoleRelationID); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_DELETE_UNIT_ROLE_RELATION, "delete unit role relation error: "+err.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, nil)
+	return
+}
@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/accesscontrol"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/idconvertor"
+)
+
+func TestCreateUnitRoleRelation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	controller := newTestSelfHostController(t)
+	if err := accesscontrol.SeedSystemRoles(controller.Storage.RoleStorage); err != nil {
+		t.Fatalf("seed system roles failed: %v", err)
+	}
+	const ownerID = 1
+	createTestTeamMember(t, controller.Storage, model.TEAM_DEFAULT_ID, ownerID, model.USER_ROLE_OWNER, model.TEAM_MEMBER_STATUS_OK)
+	unitID := idconvertor.ConvertIntToString(testAppID)
+	body := `{"roleID":"` + idconvertor.ConvertIntToString(model.USER_ROLE_VIEWER) + `","effect":2}`
+
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) { c.Set("userID", ownerID) })
+	engine.POST("/units/:unitType/:unitID/roleRelations", controller.CreateUnitRoleRelation)
+	createUnitRoleRelation := func(unitType string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/units/"+unitType+"/"+unitID+"/roleRelations", strings.NewReader(body)))
+		return recorder
+	}
+
+	// the unit type is a magic string as the other unit routes, and must be known by the policy
+	for _, unitType := range []string{idconvertor.ConvertIntToString(999), "zzzzzzzzzzzz"} {
+		t.Run("undefined unit type "+unitType, func(t *testing.T) {
+			recorder := createUnitRoleRelation(unitType)
+			if recorder.Code != http.StatusBadRequest || !strings.Contains(recorder.Body.String(), ERROR_FLAG_VALIDATE_REQUEST_PARAM_FAILED) {
+				t.Errorf("status = %d, want %s: %s", recorder.Code, ERROR_FLAG_VALIDATE_REQUEST_PARAM_FAILED, recorder.Body.String())
+			}
+		})
+	}
+	unitRoleRelations, err := controller.Storage.UnitRoleRelationStorage.RetrieveByUnit(model.TEAM_DEFAULT_ID, 999, testAppID)
+	if err != nil || len(unitRoleRelations) != 0 {
+		t.Errorf("relations of undefined unit type = %d, %v, want none", len(unitRoleRelations), err)
+	}
+
+	recorder := createUnitRoleRelation(idconvertor.ConvertIntToString(accesscontrol.UNIT_TYPE_APP))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", recorder.Code, recorder.Body.String())
+	}
+	unitRoleRelations, err = controller.Storage.UnitRoleRelationStorage.RetrieveByUnit(model.TEAM_DEFAULT_ID, accesscontrol.UNIT_TYPE_APP, testAppID)
+	if err != nil {
+		t.Fatalf("retrieve unit role relations failed: %v", err)
+	}
+	if len(unitRoleRelations) != 1 || unitRoleRelations[0].RoleID != model.USER_ROLE_VIEWER || !unitRoleRelations[0].IsDeny() {
+		t.Errorf("relations = %+v, want the viewer denied on the app", unitRoleRelations)
+	}
+}
@@ -76,6 +76,11 @@ func (controller *Controller) SignIn(c *gin.Context) {
 		return
 	}
 
+	// join team by claimed email domain, the user can still sign in when it failed
+	if err := controller.AutoJoinTeamByEmailDomain(user); err != nil {
+		controller.Logger.Errorw("auto join team by email domain failed", "userID", user.ID, "err", err)
+	}
+
 	// generate access token and refresh token
 	accessToken, _ := model.CreateAccessToken(user.ID, user.UID)
 	expiredAtString, errInExtract := authenticator.ExtractExpiresAtFromTokenInString(accessToken)
@@ -231,8 +236,15 @@ func (controller *Controller) UpdateNickname(c *gin.Context) {
 
 	// update user Nickname
 	user.SetNickname(req.Nickname)
-	if err := controller.Storage.UserStorage.UpdateByID(user); err != nil {
-		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_USER, "update user error: "+err.Error())
+	errInUpdateUser := controller.Storage.Transaction(func(txStorage *model.Storage) error {
+		if err := txStorage.UserStorage.UpdateByID(user); err != nil {
+			return err
+		}
+		_, err := txStorage.OutboxEventStorage.Create(model.NewUserUpdatedEvent(user))
+		return err
+	})
+	if errInUpdateUser != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_USER, "update user error: "+errInUpdateUser.Error())
 		return
 	}
 
@@ -269,8 +281,15 @@ func (controller *Controller) UpdateAvatar(c *gin.Context) {
 
 	// update user Nickname
 	user.SetAvatar(req.Avatar)
-	if err := controller.Storage.UserStorage.UpdateByID(user); err != nil {
-		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_USER, "update user error: "+err.Error())
+	errInUpdateUser := controller.Storage.Transaction(func(txStorage *model.Storage) error {
+		if err := txStorage.UserStorage.UpdateByID(user); err != nil {
+			return err
+		}
+		_, err := txStorage.OutboxEventStorage.Create(model.NewUserUpdatedEvent(user))
+		return err
+	})
+	if errInUpdateUser != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_USER, "update user error: "+errInUpdateUser.Error())
 		return
 	}
 
@@ -356,8 +375,15 @@ func (controller *Controller) UpdateLanguage(c *gin.Context) {
 
 	// update user language
 	user.SetLanguage(req.Language)
-	if err := controller.Storage.UserStorage.UpdateByID(user); err != nil {
-		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_USER, "update language error: "+err.Error())
+	errInUpdateUser := controller.Storage.Transaction(func(txStorage *model.Storage) error {
+		if err := txStorage.UserStorage.UpdateByID(user); err != nil {
+			return err
+		}
+		_, err := txStorage.OutboxEventStorage.Create(model.NewUserUpdatedEvent(user))
+		return err
+	})
+	if errInUpdateUser != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_USER, "update language error: "+errInUpdateUser.Error())
 		return
 	}
 
@@ -441,17 +467,37 @@ func (controller *Controller) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	// delete
-	errInDeleteUser := controller.Storage.UserStorage.DeleteByID(userID)
+	user, errInRetrieveUser := controller.Storage.UserStorage.RetrieveByID(userID)
+	if errInRetrieveUser != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_USER, "get user error: "+errInRetrieveUser.Error())
+		return
+	}
+
+	// delete user and it's team members in one transaction, the left teams are recorded by email for the user signed up again
+	errInDeleteUser := controller.Storage.Transaction(func(txStorage *model.Storage) error {
+		teamMembers, errInRetrieveTeamMembers := txStorage.TeamMemberStorage.RetrieveByUserID(userID)
+		if errInRetrieveTeamMembers != nil {
+			return errInRetrieveTeamMembers
+		}
+		if err := txStorage.UserStorage.DeleteByID(userID); err != nil {
+			return err
+		}
+		if err := txStorage.TeamMemberStorage.DeleteByUserID(userID); err != nil {
+			return err
+		}
+		for _, teamMember := range teamMembers {
+			if _, err := txStorage.RemovedTeamMemberStorage.Create(model.NewRemovedTeamMember(teamMember, user, model.REMOVED_TEAM_MEMBER_REASON_USER_DELETED)); err != nil {
+				return err
+			}
+		}
+		if err := txStorage.UnitRoleRelationStorage.DeleteByUserID(userID); err != nil {
+			return err
+		}
+		_, err := txStorage.OutboxEventStorage.Create(model.NewUserDeletedEvent(user))
+		return err
+	})
 	if errInDeleteUser != nil {
-		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_DELETE_USER, "delete user by id error: "+errInDeleteUser.Error())
-		return
-	}
-
-	// delete from team member
-	errInDeleteTeamMember := controller.Storage.TeamMemberStorage.DeleteByUserID(userID)
-	if errInDeleteTeamMember != nil {
-		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_DELETE_TEAM_MEMBER, "delete user from team member by user id error: "+errInDeleteTeamMember.Error())
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_DELETE_USER, "delete user error: "+errInDeleteUser.Error())
 		return
 	}
 
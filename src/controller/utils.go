@@ -5,14 +5,19 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/idconvertor"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/mtls"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/tokenvalidator"
 )
 
 const PARAM_AUTHORIZATION_TOKEN = "Authorization-Token"
 const PARAM_REQUEST_TOKEN = "Request-Token"
+const PARAM_REQUEST_TIMESTAMP = "Request-Timestamp"
+const PARAM_REQUEST_NONCE = "Request-Nonce"
 const PARAM_TEAM_ID = "teamID"
 const PARAM_USER_ID = "userID"
 const PARAM_APP_ID = "appID"
@@ -30,37 +35,89 @@ const PARAM_TARGET_TEAM_MEMBER_ID = "targetTeamMemberID"
 const PARAM_FILE_NAME = "fileName"
 const PARAM_TARGET_USER_IDS = "targetUserIDs"
 const PARAM_REDIRECT_URL = "redirectURL"
+const PARAM_DOMAIN_ID = "domainID"
+const PARAM_HOSTNAME = "hostname"
+const PARAM_SEARCH_KEYWORD = "q"
+const PARAM_USER_STATUS = "userStatus"
+const PARAM_LIMIT = "limit"
+const PARAM_CURSOR = "cursor"
+const PARAM_SORT_KEY = "sortKey"
+const PARAM_SORT_DIRECTION = "direction"
+const PARAM_SCIM_TOKEN_ID = "scimTokenID"
+const PARAM_SCIM_USER_ID = "scimUserID"
+const PARAM_SCIM_GROUP_ID = "scimGroupID"
+const PARAM_SCIM_FILTER = "filter"
+const PARAM_SCIM_START_INDEX = "startIndex"
+const PARAM_SCIM_COUNT = "count"
+const PARAM_SCIM_EXCLUDED_ATTRIBUTES = "excludedAttributes"
+const PARAM_ROLE_ID = "roleID"
+const PARAM_UNIT_ROLE_RELATION_ID = "unitRoleRelationID"
+const PARAM_ACCESS_REQUEST_ID = "accessRequestID"
+const PARAM_ACCESS_REQUEST_STATUS = "status"
+const PARAM_WEBHOOK_ID = "webhookID"
+const PARAM_WEBHOOK_DELIVERY_ID = "webhookDeliveryID"
+
+// pagination headers, for endpoints which feedback array body
+const HEADER_NEXT_CURSOR = "Kozmo-Next-Cursor"
+const HEADER_HAS_MORE = "Kozmo-Has-More"
+
+// conditional request headers, for endpoints which feedback a versioned body
+const HEADER_ETAG = "ETag"
+const HEADER_IF_NONE_MATCH = "If-None-Match"
 
 const DEFAULT_TEAM_ID = 0
 
 const (
 	// validate failed
-	ERROR_FLAG_VALIDATE_ACCOUNT_FAILED                  = "ERROR_FLAG_VALIDATE_ACCOUNT_FAILED"
-	ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED             = "ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED"
-	ERROR_FLAG_VALIDATE_REQUEST_TOKEN_FAILED            = "ERROR_FLAG_VALIDATE_REQUEST_TOKEN_FAILED"
-	ERROR_FLAG_VALIDATE_REQUEST_PARAM_FAILED            = "ERROR_FLAG_VALIDATE_REQUEST_PARAM_FAILED"
-	ERROR_FLAG_VALIDATE_VERIFICATION_CODE_FAILED        = "ERROR_FLAG_VALIDATE_VERIFICATION_CODE_FAILED"
-	ERROR_FLAG_PARSE_REQUEST_BODY_FAILED                = "ERROR_FLAG_PARSE_REQUEST_BODY_FAILED"
-	ERROR_FLAG_PARSE_REQUEST_URI_FAILED                 = "ERROR_FLAG_PARSE_REQUEST_URI_FAILED"
-	ERROR_FLAG_PARSE_INVITE_LINK_HASH_FAILED            = "ERROR_FLAG_PARSE_INVITE_LINK_HASH_FAILED"
-	ERROR_FLAG_CAN_NOT_TRANSFER_OWNER_TO_PENDING_USER   = "ERROR_FLAG_CAN_NOT_TRANSFER_OWNER_TO_PENDING_USER"
-	ERROR_FLAG_CAN_NOT_REMOVE_OWNER_FROM_TEAM           = "ERROR_FLAG_CAN_NOT_REMOVE_OWNER_FROM_TEAM"
-	ERROR_FLAG_SIGN_UP_EMAIL_MISMATCH                   = "ERROR_FLAG_SIGN_UP_EMAIL_MISMATCH"
-	ERROR_FLAG_OWNER_ROLE_MUST_BE_TRANSFERED            = "ERROR_FLAG_OWNER_ROLE_MUST_BE_TRANSFERED"
-	ERROR_FLAG_PASSWORD_INVALIED                        = "ERROR_FLAG_PASSWORD_INVALIED"
-	ERROR_FLAG_TEAM_MUST_TRANSFERED_BEFORE_USER_SUSPEND = "ERROR_FLAG_TEAM_MUST_TRANSFERED_BEFORE_USER_SUSPEND"
-	ERROR_FLAG_INVITE_EMAIL_MISMATCH                    = "ERROR_FLAG_INVITE_EMAIL_MISMATCH"
+	ERROR_FLAG_VALIDATE_ACCOUNT_FAILED                     = "ERROR_FLAG_VALIDATE_ACCOUNT_FAILED"
+	ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED                = "ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED"
+	ERROR_FLAG_VALIDATE_REQUEST_TOKEN_FAILED               = "ERROR_FLAG_VALIDATE_REQUEST_TOKEN_FAILED"
+	ERROR_FLAG_VALIDATE_REQUEST_PARAM_FAILED               = "ERROR_FLAG_VALIDATE_REQUEST_PARAM_FAILED"
+	ERROR_FLAG_VALIDATE_VERIFICATION_CODE_FAILED           = "ERROR_FLAG_VALIDATE_VERIFICATION_CODE_FAILED"
+	ERROR_FLAG_PARSE_REQUEST_BODY_FAILED                   = "ERROR_FLAG_PARSE_REQUEST_BODY_FAILED"
+	ERROR_FLAG_PARSE_REQUEST_URI_FAILED                    = "ERROR_FLAG_PARSE_REQUEST_URI_FAILED"
+	ERROR_FLAG_PARSE_INVITE_LINK_HASH_FAILED               = "ERROR_FLAG_PARSE_INVITE_LINK_HASH_FAILED"
+	ERROR_FLAG_CAN_NOT_TRANSFER_OWNER_TO_PENDING_USER      = "ERROR_FLAG_CAN_NOT_TRANSFER_OWNER_TO_PENDING_USER"
+	ERROR_FLAG_CAN_NOT_REMOVE_OWNER_FROM_TEAM              = "ERROR_FLAG_CAN_NOT_REMOVE_OWNER_FROM_TEAM"
+	ERROR_FLAG_SIGN_UP_EMAIL_MISMATCH                      = "ERROR_FLAG_SIGN_UP_EMAIL_MISMATCH"
+	ERROR_FLAG_OWNER_ROLE_MUST_BE_TRANSFERED               = "ERROR_FLAG_OWNER_ROLE_MUST_BE_TRANSFERED"
+	ERROR_FLAG_PASSWORD_INVALIED                           = "ERROR_FLAG_PASSWORD_INVALIED"
+	ERROR_FLAG_TEAM_MUST_TRANSFERED_BEFORE_USER_SUSPEND    = "ERROR_FLAG_TEAM_MUST_TRANSFERED_BEFORE_USER_SUSPEND"
+	ERROR_FLAG_INVITE_EMAIL_MISMATCH                       = "ERROR_FLAG_INVITE_EMAIL_MISMATCH"
+	ERROR_FLAG_TEAM_IDENTIFIER_MISMATCH                    = "ERROR_FLAG_TEAM_IDENTIFIER_MISMATCH"
+	ERROR_FLAG_CAN_NOT_SUSPEND_OWNER                       = "ERROR_FLAG_CAN_NOT_SUSPEND_OWNER"
+	ERROR_FLAG_CAN_NOT_SUSPEND_YOURSELF                    = "ERROR_FLAG_CAN_NOT_SUSPEND_YOURSELF"
+	ERROR_FLAG_CAN_NOT_GRANT_TIME_BOUND_OWNER              = "ERROR_FLAG_CAN_NOT_GRANT_TIME_BOUND_OWNER"
+	ERROR_FLAG_CAN_NOT_GRANT_ROLE_TO_YOURSELF              = "ERROR_FLAG_CAN_NOT_GRANT_ROLE_TO_YOURSELF"
+	ERROR_FLAG_INVALID_ROLE_VALID_UNTIL                    = "ERROR_FLAG_INVALID_ROLE_VALID_UNTIL"
+	ERROR_FLAG_TEAM_MEMBER_ROLE_IS_NOT_TIME_BOUND          = "ERROR_FLAG_TEAM_MEMBER_ROLE_IS_NOT_TIME_BOUND"
+	ERROR_FLAG_CAN_NOT_SUSPEND_PENDING_USER                = "ERROR_FLAG_CAN_NOT_SUSPEND_PENDING_USER"
+	ERROR_FLAG_AUTO_JOIN_DOMAIN_NOT_CLAIMED                = "ERROR_FLAG_AUTO_JOIN_DOMAIN_NOT_CLAIMED"
+	ERROR_FLAG_AUTO_JOIN_DOMAIN_IS_PUBLIC                  = "ERROR_FLAG_AUTO_JOIN_DOMAIN_IS_PUBLIC"
+	ERROR_FLAG_TEAM_MEMBER_IS_NOT_PENDING                  = "ERROR_FLAG_TEAM_MEMBER_IS_NOT_PENDING"
+	ERROR_FLAG_CAN_NOT_MODIFY_SYSTEM_ROLE                  = "ERROR_FLAG_CAN_NOT_MODIFY_SYSTEM_ROLE"
+	ERROR_FLAG_CAN_NOT_DELETE_ROLE_IN_USE                  = "ERROR_FLAG_CAN_NOT_DELETE_ROLE_IN_USE"
+	ERROR_FLAG_ACCESS_REQUEST_IS_NOT_NEEDED                = "ERROR_FLAG_ACCESS_REQUEST_IS_NOT_NEEDED"
+	ERROR_FLAG_ACCESS_REQUEST_ALREADY_PENDING              = "ERROR_FLAG_ACCESS_REQUEST_ALREADY_PENDING"
+	ERROR_FLAG_ACCESS_REQUEST_IS_NOT_PENDING               = "ERROR_FLAG_ACCESS_REQUEST_IS_NOT_PENDING"
+	ERROR_FLAG_CAN_NOT_REVIEW_YOUR_OWN_ACCESS_REQUEST      = "ERROR_FLAG_CAN_NOT_REVIEW_YOUR_OWN_ACCESS_REQUEST"
+	ERROR_FLAG_ACCESS_REQUEST_DENIED_BY_UNIT_ROLE_RELATION = "ERROR_FLAG_ACCESS_REQUEST_DENIED_BY_UNIT_ROLE_RELATION"
 
 	// can note create
-	ERROR_FLAG_CAN_NOT_CREATE_USER            = "ERROR_FLAG_CAN_NOT_CREATE_USER"
-	ERROR_FLAG_CAN_NOT_CREATE_TEAM            = "ERROR_FLAG_CAN_NOT_CREATE_TEAM"
-	ERROR_FLAG_CAN_NOT_CREATE_TEAM_MEMBER     = "ERROR_FLAG_CAN_NOT_CREATE_TEAM_MEMBER"
-	ERROR_FLAG_CAN_NOT_CREATE_INVITE          = "ERROR_FLAG_CAN_NOT_CREATE_INVITE"
-	ERROR_FLAG_CAN_NOT_CREATE_INVITATION_CODE = "ERROR_FLAG_CAN_NOT_CREATE_INVITATION_CODE"
-	ERROR_FLAG_CAN_NOT_CREATE_DOMAIN          = "ERROR_FLAG_CAN_NOT_CREATE_DOMAIN"
-	ERROR_FLAG_CAN_NOT_CREATE_ACTION          = "ERROR_FLAG_CAN_NOT_CREATE_ACTION"
-	ERROR_FLAG_CAN_NOT_CREATE_RESOURCE        = "ERROR_FLAG_CAN_NOT_CREATE_RESOURCE"
-	ERROR_FLAG_CAN_NOT_CREATE_APP             = "ERROR_FLAG_CAN_NOT_CREATE_APP"
+	ERROR_FLAG_CAN_NOT_CREATE_USER               = "ERROR_FLAG_CAN_NOT_CREATE_USER"
+	ERROR_FLAG_CAN_NOT_CREATE_TEAM               = "ERROR_FLAG_CAN_NOT_CREATE_TEAM"
+	ERROR_FLAG_CAN_NOT_CREATE_TEAM_MEMBER        = "ERROR_FLAG_CAN_NOT_CREATE_TEAM_MEMBER"
+	ERROR_FLAG_CAN_NOT_CREATE_INVITE             = "ERROR_FLAG_CAN_NOT_CREATE_INVITE"
+	ERROR_FLAG_CAN_NOT_CREATE_INVITATION_CODE    = "ERROR_FLAG_CAN_NOT_CREATE_INVITATION_CODE"
+	ERROR_FLAG_CAN_NOT_CREATE_DOMAIN             = "ERROR_FLAG_CAN_NOT_CREATE_DOMAIN"
+	ERROR_FLAG_CAN_NOT_CREATE_ROLE               = "ERROR_FLAG_CAN_NOT_CREATE_ROLE"
+	ERROR_FLAG_CAN_NOT_CREATE_UNIT_ROLE_RELATION = "ERROR_FLAG_CAN_NOT_CREATE_UNIT_ROLE_RELATION"
+	ERROR_FLAG_CAN_NOT_CREATE_SCIM_TOKEN         = "ERROR_FLAG_CAN_NOT_CREATE_SCIM_TOKEN"
+	ERROR_FLAG_CAN_NOT_CREATE_ACTION             = "ERROR_FLAG_CAN_NOT_CREATE_ACTION"
+	ERROR_FLAG_CAN_NOT_CREATE_RESOURCE           = "ERROR_FLAG_CAN_NOT_CREATE_RESOURCE"
+	ERROR_FLAG_CAN_NOT_CREATE_APP                = "ERROR_FLAG_CAN_NOT_CREATE_APP"
+	ERROR_FLAG_CAN_NOT_CREATE_ACCESS_REQUEST     = "ERROR_FLAG_CAN_NOT_CREATE_ACCESS_REQUEST"
+	ERROR_FLAG_CAN_NOT_CREATE_WEBHOOK            = "ERROR_FLAG_CAN_NOT_CREATE_WEBHOOK"
 
 	// can not get resource
 	ERROR_FLAG_CAN_NOT_GET_USER                = "ERROR_FLAG_CAN_NOT_GET_USER"
@@ -69,11 +126,16 @@ const (
 	ERROR_FLAG_CAN_NOT_GET_INVITE              = "ERROR_FLAG_CAN_NOT_GET_INVITE"
 	ERROR_FLAG_CAN_NOT_GET_INVITATION_CODE     = "ERROR_FLAG_CAN_NOT_GET_INVITATION_CODE"
 	ERROR_FLAG_CAN_NOT_GET_DOMAIN              = "ERROR_FLAG_CAN_NOT_GET_DOMAIN"
+	ERROR_FLAG_CAN_NOT_GET_ROLE                = "ERROR_FLAG_CAN_NOT_GET_ROLE"
+	ERROR_FLAG_CAN_NOT_GET_UNIT_ROLE_RELATION  = "ERROR_FLAG_CAN_NOT_GET_UNIT_ROLE_RELATION"
+	ERROR_FLAG_CAN_NOT_GET_SCIM_TOKEN          = "ERROR_FLAG_CAN_NOT_GET_SCIM_TOKEN"
 	ERROR_FLAG_CAN_NOT_GET_ACTION              = "ERROR_FLAG_CAN_NOT_GET_ACTION"
 	ERROR_FLAG_CAN_NOT_GET_RESOURCE            = "ERROR_FLAG_CAN_NOT_GET_RESOURCE"
 	ERROR_FLAG_CAN_NOT_GET_RESOURCE_META_INFO  = "ERROR_FLAG_CAN_NOT_GET_RESOURCE_META_INFO"
 	ERROR_FLAG_CAN_NOT_GET_APP                 = "ERROR_FLAG_CAN_NOT_GET_APP"
 	ERROR_FLAG_CAN_NOT_GET_BUILDER_DESCRIPTION = "ERROR_FLAG_CAN_NOT_GET_BUILDER_DESCRIPTION"
+	ERROR_FLAG_CAN_NOT_GET_ACCESS_REQUEST      = "ERROR_FLAG_CAN_NOT_GET_ACCESS_REQUEST"
+	ERROR_FLAG_CAN_NOT_GET_WEBHOOK             = "ERROR_FLAG_CAN_NOT_GET_WEBHOOK"
 
 	// can not update resource
 	ERROR_FLAG_CAN_NOT_UPDATE_USER            = "ERROR_FLAG_CAN_NOT_UPDATE_USER"
@@ -82,26 +144,34 @@ const (
 	ERROR_FLAG_CAN_NOT_UPDATE_INVITE          = "ERROR_FLAG_CAN_NOT_UPDATE_INVITE"
 	ERROR_FLAG_CAN_NOT_UPDATE_INVITATION_CODE = "ERROR_FLAG_CAN_NOT_UPDATE_INVITATION_CODE"
 	ERROR_FLAG_CAN_NOT_UPDATE_DOMAIN          = "ERROR_FLAG_CAN_NOT_UPDATE_DOMAIN"
+	ERROR_FLAG_CAN_NOT_UPDATE_ROLE            = "ERROR_FLAG_CAN_NOT_UPDATE_ROLE"
 	ERROR_FLAG_CAN_NOT_UPDATE_ACTION          = "ERROR_FLAG_CAN_NOT_UPDATE_ACTION"
 	ERROR_FLAG_CAN_NOT_UPDATE_RESOURCE        = "ERROR_FLAG_CAN_NOT_UPDATE_RESOURCE"
 	ERROR_FLAG_CAN_NOT_UPDATE_APP             = "ERROR_FLAG_CAN_NOT_UPDATE_APP"
+	ERROR_FLAG_CAN_NOT_UPDATE_ACCESS_REQUEST  = "ERROR_FLAG_CAN_NOT_UPDATE_ACCESS_REQUEST"
+	ERROR_FLAG_CAN_NOT_UPDATE_WEBHOOK         = "ERROR_FLAG_CAN_NOT_UPDATE_WEBHOOK"
 
 	// can not delete
-	ERROR_FLAG_CAN_NOT_DELETE_USER            = "ERROR_FLAG_CAN_NOT_DELETE_USER"
-	ERROR_FLAG_CAN_NOT_DELETE_TEAM            = "ERROR_FLAG_CAN_NOT_DELETE_TEAM"
-	ERROR_FLAG_CAN_NOT_DELETE_TEAM_MEMBER     = "ERROR_FLAG_CAN_NOT_DELETE_TEAM_MEMBER"
-	ERROR_FLAG_CAN_NOT_DELETE_INVITE          = "ERROR_FLAG_CAN_NOT_DELETE_INVITE"
-	ERROR_FLAG_CAN_NOT_DELETE_INVITATION_CODE = "ERROR_FLAG_CAN_NOT_DELETE_INVITATION_CODE"
-	ERROR_FLAG_CAN_NOT_DELETE_DOMAIN          = "ERROR_FLAG_CAN_NOT_DELETE_DOMAIN"
-	ERROR_FLAG_CAN_NOT_DELETE_ACTION          = "ERROR_FLAG_CAN_NOT_DELETE_ACTION"
-	ERROR_FLAG_CAN_NOT_DELETE_RESOURCE        = "ERROR_FLAG_CAN_NOT_DELETE_RESOURCE"
-	ERROR_FLAG_CAN_NOT_DELETE_APP             = "ERROR_FLAG_CAN_NOT_DELETE_APP"
+	ERROR_FLAG_CAN_NOT_DELETE_USER               = "ERROR_FLAG_CAN_NOT_DELETE_USER"
+	ERROR_FLAG_CAN_NOT_DELETE_TEAM               = "ERROR_FLAG_CAN_NOT_DELETE_TEAM"
+	ERROR_FLAG_CAN_NOT_DELETE_TEAM_MEMBER        = "ERROR_FLAG_CAN_NOT_DELETE_TEAM_MEMBER"
+	ERROR_FLAG_CAN_NOT_DELETE_INVITE             = "ERROR_FLAG_CAN_NOT_DELETE_INVITE"
+	ERROR_FLAG_CAN_NOT_DELETE_INVITATION_CODE    = "ERROR_FLAG_CAN_NOT_DELETE_INVITATION_CODE"
+	ERROR_FLAG_CAN_NOT_DELETE_DOMAIN             = "ERROR_FLAG_CAN_NOT_DELETE_DOMAIN"
+	ERROR_FLAG_CAN_NOT_DELETE_ROLE               = "ERROR_FLAG_CAN_NOT_DELETE_ROLE"
+	ERROR_FLAG_CAN_NOT_DELETE_UNIT_ROLE_RELATION = "ERROR_FLAG_CAN_NOT_DELETE_UNIT_ROLE_RELATION"
+	ERROR_FLAG_CAN_NOT_DELETE_SCIM_TOKEN         = "ERROR_FLAG_CAN_NOT_DELETE_SCIM_TOKEN"
+	ERROR_FLAG_CAN_NOT_DELETE_ACTION             = "ERROR_FLAG_CAN_NOT_DELETE_ACTION"
+	ERROR_FLAG_CAN_NOT_DELETE_RESOURCE           = "ERROR_FLAG_CAN_NOT_DELETE_RESOURCE"
+	ERROR_FLAG_CAN_NOT_DELETE_APP                = "ERROR_FLAG_CAN_NOT_DELETE_APP"
+	ERROR_FLAG_CAN_NOT_DELETE_WEBHOOK            = "ERROR_FLAG_CAN_NOT_DELETE_WEBHOOK"
 
 	// can not other operation
 	ERROR_FLAG_CAN_NOT_CHECK_TEAM_MEMBER        = "ERROR_FLAG_CAN_NOT_CHECK_TEAM_MEMBER"
 	ERROR_FLAG_CAN_NOT_DUPLICATE_APP            = "ERROR_FLAG_CAN_NOT_DUPLICATE_APP"
 	ERROR_FLAG_CAN_NOT_RELEASE_APP              = "ERROR_FLAG_CAN_NOT_RELEASE_APP"
 	ERROR_FLAG_CAN_NOT_TEST_RESOURCE_CONNECTION = "ERROR_FLAG_CAN_NOT_TEST_RESOURCE_CONNECTION"
+	ERROR_FLAG_CAN_NOT_DELIVER_WEBHOOK          = "ERROR_FLAG_CAN_NOT_DELIVER_WEBHOOK"
 
 	// permission failed
 	ERROR_FLAG_ACCESS_DENIED                  = "ERROR_FLAG_ACCESS_DENIED"
@@ -112,6 +182,8 @@ const (
 	ERROR_FLAG_INVITATION_LINK_UNAVALIABLE    = "ERROR_FLAG_INVITATION_LINK_UNAVALIABLE"
 	ERROR_FLAG_TEAM_IDENTIFIER_HAS_BEEN_TAKEN = "ERROR_FLAG_TEAM_IDENTIFIER_HAS_BEEN_TAKEN"
 	ERROR_FLAG_USER_ALREADY_JOINED_TEAM       = "ERROR_FLAG_USER_ALREADY_JOINED_TEAM"
+	ERROR_FLAG_DOMAIN_HAS_BEEN_TAKEN          = "ERROR_FLAG_DOMAIN_HAS_BEEN_TAKEN"
+	ERROR_FLAG_ROLE_NAME_HAS_BEEN_TAKEN       = "ERROR_FLAG_ROLE_NAME_HAS_BEEN_TAKEN"
 	ERROR_FLAG_SIGN_IN_FAILED                 = "ERROR_FLAG_SIGN_IN_FAILED"
 	ERROR_FLAG_NO_SUCH_USER                   = "ERROR_FLAG_NO_SUCH_USER"
 	ERROR_FLAG_REGISTER_BLOCKED               = "ERROR_FLAG_REGISTER_BLOCKED"
@@ -123,6 +195,7 @@ const (
 	ERROR_FLAG_CREATE_UPLOAD_URL_FAILED      = "ERROR_FLAG_CREATE_UPLOAD_URL_FAILED"
 	ERROR_FLAG_EXECUTE_ACTION_FAILED         = "ERROR_FLAG_EXECUTE_ACTION_FAILED"
 	ERROR_FLAG_GENERATE_SQL_FAILED           = "ERROR_FLAG_GENERATE_SQL_FAILED"
+	ERROR_FLAG_PUBLISH_EVENT_FAILED          = "ERROR_FLAG_PUBLISH_EVENT_FAILED"
 
 	// internal failed
 	ERROR_FLAG_BUILD_TEAM_MEMBER_LIST_FAILED      = "ERROR_FLAG_BUILD_TEAM_MEMBER_LIST_FAILED"
@@ -142,36 +215,36 @@ func (controller *Controller) ValidateRequestTokenFromHeader(c *gin.Context, inp
 		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_TOKEN_FAILED, "HTTP request header missing request token.")
 		return false, errors.New("missing request token.")
 	}
-	var token string
-	token = rawToken[0]
-	// validate
-	tokenShouldBe := controller.RequestTokenValidator.GenerateValidateTokenBySliceParam(input)
-	if token != tokenShouldBe {
-		log.Println("Illegal internal request token detected: \"" + token + "\", the token should be: \"" + tokenShouldBe + "\"")
-		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_TOKEN_FAILED, "request token mismatch.")
-		return false, errors.New("request token mismatch.")
+	// validate, the request without timestamp is signed by legacy scheme
+	signedRequest := &tokenvalidator.SignedRequest{
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.Path,
+		Params:    input,
+		Timestamp: c.GetHeader(PARAM_REQUEST_TIMESTAMP),
+		Nonce:     c.GetHeader(PARAM_REQUEST_NONCE),
+		Token:     rawToken[0],
+	}
+	if err := controller.RequestTokenValidator.ValidateSignedRequest(signedRequest); err != nil {
+		log.Println("Illegal internal request token detected: \"" + rawToken[0] + "\", calling service: \"" + controller.GetCallingServiceFromRequest(c) + "\", " + err.Error())
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_TOKEN_FAILED, err.Error())
+		return false, err
 	}
 	return true, nil
 }
 
-func (controller *Controller) ValidateRequestTokenFromHeaderByStringMap(c *gin.Context, input []string) (bool, error) {
-	// fetch token
-	rawToken := c.Request.Header[PARAM_REQUEST_TOKEN]
-	if len(rawToken) != 1 {
-		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_TOKEN_FAILED, "HTTP request header missing request token.")
-		return false, errors.New("missing request token.")
-	}
-	var token string
-	token = rawToken[0]
-	// validate
-	tokenShouldBe := controller.RequestTokenValidator.GenerateValidateTokenBySliceParam(input)
-	if token != tokenShouldBe {
-		log.Println("Illegal internal request token detected: \"" + token + "\", the token should be: \"" + tokenShouldBe + "\"")
-		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_TOKEN_FAILED, "request token mismatch.")
-		return false, errors.New("request token mismatch.")
+// GetCallingServiceFromRequest feedback the calling service resolved from client certificate, it is empty when the internal port is not serving mTLS.
+func (controller *Controller) GetCallingServiceFromRequest(c *gin.Context) string {
+	identity := mtls.ClientIdentityFromContext(c.Request.Context())
+	if identity == nil {
+		return ""
 	}
-	return true, nil
+	return identity.Service
 }
+
+func (controller *Controller) ValidateRequestTokenFromHeaderByStringMap(c *gin.Context, input []string) (bool, error) {
+	return controller.ValidateRequestTokenFromHeader(c, input...)
+}
+
 func (controller *Controller) GetMagicIntParamFromRequest(c *gin.Context, paramName string) (int, error) {
 	// get request param
 	paramValue := c.Param(paramName)
@@ -272,6 +345,69 @@ func (controller *Controller) GetStringParamFromHeader(c *gin.Context, paramName
 	return ret, nil
 }
 
+// GetPaginationFromRequest build pagination by cursor, limit, sortKey and direction params in uri, missing params fallback to default.
+func (controller *Controller) GetPaginationFromRequest(c *gin.Context, defaultSortKey string, defaultDirection string) (*model.Pagination, error) {
+	return controller.getPaginationFromRequest(c, defaultSortKey, defaultDirection, model.NewPaginationByRequest)
+}
+
+// GetOptionalPaginationFromRequest is GetPaginationFromRequest for the lists which were not paginated, the whole list is in one page when both of cursor and limit are absent.
+func (controller *Controller) GetOptionalPaginationFromRequest(c *gin.Context, defaultSortKey string, defaultDirection string) (*model.Pagination, error) {
+	return controller.getPaginationFromRequest(c, defaultSortKey, defaultDirection, model.NewOptionalPaginationByRequest)
+}
+
+func (controller *Controller) getPaginationFromRequest(c *gin.Context, defaultSortKey string, defaultDirection string, newPagination func(cursorRaw string, limitRaw string, sortKeyRaw string, directionRaw string, defaultSortKey string, defaultDirection string) (*model.Pagination, error)) (*model.Pagination, error) {
+	cursor, _ := controller.TestFirstStringParamValueFromURI(c, PARAM_CURSOR)
+	limit, _ := controller.TestFirstStringParamValueFromURI(c, PARAM_LIMIT)
+	sortKey, _ := controller.TestFirstStringParamValueFromURI(c, PARAM_SORT_KEY)
+	direction, _ := controller.TestFirstStringParamValueFromURI(c, PARAM_SORT_DIRECTION)
+	pagination, err := newPagination(cursor, limit, sortKey, direction, defaultSortKey, defaultDirection)
+	if err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_PARAM_FAILED, "validate pagination param error: "+err.Error())
+		return nil, err
+	}
+	return pagination, nil
+}
+
+// SetPageInfoHeader skip the headers when page info is nil, the list is not paginated.
+func (controller *Controller) SetPageInfoHeader(c *gin.Context, pageInfo *model.PageInfo) {
+	if pageInfo == nil {
+		return
+	}
+	c.Header(HEADER_NEXT_CURSOR, pageInfo.NextCursor)
+	c.Header(HEADER_HAS_MORE, strconv.FormatBool(pageInfo.HasMore))
+}
+
+// IsETagMatched check the If-None-Match header against etag by the weak comparison of RFC 9110,
+// the header can be "*" or a comma separated list of entity tags, and it can be repeated.
+func (controller *Controller) IsETagMatched(c *gin.Context, etag string) bool {
+	opaqueTag := strings.TrimPrefix(etag, "W/")
+	for _, ifNoneMatch := range c.Request.Header.Values(HEADER_IF_NONE_MATCH) {
+		for rest := ifNoneMatch; ; {
+			rest = strings.TrimLeft(rest, " \t,")
+			if rest == "" {
+				break
+			}
+			if rest[0] == '*' {
+				return true
+			}
+			// the opaque tag is quoted and can contain comma, a malformed tag ends the list
+			rest = strings.TrimPrefix(rest, "W/")
+			if !strings.HasPrefix(rest, "\"") {
+				break
+			}
+			end := strings.IndexByte(rest[1:], '"') + 1
+			if end == 0 {
+				break
+			}
+			if rest[:end+1] == opaqueTag {
+				return true
+			}
+			rest = rest[end+1:]
+		}
+	}
+	return false
+}
+
 // @note: this param was setted by authenticator.JWTAuth() method
 func (controller *Controller) GetUserIDFromAuth(c *gin.Context) (int, error) {
 	// get request param
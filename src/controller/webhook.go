@@ -0,0 +1,396 @@
+package controller
+
+import (
+	"encoding/json"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/accesscontrol"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+)
+
+// the latest deliveries of a webhook are listed for troubleshooting.
+const WEBHOOK_DELIVERY_LIST_LIMIT = 100
+
+func (controller *Controller) GetAllWebhooks(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// validate user role
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_TEAM)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+	if !attrg.CanManage(accesscontrol.ACTION_MANAGE_TEAM_WEBHOOK) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// retrieve
+	webhooks, err := controller.Storage.WebhookStorage.RetrieveByTeamID(teamID)
+	if err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_WEBHOOK, "get webhooks error: "+err.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetAllWebhooksResponse(webhooks))
+	return
+}
+
+func (controller *Controller) CreateWebhook(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	if errInGetUserID != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewCreateWebhookRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+	if eventType, hit := req.ExportUnsubscribableEventType(); hit {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: event type "+eventType+" can not be subscribed.")
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// validate user role
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_TEAM)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+	if !attrg.CanManage(accesscontrol.ACTION_MANAGE_TEAM_WEBHOOK) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// create, the secret only feedback once
+	webhook := model.NewWebhookByCreateWebhookRequest(teamID, userID, req)
+	if _, err := controller.Storage.WebhookStorage.Create(webhook); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_CREATE_WEBHOOK, "create webhook error: "+err.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetWebhookWithSecretResponse(webhook))
+	return
+}
+
+func (controller *Controller) UpdateWebhook(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	webhookID, errInGetWebhookID := controller.GetMagicIntParamFromRequest(c, PARAM_WEBHOOK_ID)
+	if errInGetUserID != nil || errInGetWebhookID != nil {
+		return
+	}
+
+	// get request body
+	req := model.NewUpdateWebhookRequest()
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_PARSE_REQUEST_BODY_FAILED, "parse request body error: "+err.Error())
+		return
+	}
+
+	// validate payload required fields
+	validate := validator.New()
+	if err := validate.Struct(req); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: "+err.Error())
+		return
+	}
+	if eventType, hit := req.ExportUnsubscribableEventType(); hit {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_VALIDATE_REQUEST_BODY_FAILED, "validate request body error: event type "+eventType+" can not be subscribed.")
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// validate user role
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_TEAM)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+	if !attrg.CanManage(accesscontrol.ACTION_MANAGE_TEAM_WEBHOOK) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// retrieve webhook
+	webhook, errInRetrieveWebhook := controller.Storage.WebhookStorage.RetrieveByTeamIDAndID(teamID, webhookID)
+	if errInRetrieveWebhook != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_WEBHOOK, "get webhook error: "+errInRetrieveWebhook.Error())
+		return
+	}
+
+	// update
+	webhook.UpdateByUpdateWebhookRequest(req)
+	if err := controller.Storage.WebhookStorage.UpdateByID(webhook); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_WEBHOOK, "update webhook error: "+err.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetWebhookResponse(webhook))
+	return
+}
+
+func (controller *Controller) RotateWebhookSecret(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	webhookID, errInGetWebhookID := controller.GetMagicIntParamFromRequest(c, PARAM_WEBHOOK_ID)
+	if errInGetUserID != nil || errInGetWebhookID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// validate user role
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_TEAM)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+	if !attrg.CanManage(accesscontrol.ACTION_MANAGE_TEAM_WEBHOOK) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// retrieve webhook
+	webhook, errInRetrieveWebhook := controller.Storage.WebhookStorage.RetrieveByTeamIDAndID(teamID, webhookID)
+	if errInRetrieveWebhook != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_WEBHOOK, "get webhook error: "+errInRetrieveWebhook.Error())
+		return
+	}
+
+	// rotate, the new secret only feedback once
+	webhook.RotateSecret()
+	if err := controller.Storage.WebhookStorage.UpdateByID(webhook); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_UPDATE_WEBHOOK, "update webhook error: "+err.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetWebhookWithSecretResponse(webhook))
+	return
+}
+
+func (controller *Controller) DeleteWebhook(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	webhookID, errInGetWebhookID := controller.GetMagicIntParamFromRequest(c, PARAM_WEBHOOK_ID)
+	if errInGetUserID != nil || errInGetWebhookID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// validate user role
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_TEAM)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+	if !attrg.CanManage(accesscontrol.ACTION_MANAGE_TEAM_WEBHOOK) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// delete webhook and its deliveries
+	errInDelete := controller.Storage.Transaction(func(txStorage *model.Storage) error {
+		if err := txStorage.WebhookDeliveryStorage.DeleteByTeamIDAndWebhookID(teamID, webhookID); err != nil {
+			return err
+		}
+		return txStorage.WebhookStorage.DeleteByTeamIDAndID(teamID, webhookID)
+	})
+	if errInDelete != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_DELETE_WEBHOOK, "delete webhook error: "+errInDelete.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, nil)
+	return
+}
+
+// PingWebhook post a test ping to the webhook at once and feedback the delivery, it works for disabled webhook too.
+func (controller *Controller) PingWebhook(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	webhookID, errInGetWebhookID := controller.GetMagicIntParamFromRequest(c, PARAM_WEBHOOK_ID)
+	if errInGetUserID != nil || errInGetWebhookID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// validate user role
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_TEAM)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+	if !attrg.CanManage(accesscontrol.ACTION_MANAGE_TEAM_WEBHOOK) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// retrieve webhook
+	webhook, errInRetrieveWebhook := controller.Storage.WebhookStorage.RetrieveByTeamIDAndID(teamID, webhookID)
+	if errInRetrieveWebhook != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_WEBHOOK, "get webhook error: "+errInRetrieveWebhook.Error())
+		return
+	}
+
+	// ping
+	delivery, errInPing := controller.WebhookDeliverer.Ping(webhook)
+	if errInPing != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_DELIVER_WEBHOOK, "deliver webhook error: "+errInPing.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetWebhookDeliveryResponse(delivery))
+	return
+}
+
+func (controller *Controller) GetAllWebhookDeliveries(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	webhookID, errInGetWebhookID := controller.GetMagicIntParamFromRequest(c, PARAM_WEBHOOK_ID)
+	if errInGetUserID != nil || errInGetWebhookID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// validate user role
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_TEAM)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+	if !attrg.CanManage(accesscontrol.ACTION_MANAGE_TEAM_WEBHOOK) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// retrieve
+	deliveries, err := controller.Storage.WebhookDeliveryStorage.RetrieveByTeamIDAndWebhookID(teamID, webhookID, WEBHOOK_DELIVERY_LIST_LIMIT)
+	if err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_WEBHOOK, "get webhook deliveries error: "+err.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetAllWebhookDeliveriesResponse(deliveries))
+	return
+}
+
+// RedeliverWebhookDelivery post the body of a past delivery again as a new delivery and feedback it,
+// a failed redelivery is retried like other deliveries.
+func (controller *Controller) RedeliverWebhookDelivery(c *gin.Context) {
+	// get team id & user id
+	teamID := model.TEAM_DEFAULT_ID
+	userID, errInGetUserID := controller.GetUserIDFromAuth(c)
+	webhookID, errInGetWebhookID := controller.GetMagicIntParamFromRequest(c, PARAM_WEBHOOK_ID)
+	webhookDeliveryID, errInGetWebhookDeliveryID := controller.GetMagicIntParamFromRequest(c, PARAM_WEBHOOK_DELIVERY_ID)
+	if errInGetUserID != nil || errInGetWebhookID != nil || errInGetWebhookDeliveryID != nil {
+		return
+	}
+
+	// validate user
+	teamMember, errInRetrieveTeamMember := controller.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserID(teamID, userID)
+	if errInRetrieveTeamMember != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER, "please make sure that your can access this team. retrieve team member error: "+errInRetrieveTeamMember.Error())
+		return
+	}
+
+	// validate user role
+	attrg, errInApplyTeamPermission := controller.NewTeamMemberAttributeGroup(c, teamMember, teamID, accesscontrol.UNIT_TYPE_TEAM)
+	if errInApplyTeamPermission != nil {
+		return
+	}
+	if !attrg.CanManage(accesscontrol.ACTION_MANAGE_TEAM_WEBHOOK) {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_ACCESS_DENIED, "you can not access this attribute due to access control policy.")
+		return
+	}
+
+	// retrieve webhook
+	webhook, errInRetrieveWebhook := controller.Storage.WebhookStorage.RetrieveByTeamIDAndID(teamID, webhookID)
+	if errInRetrieveWebhook != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_WEBHOOK, "get webhook error: "+errInRetrieveWebhook.Error())
+		return
+	}
+
+	// retrieve delivery
+	delivery, errInRetrieveDelivery := controller.Storage.WebhookDeliveryStorage.RetrieveByTeamIDAndWebhookIDAndID(teamID, webhookID, webhookDeliveryID)
+	if errInRetrieveDelivery != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_GET_WEBHOOK, "get webhook delivery error: "+errInRetrieveDelivery.Error())
+		return
+	}
+
+	// redeliver
+	redelivery := model.NewWebhookRedelivery(delivery)
+	if err := controller.WebhookDeliverer.DeliverNow(webhook, redelivery); err != nil {
+		controller.FeedbackBadRequest(c, ERROR_FLAG_CAN_NOT_DELIVER_WEBHOOK, "deliver webhook error: "+err.Error())
+		return
+	}
+
+	// feedback
+	controller.FeedbackOK(c, model.NewGetWebhookDeliveryResponse(redelivery))
+	return
+}
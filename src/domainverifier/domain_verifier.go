@@ -0,0 +1,108 @@
+package domainverifier
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/config"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/dnsresolver"
+	"go.uber.org/zap"
+)
+
+const DEFAULT_LOOKUP_TIMEOUT = 10 * time.Second
+const DEFAULT_REVERIFY_BATCH_SIZE = 100
+
+type DomainVerifier struct {
+	logger           *zap.SugaredLogger
+	Storage          *model.Storage
+	Resolver         dnsresolver.Resolver
+	SystemDomain     string
+	ReverifyInterval time.Duration
+}
+
+func NewDomainVerifier(storage *model.Storage, resolver dnsresolver.Resolver, systemDomain string, reverifyInterval time.Duration, logger *zap.SugaredLogger) *DomainVerifier {
+	return &DomainVerifier{
+		logger:           logger,
+		Storage:          storage,
+		Resolver:         resolver,
+		SystemDomain:     systemDomain,
+		ReverifyInterval: reverifyInterval,
+	}
+}
+
+func NewDomainVerifierByGlobalConfig(config *config.Config, storage *model.Storage, resolver dnsresolver.Resolver, logger *zap.SugaredLogger) *DomainVerifier {
+	return NewDomainVerifier(storage, resolver, config.GetSystemDomain(), config.GetDomainReverifyInterval(), logger)
+}
+
+// CheckOwnership returns true when the TXT challenge or the CNAME record of the domain is present.
+func (v *DomainVerifier) CheckOwnership(domain *model.Domain) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), DEFAULT_LOOKUP_TIMEOUT)
+	defer cancel()
+
+	// TXT challenge
+	txtRecords, errInLookupTXT := v.Resolver.LookupTXT(ctx, domain.ExportTXTRecordName())
+	if errInLookupTXT == nil {
+		for _, txtRecord := range txtRecords {
+			if strings.TrimSpace(txtRecord) == domain.ExportTXTRecordValue() {
+				return true
+			}
+		}
+	}
+
+	// CNAME to system domain
+	cnameTarget := domain.ExportCNAMERecordTarget(v.SystemDomain)
+	if cnameTarget == "" {
+		return false
+	}
+	cname, errInLookupCNAME := v.Resolver.LookupCNAME(ctx, domain.UserDomain)
+	if errInLookupCNAME != nil {
+		return false
+	}
+	return dnsresolver.NormalizeHost(cname) == cnameTarget
+}
+
+// VerifyDomain checks ownership of the domain and persists the resolve status.
+// the first verified claim holds the user domain, other claims of it can not be verified until it is released.
+func (v *DomainVerifier) VerifyDomain(domain *model.Domain) error {
+	verified := v.CheckOwnership(domain) && !v.Storage.DomainStorage.IsUserDomainVerifiedByOthers(domain.UserDomain, domain.ID)
+	domain.UpdateResolveStatus(verified)
+	return v.Storage.DomainStorage.UpdateByID(domain)
+}
+
+// ReverifyDomains re-check all domains which not checked in the last reverify interval.
+func (v *DomainVerifier) ReverifyDomains() {
+	before := time.Now().UTC().Add(-v.ReverifyInterval)
+	for {
+		domains, err := v.Storage.DomainStorage.RetrieveLastCheckedBefore(before, DEFAULT_REVERIFY_BATCH_SIZE)
+		if err != nil {
+			v.logger.Errorw("retrieve domains for reverify failed", "err", err)
+			return
+		}
+		for _, domain := range domains {
+			if err := v.VerifyDomain(domain); err != nil {
+				v.logger.Errorw("reverify domain failed", "domain", domain.UserDomain, "err", err)
+				return
+			}
+		}
+		if len(domains) < DEFAULT_REVERIFY_BATCH_SIZE {
+			return
+		}
+	}
+}
+
+// Run reverify domains periodically, it blocks until ctx is done.
+func (v *DomainVerifier) Run(ctx context.Context) {
+	ticker := time.NewTicker(v.ReverifyInterval)
+	defer ticker.Stop()
+	v.ReverifyDomains()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			v.ReverifyDomains()
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package domainverifier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/internal/testdb"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/dnsresolver"
+	"go.uber.org/zap"
+)
+
+const testSystemDomain = "kozmo.example"
+
+func newTestVerifier(t *testing.T) (*DomainVerifier, *dnsresolver.FakeResolver) {
+	resolver := dnsresolver.NewFakeResolver()
+	verifier := NewDomainVerifier(testdb.NewStorage(t), resolver, testSystemDomain, time.Millisecond, zap.NewNop().Sugar())
+	return verifier, resolver
+}
+
+func createTestDomain(t *testing.T, storage *model.Storage, teamID int, userDomain string) *model.Domain {
+	t.Helper()
+	domain := model.NewDomainByCreateDomainRequest(teamID, &model.CreateDomainRequest{
+		UserDomain:         userDomain,
+		SystemDomainPrefix: "acme",
+		Category:           model.DOMAIN_CATEGORY_TEAM,
+	})
+	if _, err := storage.DomainStorage.Create(domain); err != nil {
+		t.Fatalf("create domain failed: %v", err)
+	}
+	return domain
+}
+
+func retrieveResolveStatus(t *testing.T, storage *model.Storage, domain *model.Domain) int {
+	t.Helper()
+	persisted, err := storage.DomainStorage.RetrieveByID(domain.ID)
+	if err != nil {
+		t.Fatalf("retrieve domain failed: %v", err)
+	}
+	return persisted.ResolveStatus
+}
+
+func TestVerifyDomain(t *testing.T) {
+	cases := []struct {
+		name    string
+		records func(resolver *dnsresolver.FakeResolver, domain *model.Domain)
+		status  int
+	}{
+		{
+			name: "txt match",
+			records: func(resolver *dnsresolver.FakeResolver, domain *model.Domain) {
+				resolver.SetTXT(domain.ExportTXTRecordName(), "v=spf1 -all", domain.ExportTXTRecordValue())
+			},
+			status: model.DOMAIN_RESOLVE_STATUS_VERIFIED,
+		},
+		{
+			name: "txt mismatch",
+			records: func(resolver *dnsresolver.FakeResolver, domain *model.Domain) {
+				resolver.SetTXT(domain.ExportTXTRecordName(), model.DOMAIN_TXT_RECORD_VALUE_PREFIX+"someone-else")
+			},
+			status: model.DOMAIN_RESOLVE_STATUS_PENDING,
+		},
+		{
+			name:    "nxdomain",
+			records: func(resolver *dnsresolver.FakeResolver, domain *model.Domain) {},
+			status:  model.DOMAIN_RESOLVE_STATUS_PENDING,
+		},
+		{
+			name: "cname match",
+			records: func(resolver *dnsresolver.FakeResolver, domain *model.Domain) {
+				resolver.SetCNAME(domain.UserDomain, "acme."+testSystemDomain+".")
+			},
+			status: model.DOMAIN_RESOLVE_STATUS_VERIFIED,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			verifier, resolver := newTestVerifier(t)
+			domain := createTestDomain(t, verifier.Storage, 1, "apps.acme.com")
+			c.records(resolver, domain)
+
+			if err := verifier.VerifyDomain(domain); err != nil {
+				t.Fatalf("verify domain failed: %v", err)
+			}
+			if got := retrieveResolveStatus(t, verifier.Storage, domain); got != c.status {
+				t.Errorf("resolve status = %d, want %d", got, c.status)
+			}
+		})
+	}
+}
+
+func TestReverifyDomainsDowngradesLostOwnership(t *testing.T) {
+	verifier, resolver := newTestVerifier(t)
+	domain := createTestDomain(t, verifier.Storage, 1, "apps.acme.com")
+	resolver.SetTXT(domain.ExportTXTRecordName(), domain.ExportTXTRecordValue())
+	if err := verifier.VerifyDomain(domain); err != nil {
+		t.Fatalf("verify domain failed: %v", err)
+	}
+	if !verifier.Storage.DomainStorage.IsUserDomainExists(domain.UserDomain) {
+		t.Fatal("verified domain should be taken")
+	}
+
+	resolver.Remove(domain.ExportTXTRecordName())
+	time.Sleep(10 * time.Millisecond)
+	verifier.ReverifyDomains()
+
+	if got := retrieveResolveStatus(t, verifier.Storage, domain); got != model.DOMAIN_RESOLVE_STATUS_FAILED {
+		t.Errorf("resolve status = %d, want %d", got, model.DOMAIN_RESOLVE_STATUS_FAILED)
+	}
+	if verifier.Storage.DomainStorage.IsUserDomainExists(domain.UserDomain) {
+		t.Error("failed domain should not be taken")
+	}
+}
+
+func TestPendingClaimDoesNotHoldDomain(t *testing.T) {
+	verifier, resolver := newTestVerifier(t)
+	squatter := createTestDomain(t, verifier.Storage, 1, "apps.acme.com")
+	if verifier.Storage.DomainStorage.IsUserDomainExists(squatter.UserDomain) {
+		t.Fatal("pending claim should not take the domain")
+	}
+
+	owner := createTestDomain(t, verifier.Storage, 2, "apps.acme.com")
+	resolver.SetTXT(owner.ExportTXTRecordName(), owner.ExportTXTRecordValue(), squatter.ExportTXTRecordValue())
+	if err := verifier.VerifyDomain(owner); err != nil {
+		t.Fatalf("verify domain failed: %v", err)
+	}
+	if err := verifier.VerifyDomain(squatter); err != nil {
+		t.Fatalf("verify domain failed: %v", err)
+	}
+
+	// the first verified claim holds the domain
+	if got := retrieveResolveStatus(t, verifier.Storage, owner); got != model.DOMAIN_RESOLVE_STATUS_VERIFIED {
+		t.Errorf("owner resolve status = %d, want %d", got, model.DOMAIN_RESOLVE_STATUS_VERIFIED)
+	}
+	if got := retrieveResolveStatus(t, verifier.Storage, squatter); got == model.DOMAIN_RESOLVE_STATUS_VERIFIED {
+		t.Error("second claim should not be verified while the domain is held")
+	}
+}
+
+func TestUpdateDomainResetsResolveStatus(t *testing.T) {
+	verifier, resolver := newTestVerifier(t)
+	domain := createTestDomain(t, verifier.Storage, 1, "apps.acme.com")
+	resolver.SetTXT(domain.ExportTXTRecordName(), domain.ExportTXTRecordValue())
+	if err := verifier.VerifyDomain(domain); err != nil {
+		t.Fatalf("verify domain failed: %v", err)
+	}
+	previousToken := domain.VerificationToken
+
+	domain.UpdateByUpdateDomainRequest(&model.UpdateDomainRequest{UserDomain: "Portal.Acme.com."})
+	if err := verifier.Storage.DomainStorage.UpdateByID(domain); err != nil {
+		t.Fatalf("update domain failed: %v", err)
+	}
+
+	persisted, err := verifier.Storage.DomainStorage.RetrieveByID(domain.ID)
+	if err != nil {
+		t.Fatalf("retrieve domain failed: %v", err)
+	}
+	if persisted.UserDomain != "portal.acme.com" || persisted.ResolveStatus != model.DOMAIN_RESOLVE_STATUS_PENDING || !persisted.VerifiedAt.IsZero() {
+		t.Errorf("changed domain not reset: %s status %d verified at %v", persisted.UserDomain, persisted.ResolveStatus, persisted.VerifiedAt)
+	}
+	if persisted.VerificationToken == previousToken {
+		t.Error("verification token should be rotated")
+	}
+}
@@ -0,0 +1,251 @@
+package minio
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+)
+
+const MEMORY_DRIVE_URL_SCHEME = "memory://"
+
+var ErrMemoryDriveNoSuchUpload = errors.New("no such multipart upload")
+var ErrMemoryDriveInvalidPart = errors.New("invalid multipart upload part")
+
+type memoryObject struct {
+	content      []byte
+	contentType  string
+	etag         string
+	lastModified time.Time
+}
+
+type memoryMultipartUpload struct {
+	fileName string
+	parts    map[int]*memoryObject
+}
+
+// MemoryS3Drive is an in-memory model.S3Instance for tests and local development.
+// the presigned urls are "memory://{bucket}/{key}?..." and can not be requested,
+// use PutObject and UploadPart to act as the client which uploads by the presigned urls.
+type MemoryS3Drive struct {
+	BucketName string
+	mutex      sync.Mutex
+	objects    map[string]*memoryObject
+	uploads    map[string]*memoryMultipartUpload
+}
+
+var _ model.S3Instance = (*MemoryS3Drive)(nil)
+
+func NewMemoryS3Drive(bucketName string) *MemoryS3Drive {
+	return &MemoryS3Drive{
+		BucketName: bucketName,
+		objects:    make(map[string]*memoryObject),
+		uploads:    make(map[string]*memoryMultipartUpload),
+	}
+}
+
+// the content is copied, so the caller can reuse its buffer.
+func newMemoryObject(content []byte, contentType string) *memoryObject {
+	digest := md5.Sum(content)
+	return &memoryObject{
+		content:      append([]byte(nil), content...),
+		contentType:  contentType,
+		etag:         hex.EncodeToString(digest[:]),
+		lastModified: time.Now().UTC(),
+	}
+}
+
+func (d *MemoryS3Drive) presignedURL(fileName string, reqParams url.Values) string {
+	presignedURL := MEMORY_DRIVE_URL_SCHEME + d.BucketName + "/" + fileName
+	if len(reqParams) > 0 {
+		presignedURL += "?" + reqParams.Encode()
+	}
+	return presignedURL
+}
+
+// PutObject store the object as it is uploaded by the presigned put url.
+func (d *MemoryS3Drive) PutObject(fileName string, content []byte, contentType string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.objects[fileName] = newMemoryObject(content, contentType)
+}
+
+// GetObject feedback a copy of the object content.
+func (d *MemoryS3Drive) GetObject(fileName string) ([]byte, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	object, hit := d.objects[fileName]
+	if !hit {
+		return nil, model.ErrDriveObjectNotFound
+	}
+	return append([]byte(nil), object.content...), nil
+}
+
+// UploadPart store the part as it is uploaded by the presigned upload part url, and feedback the etag of the part.
+func (d *MemoryS3Drive) UploadPart(fileName string, uploadID string, partNumber int, content []byte) (string, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	upload, hit := d.uploads[uploadID]
+	if !hit || upload.fileName != fileName {
+		return "", ErrMemoryDriveNoSuchUpload
+	}
+	part := newMemoryObject(content, "")
+	upload.parts[partNumber] = part
+	return part.etag, nil
+}
+
+func (d *MemoryS3Drive) GetPreSignedPutURL(fileName string) (string, error) {
+	return d.presignedURL(fileName, nil), nil
+}
+
+func (d *MemoryS3Drive) GetPreSignedGetURL(fileName string, downloadName string) (string, error) {
+	reqParams := make(url.Values)
+	if downloadName != "" {
+		reqParams.Set("response-content-disposition", "attachment; filename=\""+downloadName+"\"")
+	}
+	return d.presignedURL(fileName, reqParams), nil
+}
+
+func (d *MemoryS3Drive) StatObject(fileName string) (*model.DriveObject, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	object, hit := d.objects[fileName]
+	if !hit {
+		return nil, model.ErrDriveObjectNotFound
+	}
+	return object.export(fileName), nil
+}
+
+// ListObjects use the last key of page as continuation token.
+func (d *MemoryS3Drive) ListObjects(prefix string, continuationToken string, limit int) (*model.DriveObjectPage, error) {
+	if limit <= 0 || limit > model.DRIVE_LIST_OBJECTS_MAX_LIMIT {
+		limit = model.DRIVE_LIST_OBJECTS_MAX_LIMIT
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	keys := make([]string, 0)
+	for key := range d.objects {
+		if strings.HasPrefix(key, prefix) && key > continuationToken {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	page := model.NewDriveObjectPage()
+	for _, key := range keys {
+		if len(page.Objects) == limit {
+			page.NextContinuationToken = page.Objects[limit-1].Key
+			break
+		}
+		page.Objects = append(page.Objects, d.objects[key].export(key))
+	}
+	return page, nil
+}
+
+func (d *MemoryS3Drive) CopyObject(srcFileName string, dstFileName string) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	object, hit := d.objects[srcFileName]
+	if !hit {
+		return model.ErrDriveObjectNotFound
+	}
+	d.objects[dstFileName] = newMemoryObject(object.content, object.contentType)
+	return nil
+}
+
+func (d *MemoryS3Drive) RemoveObject(fileName string) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	delete(d.objects, fileName)
+	return nil
+}
+
+func (d *MemoryS3Drive) RemoveObjects(fileNames []string) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for _, fileName := range fileNames {
+		delete(d.objects, fileName)
+	}
+	return nil
+}
+
+func (d *MemoryS3Drive) RemoveObjectsByPrefix(prefix string) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for key := range d.objects {
+		if strings.HasPrefix(key, prefix) {
+			delete(d.objects, key)
+		}
+	}
+	return nil
+}
+
+func (d *MemoryS3Drive) CreateMultipartUpload(fileName string) (string, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	uploadID := uuid.New().String()
+	d.uploads[uploadID] = &memoryMultipartUpload{
+		fileName: fileName,
+		parts:    make(map[int]*memoryObject),
+	}
+	return uploadID, nil
+}
+
+func (d *MemoryS3Drive) GetPreSignedUploadPartURL(fileName string, uploadID string, partNumber int) (string, error) {
+	reqParams := make(url.Values)
+	reqParams.Set("uploadId", uploadID)
+	reqParams.Set("partNumber", strconv.Itoa(partNumber))
+	return d.presignedURL(fileName, reqParams), nil
+}
+
+// CompleteMultipartUpload concatenate the reported parts in part number order, the etag of each part must match the uploaded one.
+func (d *MemoryS3Drive) CompleteMultipartUpload(fileName string, uploadID string, parts []*model.DriveUploadedPart) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	upload, hit := d.uploads[uploadID]
+	if !hit || upload.fileName != fileName {
+		return ErrMemoryDriveNoSuchUpload
+	}
+	sortedParts := make([]*model.DriveUploadedPart, len(parts))
+	copy(sortedParts, parts)
+	sort.Slice(sortedParts, func(i, j int) bool { return sortedParts[i].PartNumber < sortedParts[j].PartNumber })
+	content := make([]byte, 0)
+	for _, part := range sortedParts {
+		uploadedPart, hit := upload.parts[part.PartNumber]
+		if !hit || uploadedPart.etag != part.ETag {
+			return ErrMemoryDriveInvalidPart
+		}
+		content = append(content, uploadedPart.content...)
+	}
+	d.objects[fileName] = newMemoryObject(content, "")
+	delete(d.uploads, uploadID)
+	return nil
+}
+
+func (d *MemoryS3Drive) AbortMultipartUpload(fileName string, uploadID string) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	upload, hit := d.uploads[uploadID]
+	if !hit || upload.fileName != fileName {
+		return ErrMemoryDriveNoSuchUpload
+	}
+	delete(d.uploads, uploadID)
+	return nil
+}
+
+func (o *memoryObject) export(key string) *model.DriveObject {
+	return &model.DriveObject{
+		Key:          key,
+		Size:         int64(len(o.content)),
+		ETag:         o.etag,
+		ContentType:  o.contentType,
+		LastModified: o.lastModified,
+	}
+}
@@ -0,0 +1,185 @@
+package minio
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+)
+
+const testBucketName = "kozmo-drive-test"
+
+func TestMemoryS3DriveMultipartUpload(t *testing.T) {
+	drive := NewMemoryS3Drive(testBucketName)
+	uploadID, err := drive.CreateMultipartUpload("team/video.mp4")
+	if err != nil {
+		t.Fatalf("create multipart upload failed: %v", err)
+	}
+
+	// upload out of order, the parts are concatenated in part number order
+	contents := map[int]string{2: "world", 1: "hello ", 3: "!"}
+	parts := make([]*model.DriveUploadedPart, 0, len(contents))
+	for _, partNumber := range []int{2, 1, 3} {
+		etag, err := drive.UploadPart("team/video.mp4", uploadID, partNumber, []byte(contents[partNumber]))
+		if err != nil {
+			t.Fatalf("upload part %d failed: %v", partNumber, err)
+		}
+		parts = append(parts, &model.DriveUploadedPart{PartNumber: partNumber, ETag: etag})
+	}
+	if _, err := drive.StatObject("team/video.mp4"); !errors.Is(err, model.ErrDriveObjectNotFound) {
+		t.Errorf("object visible before complete: %v", err)
+	}
+	if _, err := drive.UploadPart("team/other.mp4", uploadID, 4, []byte("x")); !errors.Is(err, ErrMemoryDriveNoSuchUpload) {
+		t.Errorf("upload part to another file = %v, want %v", err, ErrMemoryDriveNoSuchUpload)
+	}
+	if err := drive.CompleteMultipartUpload("team/video.mp4", uploadID, []*model.DriveUploadedPart{{PartNumber: 1, ETag: "wrong"}}); !errors.Is(err, ErrMemoryDriveInvalidPart) {
+		t.Errorf("complete with wrong etag = %v, want %v", err, ErrMemoryDriveInvalidPart)
+	}
+	if err := drive.CompleteMultipartUpload("team/video.mp4", uploadID, parts); err != nil {
+		t.Fatalf("complete multipart upload failed: %v", err)
+	}
+	content, err := drive.GetObject("team/video.mp4")
+	if err != nil {
+		t.Fatalf("get object failed: %v", err)
+	}
+	if string(content) != "hello world!" {
+		t.Errorf("content = %q, want %q", content, "hello world!")
+	}
+	if err := drive.CompleteMultipartUpload("team/video.mp4", uploadID, parts); !errors.Is(err, ErrMemoryDriveNoSuchUpload) {
+		t.Errorf("complete twice = %v, want %v", err, ErrMemoryDriveNoSuchUpload)
+	}
+}
+
+func TestMemoryS3DriveAbortMultipartUpload(t *testing.T) {
+	drive := NewMemoryS3Drive(testBucketName)
+	uploadID, err := drive.CreateMultipartUpload("team/video.mp4")
+	if err != nil {
+		t.Fatalf("create multipart upload failed: %v", err)
+	}
+	etag, err := drive.UploadPart("team/video.mp4", uploadID, 1, []byte("hello"))
+	if err != nil {
+		t.Fatalf("upload part failed: %v", err)
+	}
+	if err := drive.AbortMultipartUpload("team/other.mp4", uploadID); !errors.Is(err, ErrMemoryDriveNoSuchUpload) {
+		t.Errorf("abort upload of another file = %v, want %v", err, ErrMemoryDriveNoSuchUpload)
+	}
+	if err := drive.AbortMultipartUpload("team/video.mp4", uploadID); err != nil {
+		t.Fatalf("abort multipart upload failed: %v", err)
+	}
+	if _, err := drive.UploadPart("team/video.mp4", uploadID, 2, []byte("world")); !errors.Is(err, ErrMemoryDriveNoSuchUpload) {
+		t.Errorf("upload part after abort = %v, want %v", err, ErrMemoryDriveNoSuchUpload)
+	}
+	if err := drive.CompleteMultipartUpload("team/video.mp4", uploadID, []*model.DriveUploadedPart{{PartNumber: 1, ETag: etag}}); !errors.Is(err, ErrMemoryDriveNoSuchUpload) {
+		t.Errorf("complete after abort = %v, want %v", err, ErrMemoryDriveNoSuchUpload)
+	}
+	if _, err := drive.StatObject("team/video.mp4"); !errors.Is(err, model.ErrDriveObjectNotFound) {
+		t.Errorf("aborted object is visible: %v", err)
+	}
+}
+
+func TestMemoryS3DriveCopyObject(t *testing.T) {
+	drive := NewMemoryS3Drive(testBucketName)
+	content := []byte("hello")
+	drive.PutObject("team/a.txt", content, "text/plain")
+	content[0] = 'j' // the stored object does not share the caller buffer
+
+	if err := drive.CopyObject("team/a.txt", "team/b.txt"); err != nil {
+		t.Fatalf("copy object failed: %v", err)
+	}
+	src, err := drive.StatObject("team/a.txt")
+	if err != nil {
+		t.Fatalf("stat source failed: %v", err)
+	}
+	dst, err := drive.StatObject("team/b.txt")
+	if err != nil {
+		t.Fatalf("stat copy failed: %v", err)
+	}
+	if dst.Key != "team/b.txt" || dst.Size != src.Size || dst.ETag != src.ETag || dst.ContentType != "text/plain" {
+		t.Errorf("copy = %+v, source = %+v", dst, src)
+	}
+	copied, err := drive.GetObject("team/b.txt")
+	if err != nil {
+		t.Fatalf("get copy failed: %v", err)
+	}
+	if string(copied) != "hello" {
+		t.Errorf("copied content = %q, want hello", copied)
+	}
+
+	// the copy is independent of the source
+	if err := drive.RemoveObject("team/a.txt"); err != nil {
+		t.Fatalf("remove source failed: %v", err)
+	}
+	if _, err := drive.GetObject("team/b.txt"); err != nil {
+		t.Errorf("copy removed with source: %v", err)
+	}
+	if err := drive.CopyObject("team/a.txt", "team/c.txt"); !errors.Is(err, model.ErrDriveObjectNotFound) {
+		t.Errorf("copy missing source = %v, want %v", err, model.ErrDriveObjectNotFound)
+	}
+}
+
+func TestMemoryS3DriveListObjectsPagination(t *testing.T) {
+	drive := NewMemoryS3Drive(testBucketName)
+	for i := 0; i < 5; i++ {
+		drive.PutObject(fmt.Sprintf("team/%d.txt", i), []byte("x"), "text/plain")
+	}
+	drive.PutObject("other/0.txt", []byte("x"), "text/plain")
+
+	keys := make([]string, 0)
+	pages := 0
+	continuationToken := ""
+	for {
+		page, err := drive.ListObjects("team/", continuationToken, 2)
+		if err != nil {
+			t.Fatalf("list objects failed: %v", err)
+		}
+		pages++
+		for _, object := range page.Objects {
+			keys = append(keys, object.Key)
+		}
+		if page.NextContinuationToken == "" {
+			break
+		}
+		continuationToken = page.NextContinuationToken
+	}
+	want := []string{"team/0.txt", "team/1.txt", "team/2.txt", "team/3.txt", "team/4.txt"}
+	if fmt.Sprint(keys) != fmt.Sprint(want) {
+		t.Errorf("keys = %v, want %v", keys, want)
+	}
+	if pages != 3 {
+		t.Errorf("pages = %d, want 3", pages)
+	}
+
+	// the page which ends with the last object has no continuation token
+	page, err := drive.ListObjects("team/", "team/2.txt", 2)
+	if err != nil {
+		t.Fatalf("list objects failed: %v", err)
+	}
+	if len(page.Objects) != 2 || page.NextContinuationToken != "" {
+		t.Errorf("last page = %d objects, token %q, want 2 objects and no token", len(page.Objects), page.NextContinuationToken)
+	}
+	page, err = drive.ListObjects("missing/", "", 0)
+	if err != nil {
+		t.Fatalf("list objects failed: %v", err)
+	}
+	if len(page.Objects) != 0 || page.NextContinuationToken != "" {
+		t.Errorf("empty prefix page = %+v", page)
+	}
+}
+
+func TestMemoryS3DriveStatObjectNotFound(t *testing.T) {
+	drive := NewMemoryS3Drive(testBucketName)
+	if _, err := drive.StatObject("team/missing.txt"); !errors.Is(err, model.ErrDriveObjectNotFound) {
+		t.Errorf("stat missing object = %v, want %v", err, model.ErrDriveObjectNotFound)
+	}
+	drive.PutObject("team/a.txt", []byte("hello"), "text/plain")
+	if err := drive.RemoveObject("team/a.txt"); err != nil {
+		t.Fatalf("remove object failed: %v", err)
+	}
+	if _, err := drive.StatObject("team/a.txt"); !errors.Is(err, model.ErrDriveObjectNotFound) {
+		t.Errorf("stat removed object = %v, want %v", err, model.ErrDriveObjectNotFound)
+	}
+	if _, err := drive.GetObject("team/a.txt"); !errors.Is(err, model.ErrDriveObjectNotFound) {
+		t.Errorf("get removed object = %v, want %v", err, model.ErrDriveObjectNotFound)
+	}
+}
@@ -2,11 +2,18 @@ package minio
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/config"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
@@ -15,6 +22,7 @@ import (
 const MINIO_DEFAULT_SERVE_ADDRESS = "http://127.0.0.1:9000/"
 const DEFAULT_PUBLIC_POLICY = `{"Version": "2012-10-17","Statement": [{"Action": ["s3:GetObject"],"Effect": "Allow","Principal": {"AWS": ["*"]},"Resource": ["arn:aws:s3:::%s/*"],"Sid": ""}]}`
 const MINIO_CONNECT_RETRY_TIMES = 6
+const MINIO_ERROR_CODE_NO_SUCH_KEY = "NoSuchKey"
 
 type MINIOConfig struct {
 	AccessKeyID     string
@@ -126,3 +134,169 @@ func (s3Drive *S3Drive) GetPreSignedPutURL(fileName string) (string, error) {
 	}
 	return formatPresignedURLForSelfHostEnv(presignedURL.String()), nil
 }
+
+func (s3Drive *S3Drive) RemoveObjectsByPrefix(prefix string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// list objects, stop at first listing error
+	var errInList error
+	objectsCh := make(chan minio.ObjectInfo)
+	go func() {
+		defer close(objectsCh)
+		for object := range s3Drive.Instance.ListObjects(ctx, s3Drive.Config.BucketName, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+			if object.Err != nil {
+				errInList = object.Err
+				return
+			}
+			objectsCh <- object
+		}
+	}()
+
+	// remove listed objects
+	var errInRemove error
+	for removeErr := range s3Drive.Instance.RemoveObjects(ctx, s3Drive.Config.BucketName, objectsCh, minio.RemoveObjectsOptions{}) {
+		if errInRemove == nil {
+			errInRemove = removeErr.Err
+		}
+	}
+	if errInList != nil {
+		return errInList
+	}
+	return errInRemove
+}
+
+// the core client exposes the low level apis of pagination and multipart upload.
+func (s3Drive *S3Drive) core() *minio.Core {
+	return &minio.Core{Client: s3Drive.Instance}
+}
+
+func (s3Drive *S3Drive) GetPreSignedGetURL(fileName string, downloadName string) (string, error) {
+	ctx := context.Background()
+	reqParams := make(url.Values)
+	if downloadName != "" {
+		reqParams.Set("response-content-disposition", mime.FormatMediaType("attachment", map[string]string{"filename": downloadName}))
+	}
+	presignedURL, err := s3Drive.Instance.PresignedGetObject(ctx, s3Drive.Config.BucketName, fileName, s3Drive.Config.UploadTimeout, reqParams)
+	if err != nil {
+		return "", err
+	}
+	return formatPresignedURLForSelfHostEnv(presignedURL.String()), nil
+}
+
+func (s3Drive *S3Drive) StatObject(fileName string) (*model.DriveObject, error) {
+	ctx := context.Background()
+	objectInfo, err := s3Drive.Instance.StatObject(ctx, s3Drive.Config.BucketName, fileName, minio.StatObjectOptions{})
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == MINIO_ERROR_CODE_NO_SUCH_KEY {
+			return nil, model.ErrDriveObjectNotFound
+		}
+		return nil, err
+	}
+	return newDriveObjectByObjectInfo(objectInfo), nil
+}
+
+func (s3Drive *S3Drive) ListObjects(prefix string, continuationToken string, limit int) (*model.DriveObjectPage, error) {
+	if limit <= 0 || limit > model.DRIVE_LIST_OBJECTS_MAX_LIMIT {
+		limit = model.DRIVE_LIST_OBJECTS_MAX_LIMIT
+	}
+	result, err := s3Drive.core().ListObjectsV2(s3Drive.Config.BucketName, prefix, "", continuationToken, "", limit)
+	if err != nil {
+		return nil, err
+	}
+	page := model.NewDriveObjectPage()
+	for _, objectInfo := range result.Contents {
+		page.Objects = append(page.Objects, newDriveObjectByObjectInfo(objectInfo))
+	}
+	if result.IsTruncated {
+		page.NextContinuationToken = result.NextContinuationToken
+	}
+	return page, nil
+}
+
+// CopyObject copy object in the bucket on server side.
+func (s3Drive *S3Drive) CopyObject(srcFileName string, dstFileName string) error {
+	ctx := context.Background()
+	src := minio.CopySrcOptions{
+		Bucket: s3Drive.Config.BucketName,
+		Object: srcFileName,
+	}
+	dst := minio.CopyDestOptions{
+		Bucket: s3Drive.Config.BucketName,
+		Object: dstFileName,
+	}
+	_, err := s3Drive.Instance.CopyObject(ctx, dst, src)
+	return err
+}
+
+func (s3Drive *S3Drive) RemoveObject(fileName string) error {
+	ctx := context.Background()
+	return s3Drive.Instance.RemoveObject(ctx, s3Drive.Config.BucketName, fileName, minio.RemoveObjectOptions{})
+}
+
+// RemoveObjects remove objects in one batch request, the objects which do not exist are ignored.
+func (s3Drive *S3Drive) RemoveObjects(fileNames []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	objectsCh := make(chan minio.ObjectInfo)
+	go func() {
+		defer close(objectsCh)
+		for _, fileName := range fileNames {
+			objectsCh <- minio.ObjectInfo{Key: fileName}
+		}
+	}()
+
+	// feedback the first failure
+	var errInRemove error
+	for removeErr := range s3Drive.Instance.RemoveObjects(ctx, s3Drive.Config.BucketName, objectsCh, minio.RemoveObjectsOptions{}) {
+		if errInRemove == nil {
+			errInRemove = errors.New("remove object " + removeErr.ObjectName + " failed: " + removeErr.Err.Error())
+		}
+	}
+	return errInRemove
+}
+
+func (s3Drive *S3Drive) CreateMultipartUpload(fileName string) (string, error) {
+	ctx := context.Background()
+	return s3Drive.core().NewMultipartUpload(ctx, s3Drive.Config.BucketName, fileName, minio.PutObjectOptions{})
+}
+
+func (s3Drive *S3Drive) GetPreSignedUploadPartURL(fileName string, uploadID string, partNumber int) (string, error) {
+	ctx := context.Background()
+	reqParams := make(url.Values)
+	reqParams.Set("uploadId", uploadID)
+	reqParams.Set("partNumber", strconv.Itoa(partNumber))
+	presignedURL, err := s3Drive.Instance.Presign(ctx, http.MethodPut, s3Drive.Config.BucketName, fileName, s3Drive.Config.UploadTimeout, reqParams)
+	if err != nil {
+		return "", err
+	}
+	return formatPresignedURLForSelfHostEnv(presignedURL.String()), nil
+}
+
+func (s3Drive *S3Drive) CompleteMultipartUpload(fileName string, uploadID string, parts []*model.DriveUploadedPart) error {
+	ctx := context.Background()
+	// s3 requires the parts in ascending order
+	completeParts := make([]minio.CompletePart, 0, len(parts))
+	for _, part := range parts {
+		completeParts = append(completeParts, minio.CompletePart{PartNumber: part.PartNumber, ETag: part.ETag})
+	}
+	sort.Slice(completeParts, func(i, j int) bool { return completeParts[i].PartNumber < completeParts[j].PartNumber })
+	_, err := s3Drive.core().CompleteMultipartUpload(ctx, s3Drive.Config.BucketName, fileName, uploadID, completeParts, minio.PutObjectOptions{})
+	return err
+}
+
+func (s3Drive *S3Drive) AbortMultipartUpload(fileName string, uploadID string) error {
+	ctx := context.Background()
+	return s3Drive.core().AbortMultipartUpload(ctx, s3Drive.Config.BucketName, fileName, uploadID)
+}
+
+func newDriveObjectByObjectInfo(objectInfo minio.ObjectInfo) *model.DriveObject {
+	return &model.DriveObject{
+		Key:          objectInfo.Key,
+		Size:         objectInfo.Size,
+		ETag:         objectInfo.ETag,
+		ContentType:  objectInfo.ContentType,
+		LastModified: objectInfo.LastModified,
+	}
+}
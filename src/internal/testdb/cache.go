@@ -0,0 +1,141 @@
+package testdb
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// redisStub speaks the subset of RESP2 used by model.Cache, the values are kept in memory and never expire.
+type redisStub struct {
+	mutex  sync.Mutex
+	values map[string]string
+}
+
+func (s *redisStub) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readRESPCommand(reader)
+		if err != nil {
+			return
+		}
+		if _, err := io.WriteString(conn, s.execute(args)); err != nil {
+			return
+		}
+	}
+}
+
+func (s *redisStub) execute(args []string) string {
+	if len(args) == 0 {
+		return "-ERR empty command\r\n"
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		return "+PONG\r\n"
+	case "GET":
+		value, hit := s.values[args[1]]
+		if !hit {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+	case "SET":
+		// the options after value are ignored except NX
+		for _, option := range args[3:] {
+			if _, hit := s.values[args[1]]; hit && strings.ToUpper(option) == "NX" {
+				return "$-1\r\n"
+			}
+		}
+		s.values[args[1]] = args[2]
+		return "+OK\r\n"
+	case "DEL":
+		deleted := 0
+		for _, key := range args[1:] {
+			if _, hit := s.values[key]; hit {
+				delete(s.values, key)
+				deleted++
+			}
+		}
+		return fmt.Sprintf(":%d\r\n", deleted)
+	case "EXPIRE":
+		return ":1\r\n"
+	}
+	// HELLO included, so the client falls back to RESP2
+	return fmt.Sprintf("-ERR unknown command '%s'\r\n", strings.ToLower(args[0]))
+}
+
+func readRESPCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := readRESPLine(reader, '*')
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(line)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		line, err := readRESPLine(reader, '$')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(line)
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			return nil, err
+		}
+		args = append(args, string(buf[:size]))
+	}
+	return args, nil
+}
+
+func readRESPLine(reader *bufio.Reader, prefix byte) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 3 || line[0] != prefix {
+		return "", errors.New("unexpected RESP line: " + line)
+	}
+	return strings.TrimRight(line[1:], "\r\n"), nil
+}
+
+// NewCache returns a model.Cache backed by an in-memory redis stub, the stub is closed when the test finished.
+func NewCache(t testing.TB) *model.Cache {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen redis stub failed: %v", err)
+	}
+	stub := &redisStub{values: make(map[string]string)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go stub.serve(conn)
+		}
+	}()
+	client := redis.NewClient(&redis.Options{Addr: listener.Addr().String()})
+	t.Cleanup(func() {
+		client.Close()
+		listener.Close()
+	})
+	return model.NewCache(client, zap.NewNop().Sugar())
+}
@@ -0,0 +1,36 @@
+//go:build cgo
+
+package testdb
+
+import (
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/migrator"
+	"gorm.io/gorm/schema"
+)
+
+// dialector maps the postgres bigserial primary key to the sqlite rowid, so the ids are generated on insert.
+type dialector struct {
+	sqlite.Dialector
+}
+
+func (d dialector) Migrator(db *gorm.DB) gorm.Migrator {
+	return sqlite.Migrator{Migrator: migrator.Migrator{Config: migrator.Config{
+		DB:                          db,
+		Dialector:                   d,
+		CreateIndexAfterCreateTable: true,
+	}}}
+}
+
+func (d dialector) DataTypeOf(field *schema.Field) string {
+	if field.PrimaryKey && field.DataType == "bigserial" {
+		return "integer"
+	}
+	return d.Dialector.DataTypeOf(field)
+}
+
+func openSQLite(t testing.TB, dsn string, config *gorm.Config) (*gorm.DB, error) {
+	return gorm.Open(dialector{Dialector: sqlite.Dialector{DSN: dsn}}, config)
+}
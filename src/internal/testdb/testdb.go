@@ -0,0 +1,63 @@
+// Package testdb provides an in-memory sqlite database with the supervisor tables, it is only imported by tests.
+package testdb
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var databaseSerial int64
+
+// Tables lists the models persisted by model.Storage.
+var Tables = []interface{}{
+	&model.User{},
+	&model.Team{},
+	&model.TeamMember{},
+	&model.RemovedTeamMember{},
+	&model.Invite{},
+	&model.Domain{},
+	&model.SCIMToken{},
+	&model.Role{},
+	&model.UnitRoleRelation{},
+	&model.AccessRequest{},
+	&model.AccessRequestEvent{},
+	&model.OutboxEvent{},
+	&model.Webhook{},
+	&model.WebhookDelivery{},
+}
+
+// NewDB open a private in-memory database with all tables migrated, the database is closed when the test finished.
+// The sqlite driver requires cgo, the test is skipped when it is built without cgo.
+func NewDB(t testing.TB) *gorm.DB {
+	t.Helper()
+	dsn := fmt.Sprintf("file:testdb%d?mode=memory&cache=shared", atomic.AddInt64(&databaseSerial, 1))
+	db, err := openSQLite(t, dsn, &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("open test database failed: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("open test database failed: %v", err)
+	}
+	// a single connection keeps the in-memory database alive and serializes the transactions
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.AutoMigrate(Tables...); err != nil {
+		t.Fatalf("migrate test database failed: %v", err)
+	}
+	return db
+}
+
+// NewStorage returns a model.Storage backed by NewDB.
+func NewStorage(t testing.TB) *model.Storage {
+	t.Helper()
+	return model.NewStorage(NewDB(t), zap.NewNop().Sugar())
+}
@@ -27,14 +27,24 @@ func (r *Router) RegisterRouters(engine *gin.Engine) {
 
 	// access control routers
 	accessControlRouter.GET("/account/validateResult", r.Controller.ValidateAccount)
+	accessControlRouter.POST("/account/introspect", r.Controller.IntrospectToken)
 	accessControlRouter.GET("/teams/:teamID/unitType/:unitType/unitID/:unitID/attribute/canAccess/:attributeID", r.Controller.CanAccess)
 	accessControlRouter.GET("/teams/:teamID/unitType/:unitType/unitID/:unitID/attribute/canManage/:attributeID", r.Controller.CanManage)
 	accessControlRouter.GET("/teams/:teamID/unitType/:unitType/unitID/:unitID/attribute/canManageSpecial/:attributeID", r.Controller.CanManageSpecial)
 	accessControlRouter.GET("/teams/:teamID/unitType/:unitType/unitID/:unitID/attribute/canModify/:attributeID/from/:fromID/to/:toID", r.Controller.CanModify)
 	accessControlRouter.GET("/teams/:teamID/unitType/:unitType/unitID/:unitID/attribute/canDelete/:attributeID", r.Controller.CanDelete)
+	accessControlRouter.POST("/batch", r.Controller.BatchCheckAccessControl)
+	accessControlRouter.POST("/explain", r.Controller.ExplainAccessControl)
 
 	// data control routers
 	dataControlRouter.GET("/users/:targetUserID", r.Controller.GetTargetUserByInternalRequest)
 	dataControlRouter.GET("/users/multi/:targetUserIDs", r.Controller.GetTargetUsersByInternalRequest)
 	dataControlRouter.GET("/teams/byIdentifier/:teamIdentifier", r.Controller.GetTargetTeamByIdentifier)
+	dataControlRouter.GET("/teams/byHostname/:hostname", r.Controller.GetTargetTeamByHostname)
+	dataControlRouter.POST("/users/batch/byIDs", r.Controller.BatchGetTargetUsersByIDs)
+	dataControlRouter.POST("/users/batch/byUIDs", r.Controller.BatchGetTargetUsersByUIDs)
+	dataControlRouter.POST("/users/batch/byEmails", r.Controller.BatchGetTargetUsersByEmails)
+	dataControlRouter.POST("/users/teams", r.Controller.GetTargetUserTeams)
+	dataControlRouter.POST("/teams/batch/byIDs", r.Controller.BatchGetTargetTeamsByIDs)
+	dataControlRouter.POST("/teams/batch/byUIDs", r.Controller.BatchGetTargetTeamsByUIDs)
 }
@@ -0,0 +1,114 @@
+package internalrpc
+
+import (
+	"github.com/kozmoai/kozmo-supervisor-backend/src/controller"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/internalrpc/internalrpcpb"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// convertFeedbackErrorToStatus map the error flag of controller to grpc status code, the flag is kept in message for the callers migrating from REST.
+func convertFeedbackErrorToStatus(err *controller.FeedbackError) error {
+	code := codes.InvalidArgument
+	switch err.Flag {
+	case controller.ERROR_FLAG_VALIDATE_ACCOUNT_FAILED, controller.ERROR_FLAG_VALIDATE_REQUEST_TOKEN_FAILED:
+		code = codes.Unauthenticated
+	case controller.ERROR_FLAG_ACCESS_DENIED:
+		code = codes.PermissionDenied
+	case controller.ERROR_FLAG_CAN_NOT_GET_USER, controller.ERROR_FLAG_CAN_NOT_GET_TEAM, controller.ERROR_FLAG_CAN_NOT_GET_TEAM_MEMBER:
+		code = codes.NotFound
+	case controller.ERROR_FLAG_CAN_NOT_GET_UNIT_ROLE_RELATION:
+		code = codes.Internal
+	}
+	return status.Error(code, err.Flag+": "+err.Message)
+}
+
+func convertAccessControlCheck(category string, unitType string, unitID string, attributeID string, fromID string, toID string) *model.AccessControlCheck {
+	return &model.AccessControlCheck{
+		Category:    category,
+		UnitType:    unitType,
+		UnitID:      unitID,
+		AttributeID: attributeID,
+		FromID:      fromID,
+		ToID:        toID,
+	}
+}
+
+// exportAccessControlCheck convert the magic string ids of check, fromID and toID are zero unless the check is canModify.
+func exportAccessControlCheck(check *model.AccessControlCheck) (int, int, int, int, int, error) {
+	unitType, errInExportUnitType := check.ExportUnitType()
+	if errInExportUnitType != nil {
+		return 0, 0, 0, 0, 0, errInExportUnitType
+	}
+	attributeID, errInExportAttributeID := check.ExportAttributeID()
+	if errInExportAttributeID != nil {
+		return 0, 0, 0, 0, 0, errInExportAttributeID
+	}
+	unitID, errInExportUnitID := check.ExportUnitID()
+	if errInExportUnitID != nil {
+		return 0, 0, 0, 0, 0, errInExportUnitID
+	}
+	if !check.IsModify() {
+		return unitType, attributeID, unitID, 0, 0, nil
+	}
+	fromID, toID, errInExportFromIDAndToID := check.ExportFromIDAndToID()
+	if errInExportFromIDAndToID != nil {
+		return 0, 0, 0, 0, 0, errInExportFromIDAndToID
+	}
+	return unitType, attributeID, unitID, fromID, toID, nil
+}
+
+func convertBatchAccessControlResponse(resp *model.BatchAccessControlResponse) *internalrpcpb.BatchCheckResponse {
+	results := make([]*internalrpcpb.AccessControlCheckResult, 0, len(resp.Results))
+	for _, result := range resp.Results {
+		results = append(results, &internalrpcpb.AccessControlCheckResult{
+			Allowed: result.Allowed,
+			Error:   result.Error,
+		})
+	}
+	return &internalrpcpb.BatchCheckResponse{Results: results}
+}
+
+func convertUser(resp *model.GetTargetUserByInternalRequestResponse) *internalrpcpb.User {
+	return &internalrpcpb.User{
+		Id:           resp.ID,
+		Uid:          resp.UID.String(),
+		TeamMemberId: resp.TeamMemberID,
+		Nickname:     resp.Nickname,
+		Email:        resp.Email,
+		Avatar:       resp.Avatar,
+		Language:     resp.Language,
+		IsSubscribed: resp.IsSubscribed,
+		CreatedAt:    resp.CreatedAt.UnixMilli(),
+		UpdatedAt:    resp.UpdatedAt.UnixMilli(),
+	}
+}
+
+func convertGetUsersResponse(resp *model.GetTargetUsersByInternalRequestResponse) *internalrpcpb.GetUsersResponse {
+	users := make(map[string]*internalrpcpb.User, len(resp.Users))
+	for id, user := range resp.Users {
+		users[id] = convertUser(user)
+	}
+	ret := &internalrpcpb.GetUsersResponse{Users: users}
+	if resp.PageInfo != nil {
+		ret.PageInfo = &internalrpcpb.PageInfo{
+			NextCursor: resp.PageInfo.NextCursor,
+			HasMore:    resp.PageInfo.HasMore,
+		}
+	}
+	return ret
+}
+
+func convertTeam(resp *model.GetTargetTeamByInternalRequestResponse) *internalrpcpb.Team {
+	return &internalrpcpb.Team{
+		Id:         resp.ID,
+		Uid:        resp.UID.String(),
+		Name:       resp.Name,
+		Identifier: resp.Identifier,
+		Icon:       resp.Icon,
+		Permission: resp.Permission,
+		CreatedAt:  resp.CreatedAt.UnixMilli(),
+		UpdatedAt:  resp.UpdatedAt.UnixMilli(),
+	}
+}
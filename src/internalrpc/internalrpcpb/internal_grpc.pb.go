@@ -0,0 +1,550 @@
+// internal access-control and data-control services, the gRPC counterpart of the internal REST routers.
+// ids are in the same magic string format as the REST path params.
+// every call carries metadata "request-token", "request-timestamp" and "request-nonce", signed by the same inputs as the REST endpoint,
+// the signed method is "POST" and the signed path is the full rpc method name, e.g. "/kozmo.supervisor.internal.v1.DataControlService/GetUser".
+// access-control calls carry the principal in metadata "authorization-token".
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: internal.proto
+
+package internalrpcpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	AccessControlService_ValidateAccount_FullMethodName  = "/kozmo.supervisor.internal.v1.AccessControlService/ValidateAccount"
+	AccessControlService_CanAccess_FullMethodName        = "/kozmo.supervisor.internal.v1.AccessControlService/CanAccess"
+	AccessControlService_CanManage_FullMethodName        = "/kozmo.supervisor.internal.v1.AccessControlService/CanManage"
+	AccessControlService_CanManageSpecial_FullMethodName = "/kozmo.supervisor.internal.v1.AccessControlService/CanManageSpecial"
+	AccessControlService_CanDelete_FullMethodName        = "/kozmo.supervisor.internal.v1.AccessControlService/CanDelete"
+	AccessControlService_CanModify_FullMethodName        = "/kozmo.supervisor.internal.v1.AccessControlService/CanModify"
+	AccessControlService_BatchCheck_FullMethodName       = "/kozmo.supervisor.internal.v1.AccessControlService/BatchCheck"
+)
+
+// AccessControlServiceClient is the client API for AccessControlService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AccessControlServiceClient interface {
+	// request token inputs: authorization token
+	ValidateAccount(ctx context.Context, in *ValidateAccountRequest, opts ...grpc.CallOption) (*ValidateAccountResponse, error)
+	// request token inputs: authorization token, team id, unit type, unit id, attribute id
+	CanAccess(ctx context.Context, in *AttributeCheckRequest, opts ...grpc.CallOption) (*AttributeCheckResponse, error)
+	CanManage(ctx context.Context, in *AttributeCheckRequest, opts ...grpc.CallOption) (*AttributeCheckResponse, error)
+	CanManageSpecial(ctx context.Context, in *AttributeCheckRequest, opts ...grpc.CallOption) (*AttributeCheckResponse, error)
+	CanDelete(ctx context.Context, in *AttributeCheckRequest, opts ...grpc.CallOption) (*AttributeCheckResponse, error)
+	// request token inputs: authorization token, team id, unit type, unit id, attribute id, from id, to id
+	CanModify(ctx context.Context, in *AttributeCheckRequest, opts ...grpc.CallOption) (*AttributeCheckResponse, error)
+	// the stream is signed once when it is opened, request token inputs: authorization token, team id in metadata "team-id".
+	// every request is answered by one response in the same order.
+	BatchCheck(ctx context.Context, opts ...grpc.CallOption) (AccessControlService_BatchCheckClient, error)
+}
+
+type accessControlServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAccessControlServiceClient(cc grpc.ClientConnInterface) AccessControlServiceClient {
+	return &accessControlServiceClient{cc}
+}
+
+func (c *accessControlServiceClient) ValidateAccount(ctx context.Context, in *ValidateAccountRequest, opts ...grpc.CallOption) (*ValidateAccountResponse, error) {
+	out := new(ValidateAccountResponse)
+	err := c.cc.Invoke(ctx, AccessControlService_ValidateAccount_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accessControlServiceClient) CanAccess(ctx context.Context, in *AttributeCheckRequest, opts ...grpc.CallOption) (*AttributeCheckResponse, error) {
+	out := new(AttributeCheckResponse)
+	err := c.cc.Invoke(ctx, AccessControlService_CanAccess_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accessControlServiceClient) CanManage(ctx context.Context, in *AttributeCheckRequest, opts ...grpc.CallOption) (*AttributeCheckResponse, error) {
+	out := new(AttributeCheckResponse)
+	err := c.cc.Invoke(ctx, AccessControlService_CanManage_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accessControlServiceClient) CanManageSpecial(ctx context.Context, in *AttributeCheckRequest, opts ...grpc.CallOption) (*AttributeCheckResponse, error) {
+	out := new(AttributeCheckResponse)
+	err := c.cc.Invoke(ctx, AccessControlService_CanManageSpecial_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accessControlServiceClient) CanDelete(ctx context.Context, in *AttributeCheckRequest, opts ...grpc.CallOption) (*AttributeCheckResponse, error) {
+	out := new(AttributeCheckResponse)
+	err := c.cc.Invoke(ctx, AccessControlService_CanDelete_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accessControlServiceClient) CanModify(ctx context.Context, in *AttributeCheckRequest, opts ...grpc.CallOption) (*AttributeCheckResponse, error) {
+	out := new(AttributeCheckResponse)
+	err := c.cc.Invoke(ctx, AccessControlService_CanModify_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *accessControlServiceClient) BatchCheck(ctx context.Context, opts ...grpc.CallOption) (AccessControlService_BatchCheckClient, error) {
+	stream, err := c.cc.NewStream(ctx, &AccessControlService_ServiceDesc.Streams[0], AccessControlService_BatchCheck_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &accessControlServiceBatchCheckClient{stream}
+	return x, nil
+}
+
+type AccessControlService_BatchCheckClient interface {
+	Send(*BatchCheckRequest) error
+	Recv() (*BatchCheckResponse, error)
+	grpc.ClientStream
+}
+
+type accessControlServiceBatchCheckClient struct {
+	grpc.ClientStream
+}
+
+func (x *accessControlServiceBatchCheckClient) Send(m *BatchCheckRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *accessControlServiceBatchCheckClient) Recv() (*BatchCheckResponse, error) {
+	m := new(BatchCheckResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// AccessControlServiceServer is the server API for AccessControlService service.
+// All implementations must embed UnimplementedAccessControlServiceServer
+// for forward compatibility
+type AccessControlServiceServer interface {
+	// request token inputs: authorization token
+	ValidateAccount(context.Context, *ValidateAccountRequest) (*ValidateAccountResponse, error)
+	// request token inputs: authorization token, team id, unit type, unit id, attribute id
+	CanAccess(context.Context, *AttributeCheckRequest) (*AttributeCheckResponse, error)
+	CanManage(context.Context, *AttributeCheckRequest) (*AttributeCheckResponse, error)
+	CanManageSpecial(context.Context, *AttributeCheckRequest) (*AttributeCheckResponse, error)
+	CanDelete(context.Context, *AttributeCheckRequest) (*AttributeCheckResponse, error)
+	// request token inputs: authorization token, team id, unit type, unit id, attribute id, from id, to id
+	CanModify(context.Context, *AttributeCheckRequest) (*AttributeCheckResponse, error)
+	// the stream is signed once when it is opened, request token inputs: authorization token, team id in metadata "team-id".
+	// every request is answered by one response in the same order.
+	BatchCheck(AccessControlService_BatchCheckServer) error
+	mustEmbedUnimplementedAccessControlServiceServer()
+}
+
+// UnimplementedAccessControlServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedAccessControlServiceServer struct {
+}
+
+func (UnimplementedAccessControlServiceServer) ValidateAccount(context.Context, *ValidateAccountRequest) (*ValidateAccountResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValidateAccount not implemented")
+}
+func (UnimplementedAccessControlServiceServer) CanAccess(context.Context, *AttributeCheckRequest) (*AttributeCheckResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CanAccess not implemented")
+}
+func (UnimplementedAccessControlServiceServer) CanManage(context.Context, *AttributeCheckRequest) (*AttributeCheckResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CanManage not implemented")
+}
+func (UnimplementedAccessControlServiceServer) CanManageSpecial(context.Context, *AttributeCheckRequest) (*AttributeCheckResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CanManageSpecial not implemented")
+}
+func (UnimplementedAccessControlServiceServer) CanDelete(context.Context, *AttributeCheckRequest) (*AttributeCheckResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CanDelete not implemented")
+}
+func (UnimplementedAccessControlServiceServer) CanModify(context.Context, *AttributeCheckRequest) (*AttributeCheckResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CanModify not implemented")
+}
+func (UnimplementedAccessControlServiceServer) BatchCheck(AccessControlService_BatchCheckServer) error {
+	return status.Errorf(codes.Unimplemented, "method BatchCheck not implemented")
+}
+func (UnimplementedAccessControlServiceServer) mustEmbedUnimplementedAccessControlServiceServer() {}
+
+// UnsafeAccessControlServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AccessControlServiceServer will
+// result in compilation errors.
+type UnsafeAccessControlServiceServer interface {
+	mustEmbedUnimplementedAccessControlServiceServer()
+}
+
+func RegisterAccessControlServiceServer(s grpc.ServiceRegistrar, srv AccessControlServiceServer) {
+	s.RegisterService(&AccessControlService_ServiceDesc, srv)
+}
+
+func _AccessControlService_ValidateAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateAccountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccessControlServiceServer).ValidateAccount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccessControlService_ValidateAccount_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccessControlServiceServer).ValidateAccount(ctx, req.(*ValidateAccountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccessControlService_CanAccess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttributeCheckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccessControlServiceServer).CanAccess(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccessControlService_CanAccess_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccessControlServiceServer).CanAccess(ctx, req.(*AttributeCheckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccessControlService_CanManage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttributeCheckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccessControlServiceServer).CanManage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccessControlService_CanManage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccessControlServiceServer).CanManage(ctx, req.(*AttributeCheckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccessControlService_CanManageSpecial_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttributeCheckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccessControlServiceServer).CanManageSpecial(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccessControlService_CanManageSpecial_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccessControlServiceServer).CanManageSpecial(ctx, req.(*AttributeCheckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccessControlService_CanDelete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttributeCheckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccessControlServiceServer).CanDelete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccessControlService_CanDelete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccessControlServiceServer).CanDelete(ctx, req.(*AttributeCheckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccessControlService_CanModify_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttributeCheckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccessControlServiceServer).CanModify(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccessControlService_CanModify_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccessControlServiceServer).CanModify(ctx, req.(*AttributeCheckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AccessControlService_BatchCheck_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AccessControlServiceServer).BatchCheck(&accessControlServiceBatchCheckServer{stream})
+}
+
+type AccessControlService_BatchCheckServer interface {
+	Send(*BatchCheckResponse) error
+	Recv() (*BatchCheckRequest, error)
+	grpc.ServerStream
+}
+
+type accessControlServiceBatchCheckServer struct {
+	grpc.ServerStream
+}
+
+func (x *accessControlServiceBatchCheckServer) Send(m *BatchCheckResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *accessControlServiceBatchCheckServer) Recv() (*BatchCheckRequest, error) {
+	m := new(BatchCheckRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// AccessControlService_ServiceDesc is the grpc.ServiceDesc for AccessControlService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AccessControlService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "kozmo.supervisor.internal.v1.AccessControlService",
+	HandlerType: (*AccessControlServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ValidateAccount",
+			Handler:    _AccessControlService_ValidateAccount_Handler,
+		},
+		{
+			MethodName: "CanAccess",
+			Handler:    _AccessControlService_CanAccess_Handler,
+		},
+		{
+			MethodName: "CanManage",
+			Handler:    _AccessControlService_CanManage_Handler,
+		},
+		{
+			MethodName: "CanManageSpecial",
+			Handler:    _AccessControlService_CanManageSpecial_Handler,
+		},
+		{
+			MethodName: "CanDelete",
+			Handler:    _AccessControlService_CanDelete_Handler,
+		},
+		{
+			MethodName: "CanModify",
+			Handler:    _AccessControlService_CanModify_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "BatchCheck",
+			Handler:       _AccessControlService_BatchCheck_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "internal.proto",
+}
+
+const (
+	DataControlService_GetUser_FullMethodName             = "/kozmo.supervisor.internal.v1.DataControlService/GetUser"
+	DataControlService_GetUsers_FullMethodName            = "/kozmo.supervisor.internal.v1.DataControlService/GetUsers"
+	DataControlService_GetTeamByIdentifier_FullMethodName = "/kozmo.supervisor.internal.v1.DataControlService/GetTeamByIdentifier"
+)
+
+// DataControlServiceClient is the client API for DataControlService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type DataControlServiceClient interface {
+	// request token inputs: target user id
+	GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*User, error)
+	// request token inputs: target user ids joined by ","
+	GetUsers(ctx context.Context, in *GetUsersRequest, opts ...grpc.CallOption) (*GetUsersResponse, error)
+	// request token inputs: team identifier
+	GetTeamByIdentifier(ctx context.Context, in *GetTeamByIdentifierRequest, opts ...grpc.CallOption) (*Team, error)
+}
+
+type dataControlServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDataControlServiceClient(cc grpc.ClientConnInterface) DataControlServiceClient {
+	return &dataControlServiceClient{cc}
+}
+
+func (c *dataControlServiceClient) GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*User, error) {
+	out := new(User)
+	err := c.cc.Invoke(ctx, DataControlService_GetUser_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataControlServiceClient) GetUsers(ctx context.Context, in *GetUsersRequest, opts ...grpc.CallOption) (*GetUsersResponse, error) {
+	out := new(GetUsersResponse)
+	err := c.cc.Invoke(ctx, DataControlService_GetUsers_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *dataControlServiceClient) GetTeamByIdentifier(ctx context.Context, in *GetTeamByIdentifierRequest, opts ...grpc.CallOption) (*Team, error) {
+	out := new(Team)
+	err := c.cc.Invoke(ctx, DataControlService_GetTeamByIdentifier_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DataControlServiceServer is the server API for DataControlService service.
+// All implementations must embed UnimplementedDataControlServiceServer
+// for forward compatibility
+type DataControlServiceServer interface {
+	// request token inputs: target user id
+	GetUser(context.Context, *GetUserRequest) (*User, error)
+	// request token inputs: target user ids joined by ","
+	GetUsers(context.Context, *GetUsersRequest) (*GetUsersResponse, error)
+	// request token inputs: team identifier
+	GetTeamByIdentifier(context.Context, *GetTeamByIdentifierRequest) (*Team, error)
+	mustEmbedUnimplementedDataControlServiceServer()
+}
+
+// UnimplementedDataControlServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedDataControlServiceServer struct {
+}
+
+func (UnimplementedDataControlServiceServer) GetUser(context.Context, *GetUserRequest) (*User, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUser not implemented")
+}
+func (UnimplementedDataControlServiceServer) GetUsers(context.Context, *GetUsersRequest) (*GetUsersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUsers not implemented")
+}
+func (UnimplementedDataControlServiceServer) GetTeamByIdentifier(context.Context, *GetTeamByIdentifierRequest) (*Team, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTeamByIdentifier not implemented")
+}
+func (UnimplementedDataControlServiceServer) mustEmbedUnimplementedDataControlServiceServer() {}
+
+// UnsafeDataControlServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DataControlServiceServer will
+// result in compilation errors.
+type UnsafeDataControlServiceServer interface {
+	mustEmbedUnimplementedDataControlServiceServer()
+}
+
+func RegisterDataControlServiceServer(s grpc.ServiceRegistrar, srv DataControlServiceServer) {
+	s.RegisterService(&DataControlService_ServiceDesc, srv)
+}
+
+func _DataControlService_GetUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataControlServiceServer).GetUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataControlService_GetUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataControlServiceServer).GetUser(ctx, req.(*GetUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataControlService_GetUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataControlServiceServer).GetUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataControlService_GetUsers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataControlServiceServer).GetUsers(ctx, req.(*GetUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DataControlService_GetTeamByIdentifier_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTeamByIdentifierRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataControlServiceServer).GetTeamByIdentifier(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataControlService_GetTeamByIdentifier_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataControlServiceServer).GetTeamByIdentifier(ctx, req.(*GetTeamByIdentifierRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// DataControlService_ServiceDesc is the grpc.ServiceDesc for DataControlService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DataControlService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "kozmo.supervisor.internal.v1.DataControlService",
+	HandlerType: (*DataControlServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetUser",
+			Handler:    _DataControlService_GetUser_Handler,
+		},
+		{
+			MethodName: "GetUsers",
+			Handler:    _DataControlService_GetUsers_Handler,
+		},
+		{
+			MethodName: "GetTeamByIdentifier",
+			Handler:    _DataControlService_GetTeamByIdentifier_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "internal.proto",
+}
@@ -0,0 +1,296 @@
+package internalrpc
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/controller"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/internalrpc/internalrpcpb"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/mtls"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/tokenvalidator"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// metadata keys, same as the REST headers of the internal routers.
+const (
+	METADATA_REQUEST_TOKEN       = "request-token"
+	METADATA_REQUEST_TIMESTAMP   = "request-timestamp"
+	METADATA_REQUEST_NONCE       = "request-nonce"
+	METADATA_AUTHORIZATION_TOKEN = "authorization-token"
+	METADATA_TEAM_ID             = "team-id"
+)
+
+// Server serves the internal access-control and data-control services over gRPC,
+// all of the evaluation is delegated to the controller shared with the REST handlers.
+type Server struct {
+	internalrpcpb.UnimplementedAccessControlServiceServer
+	internalrpcpb.UnimplementedDataControlServiceServer
+	Controller *controller.Controller
+	logger     *zap.SugaredLogger
+}
+
+func NewServer(c *controller.Controller, logger *zap.SugaredLogger) *Server {
+	return &Server{
+		Controller: c,
+		logger:     logger,
+	}
+}
+
+// NewGRPCServer build a grpc server with both of the internal services registered.
+func NewGRPCServer(c *controller.Controller, logger *zap.SugaredLogger, opts ...grpc.ServerOption) *grpc.Server {
+	grpcServer := grpc.NewServer(opts...)
+	server := NewServer(c, logger)
+	internalrpcpb.RegisterAccessControlServiceServer(grpcServer, server)
+	internalrpcpb.RegisterDataControlServiceServer(grpcServer, server)
+	return grpcServer
+}
+
+func (server *Server) ValidateAccount(ctx context.Context, req *internalrpcpb.ValidateAccountRequest) (*internalrpcpb.ValidateAccountResponse, error) {
+	authorizationToken, err := getMetadataValue(ctx, METADATA_AUTHORIZATION_TOKEN)
+	if err != nil {
+		return nil, err
+	}
+
+	// validate request data
+	if err := server.validateRequestToken(ctx, authorizationToken); err != nil {
+		return nil, err
+	}
+
+	// validate account
+	if err := server.Controller.ValidateAccountByToken(authorizationToken); err != nil {
+		return nil, convertFeedbackErrorToStatus(err)
+	}
+	return &internalrpcpb.ValidateAccountResponse{}, nil
+}
+
+func (server *Server) CanAccess(ctx context.Context, req *internalrpcpb.AttributeCheckRequest) (*internalrpcpb.AttributeCheckResponse, error) {
+	return server.checkAttribute(ctx, model.ACCESS_CONTROL_CHECK_CATEGORY_ACCESS, req)
+}
+
+func (server *Server) CanManage(ctx context.Context, req *internalrpcpb.AttributeCheckRequest) (*internalrpcpb.AttributeCheckResponse, error) {
+	return server.checkAttribute(ctx, model.ACCESS_CONTROL_CHECK_CATEGORY_MANAGE, req)
+}
+
+func (server *Server) CanManageSpecial(ctx context.Context, req *internalrpcpb.AttributeCheckRequest) (*internalrpcpb.AttributeCheckResponse, error) {
+	return server.checkAttribute(ctx, model.ACCESS_CONTROL_CHECK_CATEGORY_MANAGE_SPECIAL, req)
+}
+
+func (server *Server) CanDelete(ctx context.Context, req *internalrpcpb.AttributeCheckRequest) (*internalrpcpb.AttributeCheckResponse, error) {
+	return server.checkAttribute(ctx, model.ACCESS_CONTROL_CHECK_CATEGORY_DELETE, req)
+}
+
+func (server *Server) CanModify(ctx context.Context, req *internalrpcpb.AttributeCheckRequest) (*internalrpcpb.AttributeCheckResponse, error) {
+	return server.checkAttribute(ctx, model.ACCESS_CONTROL_CHECK_CATEGORY_MODIFY, req)
+}
+
+// checkAttribute is the shared path of the single check rpcs, an evaluated denial is feedback as allowed=false instead of an error.
+func (server *Server) checkAttribute(ctx context.Context, category string, req *internalrpcpb.AttributeCheckRequest) (*internalrpcpb.AttributeCheckResponse, error) {
+	authorizationToken, err := getMetadataValue(ctx, METADATA_AUTHORIZATION_TOKEN)
+	if err != nil {
+		return nil, err
+	}
+	check := convertAccessControlCheck(category, req.GetUnitType(), req.GetUnitId(), req.GetAttributeId(), req.GetFromId(), req.GetToId())
+
+	// validate request data
+	inputs := []string{authorizationToken, req.GetTeamId(), req.GetUnitType(), req.GetUnitId(), req.GetAttributeId()}
+	if check.IsModify() {
+		inputs = append(inputs, req.GetFromId(), req.GetToId())
+	}
+	if err := server.validateRequestToken(ctx, inputs...); err != nil {
+		return nil, err
+	}
+
+	userID, errInResolvePrincipal := controller.ResolvePrincipal(authorizationToken)
+	if errInResolvePrincipal != nil {
+		return nil, status.Error(codes.Unauthenticated, "validate authorization token error: "+errInResolvePrincipal.Error())
+	}
+	teamID := model.TEAM_DEFAULT_ID
+	unitType, attributeID, unitID, fromID, toID, errInExport := exportAccessControlCheck(check)
+	if errInExport != nil {
+		return nil, status.Error(codes.InvalidArgument, "validate request param error: "+errInExport.Error())
+	}
+
+	// check attribute
+	allowed, errInCheck := server.Controller.CheckAccessControl(teamID, userID, category, unitType, unitID, attributeID, fromID, toID)
+	if errInCheck != nil {
+		return nil, convertFeedbackErrorToStatus(errInCheck)
+	}
+	return &internalrpcpb.AttributeCheckResponse{Allowed: allowed}, nil
+}
+
+func (server *Server) BatchCheck(stream internalrpcpb.AccessControlService_BatchCheckServer) error {
+	ctx := stream.Context()
+	authorizationToken, err := getMetadataValue(ctx, METADATA_AUTHORIZATION_TOKEN)
+	if err != nil {
+		return err
+	}
+	teamIDString, err := getMetadataValue(ctx, METADATA_TEAM_ID)
+	if err != nil {
+		return err
+	}
+
+	// validate request data
+	if err := server.validateRequestToken(ctx, authorizationToken, teamIDString); err != nil {
+		return err
+	}
+
+	userID, errInResolvePrincipal := controller.ResolvePrincipal(authorizationToken)
+	if errInResolvePrincipal != nil {
+		return status.Error(codes.Unauthenticated, "validate authorization token error: "+errInResolvePrincipal.Error())
+	}
+	teamID := model.TEAM_DEFAULT_ID
+
+	// evaluate every batch on arrival, membership is looked up per batch so role changes apply to long-lived streams
+	for {
+		req, errInRecv := stream.Recv()
+		if errInRecv == io.EOF {
+			return nil
+		}
+		if errInRecv != nil {
+			return errInRecv
+		}
+
+		// validate payload required fields
+		batchReq := model.NewBatchAccessControlRequest()
+		for _, check := range req.GetChecks() {
+			batchReq.Checks = append(batchReq.Checks, convertAccessControlCheck(check.GetCategory(), check.GetUnitType(), check.GetUnitId(), check.GetAttributeId(), check.GetFromId(), check.GetToId()))
+		}
+		if err := validator.New().Struct(batchReq); err != nil {
+			return status.Error(codes.InvalidArgument, "parse request body error: "+err.Error())
+		}
+
+		// check attributes
+		resp, errInCheck := server.Controller.CheckAccessControlBatch(teamID, userID, batchReq.Checks)
+		if errInCheck != nil {
+			return convertFeedbackErrorToStatus(errInCheck)
+		}
+		if err := stream.Send(convertBatchAccessControlResponse(resp)); err != nil {
+			return err
+		}
+	}
+}
+
+func (server *Server) GetUser(ctx context.Context, req *internalrpcpb.GetUserRequest) (*internalrpcpb.User, error) {
+	targetUserID, errInConvert := model.ConvertMagicStringToInt(req.GetTargetUserId())
+	if errInConvert != nil {
+		return nil, status.Error(codes.InvalidArgument, "validate request param error: "+errInConvert.Error())
+	}
+
+	// validate request data
+	if err := server.validateRequestToken(ctx, req.GetTargetUserId()); err != nil {
+		return nil, err
+	}
+
+	// fetch target user info
+	resp, err := server.Controller.RetrieveTargetUser(targetUserID)
+	if err != nil {
+		return nil, convertFeedbackErrorToStatus(err)
+	}
+	return convertUser(resp), nil
+}
+
+func (server *Server) GetUsers(ctx context.Context, req *internalrpcpb.GetUsersRequest) (*internalrpcpb.GetUsersResponse, error) {
+	targetUserIDs := make([]int, 0, len(req.GetTargetUserIds()))
+	for _, idInString := range req.GetTargetUserIds() {
+		idInInt, errInConvert := strconv.Atoi(idInString)
+		if errInConvert != nil {
+			return nil, status.Error(codes.InvalidArgument, "validate request param error: invalid user id "+idInString)
+		}
+		targetUserIDs = append(targetUserIDs, idInInt)
+	}
+	pagination, errInGetPagination := model.NewOptionalPaginationByRequest(req.GetCursor(), req.GetLimit(), req.GetSortKey(), req.GetDirection(), model.PAGINATION_SORT_KEY_ID, model.PAGINATION_DIRECTION_ASC)
+	if errInGetPagination != nil {
+		return nil, status.Error(codes.InvalidArgument, "validate pagination param error: "+errInGetPagination.Error())
+	}
+
+	// validate request data
+	if err := server.validateRequestToken(ctx, strings.Join(req.GetTargetUserIds(), ",")); err != nil {
+		return nil, err
+	}
+
+	// fetch target user info
+	resp, err := server.Controller.RetrieveTargetUsers(targetUserIDs, pagination)
+	if err != nil {
+		return nil, convertFeedbackErrorToStatus(err)
+	}
+	return convertGetUsersResponse(resp), nil
+}
+
+func (server *Server) GetTeamByIdentifier(ctx context.Context, req *internalrpcpb.GetTeamByIdentifierRequest) (*internalrpcpb.Team, error) {
+	if req.GetIdentifier() == "" {
+		return nil, status.Error(codes.InvalidArgument, "please input param for request.")
+	}
+
+	// validate request data
+	if err := server.validateRequestToken(ctx, req.GetIdentifier()); err != nil {
+		return nil, err
+	}
+
+	// fetch target team info
+	resp, err := server.Controller.RetrieveTargetTeamByIdentifier(req.GetIdentifier())
+	if err != nil {
+		return nil, convertFeedbackErrorToStatus(err)
+	}
+	return convertTeam(resp), nil
+}
+
+// validateRequestToken validate the request token in metadata, the signed method and path are "POST" and the full rpc method name.
+func (server *Server) validateRequestToken(ctx context.Context, inputs ...string) error {
+	token, err := getMetadataValue(ctx, METADATA_REQUEST_TOKEN)
+	if err != nil {
+		return err
+	}
+	fullMethod, _ := grpc.Method(ctx)
+	signedRequest := &tokenvalidator.SignedRequest{
+		Method:    http.MethodPost,
+		Path:      fullMethod,
+		Params:    inputs,
+		Timestamp: getOptionalMetadataValue(ctx, METADATA_REQUEST_TIMESTAMP),
+		Nonce:     getOptionalMetadataValue(ctx, METADATA_REQUEST_NONCE),
+		Token:     token,
+	}
+	if err := server.Controller.RequestTokenValidator.ValidateSignedRequest(signedRequest); err != nil {
+		server.logger.Warnw("illegal internal rpc request token detected", "token", token, "callingService", getCallingService(ctx), "err", err)
+		return status.Error(codes.Unauthenticated, controller.ERROR_FLAG_VALIDATE_REQUEST_TOKEN_FAILED+": "+err.Error())
+	}
+	return nil
+}
+
+func getMetadataValue(ctx context.Context, key string) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", status.Error(codes.InvalidArgument, "missing metadata.")
+	}
+	values := md.Get(key)
+	if len(values) == 0 || values[0] == "" {
+		return "", status.Error(codes.InvalidArgument, "missing metadata \""+key+"\".")
+	}
+	return values[0], nil
+}
+
+func getOptionalMetadataValue(ctx context.Context, key string) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// getCallingService feedback the calling service resolved from client certificate, it is empty when the internal port is not serving mTLS.
+func getCallingService(ctx context.Context) string {
+	identity := mtls.ClientIdentityFromContext(ctx)
+	if identity == nil {
+		return ""
+	}
+	return identity.Service
+}
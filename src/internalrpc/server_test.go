@@ -0,0 +1,265 @@
+package internalrpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/accesscontrol"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/authenticator"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/controller"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/internal/testdb"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/internalrpc/internalrpcpb"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/idconvertor"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/tokenvalidator"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type testServer struct {
+	controller    *controller.Controller
+	accessControl internalrpcpb.AccessControlServiceClient
+	dataControl   internalrpcpb.DataControlServiceClient
+}
+
+// newTestServer serve both of the services for the self-host team on a loopback listener.
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	db := testdb.NewDB(t)
+	team := &model.Team{Name: "my-team", Identifier: "0", Permission: model.NewTeamPermission().ExportForTeam()}
+	if err := db.Create(team).Error; err != nil {
+		t.Fatalf("create team failed: %v", err)
+	}
+	// the generated id starts from 1, move it to the default team id
+	if err := db.Model(&model.Team{}).Where("id = ?", team.ID).Update("id", model.TEAM_DEFAULT_ID).Error; err != nil {
+		t.Fatalf("update team id failed: %v", err)
+	}
+	c := &controller.Controller{Storage: model.NewStorage(db, zap.NewNop().Sugar()), Cache: testdb.NewCache(t), Logger: zap.NewNop().Sugar()}
+	c.Authenticator = authenticator.NewAuthenticator(c.Storage, c.Cache, zap.NewNop().Sugar())
+	c.RequestTokenValidator = tokenvalidator.NewRequestTokenValidator(c.Cache.RequestNonceCache)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen failed: %v", err)
+	}
+	grpcServer := NewGRPCServer(c, zap.NewNop().Sugar())
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &testServer{
+		controller:    c,
+		accessControl: internalrpcpb.NewAccessControlServiceClient(conn),
+		dataControl:   internalrpcpb.NewDataControlServiceClient(conn),
+	}
+}
+
+// signedContext sign the inputs for the rpc method, the extra metadata pairs are attached as they are.
+func (s *testServer) signedContext(fullMethod string, inputs []string, pairs ...string) context.Context {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	nonce := uuid.NewString()
+	token := s.controller.RequestTokenValidator.GenerateSignedToken(http.MethodPost, fullMethod, inputs, timestamp, nonce)
+	pairs = append(pairs, METADATA_REQUEST_TOKEN, token, METADATA_REQUEST_TIMESTAMP, timestamp, METADATA_REQUEST_NONCE, nonce)
+	return metadata.NewOutgoingContext(context.Background(), metadata.Pairs(pairs...))
+}
+
+// signInTestMember create the user as a member of the default team, and feedback the access token of its sign in session.
+func (s *testServer) signInTestMember(t *testing.T, email string, userRole int) (*model.User, string) {
+	t.Helper()
+	user := &model.User{Nickname: strings.Split(email, "@")[0], Email: email}
+	user.InitUID()
+	if _, err := s.controller.Storage.UserStorage.Create(user); err != nil {
+		t.Fatalf("create user failed: %v", err)
+	}
+	teamMember := model.NewTeamMember()
+	teamMember.TeamID = model.TEAM_DEFAULT_ID
+	teamMember.UserID = user.ID
+	teamMember.UserRole = userRole
+	teamMember.Status = model.TEAM_MEMBER_STATUS_OK
+	teamMember.InitCreatedAt()
+	teamMember.InitUpdatedAt()
+	if _, err := s.controller.Storage.TeamMemberStorage.Create(teamMember); err != nil {
+		t.Fatalf("create team member failed: %v", err)
+	}
+	accessToken, err := authenticator.CreateAccessToken(user.ID, user.UID)
+	if err != nil {
+		t.Fatalf("create access token failed: %v", err)
+	}
+	expiresAt, err := authenticator.ExtractExpiresAtFromTokenInString(accessToken)
+	if err != nil {
+		t.Fatalf("extract expires at failed: %v", err)
+	}
+	if err := s.controller.Cache.JWTCache.InitUserJWTTokenExpiredAt(user, expiresAt); err != nil {
+		t.Fatalf("sign in failed: %v", err)
+	}
+	return user, accessToken
+}
+
+func newTestAttributeCheckRequest(unitType int, attributeID int) *internalrpcpb.AttributeCheckRequest {
+	return &internalrpcpb.AttributeCheckRequest{
+		TeamId:      idconvertor.ConvertIntToString(model.TEAM_DEFAULT_ID),
+		UnitType:    idconvertor.ConvertIntToString(unitType),
+		UnitId:      idconvertor.ConvertIntToString(42),
+		AttributeId: idconvertor.ConvertIntToString(attributeID),
+	}
+}
+
+func TestCheckAttribute(t *testing.T) {
+	s := newTestServer(t)
+	_, editorToken := s.signInTestMember(t, "editor@acme.com", model.USER_ROLE_EDITOR)
+	const fullMethod = "/kozmo.supervisor.internal.v1.AccessControlService/CanManage"
+	canManage := func(ctx context.Context, req *internalrpcpb.AttributeCheckRequest) (bool, error) {
+		resp, err := s.accessControl.CanManage(ctx, req)
+		return resp.GetAllowed(), err
+	}
+	signedCanManage := func(authorizationToken string, req *internalrpcpb.AttributeCheckRequest) (bool, error) {
+		inputs := []string{authorizationToken, req.TeamId, req.UnitType, req.UnitId, req.AttributeId}
+		return canManage(s.signedContext(fullMethod, inputs, METADATA_AUTHORIZATION_TOKEN, authorizationToken), req)
+	}
+
+	// an evaluated denial is not an error
+	allowed, err := signedCanManage(editorToken, newTestAttributeCheckRequest(accesscontrol.UNIT_TYPE_APP, accesscontrol.ACTION_MANAGE_EDIT_APP))
+	if err != nil || !allowed {
+		t.Errorf("editor edit app = %v, %v, want allowed", allowed, err)
+	}
+	allowed, err = signedCanManage(editorToken, newTestAttributeCheckRequest(accesscontrol.UNIT_TYPE_TEAM, accesscontrol.ACTION_MANAGE_TEAM_NAME))
+	if err != nil || allowed {
+		t.Errorf("editor rename team = %v, %v, want denied", allowed, err)
+	}
+
+	// the request token is validated before the authorization token is looked at
+	req := newTestAttributeCheckRequest(accesscontrol.UNIT_TYPE_APP, accesscontrol.ACTION_MANAGE_EDIT_APP)
+	unsigned := metadata.NewOutgoingContext(context.Background(), metadata.Pairs(METADATA_AUTHORIZATION_TOKEN, "malformed-token", METADATA_REQUEST_TOKEN, "forged"))
+	_, err = canManage(unsigned, req)
+	if status.Code(err) != codes.Unauthenticated || !strings.Contains(status.Convert(err).Message(), controller.ERROR_FLAG_VALIDATE_REQUEST_TOKEN_FAILED) {
+		t.Errorf("forged request token = %v, want %s", err, controller.ERROR_FLAG_VALIDATE_REQUEST_TOKEN_FAILED)
+	}
+	_, err = signedCanManage("malformed-token", req)
+	if status.Code(err) != codes.Unauthenticated || !strings.Contains(status.Convert(err).Message(), "validate authorization token error") {
+		t.Errorf("malformed authorization token = %v, want the authorization token rejected", err)
+	}
+	_, err = signedCanManage(editorToken, &internalrpcpb.AttributeCheckRequest{TeamId: req.TeamId, UnitType: "malformed", UnitId: req.UnitId, AttributeId: req.AttributeId})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("malformed unit type = %v, want %s", err, codes.InvalidArgument)
+	}
+	if _, err := canManage(context.Background(), req); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("missing metadata = %v, want %s", err, codes.InvalidArgument)
+	}
+}
+
+func TestBatchCheck(t *testing.T) {
+	s := newTestServer(t)
+	_, editorToken := s.signInTestMember(t, "editor@acme.com", model.USER_ROLE_EDITOR)
+	const fullMethod = "/kozmo.supervisor.internal.v1.AccessControlService/BatchCheck"
+	teamID := idconvertor.ConvertIntToString(model.TEAM_DEFAULT_ID)
+	newCheck := func(category string, unitType int, attributeID int) *internalrpcpb.AccessControlCheck {
+		return &internalrpcpb.AccessControlCheck{
+			Category:    category,
+			UnitType:    idconvertor.ConvertIntToString(unitType),
+			UnitId:      idconvertor.ConvertIntToString(42),
+			AttributeId: idconvertor.ConvertIntToString(attributeID),
+		}
+	}
+
+	ctx := s.signedContext(fullMethod, []string{editorToken, teamID}, METADATA_AUTHORIZATION_TOKEN, editorToken, METADATA_TEAM_ID, teamID)
+	stream, err := s.accessControl.BatchCheck(ctx)
+	if err != nil {
+		t.Fatalf("open stream failed: %v", err)
+	}
+	batches := []struct {
+		checks []*internalrpcpb.AccessControlCheck
+		want   []bool
+	}{
+		{
+			[]*internalrpcpb.AccessControlCheck{
+				newCheck(model.ACCESS_CONTROL_CHECK_CATEGORY_MANAGE, accesscontrol.UNIT_TYPE_APP, accesscontrol.ACTION_MANAGE_EDIT_APP),
+				newCheck(model.ACCESS_CONTROL_CHECK_CATEGORY_MANAGE, accesscontrol.UNIT_TYPE_TEAM, accesscontrol.ACTION_MANAGE_TEAM_NAME),
+			},
+			[]bool{true, false},
+		},
+		{
+			[]*internalrpcpb.AccessControlCheck{
+				newCheck(model.ACCESS_CONTROL_CHECK_CATEGORY_ACCESS, accesscontrol.UNIT_TYPE_APP, accesscontrol.ACTION_ACCESS_VIEW),
+			},
+			[]bool{true},
+		},
+	}
+	// every batch is answered on arrival, in the order of its checks
+	for i, batch := range batches {
+		if err := stream.Send(&internalrpcpb.BatchCheckRequest{Checks: batch.checks}); err != nil {
+			t.Fatalf("send batch %d failed: %v", i, err)
+		}
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("receive batch %d failed: %v", i, err)
+		}
+		if len(resp.GetResults()) != len(batch.want) {
+			t.Fatalf("batch %d results = %v, want %d", i, resp.GetResults(), len(batch.want))
+		}
+		for j, result := range resp.GetResults() {
+			if result.GetAllowed() != batch.want[j] || result.GetError() != "" {
+				t.Errorf("batch %d check %d = %v, want allowed %v", i, j, result, batch.want[j])
+			}
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatalf("close stream failed: %v", err)
+	}
+	if _, err := stream.Recv(); err != io.EOF {
+		t.Errorf("stream end = %v, want EOF", err)
+	}
+
+	// the stream is rejected by the forged request token before any batch is evaluated
+	forged := metadata.NewOutgoingContext(context.Background(), metadata.Pairs(METADATA_AUTHORIZATION_TOKEN, "malformed-token", METADATA_TEAM_ID, teamID, METADATA_REQUEST_TOKEN, "forged"))
+	stream, err = s.accessControl.BatchCheck(forged)
+	if err != nil {
+		t.Fatalf("open stream failed: %v", err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.Unauthenticated || !strings.Contains(status.Convert(err).Message(), controller.ERROR_FLAG_VALIDATE_REQUEST_TOKEN_FAILED) {
+		t.Errorf("forged request token = %v, want %s", err, controller.ERROR_FLAG_VALIDATE_REQUEST_TOKEN_FAILED)
+	}
+}
+
+func TestGetUser(t *testing.T) {
+	s := newTestServer(t)
+	user, _ := s.signInTestMember(t, "member@acme.com", model.USER_ROLE_VIEWER)
+	const fullMethod = "/kozmo.supervisor.internal.v1.DataControlService/GetUser"
+	getUser := func(targetUserID string) (*internalrpcpb.User, error) {
+		return s.dataControl.GetUser(s.signedContext(fullMethod, []string{targetUserID}), &internalrpcpb.GetUserRequest{TargetUserId: targetUserID})
+	}
+
+	resp, err := getUser(idconvertor.ConvertIntToString(user.ID))
+	if err != nil {
+		t.Fatalf("get user failed: %v", err)
+	}
+	if resp.GetUid() != user.UID.String() || resp.GetEmail() != user.Email || resp.GetNickname() != user.Nickname {
+		t.Errorf("user = %v, want %s", resp, user.Email)
+	}
+	if _, err := getUser(idconvertor.ConvertIntToString(user.ID + 100)); status.Code(err) != codes.NotFound {
+		t.Errorf("missing user = %v, want %s", err, codes.NotFound)
+	}
+	if _, err := getUser("malformed"); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("malformed user id = %v, want %s", err, codes.InvalidArgument)
+	}
+
+	// the request token is signed for another method
+	targetUserID := idconvertor.ConvertIntToString(user.ID)
+	ctx := s.signedContext("/kozmo.supervisor.internal.v1.DataControlService/GetUsers", []string{targetUserID})
+	if _, err := s.dataControl.GetUser(ctx, &internalrpcpb.GetUserRequest{TargetUserId: targetUserID}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("token of another method = %v, want %s", err, codes.Unauthenticated)
+	}
+}
@@ -0,0 +1,216 @@
+package model
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/idconvertor"
+)
+
+// access request ask for a target role, or a single attribute on a unit, and wait for review.
+const ACCESS_REQUEST_CATEGORY_ROLE = 1
+const ACCESS_REQUEST_CATEGORY_UNIT_ATTRIBUTE = 2
+
+const (
+	ACCESS_REQUEST_STATUS_PENDING   = 1
+	ACCESS_REQUEST_STATUS_APPROVED  = 2
+	ACCESS_REQUEST_STATUS_DENIED    = 3
+	ACCESS_REQUEST_STATUS_CANCELLED = 4
+)
+
+type AccessRequest struct {
+	ID                int       `json:"id" gorm:"column:id;type:bigserial;primary_key"`
+	UID               uuid.UUID `json:"uid" gorm:"column:uid;type:uuid;not null"`
+	TeamID            int       `json:"teamID" gorm:"column:team_id;type:bigserial;index:access_requests_team_id_and_status"`
+	TeamMemberID      int       `json:"teamMemberID" gorm:"column:team_member_id;type:bigserial"`
+	UserID            int       `json:"userID" gorm:"column:user_id;type:bigserial"`
+	Category          int       `json:"category" gorm:"column:category;type:smallint"`
+	TargetRole        int       `json:"targetRole" gorm:"column:target_role;type:bigint"`
+	UnitType          int       `json:"unitType" gorm:"column:unit_type;type:smallint"`
+	UnitID            int       `json:"unitID" gorm:"column:unit_id;type:bigint"`
+	AttributeCategory int       `json:"attributeCategory" gorm:"column:attribute_category;type:smallint"`
+	Attribute         int       `json:"attribute" gorm:"column:attribute;type:smallint"`
+	ValidUntil        time.Time `json:"validUntil" gorm:"column:valid_until;type:timestamp"`
+	Justification     string    `json:"justification" gorm:"column:justification;type:text"`
+	Status            int       `json:"status" gorm:"column:status;type:smallint;index:access_requests_team_id_and_status"`
+	ReviewerUserID    int       `json:"reviewerUserID" gorm:"column:reviewer_user_id;type:bigint"`
+	ReviewComment     string    `json:"reviewComment" gorm:"column:review_comment;type:text"`
+	ReviewedAt        time.Time `json:"reviewedAt" gorm:"column:reviewed_at;type:timestamp"`
+	CreatedAt         time.Time `gorm:"column:created_at;type:timestamp"`
+	UpdatedAt         time.Time `gorm:"column:updated_at;type:timestamp"`
+}
+
+type AccessRequestForExport struct {
+	ID                string                         `json:"accessRequestID"`
+	UID               uuid.UUID                      `json:"uid"`
+	TeamID            string                         `json:"teamID"`
+	TeamMemberID      string                         `json:"teamMemberID"`
+	UserID            string                         `json:"userID"`
+	Category          int                            `json:"category"`
+	TargetRole        int                            `json:"targetRole,omitempty"`
+	UnitType          int                            `json:"unitType,omitempty"`
+	UnitID            string                         `json:"unitID,omitempty"`
+	AttributeCategory int                            `json:"attributeCategory,omitempty"`
+	Attribute         int                            `json:"attribute,omitempty"`
+	ValidUntil        *time.Time                     `json:"validUntil,omitempty"`
+	Justification     string                         `json:"justification"`
+	Status            int                            `json:"status"`
+	ReviewerUserID    string                         `json:"reviewerUserID,omitempty"`
+	ReviewComment     string                         `json:"reviewComment,omitempty"`
+	ReviewedAt        *time.Time                     `json:"reviewedAt,omitempty"`
+	History           []*AccessRequestEventForExport `json:"history,omitempty"`
+	CreatedAt         time.Time                      `json:"createdAt"`
+	UpdatedAt         time.Time                      `json:"updatedAt"`
+}
+
+func NewAccessRequestByCreateAccessRequestRequest(teamMember *TeamMember, req *CreateAccessRequestRequest) *AccessRequest {
+	accessRequest := &AccessRequest{
+		TeamID:            teamMember.TeamID,
+		TeamMemberID:      teamMember.ExportID(),
+		UserID:            teamMember.ExportUserID(),
+		Category:          req.Category,
+		TargetRole:        req.TargetRole,
+		UnitType:          req.UnitType,
+		UnitID:            req.ExportUnitID(),
+		AttributeCategory: req.AttributeCategory,
+		Attribute:         req.Attribute,
+		Justification:     req.Justification,
+		Status:            ACCESS_REQUEST_STATUS_PENDING,
+	}
+	if req.IsTimeBound() {
+		accessRequest.ValidUntil = req.ExportValidUntil()
+	}
+	accessRequest.InitUID()
+	accessRequest.InitCreatedAt()
+	accessRequest.InitUpdatedAt()
+	return accessRequest
+}
+
+func (u *AccessRequest) InitUID() {
+	u.UID = uuid.New()
+}
+
+func (u *AccessRequest) InitCreatedAt() {
+	u.CreatedAt = time.Now().UTC()
+}
+
+func (u *AccessRequest) InitUpdatedAt() {
+	u.UpdatedAt = time.Now().UTC()
+}
+
+func (u *AccessRequest) ExportID() int {
+	return u.ID
+}
+
+func (u *AccessRequest) ExportUserID() int {
+	return u.UserID
+}
+
+func (u *AccessRequest) ExportTeamMemberID() int {
+	return u.TeamMemberID
+}
+
+func (u *AccessRequest) IsRoleRequest() bool {
+	return u.Category == ACCESS_REQUEST_CATEGORY_ROLE
+}
+
+func (u *AccessRequest) IsUnitAttributeRequest() bool {
+	return u.Category == ACCESS_REQUEST_CATEGORY_UNIT_ATTRIBUTE
+}
+
+func (u *AccessRequest) IsPending() bool {
+	return u.Status == ACCESS_REQUEST_STATUS_PENDING
+}
+
+func (u *AccessRequest) IsTimeBound() bool {
+	return !u.ValidUntil.IsZero()
+}
+
+func (u *AccessRequest) IsRequestedBy(teamMember *TeamMember) bool {
+	return u.TeamMemberID == teamMember.ExportID()
+}
+
+// ExportUnitPermissions export the requested attribute in unit role relation permissions format.
+func (u *AccessRequest) ExportUnitPermissions() UnitPermissions {
+	return UnitPermissions{u.AttributeCategory: {u.Attribute: true}}
+}
+
+func (u *AccessRequest) Approve(reviewerUserID int, comment string) {
+	u.review(ACCESS_REQUEST_STATUS_APPROVED, reviewerUserID, comment)
+}
+
+func (u *AccessRequest) Deny(reviewerUserID int, comment string) {
+	u.review(ACCESS_REQUEST_STATUS_DENIED, reviewerUserID, comment)
+}
+
+func (u *AccessRequest) Cancel() {
+	u.Status = ACCESS_REQUEST_STATUS_CANCELLED
+	u.InitUpdatedAt()
+}
+
+func (u *AccessRequest) review(status int, reviewerUserID int, comment string) {
+	u.Status = status
+	u.ReviewerUserID = reviewerUserID
+	u.ReviewComment = comment
+	u.ReviewedAt = time.Now().UTC()
+	u.InitUpdatedAt()
+}
+
+func (u *AccessRequest) Export() *AccessRequestForExport {
+	accessRequestForExport := &AccessRequestForExport{
+		ID:                idconvertor.ConvertIntToString(u.ID),
+		UID:               u.UID,
+		TeamID:            idconvertor.ConvertIntToString(u.TeamID),
+		TeamMemberID:      idconvertor.ConvertIntToString(u.TeamMemberID),
+		UserID:            idconvertor.ConvertIntToString(u.UserID),
+		Category:          u.Category,
+		TargetRole:        u.TargetRole,
+		UnitType:          u.UnitType,
+		AttributeCategory: u.AttributeCategory,
+		Attribute:         u.Attribute,
+		Justification:     u.Justification,
+		Status:            u.Status,
+		ReviewComment:     u.ReviewComment,
+		CreatedAt:         u.CreatedAt,
+		UpdatedAt:         u.UpdatedAt,
+	}
+	if u.IsUnitAttributeRequest() {
+		accessRequestForExport.UnitID = idconvertor.ConvertIntToString(u.UnitID)
+	}
+	if u.IsTimeBound() {
+		validUntil := u.ValidUntil
+		accessRequestForExport.ValidUntil = &validUntil
+	}
+	if !u.ReviewedAt.IsZero() {
+		reviewedAt := u.ReviewedAt
+		accessRequestForExport.ReviewerUserID = idconvertor.ConvertIntToString(u.ReviewerUserID)
+		accessRequestForExport.ReviewedAt = &reviewedAt
+	}
+	return accessRequestForExport
+}
+
+func (u *AccessRequest) ExportWithHistory(events []*AccessRequestEvent) *AccessRequestForExport {
+	accessRequestForExport := u.Export()
+	accessRequestForExport.History = make([]*AccessRequestEventForExport, 0, len(events))
+	for _, event := range events {
+		accessRequestForExport.History = append(accessRequestForExport.History, event.Export())
+	}
+	return accessRequestForExport
+}
+
+func ParseAccessRequestStatus(statusRaw string) (int, error) {
+	if statusRaw == "" {
+		return 0, nil
+	}
+	status, err := strconv.Atoi(statusRaw)
+	if err != nil {
+		return 0, errors.New("invalid access request status")
+	}
+	switch status {
+	case ACCESS_REQUEST_STATUS_PENDING, ACCESS_REQUEST_STATUS_APPROVED, ACCESS_REQUEST_STATUS_DENIED, ACCESS_REQUEST_STATUS_CANCELLED:
+		return status, nil
+	}
+	return 0, errors.New("invalid access request status")
+}
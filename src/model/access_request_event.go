@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/idconvertor"
+)
+
+// access request events are the audit history of an access request, they are append only.
+const (
+	ACCESS_REQUEST_ACTION_CREATE  = 1
+	ACCESS_REQUEST_ACTION_APPROVE = 2
+	ACCESS_REQUEST_ACTION_DENY    = 3
+	ACCESS_REQUEST_ACTION_CANCEL  = 4
+)
+
+type AccessRequestEvent struct {
+	ID              int       `json:"id" gorm:"column:id;type:bigserial;primary_key"`
+	TeamID          int       `json:"teamID" gorm:"column:team_id;type:bigserial"`
+	AccessRequestID int       `json:"accessRequestID" gorm:"column:access_request_id;type:bigserial;index:access_request_events_access_request_id"`
+	ActorUserID     int       `json:"actorUserID" gorm:"column:actor_user_id;type:bigserial"`
+	Action          int       `json:"action" gorm:"column:action;type:smallint"`
+	Comment         string    `json:"comment" gorm:"column:comment;type:text"`
+	CreatedAt       time.Time `gorm:"column:created_at;type:timestamp"`
+}
+
+type AccessRequestEventForExport struct {
+	ActorUserID string    `json:"actorUserID"`
+	Action      int       `json:"action"`
+	Comment     string    `json:"comment,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+func NewAccessRequestEvent(accessRequest *AccessRequest, actorUserID int, action int, comment string) *AccessRequestEvent {
+	return &AccessRequestEvent{
+		TeamID:          accessRequest.TeamID,
+		AccessRequestID: accessRequest.ExportID(),
+		ActorUserID:     actorUserID,
+		Action:          action,
+		Comment:         comment,
+		CreatedAt:       time.Now().UTC(),
+	}
+}
+
+func (u *AccessRequestEvent) Export() *AccessRequestEventForExport {
+	return &AccessRequestEventForExport{
+		ActorUserID: idconvertor.ConvertIntToString(u.ActorUserID),
+		Action:      u.Action,
+		Comment:     u.Comment,
+		CreatedAt:   u.CreatedAt,
+	}
+}
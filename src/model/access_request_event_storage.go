@@ -0,0 +1,40 @@
+package model
+
+import (
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type AccessRequestEventStorage struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewAccessRequestEventStorage(db *gorm.DB, logger *zap.SugaredLogger) *AccessRequestEventStorage {
+	return &AccessRequestEventStorage{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (d *AccessRequestEventStorage) Create(u *AccessRequestEvent) (int, error) {
+	if err := d.db.Create(u).Error; err != nil {
+		return 0, err
+	}
+	return u.ID, nil
+}
+
+func (d *AccessRequestEventStorage) RetrieveByTeamIDAndAccessRequestID(teamID int, accessRequestID int) ([]*AccessRequestEvent, error) {
+	var accessRequestEvents []*AccessRequestEvent
+	if err := d.db.Where("team_id = ? AND access_request_id = ?", teamID, accessRequestID).Order("id ASC").Find(&accessRequestEvents).Error; err != nil {
+		return nil, err
+	}
+	return accessRequestEvents, nil
+}
+
+func (d *AccessRequestEventStorage) DeleteByTeamID(teamID int) error {
+	if err := d.db.Where("team_id = ?", teamID).Delete(&AccessRequestEvent{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type AccessRequestStorage struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewAccessRequestStorage(db *gorm.DB, logger *zap.SugaredLogger) *AccessRequestStorage {
+	return &AccessRequestStorage{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (d *AccessRequestStorage) Create(u *AccessRequest) (int, error) {
+	if err := d.db.Create(u).Error; err != nil {
+		return 0, err
+	}
+	return u.ID, nil
+}
+
+func (d *AccessRequestStorage) RetrieveByTeamIDAndID(teamID int, id int) (*AccessRequest, error) {
+	u := &AccessRequest{}
+	if err := d.db.Where("team_id = ? AND id = ?", teamID, id).First(u).Error; err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// RetrieveByTeamID retrieve access requests of the team, status 0 for all status.
+func (d *AccessRequestStorage) RetrieveByTeamID(teamID int, status int) ([]*AccessRequest, error) {
+	var accessRequests []*AccessRequest
+	query := d.db.Where("team_id = ?", teamID)
+	if status != 0 {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Order("id DESC").Find(&accessRequests).Error; err != nil {
+		return nil, err
+	}
+	return accessRequests, nil
+}
+
+// RetrieveByTeamIDAndTeamMemberID retrieve access requests of the team member, status 0 for all status.
+func (d *AccessRequestStorage) RetrieveByTeamIDAndTeamMemberID(teamID int, teamMemberID int, status int) ([]*AccessRequest, error) {
+	var accessRequests []*AccessRequest
+	query := d.db.Where("team_id = ? AND team_member_id = ?", teamID, teamMemberID)
+	if status != 0 {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Order("id DESC").Find(&accessRequests).Error; err != nil {
+		return nil, err
+	}
+	return accessRequests, nil
+}
+
+// CountPendingDuplicates count pending requests of the team member which ask for the same access.
+func (d *AccessRequestStorage) CountPendingDuplicates(u *AccessRequest) (int64, error) {
+	var count int64
+	if err := d.db.Model(&AccessRequest{}).Where(
+		"team_id = ? AND team_member_id = ? AND status = ? AND category = ? AND target_role = ? AND unit_type = ? AND unit_id = ? AND attribute_category = ? AND attribute = ?",
+		u.TeamID, u.TeamMemberID, ACCESS_REQUEST_STATUS_PENDING, u.Category, u.TargetRole, u.UnitType, u.UnitID, u.AttributeCategory, u.Attribute,
+	).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// UpdateStatusFromPending only update the request which is still pending, it returns false if the request has been reviewed concurrently.
+func (d *AccessRequestStorage) UpdateStatusFromPending(u *AccessRequest) (bool, error) {
+	result := d.db.Model(&AccessRequest{}).Where("id = ? AND status = ?", u.ID, ACCESS_REQUEST_STATUS_PENDING).Select("status", "reviewer_user_id", "review_comment", "reviewed_at", "updated_at").Updates(u)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (d *AccessRequestStorage) DeleteByTeamID(teamID int) error {
+	if err := d.db.Where("team_id = ?", teamID).Delete(&AccessRequest{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
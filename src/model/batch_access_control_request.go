@@ -0,0 +1,74 @@
+package model
+
+import (
+	"errors"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/idconvertor"
+)
+
+// check category of batch access control request, same as the single check endpoints.
+const (
+	ACCESS_CONTROL_CHECK_CATEGORY_ACCESS         = "canAccess"
+	ACCESS_CONTROL_CHECK_CATEGORY_DELETE         = "canDelete"
+	ACCESS_CONTROL_CHECK_CATEGORY_MANAGE         = "canManage"
+	ACCESS_CONTROL_CHECK_CATEGORY_MANAGE_SPECIAL = "canManageSpecial"
+	ACCESS_CONTROL_CHECK_CATEGORY_MODIFY         = "canModify"
+)
+
+// magic id length of idconvertor
+const MAGIC_ID_LENGTH = 12
+
+// all ids are in magic string format, same as the path params of the single check endpoints.
+type AccessControlCheck struct {
+	Category    string `json:"category" validate:"required,oneof=canAccess canDelete canManage canManageSpecial canModify"`
+	UnitType    string `json:"unitType" validate:"required"`
+	UnitID      string `json:"unitID" validate:"required"`
+	AttributeID string `json:"attributeID" validate:"required"`
+	FromID      string `json:"fromID"`
+	ToID        string `json:"toID"`
+}
+
+func NewAccessControlCheck() *AccessControlCheck {
+	return &AccessControlCheck{}
+}
+
+// the principal is the authorization token in header.
+type BatchAccessControlRequest struct {
+	Checks []*AccessControlCheck `json:"checks" validate:"required,min=1,max=200,dive,required"`
+}
+
+func NewBatchAccessControlRequest() *BatchAccessControlRequest {
+	return &BatchAccessControlRequest{}
+}
+
+func ConvertMagicStringToInt(magicID string) (int, error) {
+	if len(magicID) != MAGIC_ID_LENGTH {
+		return 0, errors.New("invalid id format")
+	}
+	return idconvertor.ConvertStringToInt(magicID), nil
+}
+
+func (check *AccessControlCheck) ExportUnitType() (int, error) {
+	return ConvertMagicStringToInt(check.UnitType)
+}
+
+func (check *AccessControlCheck) ExportUnitID() (int, error) {
+	return ConvertMagicStringToInt(check.UnitID)
+}
+
+func (check *AccessControlCheck) ExportAttributeID() (int, error) {
+	return ConvertMagicStringToInt(check.AttributeID)
+}
+
+func (check *AccessControlCheck) ExportFromIDAndToID() (int, int, error) {
+	fromID, errInConvertFromID := ConvertMagicStringToInt(check.FromID)
+	toID, errInConvertToID := ConvertMagicStringToInt(check.ToID)
+	if errInConvertFromID != nil || errInConvertToID != nil {
+		return 0, 0, errors.New("invalid from or to id format")
+	}
+	return fromID, toID, nil
+}
+
+func (check *AccessControlCheck) IsModify() bool {
+	return check.Category == ACCESS_CONTROL_CHECK_CATEGORY_MODIFY
+}
@@ -0,0 +1,29 @@
+package model
+
+type AccessControlCheckResult struct {
+	Allowed bool   `json:"allowed"`
+	Error   string `json:"error,omitempty"`
+}
+
+// results are in the same order as the checks of request.
+type BatchAccessControlResponse struct {
+	Results []*AccessControlCheckResult `json:"results"`
+}
+
+func NewBatchAccessControlResponse(size int) *BatchAccessControlResponse {
+	return &BatchAccessControlResponse{
+		Results: make([]*AccessControlCheckResult, 0, size),
+	}
+}
+
+func (resp *BatchAccessControlResponse) AppendResult(allowed bool) {
+	resp.Results = append(resp.Results, &AccessControlCheckResult{Allowed: allowed})
+}
+
+func (resp *BatchAccessControlResponse) AppendError(err error) {
+	resp.Results = append(resp.Results, &AccessControlCheckResult{Allowed: false, Error: err.Error()})
+}
+
+func (resp *BatchAccessControlResponse) ExportForFeedback() interface{} {
+	return resp
+}
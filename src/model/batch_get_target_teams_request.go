@@ -0,0 +1,30 @@
+package model
+
+import "github.com/google/uuid"
+
+// at most 200 ids or uids in one batch lookup request, ids are in magic string format.
+type BatchGetTargetTeamsByIDsRequest struct {
+	IDs []string `json:"ids" validate:"required,min=1,max=200,dive,len=12"`
+}
+
+func NewBatchGetTargetTeamsByIDsRequest() *BatchGetTargetTeamsByIDsRequest {
+	return &BatchGetTargetTeamsByIDsRequest{}
+}
+
+// ExportIDs feedback map[id]magicID of the requested ids and the malformed ones, duplicated ids are merged.
+func (req *BatchGetTargetTeamsByIDsRequest) ExportIDs() (map[int]string, []string) {
+	return exportMagicIDs(req.IDs)
+}
+
+type BatchGetTargetTeamsByUIDsRequest struct {
+	UIDs []string `json:"uids" validate:"required,min=1,max=200,dive,uuid"`
+}
+
+func NewBatchGetTargetTeamsByUIDsRequest() *BatchGetTargetTeamsByUIDsRequest {
+	return &BatchGetTargetTeamsByUIDsRequest{}
+}
+
+// ExportUIDs feedback map[uid]requestedUIDs of the requested uids, duplicated uids are merged.
+func (req *BatchGetTargetTeamsByUIDsRequest) ExportUIDs() map[uuid.UUID][]string {
+	return exportUIDs(req.UIDs)
+}
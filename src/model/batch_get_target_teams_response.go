@@ -0,0 +1,34 @@
+package model
+
+import "sort"
+
+// teams are keyed by the requested id or uid, the requested keys which have no team are in notFound.
+type BatchGetTargetTeamsResponse struct {
+	Teams    map[string]*GetTargetTeamByInternalRequestResponse `json:"teams"`
+	NotFound []string                                           `json:"notFound"`
+}
+
+func NewBatchGetTargetTeamsResponse() *BatchGetTargetTeamsResponse {
+	return &BatchGetTargetTeamsResponse{
+		Teams:    make(map[string]*GetTargetTeamByInternalRequestResponse),
+		NotFound: make([]string, 0),
+	}
+}
+
+func (resp *BatchGetTargetTeamsResponse) SetTeam(key string, team *Team) {
+	resp.Teams[key] = NewGetTargetTeamByInternalRequestResponse(team)
+}
+
+// SetNotFound mark the keys which has no team as not found.
+func (resp *BatchGetTargetTeamsResponse) SetNotFound(keys []string) {
+	for _, key := range keys {
+		if _, hit := resp.Teams[key]; !hit {
+			resp.NotFound = append(resp.NotFound, key)
+		}
+	}
+	sort.Strings(resp.NotFound)
+}
+
+func (resp *BatchGetTargetTeamsResponse) ExportForFeedback() interface{} {
+	return resp
+}
@@ -0,0 +1,77 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/idconvertor"
+)
+
+// at most 200 ids, uids or emails in one batch lookup request, ids are in magic string format.
+type BatchGetTargetUsersByIDsRequest struct {
+	IDs []string `json:"ids" validate:"required,min=1,max=200,dive,len=12"`
+}
+
+func NewBatchGetTargetUsersByIDsRequest() *BatchGetTargetUsersByIDsRequest {
+	return &BatchGetTargetUsersByIDsRequest{}
+}
+
+// ExportIDs feedback map[id]magicID of the requested ids and the malformed ones, duplicated ids are merged.
+func (req *BatchGetTargetUsersByIDsRequest) ExportIDs() (map[int]string, []string) {
+	return exportMagicIDs(req.IDs)
+}
+
+type BatchGetTargetUsersByUIDsRequest struct {
+	UIDs []string `json:"uids" validate:"required,min=1,max=200,dive,uuid"`
+}
+
+func NewBatchGetTargetUsersByUIDsRequest() *BatchGetTargetUsersByUIDsRequest {
+	return &BatchGetTargetUsersByUIDsRequest{}
+}
+
+// ExportUIDs feedback map[uid]requestedUIDs of the requested uids, duplicated uids are merged.
+func (req *BatchGetTargetUsersByUIDsRequest) ExportUIDs() map[uuid.UUID][]string {
+	return exportUIDs(req.UIDs)
+}
+
+type BatchGetTargetUsersByEmailsRequest struct {
+	Emails []string `json:"emails" validate:"required,min=1,max=200,dive,email"`
+}
+
+func NewBatchGetTargetUsersByEmailsRequest() *BatchGetTargetUsersByEmailsRequest {
+	return &BatchGetTargetUsersByEmailsRequest{}
+}
+
+// exportMagicIDs only accept the magic ids which convert back to themselves, so that two distinct magic ids never
+// collide on one id. the others are malformed, and feedback as they are to be reported as not found.
+func exportMagicIDs(magicIDs []string) (map[int]string, []string) {
+	ids := make(map[int]string, len(magicIDs))
+	malformedIDs := make([]string, 0)
+	malformed := make(map[string]bool)
+	for _, magicID := range magicIDs {
+		id := idconvertor.ConvertStringToInt(magicID)
+		if idconvertor.ConvertIntToString(id) != magicID {
+			if !malformed[magicID] {
+				malformed[magicID] = true
+				malformedIDs = append(malformedIDs, magicID)
+			}
+			continue
+		}
+		ids[id] = magicID
+	}
+	return ids, malformedIDs
+}
+
+// the uids are validated by request validator. one uid can be requested in different forms (e.g. in upper case),
+// all of them are kept as the keys of the response.
+func exportUIDs(uidsInString []string) map[uuid.UUID][]string {
+	uids := make(map[uuid.UUID][]string, len(uidsInString))
+	requested := make(map[string]bool, len(uidsInString))
+	for _, uidInString := range uidsInString {
+		if requested[uidInString] {
+			continue
+		}
+		requested[uidInString] = true
+		uid, _ := uuid.Parse(uidInString)
+		uids[uid] = append(uids[uid], uidInString)
+	}
+	return uids
+}
@@ -0,0 +1,34 @@
+package model
+
+import "sort"
+
+// users are keyed by the requested id, uid or email, the requested keys which have no user are in notFound.
+type BatchGetTargetUsersResponse struct {
+	Users    map[string]*GetTargetUserByInternalRequestResponse `json:"users"`
+	NotFound []string                                           `json:"notFound"`
+}
+
+func NewBatchGetTargetUsersResponse() *BatchGetTargetUsersResponse {
+	return &BatchGetTargetUsersResponse{
+		Users:    make(map[string]*GetTargetUserByInternalRequestResponse),
+		NotFound: make([]string, 0),
+	}
+}
+
+func (resp *BatchGetTargetUsersResponse) SetUser(key string, user *User) {
+	resp.Users[key] = NewGetTargetUserByInternalRequestResponse(user)
+}
+
+// SetNotFound mark the keys which has no user as not found.
+func (resp *BatchGetTargetUsersResponse) SetNotFound(keys []string) {
+	for _, key := range keys {
+		if _, hit := resp.Users[key]; !hit {
+			resp.NotFound = append(resp.NotFound, key)
+		}
+	}
+	sort.Strings(resp.NotFound)
+}
+
+func (resp *BatchGetTargetUsersResponse) ExportForFeedback() interface{} {
+	return resp
+}
@@ -6,12 +6,18 @@ import (
 )
 
 type Cache struct {
-	JWTCache *JWTCache
+	JWTCache          *JWTCache
+	EventStream       *EventStream
+	RequestNonceCache *RequestNonceCache
 }
 
 func NewCache(redisDriver *redis.Client, logger *zap.SugaredLogger) *Cache {
 	jwtCache := NewJWTCache(redisDriver, logger)
+	eventStream := NewEventStream(redisDriver, logger)
+	requestNonceCache := NewRequestNonceCache(redisDriver, logger)
 	return &Cache{
-		JWTCache: jwtCache,
+		JWTCache:          jwtCache,
+		EventStream:       eventStream,
+		RequestNonceCache: requestNonceCache,
 	}
 }
@@ -0,0 +1,74 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/idconvertor"
+)
+
+type CreateAccessRequestRequest struct {
+	Category          int        `json:"category" validate:"required,oneof=1 2"`
+	TargetRole        int        `json:"targetRole"`
+	UnitType          int        `json:"unitType"`
+	UnitID            string     `json:"unitID"`
+	AttributeCategory int        `json:"attributeCategory"`
+	Attribute         int        `json:"attribute"`
+	ValidUntil        *time.Time `json:"validUntil"`
+	Justification     string     `json:"justification" validate:"required,max=1024"`
+}
+
+func NewCreateAccessRequestRequest() *CreateAccessRequestRequest {
+	return &CreateAccessRequestRequest{}
+}
+
+func (req *CreateAccessRequestRequest) ExportUnitID() int {
+	return idconvertor.ConvertStringToInt(req.UnitID)
+}
+
+func (req *CreateAccessRequestRequest) IsTimeBound() bool {
+	return req.ValidUntil != nil
+}
+
+func (req *CreateAccessRequestRequest) ExportValidUntil() time.Time {
+	return req.ValidUntil.UTC()
+}
+
+// ValidateTarget check the fields required by the request category.
+func (req *CreateAccessRequestRequest) ValidateTarget() error {
+	switch req.Category {
+	case ACCESS_REQUEST_CATEGORY_ROLE:
+		if req.TargetRole == 0 {
+			return errors.New("targetRole is required for role request")
+		}
+		if req.UnitType != 0 || req.UnitID != "" || req.AttributeCategory != 0 || req.Attribute != 0 {
+			return errors.New("unit fields are not allowed for role request")
+		}
+	case ACCESS_REQUEST_CATEGORY_UNIT_ATTRIBUTE:
+		if req.UnitType == 0 || req.UnitID == "" || req.AttributeCategory == 0 || req.Attribute == 0 {
+			return errors.New("unitType, unitID, attributeCategory and attribute are required for unit attribute request")
+		}
+		if req.TargetRole != 0 {
+			return errors.New("targetRole is not allowed for unit attribute request")
+		}
+		// unit role relations do not expire
+		if req.IsTimeBound() {
+			return errors.New("validUntil is only supported by role request")
+		}
+	}
+	return nil
+}
+
+func (req *CreateAccessRequestRequest) ValidateValidUntil() error {
+	if !req.IsTimeBound() {
+		return nil
+	}
+	now := time.Now().UTC()
+	if !req.ExportValidUntil().After(now) {
+		return errors.New("validUntil should be in the future")
+	}
+	if req.ExportValidUntil().After(now.Add(TIME_BOUND_ROLE_MAX_DURATION)) {
+		return errors.New("validUntil exceeds the max duration of time-bound role")
+	}
+	return nil
+}
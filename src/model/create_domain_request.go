@@ -0,0 +1,11 @@
+package model
+
+type CreateDomainRequest struct {
+	UserDomain         string `json:"userDomain" validate:"required,fqdn"`
+	SystemDomainPrefix string `json:"systemDomainPrefix" validate:"required,alphanum"`
+	Category           int    `json:"category" validate:"required,oneof=1 2"`
+}
+
+func NewCreateDomainRequest() *CreateDomainRequest {
+	return &CreateDomainRequest{}
+}
@@ -0,0 +1,10 @@
+package model
+
+type CreateRoleRequest struct {
+	Name        string          `json:"name" validate:"required,max=255"`
+	Permissions RolePermissions `json:"permissions" validate:"required"`
+}
+
+func NewCreateRoleRequest() *CreateRoleRequest {
+	return &CreateRoleRequest{}
+}
@@ -0,0 +1,17 @@
+package model
+
+import "github.com/kozmoai/kozmo-supervisor-backend/src/utils/idconvertor"
+
+type CreateUnitRoleRelationRequest struct {
+	RoleID      string          `json:"roleID" validate:"required"`
+	Effect      int             `json:"effect" validate:"required,oneof=1 2"`
+	Permissions UnitPermissions `json:"permissions"`
+}
+
+func NewCreateUnitRoleRelationRequest() *CreateUnitRoleRelationRequest {
+	return &CreateUnitRoleRelationRequest{}
+}
+
+func (req *CreateUnitRoleRelationRequest) ExportRoleID() int {
+	return idconvertor.ConvertStringToInt(req.RoleID)
+}
@@ -0,0 +1,33 @@
+package model
+
+type CreateWebhookRequest struct {
+	Name       string   `json:"name" validate:"required,max=255"`
+	URL        string   `json:"url" validate:"required,url,max=2048,startswith=http"`
+	EventTypes []string `json:"eventTypes" validate:"required,min=1,dive,required"`
+	Format     int      `json:"format" validate:"omitempty,oneof=1 2"`
+}
+
+func NewCreateWebhookRequest() *CreateWebhookRequest {
+	return &CreateWebhookRequest{}
+}
+
+// json format by default.
+func (req *CreateWebhookRequest) ExportFormat() int {
+	if req.Format == 0 {
+		return WEBHOOK_FORMAT_JSON
+	}
+	return req.Format
+}
+
+func (req *CreateWebhookRequest) ExportUnsubscribableEventType() (string, bool) {
+	return exportUnsubscribableEventType(req.EventTypes)
+}
+
+func exportUnsubscribableEventType(eventTypes []string) (string, bool) {
+	for _, eventType := range eventTypes {
+		if !WebhookSubscribableEventTypes[eventType] {
+			return eventType, true
+		}
+	}
+	return "", false
+}
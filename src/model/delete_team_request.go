@@ -0,0 +1,14 @@
+package model
+
+type DeleteTeamRequest struct {
+	TeamIdentifier string `json:"teamIdentifier" validate:"required"`
+}
+
+func NewDeleteTeamRequest() *DeleteTeamRequest {
+	return &DeleteTeamRequest{}
+}
+
+// the team identifier must be typed by user to confirm the deletion.
+func (req *DeleteTeamRequest) DoesConfirmed(team *Team) bool {
+	return req.TeamIdentifier == team.GetIdentifier()
+}
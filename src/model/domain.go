@@ -0,0 +1,185 @@
+package model
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/dnsresolver"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/idconvertor"
+)
+
+const DOMAIN_CATEGORY_TEAM = 1
+const DOMAIN_CATEGORY_APP = 2
+
+const DOMAIN_RESOLVE_STATUS_PENDING = 1
+const DOMAIN_RESOLVE_STATUS_VERIFIED = 2
+const DOMAIN_RESOLVE_STATUS_FAILED = 3
+
+// ownership verification records
+// TXT   _kozmo-challenge.{user_domain} -> kozmo-domain-verification={verification_token}
+// CNAME {user_domain}                  -> {system_domain_prefix}.{system_domain}
+const DOMAIN_TXT_RECORD_PREFIX = "_kozmo-challenge."
+const DOMAIN_TXT_RECORD_VALUE_PREFIX = "kozmo-domain-verification="
+
+type Domain struct {
+	ID                 int       `json:"id" gorm:"column:id;type:bigserial;primary_key;index:domains_ukey"`
+	UID                uuid.UUID `json:"uid" gorm:"column:uid;type:uuid;not null;index:domains_ukey"`
+	TeamID             int       `json:"teamID" gorm:"column:team_id;type:bigserial;index:domains_team_id"`
+	UserDomain         string    `json:"userDomain" gorm:"column:user_domain;type:varchar;size:255;not null"`
+	SystemDomainPrefix string    `json:"systemDomainPrefix" gorm:"column:system_domain_prefix;type:varchar;size:255;not null"`
+	ResolveStatus      int       `json:"resolveStatus" gorm:"column:resolve_status;type:smallint;"`
+	Category           int       `json:"category" gorm:"column:category;type:smallint;"`
+	VerificationToken  string    `json:"verificationToken" gorm:"column:verification_token;type:varchar;size:64;not null"`
+	VerifiedAt         time.Time `gorm:"column:verified_at;type:timestamp"`
+	LastCheckedAt      time.Time `gorm:"column:last_checked_at;type:timestamp"`
+	CreatedAt          time.Time `gorm:"column:created_at;type:timestamp"`
+	UpdatedAt          time.Time `gorm:"column:updated_at;type:timestamp"`
+}
+
+type DomainForExport struct {
+	ID                 string    `json:"domainID"`
+	UID                uuid.UUID `json:"uid"`
+	TeamID             string    `json:"teamID"`
+	UserDomain         string    `json:"userDomain"`
+	SystemDomainPrefix string    `json:"systemDomainPrefix"`
+	ResolveStatus      int       `json:"resolveStatus"`
+	Category           int       `json:"category"`
+	TXTRecordName      string    `json:"txtRecordName"`
+	TXTRecordValue     string    `json:"txtRecordValue"`
+	CNAMERecordTarget  string    `json:"cnameRecordTarget"`
+	VerifiedAt         time.Time `json:"verifiedAt"`
+	LastCheckedAt      time.Time `json:"lastCheckedAt"`
+	CreatedAt          time.Time `json:"createdAt"`
+	UpdatedAt          time.Time `json:"updatedAt"`
+}
+
+func NewDomain() *Domain {
+	return &Domain{}
+}
+
+func NewDomainByCreateDomainRequest(teamID int, req *CreateDomainRequest) *Domain {
+	domain := &Domain{
+		TeamID:             teamID,
+		UserDomain:         dnsresolver.NormalizeHost(req.UserDomain),
+		SystemDomainPrefix: req.SystemDomainPrefix,
+		ResolveStatus:      DOMAIN_RESOLVE_STATUS_PENDING,
+		Category:           req.Category,
+	}
+	domain.InitUID()
+	domain.InitVerificationToken()
+	domain.InitCreatedAt()
+	domain.InitUpdatedAt()
+	return domain
+}
+
+func (d *Domain) InitUID() {
+	d.UID = uuid.New()
+}
+
+func (d *Domain) InitVerificationToken() {
+	b := make([]byte, 16)
+	rand.Read(b)
+	d.VerificationToken = hex.EncodeToString(b)
+}
+
+func (d *Domain) InitCreatedAt() {
+	d.CreatedAt = time.Now().UTC()
+}
+
+func (d *Domain) InitUpdatedAt() {
+	d.UpdatedAt = time.Now().UTC()
+}
+
+func (d *Domain) ExportID() int {
+	return d.ID
+}
+
+func (d *Domain) ExportTeamID() int {
+	return d.TeamID
+}
+
+func (d *Domain) ExportCategory() int {
+	return d.Category
+}
+
+func (d *Domain) ExportTXTRecordName() string {
+	return DOMAIN_TXT_RECORD_PREFIX + d.UserDomain
+}
+
+func (d *Domain) ExportTXTRecordValue() string {
+	return DOMAIN_TXT_RECORD_VALUE_PREFIX + d.VerificationToken
+}
+
+func (d *Domain) ExportCNAMERecordTarget(systemDomain string) string {
+	if systemDomain == "" {
+		return ""
+	}
+	return d.SystemDomainPrefix + "." + dnsresolver.NormalizeHost(systemDomain)
+}
+
+func (d *Domain) IsVerified() bool {
+	if d.ResolveStatus == DOMAIN_RESOLVE_STATUS_VERIFIED {
+		return true
+	}
+	return false
+}
+
+// user domain or system domain prefix changed domain need verify ownership again, it is checked on the next reverify.
+func (d *Domain) UpdateByUpdateDomainRequest(req *UpdateDomainRequest) {
+	userDomain := dnsresolver.NormalizeHost(req.UserDomain)
+	if userDomain != "" && userDomain != d.UserDomain {
+		d.UserDomain = userDomain
+		d.InitVerificationToken()
+		d.resetResolveStatus()
+	}
+	if req.SystemDomainPrefix != "" && req.SystemDomainPrefix != d.SystemDomainPrefix {
+		d.SystemDomainPrefix = req.SystemDomainPrefix
+		d.resetResolveStatus()
+	}
+	if req.Category != 0 {
+		d.Category = req.Category
+	}
+	d.InitUpdatedAt()
+}
+
+func (d *Domain) resetResolveStatus() {
+	d.ResolveStatus = DOMAIN_RESOLVE_STATUS_PENDING
+	d.VerifiedAt = time.Time{}
+	d.LastCheckedAt = time.Time{}
+}
+
+func (d *Domain) UpdateResolveStatus(verified bool) {
+	now := time.Now().UTC()
+	d.LastCheckedAt = now
+	if verified {
+		if !d.IsVerified() {
+			d.VerifiedAt = now
+		}
+		d.ResolveStatus = DOMAIN_RESOLVE_STATUS_VERIFIED
+	} else if d.IsVerified() || d.ResolveStatus == DOMAIN_RESOLVE_STATUS_FAILED {
+		// lost ownership records after verified
+		d.ResolveStatus = DOMAIN_RESOLVE_STATUS_FAILED
+	}
+	d.InitUpdatedAt()
+}
+
+func (d *Domain) Export(systemDomain string) *DomainForExport {
+	return &DomainForExport{
+		ID:                 idconvertor.ConvertIntToString(d.ID),
+		UID:                d.UID,
+		TeamID:             idconvertor.ConvertIntToString(d.TeamID),
+		UserDomain:         d.UserDomain,
+		SystemDomainPrefix: d.SystemDomainPrefix,
+		ResolveStatus:      d.ResolveStatus,
+		Category:           d.Category,
+		TXTRecordName:      d.ExportTXTRecordName(),
+		TXTRecordValue:     d.ExportTXTRecordValue(),
+		CNAMERecordTarget:  d.ExportCNAMERecordTarget(systemDomain),
+		VerifiedAt:         d.VerifiedAt,
+		LastCheckedAt:      d.LastCheckedAt,
+		CreatedAt:          d.CreatedAt,
+		UpdatedAt:          d.UpdatedAt,
+	}
+}
@@ -0,0 +1,109 @@
+package model
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type DomainStorage struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewDomainStorage(db *gorm.DB, logger *zap.SugaredLogger) *DomainStorage {
+	return &DomainStorage{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (d *DomainStorage) Create(u *Domain) (int, error) {
+	if err := d.db.Create(u).Error; err != nil {
+		return 0, err
+	}
+	return u.ID, nil
+}
+
+func (d *DomainStorage) RetrieveByID(id int) (*Domain, error) {
+	u := &Domain{}
+	if err := d.db.First(u, id).Error; err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (d *DomainStorage) RetrieveByTeamID(teamID int) ([]*Domain, error) {
+	var domains []*Domain
+	if err := d.db.Where("team_id = ?", teamID).Order("id").Find(&domains).Error; err != nil {
+		return nil, err
+	}
+	return domains, nil
+}
+
+func (d *DomainStorage) RetrieveByTeamIDAndID(teamID int, id int) (*Domain, error) {
+	u := &Domain{}
+	if err := d.db.Where("team_id = ? AND id = ?", teamID, id).First(&u).Error; err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (d *DomainStorage) RetrieveVerifiedByUserDomain(userDomain string) (*Domain, error) {
+	u := &Domain{}
+	if err := d.db.Where("user_domain = ? AND resolve_status = ?", userDomain, DOMAIN_RESOLVE_STATUS_VERIFIED).First(&u).Error; err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// retrieve domains which last checked before given time, oldest first.
+func (d *DomainStorage) RetrieveLastCheckedBefore(before time.Time, limit int) ([]*Domain, error) {
+	var domains []*Domain
+	if err := d.db.Where("last_checked_at < ?", before).Order("last_checked_at").Limit(limit).Find(&domains).Error; err != nil {
+		return nil, err
+	}
+	return domains, nil
+}
+
+// IsUserDomainExists returns true when the user domain is verified by any claim, pending and failed claims do not hold the domain.
+func (d *DomainStorage) IsUserDomainExists(userDomain string) bool {
+	var count int64
+	d.db.Model(&Domain{}).Where("user_domain = ? AND resolve_status = ?", userDomain, DOMAIN_RESOLVE_STATUS_VERIFIED).Count(&count)
+	if count == 0 {
+		return false
+	}
+	return true
+}
+
+// IsUserDomainVerifiedByOthers returns true when the user domain is verified by a claim other than the given one.
+func (d *DomainStorage) IsUserDomainVerifiedByOthers(userDomain string, id int) bool {
+	var count int64
+	d.db.Model(&Domain{}).Where("user_domain = ? AND resolve_status = ? AND id <> ?", userDomain, DOMAIN_RESOLVE_STATUS_VERIFIED, id).Count(&count)
+	if count == 0 {
+		return false
+	}
+	return true
+}
+
+func (d *DomainStorage) UpdateByID(u *Domain) error {
+	if err := d.db.Model(&Domain{}).Where("id = ?", u.ID).Select("*").Omit("id", "created_at").Updates(u).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *DomainStorage) DeleteByTeamIDAndID(teamID int, id int) error {
+	if err := d.db.Where("team_id = ? AND id = ?", teamID, id).Delete(&Domain{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *DomainStorage) DeleteByTeamID(teamID int) error {
+	if err := d.db.Where("team_id = ?", teamID).Delete(&Domain{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
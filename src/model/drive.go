@@ -1,11 +1,64 @@
 package model
 
 import (
+	"errors"
+	"time"
+
 	"go.uber.org/zap"
 )
 
+// max count of objects in one page of object listing, same as the s3 limit.
+const DRIVE_LIST_OBJECTS_MAX_LIMIT = 1000
+
+var ErrDriveObjectNotFound = errors.New("drive object not found")
+
+// S3Instance is the object storage of a bucket, file names are the object keys in the bucket.
+// the presigned urls expire after the drive timeout.
 type S3Instance interface {
 	GetPreSignedPutURL(fileName string) (string, error)
+	// downloadName sets the content-disposition of response to attachment with the file name, it is inline when empty.
+	GetPreSignedGetURL(fileName string, downloadName string) (string, error)
+	// StatObject feedback ErrDriveObjectNotFound when the object does not exist.
+	StatObject(fileName string) (*DriveObject, error)
+	// ListObjects list objects under prefix recursively in key order, continue with the NextContinuationToken of the last page.
+	ListObjects(prefix string, continuationToken string, limit int) (*DriveObjectPage, error)
+	CopyObject(srcFileName string, dstFileName string) error
+	RemoveObject(fileName string) error
+	RemoveObjects(fileNames []string) error
+	RemoveObjectsByPrefix(prefix string) error
+	// multipart upload, the client uploads each part by a presigned url and reports the etag of the parts to complete.
+	CreateMultipartUpload(fileName string) (string, error)
+	GetPreSignedUploadPartURL(fileName string, uploadID string, partNumber int) (string, error)
+	CompleteMultipartUpload(fileName string, uploadID string, parts []*DriveUploadedPart) error
+	AbortMultipartUpload(fileName string, uploadID string) error
+}
+
+type DriveObject struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	ETag         string    `json:"etag"`
+	ContentType  string    `json:"contentType"`
+	LastModified time.Time `json:"lastModified"`
+}
+
+type DriveObjectPage struct {
+	Objects               []*DriveObject `json:"objects"`
+	NextContinuationToken string         `json:"nextContinuationToken"` // empty for the last page
+}
+
+func NewDriveObjectPage() *DriveObjectPage {
+	return &DriveObjectPage{
+		Objects: make([]*DriveObject, 0),
+	}
+}
+
+func (page *DriveObjectPage) HasMore() bool {
+	return page.NextContinuationToken != ""
+}
+
+type DriveUploadedPart struct {
+	PartNumber int    `json:"partNumber"`
+	ETag       string `json:"etag"`
 }
 
 type Drive struct {
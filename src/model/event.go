@@ -0,0 +1,166 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/idconvertor"
+)
+
+// domain events for other kozmo services, they are written to outbox in the same transaction of the change,
+// and published by outbox relay at least once, consumers should deduplicate them by uid.
+const (
+	EVENT_TYPE_USER_UPDATED               = "UserUpdated"
+	EVENT_TYPE_USER_DELETED               = "UserDeleted"
+	EVENT_TYPE_TEAM_UPDATED               = "TeamUpdated"
+	EVENT_TYPE_TEAM_DELETED               = "TeamDeleted"
+	EVENT_TYPE_TEAM_MEMBER_JOINED         = "TeamMemberJoined"
+	EVENT_TYPE_TEAM_MEMBER_ROLE_CHANGED   = "TeamMemberRoleChanged"
+	EVENT_TYPE_TEAM_MEMBER_STATUS_CHANGED = "TeamMemberStatusChanged"
+	EVENT_TYPE_TEAM_MEMBER_REMOVED        = "TeamMemberRemoved"
+)
+
+// events of the same aggregate are published in order.
+const (
+	EVENT_AGGREGATE_TYPE_USER        = "user"
+	EVENT_AGGREGATE_TYPE_TEAM        = "team"
+	EVENT_AGGREGATE_TYPE_TEAM_MEMBER = "teamMember"
+)
+
+// operator of the changes made by the supervisor itself or by SCIM provisioning.
+const SYSTEM_OPERATOR_USER_ID = 0
+
+type Event struct {
+	UID           uuid.UUID `json:"uid"`
+	Type          string    `json:"type"`
+	AggregateType string    `json:"aggregateType"`
+	AggregateID   string    `json:"aggregateID"`
+	Payload       string    `json:"payload"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+func NewEvent(eventType string, aggregateType string, aggregateID string, payload interface{}) *Event {
+	payloadInJSON, _ := json.Marshal(payload)
+	return &Event{
+		UID:           uuid.New(),
+		Type:          eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Payload:       string(payloadInJSON),
+		CreatedAt:     time.Now().UTC(),
+	}
+}
+
+type TeamDeletedEventPayload struct {
+	TeamID            string      `json:"teamID"`
+	TeamUID           uuid.UUID   `json:"teamUID"`
+	TeamIdentifier    string      `json:"teamIdentifier"`
+	RevokedInviteUIDs []uuid.UUID `json:"revokedInviteUIDs"`
+	DeletedBy         string      `json:"deletedBy"`
+}
+
+func NewTeamDeletedEvent(team *Team, revokedInvites []*Invite, operatorUserID int) *Event {
+	payload := &TeamDeletedEventPayload{
+		TeamID:            idconvertor.ConvertIntToString(team.ID),
+		TeamUID:           team.GetUID(),
+		TeamIdentifier:    team.GetIdentifier(),
+		RevokedInviteUIDs: make([]uuid.UUID, 0, len(revokedInvites)),
+		DeletedBy:         idconvertor.ConvertIntToString(operatorUserID),
+	}
+	for _, invite := range revokedInvites {
+		payload.RevokedInviteUIDs = append(payload.RevokedInviteUIDs, invite.UID)
+	}
+	return NewEvent(EVENT_TYPE_TEAM_DELETED, EVENT_AGGREGATE_TYPE_TEAM, team.GetUIDInString(), payload)
+}
+
+type UserEventPayload struct {
+	UserID   string    `json:"userID"`
+	UserUID  uuid.UUID `json:"userUID"`
+	Nickname string    `json:"nickname"`
+	Email    string    `json:"email"`
+	Avatar   string    `json:"avatar"`
+	Language string    `json:"language"`
+}
+
+func newUserEventPayload(user *User) *UserEventPayload {
+	userForExport := user.Export()
+	return &UserEventPayload{
+		UserID:   idconvertor.ConvertIntToString(user.ID),
+		UserUID:  user.UID,
+		Nickname: userForExport.Nickname,
+		Email:    userForExport.Email,
+		Avatar:   userForExport.Avatar,
+		Language: userForExport.Language,
+	}
+}
+
+func NewUserUpdatedEvent(user *User) *Event {
+	return NewEvent(EVENT_TYPE_USER_UPDATED, EVENT_AGGREGATE_TYPE_USER, user.GetUIDInString(), newUserEventPayload(user))
+}
+
+func NewUserDeletedEvent(user *User) *Event {
+	return NewEvent(EVENT_TYPE_USER_DELETED, EVENT_AGGREGATE_TYPE_USER, user.GetUIDInString(), newUserEventPayload(user))
+}
+
+type TeamUpdatedEventPayload struct {
+	TeamID         string    `json:"teamID"`
+	TeamUID        uuid.UUID `json:"teamUID"`
+	TeamIdentifier string    `json:"teamIdentifier"`
+	Name           string    `json:"name"`
+	Icon           string    `json:"icon"`
+	UpdatedBy      string    `json:"updatedBy"`
+}
+
+func NewTeamUpdatedEvent(team *Team, operatorUserID int) *Event {
+	payload := &TeamUpdatedEventPayload{
+		TeamID:         idconvertor.ConvertIntToString(team.ID),
+		TeamUID:        team.GetUID(),
+		TeamIdentifier: team.GetIdentifier(),
+		Name:           team.Name,
+		Icon:           team.Icon,
+		UpdatedBy:      idconvertor.ConvertIntToString(operatorUserID),
+	}
+	return NewEvent(EVENT_TYPE_TEAM_UPDATED, EVENT_AGGREGATE_TYPE_TEAM, team.GetUIDInString(), payload)
+}
+
+type TeamMemberEventPayload struct {
+	TeamID           string     `json:"teamID"`
+	TeamMemberID     string     `json:"teamMemberID"`
+	UserID           string     `json:"userID"`
+	UserRole         int        `json:"userRole"`
+	PreviousUserRole int        `json:"previousUserRole,omitempty"`
+	ValidUntil       *time.Time `json:"validUntil,omitempty"` // nil for permanent role
+	Status           int        `json:"status"`
+	OperatorUserID   string     `json:"operatorUserID"`
+}
+
+func newTeamMemberEvent(eventType string, teamMember *TeamMember, previousUserRole int, operatorUserID int) *Event {
+	payload := &TeamMemberEventPayload{
+		TeamID:           idconvertor.ConvertIntToString(teamMember.TeamID),
+		TeamMemberID:     idconvertor.ConvertIntToString(teamMember.ID),
+		UserID:           idconvertor.ConvertIntToString(teamMember.UserID),
+		UserRole:         teamMember.ExportUserRole(),
+		PreviousUserRole: previousUserRole,
+		ValidUntil:       teamMember.ExportRoleValidUntil(),
+		Status:           teamMember.ExportStatus(),
+		OperatorUserID:   idconvertor.ConvertIntToString(operatorUserID),
+	}
+	return NewEvent(eventType, EVENT_AGGREGATE_TYPE_TEAM_MEMBER, idconvertor.ConvertIntToString(teamMember.ID), payload)
+}
+
+func NewTeamMemberJoinedEvent(teamMember *TeamMember, operatorUserID int) *Event {
+	return newTeamMemberEvent(EVENT_TYPE_TEAM_MEMBER_JOINED, teamMember, 0, operatorUserID)
+}
+
+func NewTeamMemberRoleChangedEvent(teamMember *TeamMember, previousUserRole int, operatorUserID int) *Event {
+	return newTeamMemberEvent(EVENT_TYPE_TEAM_MEMBER_ROLE_CHANGED, teamMember, previousUserRole, operatorUserID)
+}
+
+func NewTeamMemberStatusChangedEvent(teamMember *TeamMember, operatorUserID int) *Event {
+	return newTeamMemberEvent(EVENT_TYPE_TEAM_MEMBER_STATUS_CHANGED, teamMember, 0, operatorUserID)
+}
+
+func NewTeamMemberRemovedEvent(teamMember *TeamMember, operatorUserID int) *Event {
+	return newTeamMemberEvent(EVENT_TYPE_TEAM_MEMBER_REMOVED, teamMember, 0, operatorUserID)
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	redis "github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// other kozmo services consume this redis stream to sync their units.
+const EVENT_STREAM_KEY = "kozmo_supervisor_events"
+
+type EventStream struct {
+	logger  *zap.SugaredLogger
+	cache   *redis.Client
+	context context.Context
+}
+
+func NewEventStream(cache *redis.Client, logger *zap.SugaredLogger) *EventStream {
+	return &EventStream{
+		logger:  logger,
+		cache:   cache,
+		context: context.Background(),
+	}
+}
+
+func (s *EventStream) Publish(event *Event) error {
+	err := s.cache.XAdd(s.context, &redis.XAddArgs{
+		Stream: EVENT_STREAM_KEY,
+		Values: map[string]interface{}{
+			"uid":           event.UID.String(),
+			"type":          event.Type,
+			"aggregateType": event.AggregateType,
+			"aggregateID":   event.AggregateID,
+			"payload":       event.Payload,
+			"createdAt":     event.CreatedAt.Format(time.RFC3339Nano),
+		},
+	}).Err()
+	if err != nil {
+		s.logger.Errorw("publish event failed", "event", event, "err", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package model
+
+import (
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/idconvertor"
+)
+
+var TeamMemberStatusNameMap = map[int]string{
+	TEAM_MEMBER_STATUS_OK:      "ok",
+	TEAM_MEMBER_STATUS_PENDING: "pending",
+	TEAM_MEMBER_STATUS_SUSPEND: "suspended",
+}
+
+// AccessControlDecisionRule is a rule considered in the decision, in evaluation order.
+// the first matched rule decides the verdict.
+type AccessControlDecisionRule struct {
+	Source  string `json:"source"`
+	Matched bool   `json:"matched"`
+	Allowed bool   `json:"allowed"`
+	Detail  string `json:"detail"`
+}
+
+type AccessControlDecisionPrincipal struct {
+	Resolved  bool   `json:"resolved"`
+	Anonymous bool   `json:"anonymous"`
+	UserID    string `json:"userID,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+type AccessControlDecisionMembership struct {
+	Found      bool   `json:"found"`
+	Status     int    `json:"status"`
+	StatusName string `json:"statusName"`
+	UserRole   int    `json:"userRole"`
+	RoleName   string `json:"roleName"`
+	Error      string `json:"error,omitempty"`
+}
+
+type ExplainAccessControlResponse struct {
+	Principal  *AccessControlDecisionPrincipal  `json:"principal"`
+	TeamID     string                           `json:"teamID"`
+	Membership *AccessControlDecisionMembership `json:"membership"`
+	Check      *AccessControlCheck              `json:"check"`
+	Rules      []*AccessControlDecisionRule     `json:"rules"`
+	Allowed    bool                             `json:"allowed"`
+	Reason     string                           `json:"reason"`
+}
+
+func NewExplainAccessControlResponse(teamID int, check *AccessControlCheck) *ExplainAccessControlResponse {
+	return &ExplainAccessControlResponse{
+		Principal:  &AccessControlDecisionPrincipal{},
+		TeamID:     idconvertor.ConvertIntToString(teamID),
+		Membership: &AccessControlDecisionMembership{},
+		Check:      check,
+		Rules:      make([]*AccessControlDecisionRule, 0),
+	}
+}
+
+func (resp *ExplainAccessControlResponse) SetPrincipal(userID int, err error) {
+	if err != nil {
+		resp.Principal.Error = err.Error()
+		return
+	}
+	resp.Principal.Resolved = true
+	if userID == USER_ROLE_ANONYMOUS {
+		resp.Principal.Anonymous = true
+		return
+	}
+	resp.Principal.UserID = idconvertor.ConvertIntToString(userID)
+}
+
+func (resp *ExplainAccessControlResponse) SetMembership(teamMember *TeamMember, roleName string) {
+	resp.Membership.Found = true
+	resp.Membership.Status = teamMember.ExportStatus()
+	resp.Membership.StatusName = TeamMemberStatusNameMap[teamMember.ExportStatus()]
+	resp.Membership.UserRole = teamMember.ExportUserRole()
+	resp.Membership.RoleName = roleName
+}
+
+func (resp *ExplainAccessControlResponse) SetMembershipError(err error) {
+	resp.Membership.Error = err.Error()
+}
+
+func (resp *ExplainAccessControlResponse) SetDecision(rules []*AccessControlDecisionRule, allowed bool, reason string) {
+	resp.Rules = append(resp.Rules, rules...)
+	resp.Allowed = allowed
+	resp.Reason = reason
+}
+
+func (resp *ExplainAccessControlResponse) ExportForFeedback() interface{} {
+	return resp
+}
@@ -0,0 +1,15 @@
+package model
+
+type GetAccessRequestResponse struct {
+	AccessRequest *AccessRequestForExport
+}
+
+func NewGetAccessRequestResponse(accessRequest *AccessRequest, events []*AccessRequestEvent) *GetAccessRequestResponse {
+	return &GetAccessRequestResponse{
+		AccessRequest: accessRequest.ExportWithHistory(events),
+	}
+}
+
+func (resp *GetAccessRequestResponse) ExportForFeedback() interface{} {
+	return resp.AccessRequest
+}
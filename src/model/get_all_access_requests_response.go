@@ -0,0 +1,19 @@
+package model
+
+type GetAllAccessRequestsResponse struct {
+	AccessRequests []*AccessRequestForExport
+}
+
+func NewGetAllAccessRequestsResponse(accessRequests []*AccessRequest) *GetAllAccessRequestsResponse {
+	resp := &GetAllAccessRequestsResponse{
+		AccessRequests: make([]*AccessRequestForExport, 0, len(accessRequests)),
+	}
+	for _, accessRequest := range accessRequests {
+		resp.AccessRequests = append(resp.AccessRequests, accessRequest.Export())
+	}
+	return resp
+}
+
+func (resp *GetAllAccessRequestsResponse) ExportForFeedback() interface{} {
+	return resp.AccessRequests
+}
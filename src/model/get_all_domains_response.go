@@ -0,0 +1,19 @@
+package model
+
+type GetAllDomainsResponse struct {
+	Domains []*DomainForExport
+}
+
+func NewGetAllDomainsResponse(domains []*Domain, systemDomain string) *GetAllDomainsResponse {
+	resp := &GetAllDomainsResponse{
+		Domains: make([]*DomainForExport, 0, len(domains)),
+	}
+	for _, domain := range domains {
+		resp.Domains = append(resp.Domains, domain.Export(systemDomain))
+	}
+	return resp
+}
+
+func (resp *GetAllDomainsResponse) ExportForFeedback() interface{} {
+	return resp.Domains
+}
@@ -0,0 +1,19 @@
+package model
+
+type GetAllRolesResponse struct {
+	Roles []*RoleForExport
+}
+
+func NewGetAllRolesResponse(roles []*Role) *GetAllRolesResponse {
+	resp := &GetAllRolesResponse{
+		Roles: make([]*RoleForExport, 0, len(roles)),
+	}
+	for _, role := range roles {
+		resp.Roles = append(resp.Roles, role.Export())
+	}
+	return resp
+}
+
+func (resp *GetAllRolesResponse) ExportForFeedback() interface{} {
+	return resp.Roles
+}
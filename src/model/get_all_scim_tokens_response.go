@@ -0,0 +1,19 @@
+package model
+
+type GetAllSCIMTokensResponse struct {
+	SCIMTokens []*SCIMTokenForExport
+}
+
+func NewGetAllSCIMTokensResponse(scimTokens []*SCIMToken) *GetAllSCIMTokensResponse {
+	resp := &GetAllSCIMTokensResponse{
+		SCIMTokens: make([]*SCIMTokenForExport, 0, len(scimTokens)),
+	}
+	for _, scimToken := range scimTokens {
+		resp.SCIMTokens = append(resp.SCIMTokens, scimToken.Export())
+	}
+	return resp
+}
+
+func (resp *GetAllSCIMTokensResponse) ExportForFeedback() interface{} {
+	return resp.SCIMTokens
+}
@@ -17,7 +17,9 @@ type TeamMemberWithUserInfoForExportConverted struct {
 	Language     string                `json:"language"`
 	IsSubscribed bool                  `json:"isSubscribed"`
 	UserRole     int                   `json:"userRole"`
-	Permission   *TeamMemberPermission `json:"permission"` // for user permission config
+	BaseUserRole int                   `json:"baseUserRole,omitempty"`
+	ValidUntil   *time.Time            `json:"validUntil,omitempty"` // nil for permanent role
+	Permission   *TeamMemberPermission `json:"permission"`           // for user permission config
 	UserStatus   int                   `json:"userStatus"`
 	CreatedAt    time.Time             `json:"createdAt"`
 	UpdatedAt    time.Time             `json:"updatedAt"`
@@ -34,6 +36,8 @@ func NewTeamMemberWithUserInfoForExportConverted(i *TeamMemberWithUserInfoForExp
 		Language:     i.Language,
 		IsSubscribed: i.IsSubscribed,
 		UserRole:     i.UserRole,
+		BaseUserRole: i.BaseUserRole,
+		ValidUntil:   i.ValidUntil,
 		Permission:   i.Permission,
 		UserStatus:   i.UserStatus,
 		CreatedAt:    i.CreatedAt,
@@ -46,7 +50,9 @@ type GetAllTeamMembersResponse struct {
 }
 
 func NewGetAllTeamMembersResponse(d []*TeamMemberWithUserInfoForExport) *GetAllTeamMembersResponse {
-	resp := &GetAllTeamMembersResponse{}
+	resp := &GetAllTeamMembersResponse{
+		AllTeamMembers: make([]*TeamMemberWithUserInfoForExportConverted, 0, len(d)),
+	}
 	for _, item := range d {
 		resp.AllTeamMembers = append(resp.AllTeamMembers, NewTeamMemberWithUserInfoForExportConverted(item))
 	}
@@ -0,0 +1,19 @@
+package model
+
+type GetAllUnitRoleRelationsResponse struct {
+	UnitRoleRelations []*UnitRoleRelationForExport
+}
+
+func NewGetAllUnitRoleRelationsResponse(unitRoleRelations []*UnitRoleRelation) *GetAllUnitRoleRelationsResponse {
+	resp := &GetAllUnitRoleRelationsResponse{
+		UnitRoleRelations: make([]*UnitRoleRelationForExport, 0, len(unitRoleRelations)),
+	}
+	for _, unitRoleRelation := range unitRoleRelations {
+		resp.UnitRoleRelations = append(resp.UnitRoleRelations, unitRoleRelation.Export())
+	}
+	return resp
+}
+
+func (resp *GetAllUnitRoleRelationsResponse) ExportForFeedback() interface{} {
+	return resp.UnitRoleRelations
+}
@@ -0,0 +1,19 @@
+package model
+
+type GetAllWebhookDeliveriesResponse struct {
+	WebhookDeliveries []*WebhookDeliveryForExport
+}
+
+func NewGetAllWebhookDeliveriesResponse(deliveries []*WebhookDelivery) *GetAllWebhookDeliveriesResponse {
+	resp := &GetAllWebhookDeliveriesResponse{
+		WebhookDeliveries: make([]*WebhookDeliveryForExport, 0, len(deliveries)),
+	}
+	for _, delivery := range deliveries {
+		resp.WebhookDeliveries = append(resp.WebhookDeliveries, delivery.Export())
+	}
+	return resp
+}
+
+func (resp *GetAllWebhookDeliveriesResponse) ExportForFeedback() interface{} {
+	return resp.WebhookDeliveries
+}
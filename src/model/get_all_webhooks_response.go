@@ -0,0 +1,19 @@
+package model
+
+type GetAllWebhooksResponse struct {
+	Webhooks []*WebhookForExport
+}
+
+func NewGetAllWebhooksResponse(webhooks []*Webhook) *GetAllWebhooksResponse {
+	resp := &GetAllWebhooksResponse{
+		Webhooks: make([]*WebhookForExport, 0, len(webhooks)),
+	}
+	for _, webhook := range webhooks {
+		resp.Webhooks = append(resp.Webhooks, webhook.Export())
+	}
+	return resp
+}
+
+func (resp *GetAllWebhooksResponse) ExportForFeedback() interface{} {
+	return resp.Webhooks
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/idconvertor"
+)
+
+// the version changes when role, team permission or attribute config changes, clients can use it as cache key.
+type GetCapabilitiesResponse struct {
+	TeamID       string                   `json:"teamID"`
+	UserRole     int                      `json:"userRole"`
+	Version      string                   `json:"version"`
+	Capabilities map[string]map[int][]int `json:"capabilities"`
+}
+
+func NewGetCapabilitiesResponse(teamID int, userRole int, capabilities map[string]map[int][]int) *GetCapabilitiesResponse {
+	resp := &GetCapabilitiesResponse{
+		TeamID:       idconvertor.ConvertIntToString(teamID),
+		UserRole:     userRole,
+		Capabilities: capabilities,
+	}
+	resp.InitVersion()
+	return resp
+}
+
+// json marshaling sorts map keys, so the same capabilities always get the same version.
+func (resp *GetCapabilitiesResponse) InitVersion() {
+	resp.Version = ""
+	payload, _ := json.Marshal(resp)
+	digest := sha256.Sum256(payload)
+	resp.Version = hex.EncodeToString(digest[:8])
+}
+
+func (resp *GetCapabilitiesResponse) ExportETag() string {
+	return "\"" + resp.Version + "\""
+}
+
+func (resp *GetCapabilitiesResponse) ExportForFeedback() interface{} {
+	return resp
+}
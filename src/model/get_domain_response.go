@@ -0,0 +1,15 @@
+package model
+
+type GetDomainResponse struct {
+	Domain *DomainForExport
+}
+
+func NewGetDomainResponse(domain *Domain, systemDomain string) *GetDomainResponse {
+	return &GetDomainResponse{
+		Domain: domain.Export(systemDomain),
+	}
+}
+
+func (resp *GetDomainResponse) ExportForFeedback() interface{} {
+	return resp.Domain
+}
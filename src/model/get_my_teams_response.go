@@ -1,7 +1,6 @@
 package model
 
 import (
-	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -32,7 +31,7 @@ func NewMyTeamResponse(team *Team, targetTeamMember *TeamMember) *MyTeamResponse
 		Name:                 team.Name,
 		Identifier:           team.Identifier,
 		Icon:                 team.Icon,
-		MyRole:               targetTeamMember.UserRole,
+		MyRole:               targetTeamMember.ExportUserRole(),
 		TeamMemberID:         idconvertor.ConvertIntToString(targetTeamMember.ID),
 		TeamMemberPermission: targetTeamMember.ExportPermission(),
 		TeamPermission:       team.ExportTeamPermission(),
@@ -44,29 +43,23 @@ func (resp *MyTeamResponse) ExportForFeedback() interface{} {
 	return resp
 }
 
-func NewGetMyTeamsResponse(teams []*Team, teamMembersLT map[int]*TeamMemberForExport) *GetMyTeamsResponse {
-	// build team  members lookup table
-	ret := &GetMyTeamsResponse{}
+// NewGetMyTeamsResponse build response in the order of given team members, the team members are sorted by storage pagination.
+func NewGetMyTeamsResponse(teamMembers []*TeamMember, teams []*Team) *GetMyTeamsResponse {
+	// build teams lookup table
+	teamsLT := make(map[int]*Team, len(teams))
 	for _, team := range teams {
-		targetTeamMember := teamMembersLT[team.ID]
-		myTeam := &MyTeamResponse{
-			ID:                   idconvertor.ConvertIntToString(team.ID),
-			UID:                  team.UID,
-			Name:                 team.Name,
-			Identifier:           team.Identifier,
-			Icon:                 team.Icon,
-			MyRole:               targetTeamMember.UserRole,
-			TeamMemberID:         idconvertor.ConvertIntToString(targetTeamMember.ID),
-			TeamMemberPermission: targetTeamMember.Permission,
-			TeamPermission:       team.ExportTeamPermission(),
-			JoinedAt:             targetTeamMember.CreatedAt,
+		teamsLT[team.ID] = team
+	}
+	ret := &GetMyTeamsResponse{
+		MyTeams: make([]*MyTeamResponse, 0, len(teamMembers)),
+	}
+	for _, teamMember := range teamMembers {
+		team, hit := teamsLT[teamMember.TeamID]
+		if !hit {
+			continue
 		}
-		ret.MyTeams = append(ret.MyTeams, myTeam)
+		ret.MyTeams = append(ret.MyTeams, NewMyTeamResponse(team, teamMember))
 	}
-	// sort
-	sort.Slice(ret.MyTeams, func(i, j int) bool {
-		return ret.MyTeams[i].JoinedAt.After(ret.MyTeams[j].JoinedAt)
-	})
 	return ret
 }
 
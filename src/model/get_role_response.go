@@ -0,0 +1,15 @@
+package model
+
+type GetRoleResponse struct {
+	Role *RoleForExport
+}
+
+func NewGetRoleResponse(role *Role) *GetRoleResponse {
+	return &GetRoleResponse{
+		Role: role.Export(),
+	}
+}
+
+func (resp *GetRoleResponse) ExportForFeedback() interface{} {
+	return resp.Role
+}
@@ -0,0 +1,16 @@
+package model
+
+import "github.com/kozmoai/kozmo-supervisor-backend/src/utils/idconvertor"
+
+// user id is in magic string format.
+type GetTargetUserTeamsRequest struct {
+	UserID string `json:"userID" validate:"required,len=12"`
+}
+
+func NewGetTargetUserTeamsRequest() *GetTargetUserTeamsRequest {
+	return &GetTargetUserTeamsRequest{}
+}
+
+func (req *GetTargetUserTeamsRequest) ExportUserID() int {
+	return idconvertor.ConvertStringToInt(req.UserID)
+}
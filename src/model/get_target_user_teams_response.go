@@ -0,0 +1,52 @@
+package model
+
+import (
+	"time"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/idconvertor"
+)
+
+type TargetUserTeamForExport struct {
+	Team           *GetTargetTeamByInternalRequestResponse `json:"team"`
+	TeamMemberID   string                                  `json:"teamMemberID"`
+	UserRole       int                                     `json:"userRole"`
+	BaseUserRole   int                                     `json:"baseUserRole"`
+	RoleValidUntil *time.Time                              `json:"roleValidUntil,omitempty"` // nil for permanent role
+	Status         int                                     `json:"status"`
+}
+
+type GetTargetUserTeamsResponse struct {
+	UserID string                     `json:"userID"`
+	Teams  []*TargetUserTeamForExport `json:"teams"`
+}
+
+// the team members of deleted teams are skipped.
+func NewGetTargetUserTeamsResponse(userID int, teamMembers []*TeamMember, teams []*Team) *GetTargetUserTeamsResponse {
+	teamsMap := make(map[int]*Team, len(teams))
+	for _, team := range teams {
+		teamsMap[team.ID] = team
+	}
+	resp := &GetTargetUserTeamsResponse{
+		UserID: idconvertor.ConvertIntToString(userID),
+		Teams:  make([]*TargetUserTeamForExport, 0, len(teamMembers)),
+	}
+	for _, teamMember := range teamMembers {
+		team, hit := teamsMap[teamMember.TeamID]
+		if !hit {
+			continue
+		}
+		resp.Teams = append(resp.Teams, &TargetUserTeamForExport{
+			Team:           NewGetTargetTeamByInternalRequestResponse(team),
+			TeamMemberID:   idconvertor.ConvertIntToString(teamMember.ID),
+			UserRole:       teamMember.ExportUserRole(),
+			BaseUserRole:   teamMember.ExportBaseUserRole(),
+			RoleValidUntil: teamMember.ExportRoleValidUntil(),
+			Status:         teamMember.ExportStatus(),
+		})
+	}
+	return resp
+}
+
+func (resp *GetTargetUserTeamsResponse) ExportForFeedback() interface{} {
+	return resp
+}
@@ -3,11 +3,13 @@ package model
 import "strconv"
 
 type GetTargetUsersByInternalRequestResponse struct {
-	Users map[string]*GetTargetUserByInternalRequestResponse `json:"users"`
+	Users    map[string]*GetTargetUserByInternalRequestResponse `json:"users"`
+	PageInfo *PageInfo                                          `json:"pageInfo,omitempty"` // nil when the users are not paginated
 }
 
-func NewGetTargetUsersByInternalRequestResponse(users []*User) *GetTargetUsersByInternalRequestResponse {
+func NewGetTargetUsersByInternalRequestResponse(users []*User, pageInfo *PageInfo) *GetTargetUsersByInternalRequestResponse {
 	var getTargetUsersByInternalRequestResponse GetTargetUsersByInternalRequestResponse
+	getTargetUsersByInternalRequestResponse.PageInfo = pageInfo
 	getTargetUsersByInternalRequestResponse.Users = make(map[string]*GetTargetUserByInternalRequestResponse, len(users))
 	for _, user := range users {
 		userResp := NewGetTargetUserByInternalRequestResponse(user)
@@ -17,7 +17,9 @@ type GetTeamMemberResponse struct {
 	Language     string                `json:"language"`
 	IsSubscribed bool                  `json:"isSubscribed"`
 	UserRole     int                   `json:"userRole"`
-	Permission   *TeamMemberPermission `json:"permission"` // for user permission config
+	BaseUserRole int                   `json:"baseUserRole,omitempty"`
+	ValidUntil   *time.Time            `json:"validUntil,omitempty"` // nil for permanent role
+	Permission   *TeamMemberPermission `json:"permission"`           // for user permission config
 	UserStatus   int                   `json:"userStatus"`
 	CreatedAt    time.Time             `json:"createdAt"`
 	UpdatedAt    time.Time             `json:"updatedAt"`
@@ -34,6 +36,8 @@ func NewGetTeamMemberResponse(i *TeamMemberWithUserInfoForExport) *GetTeamMember
 		Language:     i.Language,
 		IsSubscribed: i.IsSubscribed,
 		UserRole:     i.UserRole,
+		BaseUserRole: i.BaseUserRole,
+		ValidUntil:   i.ValidUntil,
 		Permission:   i.Permission,
 		UserStatus:   i.UserStatus,
 		CreatedAt:    i.CreatedAt,
@@ -0,0 +1,15 @@
+package model
+
+type GetUnitRoleRelationResponse struct {
+	UnitRoleRelation *UnitRoleRelationForExport
+}
+
+func NewGetUnitRoleRelationResponse(unitRoleRelation *UnitRoleRelation) *GetUnitRoleRelationResponse {
+	return &GetUnitRoleRelationResponse{
+		UnitRoleRelation: unitRoleRelation.Export(),
+	}
+}
+
+func (resp *GetUnitRoleRelationResponse) ExportForFeedback() interface{} {
+	return resp.UnitRoleRelation
+}
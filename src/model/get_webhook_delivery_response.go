@@ -0,0 +1,15 @@
+package model
+
+type GetWebhookDeliveryResponse struct {
+	WebhookDelivery *WebhookDeliveryForExport
+}
+
+func NewGetWebhookDeliveryResponse(delivery *WebhookDelivery) *GetWebhookDeliveryResponse {
+	return &GetWebhookDeliveryResponse{
+		WebhookDelivery: delivery.Export(),
+	}
+}
+
+func (resp *GetWebhookDeliveryResponse) ExportForFeedback() interface{} {
+	return resp.WebhookDelivery
+}
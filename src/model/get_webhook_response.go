@@ -0,0 +1,22 @@
+package model
+
+type GetWebhookResponse struct {
+	Webhook *WebhookForExport
+}
+
+func NewGetWebhookResponse(webhook *Webhook) *GetWebhookResponse {
+	return &GetWebhookResponse{
+		Webhook: webhook.Export(),
+	}
+}
+
+// the secret only feedback when webhook created or secret rotated.
+func NewGetWebhookWithSecretResponse(webhook *Webhook) *GetWebhookResponse {
+	return &GetWebhookResponse{
+		Webhook: webhook.ExportWithSecret(),
+	}
+}
+
+func (resp *GetWebhookResponse) ExportForFeedback() interface{} {
+	return resp.Webhook
+}
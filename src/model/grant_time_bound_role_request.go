@@ -0,0 +1,37 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// time-bound role can not last longer than this, use permanent role assignment instead.
+const TIME_BOUND_ROLE_MAX_DURATION = 90 * 24 * time.Hour
+
+type GrantTimeBoundRoleRequest struct {
+	UserRole   int       `json:"userRole" validate:"required"`
+	ValidUntil time.Time `json:"validUntil" validate:"required"`
+}
+
+func NewGrantTimeBoundRoleRequest() *GrantTimeBoundRoleRequest {
+	return &GrantTimeBoundRoleRequest{}
+}
+
+func (req *GrantTimeBoundRoleRequest) ExportUserRole() int {
+	return req.UserRole
+}
+
+func (req *GrantTimeBoundRoleRequest) ExportValidUntil() time.Time {
+	return req.ValidUntil.UTC()
+}
+
+func (req *GrantTimeBoundRoleRequest) ValidateValidUntil() error {
+	now := time.Now().UTC()
+	if !req.ExportValidUntil().After(now) {
+		return errors.New("validUntil should be in the future")
+	}
+	if req.ExportValidUntil().After(now.Add(TIME_BOUND_ROLE_MAX_DURATION)) {
+		return errors.New("validUntil exceeds the max duration of time-bound role")
+	}
+	return nil
+}
@@ -0,0 +1,16 @@
+package model
+
+// the team is optional, the membership and scopes are only feedback when team given.
+// like the other internal requests, the team is always the default team, the given team id is not resolved.
+type IntrospectTokenRequest struct {
+	Token  string `json:"token" validate:"required"`
+	TeamID string `json:"teamID"`
+}
+
+func NewIntrospectTokenRequest() *IntrospectTokenRequest {
+	return &IntrospectTokenRequest{}
+}
+
+func (req *IntrospectTokenRequest) HasTeam() bool {
+	return req.TeamID != ""
+}
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/idconvertor"
+)
+
+const INTROSPECT_TOKEN_TYPE = "access_token"
+
+// IntrospectTokenResponse follows RFC 7662, an inactive token only feedback {"active": false} without the reason.
+// scope is the space separated granted attributes in team, it is empty when team not given or user is not a team member.
+type IntrospectTokenResponse struct {
+	Active     bool                           `json:"active"`
+	Scope      string                         `json:"scope,omitempty"`
+	TokenType  string                         `json:"token_type,omitempty"`
+	Exp        int64                          `json:"exp,omitempty"`
+	Sub        string                         `json:"sub,omitempty"` // user uid
+	Iss        string                         `json:"iss,omitempty"`
+	UserID     string                         `json:"userID,omitempty"`
+	UserUID    *uuid.UUID                     `json:"userUID,omitempty"`
+	Email      string                         `json:"email,omitempty"`
+	Nickname   string                         `json:"nickname,omitempty"`
+	SessionID  string                         `json:"sessionID,omitempty"`
+	ExpiresAt  *time.Time                     `json:"expiresAt,omitempty"`
+	Membership *IntrospectTokenMembershipInfo `json:"membership,omitempty"`
+}
+
+type IntrospectTokenMembershipInfo struct {
+	TeamID         string     `json:"teamID"`
+	TeamMemberID   string     `json:"teamMemberID"`
+	UserRole       int        `json:"userRole"`
+	BaseUserRole   int        `json:"baseUserRole"`
+	RoleValidUntil *time.Time `json:"roleValidUntil,omitempty"` // nil for permanent role
+	Status         int        `json:"status"`
+}
+
+func NewInactiveIntrospectTokenResponse() *IntrospectTokenResponse {
+	return &IntrospectTokenResponse{
+		Active: false,
+	}
+}
+
+func NewIntrospectTokenResponse(user *User, issuer string, sessionID string, expiresAt time.Time) *IntrospectTokenResponse {
+	userForExport := user.Export()
+	userUID := user.UID
+	expiresAt = expiresAt.UTC()
+	return &IntrospectTokenResponse{
+		Active:    true,
+		TokenType: INTROSPECT_TOKEN_TYPE,
+		Exp:       expiresAt.Unix(),
+		Sub:       user.UID.String(),
+		Iss:       issuer,
+		UserID:    idconvertor.ConvertIntToString(user.ID),
+		UserUID:   &userUID,
+		Email:     userForExport.Email,
+		Nickname:  userForExport.Nickname,
+		SessionID: sessionID,
+		ExpiresAt: &expiresAt,
+	}
+}
+
+func (resp *IntrospectTokenResponse) SetMembership(teamMember *TeamMember, scopes []string) {
+	resp.Membership = &IntrospectTokenMembershipInfo{
+		TeamID:         idconvertor.ConvertIntToString(teamMember.TeamID),
+		TeamMemberID:   idconvertor.ConvertIntToString(teamMember.ID),
+		UserRole:       teamMember.ExportUserRole(),
+		BaseUserRole:   teamMember.ExportBaseUserRole(),
+		RoleValidUntil: teamMember.ExportRoleValidUntil(),
+		Status:         teamMember.ExportStatus(),
+	}
+	resp.Scope = strings.Join(scopes, " ")
+}
+
+func (resp *IntrospectTokenResponse) ExportForFeedback() interface{} {
+	return resp
+}
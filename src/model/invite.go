@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const INVITE_CATEGORY_BY_LINK = 1
+const INVITE_CATEGORY_BY_EMAIL = 2
+
+const INVITE_STATUS_PENDING = 1
+const INVITE_STATUS_ACCEPTED = 2
+
+type Invite struct {
+	ID           int       `json:"id" gorm:"column:id;type:bigserial;primary_key;index:invite_ukey"`
+	UID          uuid.UUID `json:"uid" gorm:"column:uid;type:uuid;not null;index:invite_ukey"`
+	Category     int       `json:"category" gorm:"column:category;type:smallint"`
+	TeamID       int       `json:"teamID" gorm:"column:team_id;type:bigserial"`
+	TeamMemberID int       `json:"teamMemberID" gorm:"column:team_member_id;type:bigserial"`
+	Email        string    `json:"email" gorm:"column:email;type:varchar;size:255"`
+	EmailStatus  bool      `json:"emailStatus" gorm:"column:email_status;type:bool"`
+	UserRole     int       `json:"userRole" gorm:"column:user_role;type:bigint"`
+	Status       int       `json:"status" gorm:"column:status;type:smallint"`
+	CreatedAt    time.Time `gorm:"column:created_at;type:timestamp"`
+	UpdatedAt    time.Time `gorm:"column:updated_at;type:timestamp"`
+}
+
+func NewInvite() *Invite {
+	return &Invite{}
+}
+
+func (i *Invite) IsStatusPending() bool {
+	if i.Status == INVITE_STATUS_PENDING {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type InviteStorage struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewInviteStorage(db *gorm.DB, logger *zap.SugaredLogger) *InviteStorage {
+	return &InviteStorage{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (d *InviteStorage) RetrieveByTeamID(teamID int) ([]*Invite, error) {
+	var invites []*Invite
+	if err := d.db.Where("team_id = ?", teamID).Find(&invites).Error; err != nil {
+		return nil, err
+	}
+	return invites, nil
+}
+
+func (d *InviteStorage) RetrievePendingByTeamID(teamID int) ([]*Invite, error) {
+	var invites []*Invite
+	if err := d.db.Where("team_id = ? AND status = ?", teamID, INVITE_STATUS_PENDING).Find(&invites).Error; err != nil {
+		return nil, err
+	}
+	return invites, nil
+}
+
+func (d *InviteStorage) DeleteByTeamID(teamID int) error {
+	if err := d.db.Where("team_id = ?", teamID).Delete(&Invite{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
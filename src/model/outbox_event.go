@@ -0,0 +1,95 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// outbox events are written in the same transaction of the change, then published by outbox relay.
+// the serial id keeps the order of events, zero published at means the event is not published to all sinks yet.
+// next attempt at is the lease of the claimed event or the retry time of the failed one, zero means due at once.
+type OutboxEvent struct {
+	ID             int       `json:"id" gorm:"column:id;type:bigserial;primary_key"`
+	UID            uuid.UUID `json:"uid" gorm:"column:uid;type:uuid;not null"`
+	Type           string    `json:"type" gorm:"column:type;type:varchar;size:63;not null"`
+	AggregateType  string    `json:"aggregateType" gorm:"column:aggregate_type;type:varchar;size:63;not null"`
+	AggregateID    string    `json:"aggregateID" gorm:"column:aggregate_id;type:varchar;size:63;not null"`
+	Payload        string    `json:"payload" gorm:"column:payload;type:jsonb"`
+	Attempts       int       `json:"attempts" gorm:"column:attempts;type:integer"`
+	LastError      string    `json:"lastError" gorm:"column:last_error;type:text"`
+	PublishedSinks string    `json:"publishedSinks" gorm:"column:published_sinks;type:varchar;size:255"` // sinks published to, separated by comma
+	NextAttemptAt  time.Time `gorm:"column:next_attempt_at;type:timestamp"`
+	CreatedAt      time.Time `gorm:"column:created_at;type:timestamp"`
+	PublishedAt    time.Time `gorm:"column:published_at;type:timestamp;index:outbox_events_published_at"`
+}
+
+func NewOutboxEventByEvent(event *Event) *OutboxEvent {
+	return &OutboxEvent{
+		UID:           event.UID,
+		Type:          event.Type,
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		Payload:       event.Payload,
+		CreatedAt:     event.CreatedAt,
+	}
+}
+
+func (u *OutboxEvent) ExportID() int {
+	return u.ID
+}
+
+// ExportAggregateKey is the ordering key of event.
+func (u *OutboxEvent) ExportAggregateKey() string {
+	return u.AggregateType + ":" + u.AggregateID
+}
+
+func (u *OutboxEvent) ExportEvent() *Event {
+	return &Event{
+		UID:           u.UID,
+		Type:          u.Type,
+		AggregateType: u.AggregateType,
+		AggregateID:   u.AggregateID,
+		Payload:       u.Payload,
+		CreatedAt:     u.CreatedAt,
+	}
+}
+
+func (u *OutboxEvent) IsPublishedTo(sinkName string) bool {
+	for _, publishedSink := range strings.Split(u.PublishedSinks, ",") {
+		if publishedSink == sinkName {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkPublishedTo record the sink published to, the sink is skipped when the event is published again.
+func (u *OutboxEvent) MarkPublishedTo(sinkName string) {
+	if u.IsPublishedTo(sinkName) {
+		return
+	}
+	if u.PublishedSinks != "" {
+		u.PublishedSinks += ","
+	}
+	u.PublishedSinks += sinkName
+}
+
+// MarkPublished mark the event published to all sinks.
+func (u *OutboxEvent) MarkPublished() {
+	u.PublishedAt = time.Now().UTC()
+	u.NextAttemptAt = time.Time{}
+}
+
+// MarkFailed record the failure, the event is published again at nextAttemptAt.
+func (u *OutboxEvent) MarkFailed(err error, nextAttemptAt time.Time) {
+	u.Attempts++
+	u.LastError = err.Error()
+	u.NextAttemptAt = nextAttemptAt
+}
+
+// Release give up the claim, the event is due at once.
+func (u *OutboxEvent) Release() {
+	u.NextAttemptAt = time.Time{}
+}
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type OutboxEventStorage struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewOutboxEventStorage(db *gorm.DB, logger *zap.SugaredLogger) *OutboxEventStorage {
+	return &OutboxEventStorage{
+		logger: logger,
+		db:     db,
+	}
+}
+
+// Create write event to outbox, it should be called with the transaction storage of the change.
+func (d *OutboxEventStorage) Create(event *Event) (int, error) {
+	u := NewOutboxEventByEvent(event)
+	if err := d.db.Create(u).Error; err != nil {
+		return 0, err
+	}
+	return u.ID, nil
+}
+
+// RetrieveDue retrieve the due unpublished events in order, the events behind a claimed or failed event of the same aggregate are excluded.
+func (d *OutboxEventStorage) RetrieveDue(now time.Time, limit int) ([]*OutboxEvent, error) {
+	var outboxEvents []*OutboxEvent
+	if err := d.db.Where("published_at = ? AND next_attempt_at <= ?", time.Time{}, now).
+		Where("NOT EXISTS (SELECT 1 FROM outbox_events AS earlier WHERE earlier.aggregate_type = outbox_events.aggregate_type "+
+			"AND earlier.aggregate_id = outbox_events.aggregate_id AND earlier.id < outbox_events.id "+
+			"AND earlier.published_at = ? AND earlier.next_attempt_at > ?)", time.Time{}, now).
+		Order("id ASC").Limit(limit).Find(&outboxEvents).Error; err != nil {
+		return nil, err
+	}
+	return outboxEvents, nil
+}
+
+// Claim lease the event until leaseUntil, it returns false when the event was claimed, retried or published by others since retrieved.
+func (d *OutboxEventStorage) Claim(u *OutboxEvent, leaseUntil time.Time) (bool, error) {
+	result := d.db.Model(&OutboxEvent{}).
+		Where("id = ? AND published_at = ? AND next_attempt_at = ?", u.ID, time.Time{}, u.NextAttemptAt).
+		UpdateColumn("next_attempt_at", leaseUntil)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	u.NextAttemptAt = leaseUntil
+	return true, nil
+}
+
+func (d *OutboxEventStorage) Update(u *OutboxEvent) error {
+	if err := d.db.Model(&OutboxEvent{}).Where("id = ?", u.ID).UpdateColumns(map[string]interface{}{
+		"attempts":        u.Attempts,
+		"last_error":      u.LastError,
+		"published_sinks": u.PublishedSinks,
+		"next_attempt_at": u.NextAttemptAt,
+		"published_at":    u.PublishedAt,
+	}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeletePublishedBefore clean the published events, the outbox is not an event store.
+func (d *OutboxEventStorage) DeletePublishedBefore(before time.Time) error {
+	if err := d.db.Where("published_at <> ? AND published_at < ?", time.Time{}, before).Delete(&OutboxEvent{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
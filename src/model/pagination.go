@@ -0,0 +1,182 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const PAGINATION_DEFAULT_LIMIT = 20
+const PAGINATION_MAX_LIMIT = 100
+const PAGINATION_UNLIMITED = 0 // the whole list in one page, for the lists which were not paginated
+
+const PAGINATION_DIRECTION_ASC = "asc"
+const PAGINATION_DIRECTION_DESC = "desc"
+
+// sort keys, all list endpoints share the same sort keys, and every paginated table have "id" and "created_at" columns.
+const PAGINATION_SORT_KEY_ID = "id"
+const PAGINATION_SORT_KEY_CREATED_AT = "createdAt"
+
+var paginationSortKeyColumns = map[string]string{
+	PAGINATION_SORT_KEY_ID:         "id",
+	PAGINATION_SORT_KEY_CREATED_AT: "created_at",
+}
+
+// PaginationCursor is the keyset position of the last item in a page.
+// It encoded as base64 json and should be treated as opaque by the client.
+type PaginationCursor struct {
+	SortKey   string `json:"k"`
+	SortValue string `json:"v"`
+	ID        int    `json:"id"`
+}
+
+type Pagination struct {
+	Limit     int
+	SortKey   string
+	Direction string
+	cursor    *PaginationCursor
+}
+
+type PageInfo struct {
+	NextCursor string `json:"nextCursor"`
+	HasMore    bool   `json:"hasMore"`
+}
+
+func NewPagination(sortKey string, direction string) *Pagination {
+	return &Pagination{
+		Limit:     PAGINATION_DEFAULT_LIMIT,
+		SortKey:   sortKey,
+		Direction: direction,
+	}
+}
+
+// NewPaginationByRequest build pagination by raw request params, empty param fallback to the given default value.
+func NewPaginationByRequest(cursorRaw string, limitRaw string, sortKeyRaw string, directionRaw string, defaultSortKey string, defaultDirection string) (*Pagination, error) {
+	p := NewPagination(defaultSortKey, defaultDirection)
+	if limitRaw != "" {
+		limit, err := strconv.Atoi(limitRaw)
+		if err != nil || limit <= 0 {
+			return nil, errors.New("invalid limit")
+		}
+		if limit > PAGINATION_MAX_LIMIT {
+			limit = PAGINATION_MAX_LIMIT
+		}
+		p.Limit = limit
+	}
+	if sortKeyRaw != "" {
+		if _, hit := paginationSortKeyColumns[sortKeyRaw]; !hit {
+			return nil, errors.New("invalid sort key")
+		}
+		p.SortKey = sortKeyRaw
+	}
+	if directionRaw != "" {
+		if directionRaw != PAGINATION_DIRECTION_ASC && directionRaw != PAGINATION_DIRECTION_DESC {
+			return nil, errors.New("invalid sort direction")
+		}
+		p.Direction = directionRaw
+	}
+	if cursorRaw != "" {
+		cursor, err := DecodePaginationCursor(cursorRaw)
+		if err != nil {
+			return nil, err
+		}
+		if cursor.SortKey != p.SortKey {
+			return nil, errors.New("cursor does not match sort key")
+		}
+		p.cursor = cursor
+	}
+	return p, nil
+}
+
+// NewOptionalPaginationByRequest is NewPaginationByRequest for the lists which were not paginated,
+// the whole list is feedback in one page when both of cursor and limit are absent.
+func NewOptionalPaginationByRequest(cursorRaw string, limitRaw string, sortKeyRaw string, directionRaw string, defaultSortKey string, defaultDirection string) (*Pagination, error) {
+	p, err := NewPaginationByRequest(cursorRaw, limitRaw, sortKeyRaw, directionRaw, defaultSortKey, defaultDirection)
+	if err != nil {
+		return nil, err
+	}
+	if cursorRaw == "" && limitRaw == "" {
+		p.Limit = PAGINATION_UNLIMITED
+	}
+	return p, nil
+}
+
+func DecodePaginationCursor(cursorRaw string) (*PaginationCursor, error) {
+	cursorJSON, err := base64.RawURLEncoding.DecodeString(cursorRaw)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	cursor := &PaginationCursor{}
+	if err := json.Unmarshal(cursorJSON, cursor); err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	if cursor.SortKey == PAGINATION_SORT_KEY_CREATED_AT {
+		if _, err := time.Parse(time.RFC3339Nano, cursor.SortValue); err != nil {
+			return nil, errors.New("invalid cursor")
+		}
+	}
+	return cursor, nil
+}
+
+func (p *Pagination) EncodeCursor(id int, createdAt time.Time) string {
+	cursor := &PaginationCursor{
+		SortKey: p.SortKey,
+		ID:      id,
+	}
+	if p.SortKey == PAGINATION_SORT_KEY_CREATED_AT {
+		cursor.SortValue = createdAt.UTC().Format(time.RFC3339Nano)
+	}
+	cursorJSON, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(cursorJSON)
+}
+
+// Apply add keyset condition, order and limit to the query.
+// The limit is one more than page size for detecting if there are more items.
+func (p *Pagination) Apply(query *gorm.DB, table string) *gorm.DB {
+	idColumn := table + ".id"
+	sortColumn := table + "." + paginationSortKeyColumns[p.SortKey]
+	operator := ">"
+	if p.Direction == PAGINATION_DIRECTION_DESC {
+		operator = "<"
+	}
+	if p.cursor != nil {
+		if p.SortKey == PAGINATION_SORT_KEY_ID {
+			query = query.Where(idColumn+" "+operator+" ?", p.cursor.ID)
+		} else {
+			sortValue, _ := time.Parse(time.RFC3339Nano, p.cursor.SortValue)
+			query = query.Where("("+sortColumn+", "+idColumn+") "+operator+" (?, ?)", sortValue, p.cursor.ID)
+		}
+	}
+	desc := p.Direction == PAGINATION_DIRECTION_DESC
+	query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: sortColumn, Raw: true}, Desc: desc})
+	if p.SortKey != PAGINATION_SORT_KEY_ID {
+		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: idColumn, Raw: true}, Desc: desc})
+	}
+	if p.IsUnlimited() {
+		return query
+	}
+	return query.Limit(p.Limit + 1)
+}
+
+func (p *Pagination) IsUnlimited() bool {
+	return p.Limit == PAGINATION_UNLIMITED
+}
+
+// HasMore tells if the fetched item count exceeds page size, the extra item should be trimmed.
+func (p *Pagination) HasMore(fetched int) bool {
+	return !p.IsUnlimited() && fetched > p.Limit
+}
+
+func NewPageInfo() *PageInfo {
+	return &PageInfo{}
+}
+
+func (i *PageInfo) SetNextCursor(nextCursor string) {
+	i.NextCursor = nextCursor
+	i.HasMore = true
+}
@@ -0,0 +1,43 @@
+package model
+
+// publicEmailDomains are the free mail providers, anyone can register an address under them,
+// so they can never prove the ownership of a team.
+var publicEmailDomains = map[string]bool{
+	"gmail.com":      true,
+	"googlemail.com": true,
+	"outlook.com":    true,
+	"hotmail.com":    true,
+	"live.com":       true,
+	"msn.com":        true,
+	"yahoo.com":      true,
+	"ymail.com":      true,
+	"icloud.com":     true,
+	"me.com":         true,
+	"mac.com":        true,
+	"aol.com":        true,
+	"proton.me":      true,
+	"protonmail.com": true,
+	"pm.me":          true,
+	"gmx.com":        true,
+	"gmx.net":        true,
+	"gmx.de":         true,
+	"web.de":         true,
+	"mail.com":       true,
+	"mail.ru":        true,
+	"yandex.com":     true,
+	"yandex.ru":      true,
+	"zoho.com":       true,
+	"tutanota.com":   true,
+	"fastmail.com":   true,
+	"hey.com":        true,
+	"qq.com":         true,
+	"163.com":        true,
+	"126.com":        true,
+	"sina.com":       true,
+	"naver.com":      true,
+}
+
+// IsPublicEmailDomain tells if the email domain belongs to a free mail provider.
+func IsPublicEmailDomain(domain string) bool {
+	return publicEmailDomains[NormalizeEmailDomain(domain)]
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// removed team members are kept after the team member deleted, so the user will not join the team by claimed email domain again.
+const (
+	REMOVED_TEAM_MEMBER_REASON_DEPROVISIONED = 1 // deleted by SCIM
+	REMOVED_TEAM_MEMBER_REASON_USER_DELETED  = 2 // the user deleted itself, the email is kept for the user signed up again
+)
+
+type RemovedTeamMember struct {
+	ID        int       `json:"id" gorm:"column:id;type:bigserial;primary_key"`
+	TeamID    int       `json:"teamID" gorm:"column:team_id;type:bigserial;index:removed_team_members_team_user_id"`
+	UserID    int       `json:"userID" gorm:"column:user_id;type:bigserial;index:removed_team_members_team_user_id"`
+	Email     string    `json:"email" gorm:"column:email;type:varchar;size:255"`
+	Reason    int       `json:"reason" gorm:"column:reason;type:smallint"`
+	CreatedAt time.Time `gorm:"column:created_at;type:timestamp"`
+}
+
+func NewRemovedTeamMember(teamMember *TeamMember, user *User, reason int) *RemovedTeamMember {
+	return &RemovedTeamMember{
+		TeamID:    teamMember.TeamID,
+		UserID:    teamMember.UserID,
+		Email:     strings.ToLower(user.Email),
+		Reason:    reason,
+		CreatedAt: time.Now().UTC(),
+	}
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type RemovedTeamMemberStorage struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewRemovedTeamMemberStorage(db *gorm.DB, logger *zap.SugaredLogger) *RemovedTeamMemberStorage {
+	return &RemovedTeamMemberStorage{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (d *RemovedTeamMemberStorage) Create(u *RemovedTeamMember) (int, error) {
+	if err := d.db.Create(u).Error; err != nil {
+		return 0, err
+	}
+	return u.ID, nil
+}
+
+// DoesTeamRemovedTargetUser match the user by id, or by email for the user signed up again after deleted.
+func (d *RemovedTeamMemberStorage) DoesTeamRemovedTargetUser(teamID int, userID int, email string) (bool, error) {
+	var count int64
+	if err := d.db.Model(&RemovedTeamMember{}).Where("team_id = ? AND (user_id = ? OR email = ?)", teamID, userID, strings.ToLower(email)).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (d *RemovedTeamMemberStorage) DeleteByTeamID(teamID int) error {
+	if err := d.db.Where("team_id = ?", teamID).Delete(&RemovedTeamMember{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	redis "github.com/redis/go-redis/v9"
+
+	"go.uber.org/zap"
+)
+
+const REQUEST_NONCE_KEY_TEMPLATE = "request_nonce_%s"
+
+// RequestNonceCache records the nonce of signed internal requests for replay protection.
+type RequestNonceCache struct {
+	logger  *zap.SugaredLogger
+	cache   *redis.Client
+	context context.Context
+}
+
+func NewRequestNonceCache(cache *redis.Client, logger *zap.SugaredLogger) *RequestNonceCache {
+	return &RequestNonceCache{
+		logger:  logger,
+		cache:   cache,
+		context: context.Background(),
+	}
+}
+
+// MarkNonce set the nonce only if absent, it returns false when the nonce is already used.
+func (c *RequestNonceCache) MarkNonce(nonce string, ttl time.Duration) (bool, error) {
+	key := fmt.Sprintf(REQUEST_NONCE_KEY_TEMPLATE, nonce)
+	return c.cache.SetNX(c.context, key, 1, ttl).Result()
+}
@@ -0,0 +1,13 @@
+package model
+
+type ReviewAccessRequestRequest struct {
+	Comment string `json:"comment" validate:"max=1024"`
+}
+
+func NewReviewAccessRequestRequest() *ReviewAccessRequestRequest {
+	return &ReviewAccessRequestRequest{}
+}
+
+func (req *ReviewAccessRequestRequest) ExportComment() string {
+	return req.Comment
+}
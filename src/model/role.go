@@ -0,0 +1,133 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/idconvertor"
+)
+
+// the built-in roles are seeded as system roles, their role id equals to the USER_ROLE_* constants.
+// system roles are shared by all teams and can not be modified or deleted.
+const ROLE_ID_SYSTEM_MIN = USER_ROLE_OWNER
+const ROLE_ID_SYSTEM_MAX = USER_ROLE_VIEWER
+
+var SystemRoleNameMap = map[int]string{
+	USER_ROLE_OWNER:  "Owner",
+	USER_ROLE_ADMIN:  "Admin",
+	USER_ROLE_EDITOR: "Editor",
+	USER_ROLE_VIEWER: "Viewer",
+}
+
+// RolePermissions holds the attribute set of a role.
+// map[AttributeCategory][unitType][Attribute]status
+type RolePermissions map[int]map[int]map[int]bool
+
+type Role struct {
+	ID          int       `json:"id" gorm:"column:id;type:bigserial;primary_key;index:roles_id_team_id"`
+	UID         uuid.UUID `json:"uid" gorm:"column:uid;type:uuid;not null"`
+	Name        string    `json:"name" gorm:"column:name;type:varchar;size:255;not null"`
+	TeamID      int       `json:"teamID" gorm:"column:team_id;type:bigserial;index:roles_id_team_id"`
+	Permissions string    `json:"permissions" gorm:"column:permissions;type:jsonb"`
+	CreatedAt   time.Time `gorm:"column:created_at;type:timestamp"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;type:timestamp"`
+}
+
+type RoleForExport struct {
+	ID          string          `json:"roleID"`
+	UID         uuid.UUID       `json:"uid"`
+	TeamID      string          `json:"teamID"`
+	Name        string          `json:"name"`
+	Permissions RolePermissions `json:"permissions"`
+	IsSystem    bool            `json:"isSystem"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+}
+
+func NewRoleByCreateRoleRequest(teamID int, req *CreateRoleRequest) *Role {
+	role := &Role{
+		TeamID: teamID,
+		Name:   strings.TrimSpace(req.Name),
+	}
+	role.SetPermissions(req.Permissions)
+	role.InitUID()
+	role.InitCreatedAt()
+	role.InitUpdatedAt()
+	return role
+}
+
+func NewSystemRole(roleID int, permissions RolePermissions) *Role {
+	role := &Role{
+		ID:     roleID,
+		TeamID: TEAM_DEFAULT_ID,
+		Name:   SystemRoleNameMap[roleID],
+	}
+	role.SetPermissions(permissions)
+	role.InitUID()
+	role.InitCreatedAt()
+	role.InitUpdatedAt()
+	return role
+}
+
+func IsSystemRoleID(roleID int) bool {
+	return roleID >= ROLE_ID_SYSTEM_MIN && roleID <= ROLE_ID_SYSTEM_MAX
+}
+
+func (r *Role) InitUID() {
+	r.UID = uuid.New()
+}
+
+func (r *Role) InitCreatedAt() {
+	r.CreatedAt = time.Now().UTC()
+}
+
+func (r *Role) InitUpdatedAt() {
+	r.UpdatedAt = time.Now().UTC()
+}
+
+func (r *Role) ExportID() int {
+	return r.ID
+}
+
+func (r *Role) IsSystemRole() bool {
+	return IsSystemRoleID(r.ID)
+}
+
+func (r *Role) SetPermissions(permissions RolePermissions) {
+	if permissions == nil {
+		permissions = RolePermissions{}
+	}
+	payload, _ := json.Marshal(permissions)
+	r.Permissions = string(payload)
+}
+
+func (r *Role) ExportPermissions() RolePermissions {
+	permissions := RolePermissions{}
+	json.Unmarshal([]byte(r.Permissions), &permissions)
+	return permissions
+}
+
+func (r *Role) UpdateByUpdateRoleRequest(req *UpdateRoleRequest) {
+	if name := strings.TrimSpace(req.Name); name != "" {
+		r.Name = name
+	}
+	if req.Permissions != nil {
+		r.SetPermissions(req.Permissions)
+	}
+	r.InitUpdatedAt()
+}
+
+func (r *Role) Export() *RoleForExport {
+	return &RoleForExport{
+		ID:          idconvertor.ConvertIntToString(r.ID),
+		UID:         r.UID,
+		TeamID:      idconvertor.ConvertIntToString(r.TeamID),
+		Name:        r.Name,
+		Permissions: r.ExportPermissions(),
+		IsSystem:    r.IsSystemRole(),
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}
+}
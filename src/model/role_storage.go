@@ -0,0 +1,103 @@
+package model
+
+import (
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RoleStorage struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewRoleStorage(db *gorm.DB, logger *zap.SugaredLogger) *RoleStorage {
+	return &RoleStorage{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (d *RoleStorage) Create(r *Role) (int, error) {
+	if err := d.db.Create(r).Error; err != nil {
+		return 0, err
+	}
+	return r.ID, nil
+}
+
+func (d *RoleStorage) RetrieveByID(id int) (*Role, error) {
+	r := &Role{}
+	if err := d.db.First(r, id).Error; err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// retrieve system roles and custom roles of the team.
+func (d *RoleStorage) RetrieveByTeamID(teamID int) ([]*Role, error) {
+	var roles []*Role
+	if err := d.db.Where("team_id = ? OR id BETWEEN ? AND ?", teamID, ROLE_ID_SYSTEM_MIN, ROLE_ID_SYSTEM_MAX).Order("id ASC").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (d *RoleStorage) RetrieveByTeamIDAndID(teamID int, id int) (*Role, error) {
+	r := &Role{}
+	if err := d.db.Where("id = ? AND (team_id = ? OR id BETWEEN ? AND ?)", id, teamID, ROLE_ID_SYSTEM_MIN, ROLE_ID_SYSTEM_MAX).First(r).Error; err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (d *RoleStorage) IsRoleNameExists(teamID int, name string) bool {
+	var count int64
+	d.db.Model(&Role{}).Where("(team_id = ? OR id BETWEEN ? AND ?) AND lower(name) = lower(?)", teamID, ROLE_ID_SYSTEM_MIN, ROLE_ID_SYSTEM_MAX, name).Count(&count)
+	if count == 0 {
+		return false
+	}
+	return true
+}
+
+// UpsertSystemRoles write the system roles with their fixed id, the name and permissions always follow the given roles.
+// the id sequence will be moved after the system roles to avoid conflict with custom roles.
+func (d *RoleStorage) UpsertSystemRoles(roles []*Role) error {
+	return d.db.Transaction(func(tx *gorm.DB) error {
+		for _, role := range roles {
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"name", "team_id", "permissions", "updated_at"}),
+			}).Create(role).Error; err != nil {
+				return err
+			}
+		}
+		// the postgres sequence is not moved by the rows inserted with id, move it so custom role ids start after the system roles.
+		// the other databases assign the next id after the max one.
+		if tx.Dialector.Name() != "postgres" {
+			return nil
+		}
+		return tx.Exec("SELECT setval(pg_get_serial_sequence('roles', 'id'), GREATEST((SELECT MAX(id) FROM roles), ?))", ROLE_ID_SYSTEM_MAX).Error
+	})
+}
+
+func (d *RoleStorage) UpdateByID(r *Role) error {
+	if err := d.db.Model(&Role{}).Where("id = ?", r.ID).Select("*").Omit("id", "uid", "team_id", "created_at").Updates(r).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *RoleStorage) DeleteByTeamIDAndID(teamID int, id int) error {
+	if err := d.db.Where("team_id = ? AND id = ?", teamID, id).Delete(&Role{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+// delete custom roles of the team, system roles are kept.
+func (d *RoleStorage) DeleteByTeamID(teamID int) error {
+	if err := d.db.Where("team_id = ? AND id NOT BETWEEN ? AND ?", teamID, ROLE_ID_SYSTEM_MIN, ROLE_ID_SYSTEM_MAX).Delete(&Role{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
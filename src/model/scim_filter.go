@@ -0,0 +1,240 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrSCIMFilterUnsupportedAttribute is feedback when the filter can not be translated for the columns, it is a client error.
+var ErrSCIMFilterUnsupportedAttribute = errors.New("unsupported filter attribute")
+
+const SCIM_FILTER_OPERATOR_EQ = "eq"
+const SCIM_FILTER_OPERATOR_NE = "ne"
+const SCIM_FILTER_OPERATOR_CO = "co"
+const SCIM_FILTER_OPERATOR_SW = "sw"
+const SCIM_FILTER_OPERATOR_EW = "ew"
+const SCIM_FILTER_OPERATOR_PR = "pr"
+
+var scimFilterOperators = map[string]bool{
+	SCIM_FILTER_OPERATOR_EQ: true,
+	SCIM_FILTER_OPERATOR_NE: true,
+	SCIM_FILTER_OPERATOR_CO: true,
+	SCIM_FILTER_OPERATOR_SW: true,
+	SCIM_FILTER_OPERATOR_EW: true,
+	SCIM_FILTER_OPERATOR_PR: true,
+}
+
+// SCIM user filter attributes (in lower case) and mapped columns
+var SCIMUserFilterColumns = map[string]string{
+	"id":           "users.uid::text",
+	"username":     "users.email",
+	"emails":       "users.email",
+	"emails.value": "users.email",
+	"externalid":   "users.sso_config->>'SCIMExternalID'",
+	"displayname":  "users.nickname",
+}
+
+// SCIMFilterCondition is a single attribute comparison, e.g. userName eq "bjensen@example.com".
+type SCIMFilterCondition struct {
+	Attribute string
+	Operator  string
+	Value     string
+}
+
+// SCIMFilter is the subset of RFC 7644 filter which identity providers used,
+// the comparisons joined by "and", value path like members[value eq "x"] are flatten to members.value.
+type SCIMFilter struct {
+	Conditions []*SCIMFilterCondition
+}
+
+func NewSCIMFilter() *SCIMFilter {
+	return &SCIMFilter{
+		Conditions: make([]*SCIMFilterCondition, 0),
+	}
+}
+
+func ParseSCIMFilter(raw string) (*SCIMFilter, error) {
+	filter := NewSCIMFilter()
+	parser := &scimFilterParser{input: strings.TrimSpace(raw)}
+	if parser.input == "" {
+		return filter, nil
+	}
+	for {
+		conditions, err := parser.parseComparison("")
+		if err != nil {
+			return nil, err
+		}
+		filter.Conditions = append(filter.Conditions, conditions...)
+		parser.skipSpace()
+		if parser.done() {
+			return filter, nil
+		}
+		if !strings.EqualFold(parser.readWord(), "and") {
+			return nil, errors.New("only \"and\" logical operator supported")
+		}
+	}
+}
+
+func (f *SCIMFilter) IsEmpty() bool {
+	return len(f.Conditions) == 0
+}
+
+// ExportSQL export the filter as SQL condition with mapped columns.
+func (f *SCIMFilter) ExportSQL(columns map[string]string) (string, []interface{}, error) {
+	sqls := make([]string, 0, len(f.Conditions))
+	vars := make([]interface{}, 0, len(f.Conditions))
+	for _, condition := range f.Conditions {
+		column, hit := columns[condition.Attribute]
+		if !hit {
+			return "", nil, fmt.Errorf("%w: %s", ErrSCIMFilterUnsupportedAttribute, condition.Attribute)
+		}
+		escaper := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+		switch condition.Operator {
+		case SCIM_FILTER_OPERATOR_EQ:
+			sqls = append(sqls, "LOWER("+column+") = LOWER(?)")
+			vars = append(vars, condition.Value)
+		case SCIM_FILTER_OPERATOR_NE:
+			sqls = append(sqls, "LOWER("+column+") <> LOWER(?)")
+			vars = append(vars, condition.Value)
+		case SCIM_FILTER_OPERATOR_CO:
+			sqls = append(sqls, column+" ILIKE ?")
+			vars = append(vars, "%"+escaper.Replace(condition.Value)+"%")
+		case SCIM_FILTER_OPERATOR_SW:
+			sqls = append(sqls, column+" ILIKE ?")
+			vars = append(vars, escaper.Replace(condition.Value)+"%")
+		case SCIM_FILTER_OPERATOR_EW:
+			sqls = append(sqls, column+" ILIKE ?")
+			vars = append(vars, "%"+escaper.Replace(condition.Value))
+		case SCIM_FILTER_OPERATOR_PR:
+			sqls = append(sqls, "COALESCE("+column+", '') <> ''")
+		}
+	}
+	return strings.Join(sqls, " AND "), vars, nil
+}
+
+// Match compare the given value with condition in case-insensitive, it used for filter the in-memory resources.
+func (c *SCIMFilterCondition) Match(value string) bool {
+	value = strings.ToLower(value)
+	expected := strings.ToLower(c.Value)
+	switch c.Operator {
+	case SCIM_FILTER_OPERATOR_EQ:
+		return value == expected
+	case SCIM_FILTER_OPERATOR_NE:
+		return value != expected
+	case SCIM_FILTER_OPERATOR_CO:
+		return strings.Contains(value, expected)
+	case SCIM_FILTER_OPERATOR_SW:
+		return strings.HasPrefix(value, expected)
+	case SCIM_FILTER_OPERATOR_EW:
+		return strings.HasSuffix(value, expected)
+	case SCIM_FILTER_OPERATOR_PR:
+		return value != ""
+	}
+	return false
+}
+
+type scimFilterParser struct {
+	input string
+	pos   int
+}
+
+func (p *scimFilterParser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *scimFilterParser) skipSpace() {
+	for !p.done() && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// readWord read until space, "[" or "]".
+func (p *scimFilterParser) readWord() string {
+	p.skipSpace()
+	start := p.pos
+	for !p.done() && p.input[p.pos] != ' ' && p.input[p.pos] != '[' && p.input[p.pos] != ']' {
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+func (p *scimFilterParser) readValue() (string, error) {
+	p.skipSpace()
+	if p.done() {
+		return "", errors.New("missing filter value")
+	}
+	if p.input[p.pos] != '"' {
+		// true, false, null and numbers
+		return p.readWord(), nil
+	}
+	p.pos++
+	var b strings.Builder
+	for !p.done() {
+		ch := p.input[p.pos]
+		p.pos++
+		switch ch {
+		case '\\':
+			if p.done() {
+				return "", errors.New("invalid escape in filter value")
+			}
+			b.WriteByte(p.input[p.pos])
+			p.pos++
+		case '"':
+			return b.String(), nil
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	return "", errors.New("unterminated filter value")
+}
+
+func (p *scimFilterParser) parseComparison(prefix string) ([]*SCIMFilterCondition, error) {
+	attribute := p.readWord()
+	if attribute == "" {
+		return nil, errors.New("missing filter attribute")
+	}
+	// strip schema urn prefix, e.g. urn:ietf:params:scim:schemas:core:2.0:User:userName
+	if index := strings.LastIndex(attribute, ":"); index >= 0 {
+		attribute = attribute[index+1:]
+	}
+	attribute = strings.ToLower(prefix + attribute)
+
+	// value path, e.g. emails[type eq "work"] or members[value eq "x"]
+	if !p.done() && p.input[p.pos] == '[' {
+		p.pos++
+		var conditions []*SCIMFilterCondition
+		for {
+			inner, err := p.parseComparison(attribute + ".")
+			if err != nil {
+				return nil, err
+			}
+			conditions = append(conditions, inner...)
+			p.skipSpace()
+			if !p.done() && p.input[p.pos] == ']' {
+				p.pos++
+				return conditions, nil
+			}
+			if !strings.EqualFold(p.readWord(), "and") {
+				return nil, errors.New("unterminated value path filter")
+			}
+		}
+	}
+
+	operator := strings.ToLower(p.readWord())
+	if !scimFilterOperators[operator] {
+		return nil, errors.New("unsupported filter operator: " + operator)
+	}
+	condition := &SCIMFilterCondition{
+		Attribute: attribute,
+		Operator:  operator,
+	}
+	if operator != SCIM_FILTER_OPERATOR_PR {
+		value, err := p.readValue()
+		if err != nil {
+			return nil, err
+		}
+		condition.Value = value
+	}
+	return []*SCIMFilterCondition{condition}, nil
+}
@@ -0,0 +1,109 @@
+package model
+
+import (
+	"sort"
+	"strings"
+)
+
+// SCIM Groups are mapped to the built-in user roles, groups can not be created or deleted,
+// and the owner group is read-only, team ownership should be transferred in Kozmo.
+var SCIMGroupIDUserRoleMap = map[string]int{
+	"owner":  USER_ROLE_OWNER,
+	"admin":  USER_ROLE_ADMIN,
+	"editor": USER_ROLE_EDITOR,
+	"viewer": USER_ROLE_VIEWER,
+}
+
+var SCIMGroupDisplayNameMap = map[int]string{
+	USER_ROLE_OWNER:  "Owner",
+	USER_ROLE_ADMIN:  "Admin",
+	USER_ROLE_EDITOR: "Editor",
+	USER_ROLE_VIEWER: "Viewer",
+}
+
+type SCIMGroupMember struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+type SCIMGroup struct {
+	Schemas     []string           `json:"schemas"`
+	ID          string             `json:"id"`
+	DisplayName string             `json:"displayName"`
+	Members     []*SCIMGroupMember `json:"members,omitempty"`
+	Meta        *SCIMMeta          `json:"meta,omitempty"`
+	userRole    int
+}
+
+func NewSCIMGroupByUserRole(userRole int) (*SCIMGroup, bool) {
+	displayName, hit := SCIMGroupDisplayNameMap[userRole]
+	if !hit {
+		return nil, false
+	}
+	id := strings.ToLower(displayName)
+	return &SCIMGroup{
+		Schemas:     []string{SCIM_SCHEMA_GROUP},
+		ID:          id,
+		DisplayName: displayName,
+		Meta: &SCIMMeta{
+			ResourceType: SCIM_RESOURCE_TYPE_GROUP,
+			Location:     SCIM_BASE_PATH + "/Groups/" + id,
+		},
+		userRole: userRole,
+	}, true
+}
+
+func NewSCIMGroupByID(id string) (*SCIMGroup, bool) {
+	userRole, hit := SCIMGroupIDUserRoleMap[strings.ToLower(id)]
+	if !hit {
+		return nil, false
+	}
+	return NewSCIMGroupByUserRole(userRole)
+}
+
+// NewAllSCIMGroups export all groups in role order.
+func NewAllSCIMGroups() []*SCIMGroup {
+	userRoles := make([]int, 0, len(SCIMGroupDisplayNameMap))
+	for userRole := range SCIMGroupDisplayNameMap {
+		userRoles = append(userRoles, userRole)
+	}
+	sort.Ints(userRoles)
+	groups := make([]*SCIMGroup, 0, len(userRoles))
+	for _, userRole := range userRoles {
+		group, _ := NewSCIMGroupByUserRole(userRole)
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+func (g *SCIMGroup) ExportUserRole() int {
+	return g.userRole
+}
+
+func (g *SCIMGroup) IsReadOnly() bool {
+	return g.userRole == USER_ROLE_OWNER
+}
+
+func (g *SCIMGroup) ExportRef() *SCIMGroupRef {
+	return &SCIMGroupRef{
+		Value:   g.ID,
+		Display: g.DisplayName,
+		Ref:     g.Meta.Location,
+	}
+}
+
+func (g *SCIMGroup) SetMembers(users []*User) {
+	g.Members = make([]*SCIMGroupMember, 0, len(users))
+	for _, user := range users {
+		g.Members = append(g.Members, &SCIMGroupMember{
+			Value:   user.GetUIDInString(),
+			Display: user.ExportEmail(),
+			Ref:     SCIM_BASE_PATH + "/Users/" + user.GetUIDInString(),
+		})
+	}
+}
+
+func (g *SCIMGroup) ExportForFeedback() interface{} {
+	return g
+}
@@ -0,0 +1,261 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+const SCIM_PATCH_OP_ADD = "add"
+const SCIM_PATCH_OP_REPLACE = "replace"
+const SCIM_PATCH_OP_REMOVE = "remove"
+
+type SCIMPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+type SCIMPatchRequest struct {
+	Schemas    []string              `json:"schemas"`
+	Operations []*SCIMPatchOperation `json:"Operations"`
+}
+
+func NewSCIMPatchRequest() *SCIMPatchRequest {
+	return &SCIMPatchRequest{}
+}
+
+func (req *SCIMPatchRequest) Validate() error {
+	if len(req.Operations) == 0 {
+		return errors.New("patch operations is required")
+	}
+	for _, operation := range req.Operations {
+		switch operation.ExportOp() {
+		case SCIM_PATCH_OP_ADD, SCIM_PATCH_OP_REPLACE, SCIM_PATCH_OP_REMOVE:
+		default:
+			return errors.New("unsupported patch operation: " + operation.Op)
+		}
+	}
+	return nil
+}
+
+// ExportOp export operation name in lower case, Entra ID send "Add", "Replace" and "Remove".
+func (o *SCIMPatchOperation) ExportOp() string {
+	return strings.ToLower(o.Op)
+}
+
+// ExportPath export attribute path without schema urn prefix in lower case.
+func (o *SCIMPatchOperation) ExportPath() string {
+	path := o.Path
+	if strings.HasPrefix(strings.ToLower(path), "urn:") {
+		if index := strings.LastIndex(path, ":"); index >= 0 {
+			path = path[index+1:]
+		}
+	}
+	return strings.ToLower(strings.TrimSpace(path))
+}
+
+// ApplyToSCIMUser apply operations to the SCIM user, the attributes which not mapped to Kozmo are ignored.
+func (req *SCIMPatchRequest) ApplyToSCIMUser(scimUser *SCIMUser) error {
+	for _, operation := range req.Operations {
+		path := operation.ExportPath()
+		if path == "" {
+			// Okta style, the value is an object of attributes
+			if operation.ExportOp() == SCIM_PATCH_OP_REMOVE {
+				return errors.New("remove operation requires path")
+			}
+			var attributes map[string]json.RawMessage
+			if err := json.Unmarshal(operation.Value, &attributes); err != nil {
+				return errors.New("patch value should be an object when path is absent")
+			}
+			for key, value := range attributes {
+				if err := applySCIMUserAttribute(scimUser, SCIM_PATCH_OP_REPLACE, strings.ToLower(key), value); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := applySCIMUserAttribute(scimUser, operation.ExportOp(), path, operation.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applySCIMUserAttribute(scimUser *SCIMUser, op string, path string, value json.RawMessage) error {
+	var str string
+	if op != SCIM_PATCH_OP_REMOVE {
+		json.Unmarshal(value, &str)
+	}
+	if scimUser.Name == nil {
+		scimUser.Name = &SCIMUserName{}
+	}
+	switch {
+	case path == "active":
+		if op == SCIM_PATCH_OP_REMOVE {
+			return errors.New("active can not be removed")
+		}
+		var raw interface{}
+		if err := json.Unmarshal(value, &raw); err != nil {
+			return err
+		}
+		active, err := ParseSCIMBool(raw)
+		if err != nil {
+			return err
+		}
+		scimActive := SCIMBool(active)
+		scimUser.Active = &scimActive
+	case path == "username":
+		scimUser.UserName = str
+	case path == "externalid":
+		scimUser.ExternalID = str
+	case path == "displayname":
+		scimUser.DisplayName = str
+	case path == "name":
+		if op == SCIM_PATCH_OP_REMOVE {
+			scimUser.Name = &SCIMUserName{}
+			return nil
+		}
+		name := &SCIMUserName{}
+		if err := json.Unmarshal(value, name); err != nil {
+			return err
+		}
+		scimUser.Name = name
+	case path == "name.formatted":
+		scimUser.Name.Formatted = str
+	case path == "name.givenname":
+		scimUser.Name.GivenName = str
+	case path == "name.familyname":
+		scimUser.Name.FamilyName = str
+	case path == "emails" || strings.HasPrefix(path, "emails["):
+		if op == SCIM_PATCH_OP_REMOVE {
+			return errors.New("emails can not be removed")
+		}
+		if path == "emails" {
+			var emails []*SCIMUserEmail
+			if err := json.Unmarshal(value, &emails); err != nil {
+				return err
+			}
+			scimUser.Emails = emails
+			return nil
+		}
+		// e.g. emails[type eq "work"].value
+		scimUser.Emails = []*SCIMUserEmail{{Value: str, Type: "work", Primary: true}}
+	default:
+		// attributes not mapped to Kozmo, e.g. title, phoneNumbers and enterprise extension
+	}
+	return nil
+}
+
+// ApplyToGroupMembers apply operations to the member ids of group and return the new member ids.
+func (req *SCIMPatchRequest) ApplyToGroupMembers(group *SCIMGroup, memberIDs []string) ([]string, error) {
+	members := make(map[string]bool, len(memberIDs))
+	for _, memberID := range memberIDs {
+		members[memberID] = true
+	}
+	for _, operation := range req.Operations {
+		path := operation.ExportPath()
+		op := operation.ExportOp()
+		switch {
+		case path == "":
+			// Okta style, the value is an object of attributes
+			var attributes map[string]json.RawMessage
+			if err := json.Unmarshal(operation.Value, &attributes); err != nil {
+				return nil, errors.New("patch value should be an object when path is absent")
+			}
+			for key, value := range attributes {
+				switch strings.ToLower(key) {
+				case "members":
+					newMembers, err := decodeSCIMGroupMemberIDs(value)
+					if err != nil {
+						return nil, err
+					}
+					if op == SCIM_PATCH_OP_REPLACE {
+						members = make(map[string]bool, len(newMembers))
+					}
+					for _, memberID := range newMembers {
+						members[memberID] = true
+					}
+				case "displayname":
+					if err := validateSCIMGroupDisplayName(group, value); err != nil {
+						return nil, err
+					}
+				}
+			}
+		case path == "members":
+			var memberIDsInValue []string
+			if len(operation.Value) > 0 {
+				var err error
+				memberIDsInValue, err = decodeSCIMGroupMemberIDs(operation.Value)
+				if err != nil {
+					return nil, err
+				}
+			}
+			switch op {
+			case SCIM_PATCH_OP_ADD:
+				for _, memberID := range memberIDsInValue {
+					members[memberID] = true
+				}
+			case SCIM_PATCH_OP_REPLACE:
+				members = make(map[string]bool, len(memberIDsInValue))
+				for _, memberID := range memberIDsInValue {
+					members[memberID] = true
+				}
+			case SCIM_PATCH_OP_REMOVE:
+				if len(operation.Value) == 0 {
+					members = make(map[string]bool)
+				}
+				for _, memberID := range memberIDsInValue {
+					delete(members, memberID)
+				}
+			}
+		case strings.HasPrefix(path, "members["):
+			// e.g. members[value eq "2819c223-7f76-453a-919d-413861904646"]
+			if op != SCIM_PATCH_OP_REMOVE {
+				return nil, errors.New("value path of members only support remove operation")
+			}
+			filter, err := ParseSCIMFilter(operation.Path)
+			if err != nil {
+				return nil, err
+			}
+			for _, condition := range filter.Conditions {
+				if condition.Attribute != "members.value" || condition.Operator != SCIM_FILTER_OPERATOR_EQ {
+					return nil, errors.New("unsupported members value path")
+				}
+				delete(members, condition.Value)
+			}
+		case path == "displayname":
+			if err := validateSCIMGroupDisplayName(group, operation.Value); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, errors.New("unsupported group path: " + operation.Path)
+		}
+	}
+	ret := make([]string, 0, len(members))
+	for memberID := range members {
+		ret = append(ret, memberID)
+	}
+	return ret, nil
+}
+
+func decodeSCIMGroupMemberIDs(value json.RawMessage) ([]string, error) {
+	var groupMembers []*SCIMGroupMember
+	if err := json.Unmarshal(value, &groupMembers); err != nil {
+		return nil, errors.New("members should be an array of member")
+	}
+	ret := make([]string, 0, len(groupMembers))
+	for _, groupMember := range groupMembers {
+		ret = append(ret, groupMember.Value)
+	}
+	return ret, nil
+}
+
+func validateSCIMGroupDisplayName(group *SCIMGroup, value json.RawMessage) error {
+	var displayName string
+	json.Unmarshal(value, &displayName)
+	if !strings.EqualFold(displayName, group.DisplayName) {
+		return errors.New("group displayName is mapped to role and can not be changed")
+	}
+	return nil
+}
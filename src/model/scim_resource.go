@@ -0,0 +1,80 @@
+package model
+
+import "time"
+
+// SCIM 2.0 (RFC 7643, RFC 7644) schemas
+const SCIM_SCHEMA_USER = "urn:ietf:params:scim:schemas:core:2.0:User"
+const SCIM_SCHEMA_GROUP = "urn:ietf:params:scim:schemas:core:2.0:Group"
+const SCIM_SCHEMA_LIST_RESPONSE = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+const SCIM_SCHEMA_PATCH_OP = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+const SCIM_SCHEMA_ERROR = "urn:ietf:params:scim:api:messages:2.0:Error"
+
+const SCIM_RESOURCE_TYPE_USER = "User"
+const SCIM_RESOURCE_TYPE_GROUP = "Group"
+
+const SCIM_CONTENT_TYPE = "application/scim+json"
+const SCIM_BASE_PATH = "/scim/v2"
+
+// scimType of error response
+const SCIM_ERROR_TYPE_INVALID_FILTER = "invalidFilter"
+const SCIM_ERROR_TYPE_INVALID_SYNTAX = "invalidSyntax"
+const SCIM_ERROR_TYPE_INVALID_PATH = "invalidPath"
+const SCIM_ERROR_TYPE_INVALID_VALUE = "invalidValue"
+const SCIM_ERROR_TYPE_UNIQUENESS = "uniqueness"
+const SCIM_ERROR_TYPE_MUTABILITY = "mutability"
+
+const SCIM_DEFAULT_COUNT = 100
+const SCIM_MAX_COUNT = 1000
+
+type SCIMMeta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+	Location     string    `json:"location"`
+}
+
+type SCIMError struct {
+	Schemas  []string `json:"schemas"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail"`
+	Status   string   `json:"status"`
+}
+
+func NewSCIMError(status string, scimType string, detail string) *SCIMError {
+	return &SCIMError{
+		Schemas:  []string{SCIM_SCHEMA_ERROR},
+		ScimType: scimType,
+		Detail:   detail,
+		Status:   status,
+	}
+}
+
+func (resp *SCIMError) ExportForFeedback() interface{} {
+	return resp
+}
+
+type SCIMListResponse struct {
+	Schemas      []string      `json:"schemas"`
+	TotalResults int           `json:"totalResults"`
+	StartIndex   int           `json:"startIndex"`
+	ItemsPerPage int           `json:"itemsPerPage"`
+	Resources    []interface{} `json:"Resources"`
+}
+
+func NewSCIMListResponse(totalResults int, startIndex int) *SCIMListResponse {
+	return &SCIMListResponse{
+		Schemas:      []string{SCIM_SCHEMA_LIST_RESPONSE},
+		TotalResults: totalResults,
+		StartIndex:   startIndex,
+		Resources:    make([]interface{}, 0),
+	}
+}
+
+func (resp *SCIMListResponse) AppendResource(resource interface{}) {
+	resp.Resources = append(resp.Resources, resource)
+	resp.ItemsPerPage = len(resp.Resources)
+}
+
+func (resp *SCIMListResponse) ExportForFeedback() interface{} {
+	return resp
+}
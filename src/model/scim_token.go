@@ -0,0 +1,95 @@
+package model
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/idconvertor"
+)
+
+// SCIM bearer token, only the sha256 digest of token was stored, the token itself only feedback once when created.
+const SCIM_TOKEN_PREFIX = "kozmo_scim_"
+
+type SCIMToken struct {
+	ID          int       `json:"id" gorm:"column:id;type:bigserial;primary_key;index:scim_tokens_ukey"`
+	UID         uuid.UUID `json:"uid" gorm:"column:uid;type:uuid;not null;index:scim_tokens_ukey"`
+	TeamID      int       `json:"teamID" gorm:"column:team_id;type:bigserial;index:scim_tokens_team_id"`
+	TokenDigest string    `json:"tokenDigest" gorm:"column:token_digest;type:varchar;size:64;not null"`
+	CreatedBy   int       `json:"createdBy" gorm:"column:created_by;type:bigserial"`
+	LastUsedAt  time.Time `gorm:"column:last_used_at;type:timestamp"`
+	CreatedAt   time.Time `gorm:"column:created_at;type:timestamp"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;type:timestamp"`
+}
+
+type SCIMTokenForExport struct {
+	ID         string    `json:"scimTokenID"`
+	UID        uuid.UUID `json:"uid"`
+	TeamID     string    `json:"teamID"`
+	Token      string    `json:"token,omitempty"` // only exported when token created
+	CreatedBy  string    `json:"createdBy"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// NewSCIMToken create a token record and return the plain token.
+func NewSCIMToken(teamID int, createdBy int) (*SCIMToken, string) {
+	buf := make([]byte, 32)
+	rand.Read(buf)
+	token := SCIM_TOKEN_PREFIX + hex.EncodeToString(buf)
+	scimToken := &SCIMToken{
+		TeamID:      teamID,
+		TokenDigest: DigestSCIMToken(token),
+		CreatedBy:   createdBy,
+	}
+	scimToken.InitUID()
+	scimToken.InitCreatedAt()
+	scimToken.InitUpdatedAt()
+	return scimToken, token
+}
+
+func DigestSCIMToken(token string) string {
+	digest := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(digest[:])
+}
+
+func (t *SCIMToken) InitUID() {
+	t.UID = uuid.New()
+}
+
+func (t *SCIMToken) InitCreatedAt() {
+	t.CreatedAt = time.Now().UTC()
+}
+
+func (t *SCIMToken) InitUpdatedAt() {
+	t.UpdatedAt = time.Now().UTC()
+}
+
+func (t *SCIMToken) ExportTeamID() int {
+	return t.TeamID
+}
+
+func (t *SCIMToken) Export() *SCIMTokenForExport {
+	return &SCIMTokenForExport{
+		ID:         idconvertor.ConvertIntToString(t.ID),
+		UID:        t.UID,
+		TeamID:     idconvertor.ConvertIntToString(t.TeamID),
+		CreatedBy:  idconvertor.ConvertIntToString(t.CreatedBy),
+		LastUsedAt: t.LastUsedAt,
+		CreatedAt:  t.CreatedAt,
+		UpdatedAt:  t.UpdatedAt,
+	}
+}
+
+func (t *SCIMToken) ExportWithToken(token string) *SCIMTokenForExport {
+	ret := t.Export()
+	ret.Token = token
+	return ret
+}
+
+func (t *SCIMTokenForExport) ExportForFeedback() interface{} {
+	return t
+}
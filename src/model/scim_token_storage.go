@@ -0,0 +1,64 @@
+package model
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type SCIMTokenStorage struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewSCIMTokenStorage(db *gorm.DB, logger *zap.SugaredLogger) *SCIMTokenStorage {
+	return &SCIMTokenStorage{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (d *SCIMTokenStorage) Create(t *SCIMToken) (int, error) {
+	if err := d.db.Create(t).Error; err != nil {
+		return 0, err
+	}
+	return t.ID, nil
+}
+
+func (d *SCIMTokenStorage) RetrieveByTeamID(teamID int) ([]*SCIMToken, error) {
+	var scimTokens []*SCIMToken
+	if err := d.db.Where("team_id = ?", teamID).Order("id ASC").Find(&scimTokens).Error; err != nil {
+		return nil, err
+	}
+	return scimTokens, nil
+}
+
+func (d *SCIMTokenStorage) RetrieveByTokenDigest(tokenDigest string) (*SCIMToken, error) {
+	scimToken := &SCIMToken{}
+	if err := d.db.Where("token_digest = ?", tokenDigest).First(scimToken).Error; err != nil {
+		return nil, err
+	}
+	return scimToken, nil
+}
+
+func (d *SCIMTokenStorage) UpdateLastUsedAtByID(id int, lastUsedAt time.Time) error {
+	if err := d.db.Model(&SCIMToken{}).Where("id = ?", id).UpdateColumn("last_used_at", lastUsedAt).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *SCIMTokenStorage) DeleteByTeamIDAndID(teamID int, id int) error {
+	if err := d.db.Where("team_id = ? AND id = ?", teamID, id).Delete(&SCIMToken{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *SCIMTokenStorage) DeleteByTeamID(teamID int) error {
+	if err := d.db.Where("team_id = ?", teamID).Delete(&SCIMToken{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,227 @@
+package model
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const SCIM_USER_NICKNAME_MAX_LENGTH = 15
+
+// SCIMBool accept both JSON boolean and string boolean, some identity providers (e.g. Entra ID) send "True" and "False".
+type SCIMBool bool
+
+func (b *SCIMBool) UnmarshalJSON(data []byte) error {
+	value, err := ParseSCIMBool(json.RawMessage(data))
+	if err != nil {
+		return err
+	}
+	*b = SCIMBool(value)
+	return nil
+}
+
+func ParseSCIMBool(value interface{}) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		switch strings.ToLower(v) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+	case json.RawMessage:
+		var raw interface{}
+		if err := json.Unmarshal(v, &raw); err != nil {
+			return false, err
+		}
+		return ParseSCIMBool(raw)
+	}
+	return false, errors.New("invalid boolean value")
+}
+
+type SCIMUserName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type SCIMUserEmail struct {
+	Value   string   `json:"value"`
+	Type    string   `json:"type,omitempty"`
+	Primary SCIMBool `json:"primary"`
+}
+
+type SCIMGroupRef struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+// SCIMUser is the SCIM User resource, it maps to User and the TeamMember of the token team.
+// The id is the UID of User, userName and primary email are the user email, active is the team member status.
+type SCIMUser struct {
+	Schemas     []string         `json:"schemas"`
+	ID          string           `json:"id,omitempty"`
+	ExternalID  string           `json:"externalId,omitempty"`
+	UserName    string           `json:"userName"`
+	Name        *SCIMUserName    `json:"name,omitempty"`
+	DisplayName string           `json:"displayName,omitempty"`
+	Emails      []*SCIMUserEmail `json:"emails,omitempty"`
+	Active      *SCIMBool        `json:"active,omitempty"`
+	Groups      []*SCIMGroupRef  `json:"groups,omitempty"`
+	Meta        *SCIMMeta        `json:"meta,omitempty"`
+}
+
+func NewSCIMUser() *SCIMUser {
+	return &SCIMUser{}
+}
+
+func NewSCIMUserByTeamMember(user *User, teamMember *TeamMember) *SCIMUser {
+	active := SCIMBool(teamMember.IsStatusOK())
+	nickname := user.Export().Nickname
+	scimUser := &SCIMUser{
+		Schemas:     []string{SCIM_SCHEMA_USER},
+		ID:          user.GetUIDInString(),
+		ExternalID:  user.ExportUserSSOConfig().SCIMExternalID,
+		UserName:    user.ExportEmail(),
+		Name:        &SCIMUserName{Formatted: nickname},
+		DisplayName: nickname,
+		Emails:      []*SCIMUserEmail{{Value: user.ExportEmail(), Type: "work", Primary: true}},
+		Active:      &active,
+		Meta: &SCIMMeta{
+			ResourceType: SCIM_RESOURCE_TYPE_USER,
+			Created:      user.CreatedAt,
+			LastModified: latestTime(user.UpdatedAt, teamMember.UpdatedAt),
+			Location:     SCIM_BASE_PATH + "/Users/" + user.GetUIDInString(),
+		},
+	}
+	if group, hit := NewSCIMGroupByUserRole(teamMember.ExportUserRole()); hit {
+		scimUser.Groups = []*SCIMGroupRef{group.ExportRef()}
+	}
+	return scimUser
+}
+
+func (u *SCIMUser) ExportForFeedback() interface{} {
+	return u
+}
+
+func (u *SCIMUser) Validate() error {
+	if u.ExportEmail() == "" {
+		return errors.New("userName or email is required")
+	}
+	if !strings.Contains(u.ExportEmail(), "@") {
+		return errors.New("userName or primary email should be an email address")
+	}
+	return nil
+}
+
+// ExportEmail export primary email, fallback to the first email and userName.
+func (u *SCIMUser) ExportEmail() string {
+	for _, email := range u.Emails {
+		if bool(email.Primary) && email.Value != "" {
+			return strings.TrimSpace(email.Value)
+		}
+	}
+	if strings.Contains(u.UserName, "@") {
+		return strings.TrimSpace(u.UserName)
+	}
+	for _, email := range u.Emails {
+		if email.Value != "" {
+			return strings.TrimSpace(email.Value)
+		}
+	}
+	return strings.TrimSpace(u.UserName)
+}
+
+// ExportNickname export nickname by displayName, name and email in order, and cut to fit the nickname column.
+func (u *SCIMUser) ExportNickname() string {
+	nickname := u.DisplayName
+	if nickname == "" && u.Name != nil {
+		nickname = u.Name.Formatted
+		if nickname == "" {
+			nickname = strings.TrimSpace(u.Name.GivenName + " " + u.Name.FamilyName)
+		}
+	}
+	if nickname == "" {
+		nickname = strings.Split(u.ExportEmail(), "@")[0]
+	}
+	runes := []rune(strings.TrimSpace(nickname))
+	if len(runes) > SCIM_USER_NICKNAME_MAX_LENGTH {
+		runes = runes[:SCIM_USER_NICKNAME_MAX_LENGTH]
+	}
+	return string(runes)
+}
+
+// IsActive tells if the user should be active, the absent active attribute means active.
+func (u *SCIMUser) IsActive() bool {
+	if u.Active == nil {
+		return true
+	}
+	return bool(*u.Active)
+}
+
+// NewUserBySCIMUser create user provisioned by SCIM.
+// The password is random and unknown to anyone, the user should reset password by email before sign in.
+func NewUserBySCIMUser(scimUser *SCIMUser) (*User, error) {
+	randomPassword := make([]byte, 32)
+	rand.Read(randomPassword)
+	passwordDigest, err := bcrypt.GenerateFromPassword(randomPassword, bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	userCustomization, _ := NewUserCustomization().Export()
+	userSSOConfig := NewUserSSOConfig()
+	userSSOConfig.SCIMExternalID = scimUser.ExternalID
+	user := &User{
+		Nickname:       scimUser.ExportNickname(),
+		PasswordDigest: string(passwordDigest),
+		Email:          scimUser.ExportEmail(),
+		Avatar:         "",
+		SSOConfig:      userSSOConfig.Export(),
+		Customization:  userCustomization,
+	}
+	user.InitUID()
+	user.InitCreatedAt()
+	user.InitUpdatedAt()
+	return user, nil
+}
+
+// UpdateBySCIMUser replace the mutable user attributes, the email of user can not be changed.
+func (u *User) UpdateBySCIMUser(scimUser *SCIMUser) error {
+	if !strings.EqualFold(scimUser.ExportEmail(), u.Email) {
+		return errors.New("userName and email can not be changed")
+	}
+	u.Nickname = scimUser.ExportNickname()
+	u.SetSCIMExternalID(scimUser.ExternalID)
+	return nil
+}
+
+// NewTeamMemberBySCIMUser create team member provisioned by SCIM, the provisioned member is a viewer until assigned to a group.
+func NewTeamMemberBySCIMUser(teamID int, user *User, scimUser *SCIMUser) *TeamMember {
+	teamMember := &TeamMember{
+		TeamID:     teamID,
+		UserID:     user.ID,
+		UserRole:   USER_ROLE_VIEWER,
+		Permission: NewTeamMemberPermission().ExportForTeam(),
+		Status:     TEAM_MEMBER_STATUS_OK,
+	}
+	if !scimUser.IsActive() {
+		teamMember.Status = TEAM_MEMBER_STATUS_SUSPEND
+	}
+	teamMember.InitCreatedAt()
+	teamMember.InitUpdatedAt()
+	return teamMember
+}
+
+func latestTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
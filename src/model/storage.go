@@ -6,18 +6,63 @@ import (
 )
 
 type Storage struct {
-	UserStorage       *UserStorage
-	TeamStorage       *TeamStorage
-	TeamMemberStorage *TeamMemberStorage
+	logger                    *zap.SugaredLogger
+	db                        *gorm.DB
+	UserStorage               *UserStorage
+	TeamStorage               *TeamStorage
+	TeamMemberStorage         *TeamMemberStorage
+	RemovedTeamMemberStorage  *RemovedTeamMemberStorage
+	InviteStorage             *InviteStorage
+	DomainStorage             *DomainStorage
+	SCIMTokenStorage          *SCIMTokenStorage
+	RoleStorage               *RoleStorage
+	UnitRoleRelationStorage   *UnitRoleRelationStorage
+	AccessRequestStorage      *AccessRequestStorage
+	AccessRequestEventStorage *AccessRequestEventStorage
+	OutboxEventStorage        *OutboxEventStorage
+	WebhookStorage            *WebhookStorage
+	WebhookDeliveryStorage    *WebhookDeliveryStorage
 }
 
 func NewStorage(postgresDriver *gorm.DB, logger *zap.SugaredLogger) *Storage {
 	userStorage := NewUserStorage(postgresDriver, logger)
 	teamStorage := NewTeamStorage(postgresDriver, logger)
 	teamMemberStorage := NewTeamMemberStorage(postgresDriver, logger)
+	removedTeamMemberStorage := NewRemovedTeamMemberStorage(postgresDriver, logger)
+	inviteStorage := NewInviteStorage(postgresDriver, logger)
+	domainStorage := NewDomainStorage(postgresDriver, logger)
+	scimTokenStorage := NewSCIMTokenStorage(postgresDriver, logger)
+	roleStorage := NewRoleStorage(postgresDriver, logger)
+	unitRoleRelationStorage := NewUnitRoleRelationStorage(postgresDriver, logger)
+	accessRequestStorage := NewAccessRequestStorage(postgresDriver, logger)
+	accessRequestEventStorage := NewAccessRequestEventStorage(postgresDriver, logger)
+	outboxEventStorage := NewOutboxEventStorage(postgresDriver, logger)
+	webhookStorage := NewWebhookStorage(postgresDriver, logger)
+	webhookDeliveryStorage := NewWebhookDeliveryStorage(postgresDriver, logger)
 	return &Storage{
-		UserStorage:       userStorage,
-		TeamStorage:       teamStorage,
-		TeamMemberStorage: teamMemberStorage,
+		logger:                    logger,
+		db:                        postgresDriver,
+		UserStorage:               userStorage,
+		TeamStorage:               teamStorage,
+		TeamMemberStorage:         teamMemberStorage,
+		RemovedTeamMemberStorage:  removedTeamMemberStorage,
+		InviteStorage:             inviteStorage,
+		DomainStorage:             domainStorage,
+		SCIMTokenStorage:          scimTokenStorage,
+		RoleStorage:               roleStorage,
+		UnitRoleRelationStorage:   unitRoleRelationStorage,
+		AccessRequestStorage:      accessRequestStorage,
+		AccessRequestEventStorage: accessRequestEventStorage,
+		OutboxEventStorage:        outboxEventStorage,
+		WebhookStorage:            webhookStorage,
+		WebhookDeliveryStorage:    webhookDeliveryStorage,
 	}
 }
+
+// Transaction runs fc with a Storage bound to a single database transaction.
+// the transaction commits when fc returns nil and rolls back otherwise.
+func (s *Storage) Transaction(fc func(txStorage *Storage) error) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return fc(NewStorage(tx, s.logger))
+	})
+}
@@ -20,6 +20,9 @@ const TEAM_P_FIELD_ALLOW_EDITOR_MANAGE_TEAM_MEMBER = "allowEditorManageTeamMembe
 const TEAM_P_FIELD_ALLOW_VIEWER_MANAGE_TEAM_MEMBER = "allowViewerManageTeamMember"
 const TEAM_P_FIELD_INVITE_LINK_ENABLED = "inviteLinkEnabled"
 const TEAM_P_FIELD_BLOCK_REGISTER = "blockRegister"
+const TEAM_P_FIELD_AUTO_JOIN_DOMAINS = "autoJoinDomains"
+const TEAM_P_FIELD_AUTO_JOIN_DEFAULT_ROLE = "autoJoinDefaultRole"
+const TEAM_P_FIELD_AUTO_JOIN_REQUIRE_APPROVAL = "autoJoinRequireApproval"
 
 type Team struct {
 	ID         int       `json:"id" gorm:"column:id;type:bigserial;primary_key;index:teams_ukey"`
@@ -161,6 +164,33 @@ func (u *Team) UpdateByUpdateTeamPermissionRawRequest(rawReq map[string]interfac
 			if !assertPass {
 				return errors.New("update team permission failed due to assert failed.")
 			}
+		case TEAM_P_FIELD_AUTO_JOIN_DOMAINS:
+			domains, assertPass := value.([]interface{})
+			if !assertPass {
+				return errors.New("update team permission failed due to assert failed.")
+			}
+			tp.AutoJoinDomains = make([]string, 0, len(domains))
+			for _, domain := range domains {
+				domainString, assertPass := domain.(string)
+				if !assertPass {
+					return errors.New("update team permission failed due to assert failed.")
+				}
+				tp.AutoJoinDomains = append(tp.AutoJoinDomains, NormalizeEmailDomain(domainString))
+			}
+		case TEAM_P_FIELD_AUTO_JOIN_DEFAULT_ROLE:
+			userRole, assertPass := value.(float64)
+			if !assertPass {
+				return errors.New("update team permission failed due to assert failed.")
+			}
+			if int(userRole) != USER_ROLE_EDITOR && int(userRole) != USER_ROLE_VIEWER {
+				return errors.New("auto join default role only can be editor or viewer.")
+			}
+			tp.AutoJoinDefaultRole = int(userRole)
+		case TEAM_P_FIELD_AUTO_JOIN_REQUIRE_APPROVAL:
+			tp.AutoJoinRequireApproval, assertPass = value.(bool)
+			if !assertPass {
+				return errors.New("update team permission failed due to assert failed.")
+			}
 		default:
 		}
 	}
@@ -199,6 +229,10 @@ type TeamPermission struct {
 	AllowViewerManageTeamMember bool `json:"allowViewerManageTeamMember"`
 	InviteLinkEnabled           bool `json:"inviteLinkEnabled"`
 	BlockRegister               bool `json:"blockRegister"`
+	// auto join, user who sign in with a email under the claimed domains will join the team automatically
+	AutoJoinDomains         []string `json:"autoJoinDomains"`
+	AutoJoinDefaultRole     int      `json:"autoJoinDefaultRole"`
+	AutoJoinRequireApproval bool     `json:"autoJoinRequireApproval"`
 }
 
 func NewTeamPermission() *TeamPermission {
@@ -209,6 +243,9 @@ func NewTeamPermission() *TeamPermission {
 		AllowViewerManageTeamMember: true,
 		InviteLinkEnabled:           true,
 		BlockRegister:               false,
+		AutoJoinDomains:             []string{},
+		AutoJoinDefaultRole:         USER_ROLE_VIEWER,
+		AutoJoinRequireApproval:     true,
 	}
 }
 
@@ -225,6 +262,9 @@ func (tp *TeamPermission) ImportFromTeam(team *Team) {
 	tp.AllowViewerManageTeamMember = ttp.AllowViewerManageTeamMember
 	tp.InviteLinkEnabled = ttp.InviteLinkEnabled
 	tp.BlockRegister = ttp.BlockRegister
+	tp.AutoJoinDomains = ttp.AutoJoinDomains
+	tp.AutoJoinDefaultRole = ttp.AutoJoinDefaultRole
+	tp.AutoJoinRequireApproval = ttp.AutoJoinRequireApproval
 }
 
 func (tp *TeamPermission) EnableInviteLink() {
@@ -250,3 +290,39 @@ func (tp *TeamPermission) DoesViewerCanManageTeamMember() bool {
 func (tp *TeamPermission) DoesBlockRegister() bool {
 	return tp.BlockRegister
 }
+
+func (tp *TeamPermission) DoesAutoJoinDomainClaimed(emailDomain string) bool {
+	emailDomain = NormalizeEmailDomain(emailDomain)
+	if emailDomain == "" {
+		return false
+	}
+	for _, domain := range tp.AutoJoinDomains {
+		if domain == emailDomain {
+			return true
+		}
+	}
+	return false
+}
+
+// ExportNewAutoJoinDomains export claimed domains which not exists in the given team permission, these domains need prove ownership.
+func (tp *TeamPermission) ExportNewAutoJoinDomains(previous *TeamPermission) []string {
+	ret := make([]string, 0)
+	for _, domain := range tp.AutoJoinDomains {
+		if !previous.DoesAutoJoinDomainClaimed(domain) {
+			ret = append(ret, domain)
+		}
+	}
+	return ret
+}
+
+// ExportAutoJoinDefaultRole export default role for auto joined team member, fallback to viewer for the legacy team permission.
+func (tp *TeamPermission) ExportAutoJoinDefaultRole() int {
+	if tp.AutoJoinDefaultRole != USER_ROLE_EDITOR && tp.AutoJoinDefaultRole != USER_ROLE_VIEWER {
+		return USER_ROLE_VIEWER
+	}
+	return tp.AutoJoinDefaultRole
+}
+
+func (tp *TeamPermission) DoesAutoJoinRequireApproval() bool {
+	return tp.AutoJoinRequireApproval
+}
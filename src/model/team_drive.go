@@ -42,3 +42,8 @@ func (d *TeamDrive) GetIconUploadPreSignedURL(fileName string) (string, error) {
 	path := d.TeamSystemFolder + TEAM_ICON_FOLDER + "/" + fileName
 	return d.Drive.GetPreSignedPutURL(path)
 }
+
+// remove all objects under the team folder, including team system and team space folder.
+func (d *TeamDrive) RemoveTeamFolder() error {
+	return d.Drive.RemoveObjectsByPrefix(TEAM_FOLDER_PREFIX + d.UID.String() + "/")
+}
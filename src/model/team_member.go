@@ -8,7 +8,7 @@ import (
 )
 
 // User Role ID in Team
-// @note: this will extend as role system later.
+// built-in roles are seeded as system roles, user role of team member could also be a custom role id, see role.go.
 const (
 	USER_ROLE_ANONYMOUS = -1
 	USER_ROLE_OWNER     = 1
@@ -19,16 +19,20 @@ const (
 
 const TEAM_MEMBER_STATUS_OK = 1
 const TEAM_MEMBER_STATUS_PENDING = 2
+const TEAM_MEMBER_STATUS_SUSPEND = 3
 
 type TeamMember struct {
-	ID         int       `json:"id" gorm:"column:id;type:bigserial;primary_key;index:team_members_ukey"`
-	TeamID     int       `json:"team_id" gorm:"column:team_id;type:bigserial;index:team_members_team_and_user_id"`
-	UserID     int       `json:"user_id" gorm:"column:user_id;type:bigserial;index:team_members_team_and_user_id"`
-	UserRole   int       `json:"user_role" gorm:"column:user_role;type:smallint"`
-	Permission string    `json:"permission" gorm:"column:permission;type:jsonb"` // for user permission config
-	Status     int       `json:"status" gorm:"column:status;type:smallint"`
-	CreatedAt  time.Time `gorm:"column:created_at;type:timestamp"`
-	UpdatedAt  time.Time `gorm:"column:updated_at;type:timestamp"`
+	ID                   int       `json:"id" gorm:"column:id;type:bigserial;primary_key;index:team_members_ukey"`
+	TeamID               int       `json:"team_id" gorm:"column:team_id;type:bigserial;index:team_members_team_and_user_id"`
+	UserID               int       `json:"user_id" gorm:"column:user_id;type:bigserial;index:team_members_team_and_user_id"`
+	UserRole             int       `json:"user_role" gorm:"column:user_role;type:bigint"`
+	Permission           string    `json:"permission" gorm:"column:permission;type:jsonb"` // for user permission config
+	Status               int       `json:"status" gorm:"column:status;type:smallint"`
+	BaseUserRole         int       `json:"base_user_role" gorm:"column:base_user_role;type:bigint"`                      // role restored when the time-bound role expired, 0 for permanent role
+	RoleValidUntil       time.Time `json:"role_valid_until" gorm:"column:role_valid_until;type:timestamp"`               // zero for permanent role
+	RoleExpiryNotifiedAt time.Time `json:"role_expiry_notified_at" gorm:"column:role_expiry_notified_at;type:timestamp"` // zero before the expiry notice sent
+	CreatedAt            time.Time `gorm:"column:created_at;type:timestamp"`
+	UpdatedAt            time.Time `gorm:"column:updated_at;type:timestamp"`
 }
 
 type TeamMemberWithUserInfoForExport struct {
@@ -41,27 +45,54 @@ type TeamMemberWithUserInfoForExport struct {
 	Language     string                `json:"language"`
 	IsSubscribed bool                  `json:"isSubscribed"`
 	UserRole     int                   `json:"userRole"`
-	Permission   *TeamMemberPermission `json:"permission"` // for user permission config
+	BaseUserRole int                   `json:"baseUserRole,omitempty"`
+	ValidUntil   *time.Time            `json:"validUntil,omitempty"` // nil for permanent role
+	Permission   *TeamMemberPermission `json:"permission"`           // for user permission config
 	UserStatus   int                   `json:"userStatus"`
 	CreatedAt    time.Time             `json:"createdAt"`
 	UpdatedAt    time.Time             `json:"updatedAt"`
 }
 
 type TeamMemberForExport struct {
-	ID         int                   `json:"id"`
-	TeamID     int                   `json:"teamID"`
-	UserID     int                   `json:"userID"`
-	UserRole   int                   `json:"userRole"`
-	Permission *TeamMemberPermission `json:"permission"` // for user permission config
-	Status     int                   `json:"status"`
-	CreatedAt  time.Time             `json:"createdAt"`
-	UpdatedAt  time.Time             `json:"updatedAt"`
+	ID           int                   `json:"id"`
+	TeamID       int                   `json:"teamID"`
+	UserID       int                   `json:"userID"`
+	UserRole     int                   `json:"userRole"`
+	BaseUserRole int                   `json:"baseUserRole,omitempty"`
+	ValidUntil   *time.Time            `json:"validUntil,omitempty"` // nil for permanent role
+	Permission   *TeamMemberPermission `json:"permission"`           // for user permission config
+	Status       int                   `json:"status"`
+	CreatedAt    time.Time             `json:"createdAt"`
+	UpdatedAt    time.Time             `json:"updatedAt"`
 }
 
 func NewTeamMember() *TeamMember {
 	return &TeamMember{}
 }
 
+// NewTeamMemberByAutoJoin create team member for user who's email domain claimed by team, the member need approval when the team required.
+func NewTeamMemberByAutoJoin(team *Team, user *User) *TeamMember {
+	tp := team.ExportTeamPermission()
+	teamMember := &TeamMember{
+		TeamID:     team.ID,
+		UserID:     user.ID,
+		UserRole:   tp.ExportAutoJoinDefaultRole(),
+		Permission: NewTeamMemberPermission().ExportForTeam(),
+		Status:     TEAM_MEMBER_STATUS_OK,
+	}
+	if tp.DoesAutoJoinRequireApproval() {
+		teamMember.Status = TEAM_MEMBER_STATUS_PENDING
+	}
+	teamMember.InitCreatedAt()
+	teamMember.InitUpdatedAt()
+	return teamMember
+}
+
+func (u *TeamMember) ApproveUser() {
+	u.Status = TEAM_MEMBER_STATUS_OK
+	u.InitUpdatedAt()
+}
+
 func (u *TeamMember) ConstructByJSON(TeamMemberJSON []byte) error {
 	if err := json.Unmarshal(TeamMemberJSON, u); err != nil {
 		return err
@@ -93,10 +124,87 @@ func (u *TeamMember) ActiveUser() {
 	u.Status = TEAM_MEMBER_STATUS_OK
 }
 
+func (u *TeamMember) SuspendUser() {
+	u.Status = TEAM_MEMBER_STATUS_SUSPEND
+	u.InitUpdatedAt()
+}
+
+func (u *TeamMember) ReactivateUser() {
+	u.Status = TEAM_MEMBER_STATUS_OK
+	u.InitUpdatedAt()
+}
+
+func (u *TeamMember) ExportStatus() int {
+	return u.Status
+}
+
+// ExportUserRole export the effective role, the base role is in effect once the time-bound role expired,
+// even before the expiry is persisted by the background job.
 func (u *TeamMember) ExportUserRole() int {
+	if u.IsRoleExpired() {
+		return u.BaseUserRole
+	}
 	return u.UserRole
 }
 
+func (u *TeamMember) ExportBaseUserRole() int {
+	if !u.IsRoleTimeBound() {
+		return 0
+	}
+	return u.BaseUserRole
+}
+
+func (u *TeamMember) ExportRoleValidUntil() *time.Time {
+	if !u.IsRoleTimeBound() {
+		return nil
+	}
+	validUntil := u.RoleValidUntil
+	return &validUntil
+}
+
+func (u *TeamMember) IsRoleTimeBound() bool {
+	return u.BaseUserRole != 0 && !u.RoleValidUntil.IsZero()
+}
+
+func (u *TeamMember) IsRoleExpired() bool {
+	return u.IsRoleTimeBound() && !time.Now().UTC().Before(u.RoleValidUntil)
+}
+
+func (u *TeamMember) IsRoleExpiryNotified() bool {
+	return !u.RoleExpiryNotifiedAt.IsZero()
+}
+
+// GrantTimeBoundRole elevate the team member until validUntil, the base role is kept when the grant is renewed.
+func (u *TeamMember) GrantTimeBoundRole(userRole int, validUntil time.Time) {
+	if !u.IsRoleTimeBound() {
+		u.BaseUserRole = u.UserRole
+	}
+	u.UserRole = userRole
+	u.RoleValidUntil = validUntil.UTC()
+	u.RoleExpiryNotifiedAt = time.Time{}
+	u.InitUpdatedAt()
+}
+
+// ExpireTimeBoundRole restore the base role.
+func (u *TeamMember) ExpireTimeBoundRole() {
+	if !u.IsRoleTimeBound() {
+		return
+	}
+	u.UserRole = u.BaseUserRole
+	u.clearTimeBoundRole()
+	u.InitUpdatedAt()
+}
+
+func (u *TeamMember) MarkRoleExpiryNotified() {
+	u.RoleExpiryNotifiedAt = time.Now().UTC()
+}
+
+func (u *TeamMember) clearTimeBoundRole() {
+	u.BaseUserRole = 0
+	u.RoleValidUntil = time.Time{}
+	u.RoleExpiryNotifiedAt = time.Time{}
+}
+
 func (u *TeamMember) ExportUserID() int {
 	return u.UserID
 }
@@ -111,7 +219,9 @@ func (u *TeamMember) ExportWithUserInfo(userForExport *UserForExport) *TeamMembe
 		Avatar:       userForExport.Avatar,
 		Language:     userForExport.Language,
 		IsSubscribed: userForExport.IsSubscribed,
-		UserRole:     u.UserRole,
+		UserRole:     u.ExportUserRole(),
+		BaseUserRole: u.ExportBaseUserRole(),
+		ValidUntil:   u.ExportRoleValidUntil(),
 		Permission:   u.ExportPermission(),
 		UserStatus:   u.Status,
 		CreatedAt:    u.CreatedAt,
@@ -129,7 +239,9 @@ func (u *TeamMember) ExportWithPendingUserInfo(userForExport *UserForExport) *Te
 		Avatar:       userForExport.Avatar,
 		Language:     userForExport.Language,
 		IsSubscribed: userForExport.IsSubscribed,
-		UserRole:     u.UserRole,
+		UserRole:     u.ExportUserRole(),
+		BaseUserRole: u.ExportBaseUserRole(),
+		ValidUntil:   u.ExportRoleValidUntil(),
 		Permission:   u.ExportPermission(),
 		UserStatus:   u.Status,
 		CreatedAt:    u.CreatedAt,
@@ -139,14 +251,16 @@ func (u *TeamMember) ExportWithPendingUserInfo(userForExport *UserForExport) *Te
 
 func (u *TeamMember) Export() *TeamMemberForExport {
 	return &TeamMemberForExport{
-		ID:         u.ID,
-		TeamID:     u.TeamID,
-		UserID:     u.UserID,
-		UserRole:   u.UserRole,
-		Permission: u.ExportPermission(),
-		Status:     u.Status,
-		CreatedAt:  u.CreatedAt,
-		UpdatedAt:  u.UpdatedAt,
+		ID:           u.ID,
+		TeamID:       u.TeamID,
+		UserID:       u.UserID,
+		UserRole:     u.ExportUserRole(),
+		BaseUserRole: u.ExportBaseUserRole(),
+		ValidUntil:   u.ExportRoleValidUntil(),
+		Permission:   u.ExportPermission(),
+		Status:       u.Status,
+		CreatedAt:    u.CreatedAt,
+		UpdatedAt:    u.UpdatedAt,
 	}
 }
 
@@ -154,39 +268,42 @@ func (u *TeamMember) ExportID() int {
 	return u.ID
 }
 
+// permanent role assignment replaces the time-bound role.
 func (u *TeamMember) UpdateByUpdateTeamMemberRoleRequest(req *UpdateTeamMemberRoleRequest) {
 	u.UserRole = req.UserRole
+	u.clearTimeBoundRole()
 	u.InitUpdatedAt()
 }
 
 func (u *TeamMember) UpdateTeamMemberRole(userRole int) {
 	u.UserRole = userRole
+	u.clearTimeBoundRole()
 	u.InitUpdatedAt()
 }
 
 func (u *TeamMember) IsOwner() bool {
-	if u.UserRole == USER_ROLE_OWNER {
+	if u.ExportUserRole() == USER_ROLE_OWNER {
 		return true
 	}
 	return false
 }
 
 func (u *TeamMember) IsAdmin() bool {
-	if u.UserRole == USER_ROLE_ADMIN {
+	if u.ExportUserRole() == USER_ROLE_ADMIN {
 		return true
 	}
 	return false
 }
 
 func (u *TeamMember) IsEditor() bool {
-	if u.UserRole == USER_ROLE_EDITOR {
+	if u.ExportUserRole() == USER_ROLE_EDITOR {
 		return true
 	}
 	return false
 }
 
 func (u *TeamMember) IsViewer() bool {
-	if u.UserRole == USER_ROLE_VIEWER {
+	if u.ExportUserRole() == USER_ROLE_VIEWER {
 		return true
 	}
 	return false
@@ -205,6 +322,13 @@ func (u *TeamMember) IsStatusPending() bool {
 	return false
 }
 
+func (u *TeamMember) IsStatusSuspend() bool {
+	if u.Status == TEAM_MEMBER_STATUS_SUSPEND {
+		return true
+	}
+	return false
+}
+
 func (u *TeamMember) IsStatusOK() bool {
 	if u.Status == TEAM_MEMBER_STATUS_OK {
 		return true
@@ -221,6 +345,15 @@ func PickUpTeamIDsInTeamMembers(teamMembers []*TeamMember) []int {
 	return ids
 }
 
+func PickUpUserIDsInTeamMembers(teamMembers []*TeamMember) []int {
+	idlen := len(teamMembers)
+	ids := make([]int, idlen, idlen)
+	for serial, teamMember := range teamMembers {
+		ids[serial] = teamMember.UserID
+	}
+	return ids
+}
+
 func PickUpTeamMemberIDsInTeamMembers(teamMembers []*TeamMember) []int {
 	idlen := len(teamMembers)
 	ids := make([]int, idlen, idlen)
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+const TEAM_MEMBER_SEARCH_DEFAULT_LIMIT = 20
+const TEAM_MEMBER_SEARCH_MAX_LIMIT = 100
+
+// TeamMemberSearchFilter describe the team member search conditions, the zero value of UserRole and Status means no filter.
+type TeamMemberSearchFilter struct {
+	Keyword  string
+	UserRole int
+	Status   int
+	Limit    int
+}
+
+func NewTeamMemberSearchFilter() *TeamMemberSearchFilter {
+	return &TeamMemberSearchFilter{
+		Limit: TEAM_MEMBER_SEARCH_DEFAULT_LIMIT,
+	}
+}
+
+func (f *TeamMemberSearchFilter) SetKeyword(keyword string) {
+	f.Keyword = strings.TrimSpace(keyword)
+}
+
+func (f *TeamMemberSearchFilter) SetUserRoleByString(userRoleRaw string) error {
+	if userRoleRaw == "" {
+		return nil
+	}
+	userRole, err := strconv.Atoi(userRoleRaw)
+	if err != nil {
+		return errors.New("invalid user role")
+	}
+	// system roles and custom roles
+	if userRole < ROLE_ID_SYSTEM_MIN {
+		return errors.New("invalid user role")
+	}
+	f.UserRole = userRole
+	return nil
+}
+
+func (f *TeamMemberSearchFilter) SetStatusByString(statusRaw string) error {
+	if statusRaw == "" {
+		return nil
+	}
+	status, err := strconv.Atoi(statusRaw)
+	if err != nil {
+		return errors.New("invalid user status")
+	}
+	switch status {
+	case TEAM_MEMBER_STATUS_OK, TEAM_MEMBER_STATUS_PENDING, TEAM_MEMBER_STATUS_SUSPEND:
+		f.Status = status
+		return nil
+	}
+	return errors.New("invalid user status")
+}
+
+func (f *TeamMemberSearchFilter) SetLimitByString(limitRaw string) error {
+	if limitRaw == "" {
+		return nil
+	}
+	limit, err := strconv.Atoi(limitRaw)
+	if err != nil || limit <= 0 {
+		return errors.New("invalid limit")
+	}
+	if limit > TEAM_MEMBER_SEARCH_MAX_LIMIT {
+		limit = TEAM_MEMBER_SEARCH_MAX_LIMIT
+	}
+	f.Limit = limit
+	return nil
+}
+
+func (f *TeamMemberSearchFilter) HasKeyword() bool {
+	return f.Keyword != ""
+}
+
+// ExportLikePattern export keyword as an escaped ILIKE substring pattern.
+func (f *TeamMemberSearchFilter) ExportLikePattern() string {
+	escaper := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + escaper.Replace(f.Keyword) + "%"
+}
@@ -1,8 +1,11 @@
 package model
 
 import (
+	"time"
+
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TeamMemberStorage struct {
@@ -48,6 +51,170 @@ func (d *TeamMemberStorage) RetrieveByTeamID(team_id int) ([]*TeamMember, error)
 	return teamMembers, nil
 }
 
+// SearchByTeamID fuzzy search team members by user nickname and email.
+// The trigram similarity (pg_trgm) tolerant typos, the fulltext match hit whole words, and the ILIKE match hit substrings,
+// results are ranked by the best trigram similarity of nickname and email.
+func (d *TeamMemberStorage) SearchByTeamID(teamID int, filter *TeamMemberSearchFilter) ([]*TeamMember, error) {
+	var teamMembers []*TeamMember
+	query := d.db.Model(&TeamMember{}).
+		Select("team_members.*").
+		Joins("JOIN users ON users.id = team_members.user_id").
+		Where("team_members.team_id = ?", teamID)
+	if filter.UserRole != 0 {
+		query = query.Where("team_members.user_role = ?", filter.UserRole)
+	}
+	if filter.Status != 0 {
+		query = query.Where("team_members.status = ?", filter.Status)
+	}
+	if filter.HasKeyword() {
+		likePattern := filter.ExportLikePattern()
+		query = query.Where(
+			"(users.nickname % ? OR users.email % ? OR users.nickname ILIKE ? OR users.email ILIKE ? "+
+				"OR to_tsvector('english', users.nickname) @@ plainto_tsquery('english', ?) "+
+				"OR to_tsvector('english', users.email) @@ plainto_tsquery('english', ?))",
+			filter.Keyword, filter.Keyword, likePattern, likePattern, filter.Keyword, filter.Keyword,
+		).Clauses(clause.OrderBy{Expression: clause.Expr{
+			SQL:  "GREATEST(similarity(users.nickname, ?), similarity(users.email, ?)) DESC, team_members.id ASC",
+			Vars: []interface{}{filter.Keyword, filter.Keyword},
+		}})
+	} else {
+		query = query.Order("team_members.id ASC")
+	}
+	if err := query.Limit(filter.Limit).Find(&teamMembers).Error; err != nil {
+		return nil, err
+	}
+	return teamMembers, nil
+}
+
+// RetrieveByTeamIDByPage list team members page by page, the keyword of filter is ignored, use SearchByTeamID for keyword search.
+func (d *TeamMemberStorage) RetrieveByTeamIDByPage(teamID int, filter *TeamMemberSearchFilter, pagination *Pagination) ([]*TeamMember, *PageInfo, error) {
+	var teamMembers []*TeamMember
+	query := d.db.Model(&TeamMember{}).Where("team_members.team_id = ?", teamID)
+	if filter.UserRole != 0 {
+		query = query.Where("team_members.user_role = ?", filter.UserRole)
+	}
+	if filter.Status != 0 {
+		query = query.Where("team_members.status = ?", filter.Status)
+	}
+	if err := pagination.Apply(query, "team_members").Find(&teamMembers).Error; err != nil {
+		return nil, nil, err
+	}
+	teamMembers, pageInfo := d.trimPage(teamMembers, pagination)
+	return teamMembers, pageInfo, nil
+}
+
+func (d *TeamMemberStorage) RetrieveByUserIDByPage(userID int, pagination *Pagination) ([]*TeamMember, *PageInfo, error) {
+	var teamMembers []*TeamMember
+	query := d.db.Model(&TeamMember{}).Where("team_members.user_id = ?", userID)
+	if err := pagination.Apply(query, "team_members").Find(&teamMembers).Error; err != nil {
+		return nil, nil, err
+	}
+	teamMembers, pageInfo := d.trimPage(teamMembers, pagination)
+	return teamMembers, pageInfo, nil
+}
+
+// trimPage feedback nil page info for unlimited pagination.
+func (d *TeamMemberStorage) trimPage(teamMembers []*TeamMember, pagination *Pagination) ([]*TeamMember, *PageInfo) {
+	if pagination.IsUnlimited() {
+		return teamMembers, nil
+	}
+	pageInfo := NewPageInfo()
+	if pagination.HasMore(len(teamMembers)) {
+		teamMembers = teamMembers[:pagination.Limit]
+		lastTeamMember := teamMembers[len(teamMembers)-1]
+		pageInfo.SetNextCursor(pagination.EncodeCursor(lastTeamMember.ID, lastTeamMember.CreatedAt))
+	}
+	return teamMembers, pageInfo
+}
+
+// RetrieveBySCIMFilter retrieve team members which user matched the SCIM filter, the startIndex is 1-based as SCIM defined.
+func (d *TeamMemberStorage) RetrieveBySCIMFilter(teamID int, filter *SCIMFilter, startIndex int, count int) ([]*TeamMember, int64, error) {
+	var teamMembers []*TeamMember
+	var total int64
+	query := d.db.Model(&TeamMember{}).
+		Joins("JOIN users ON users.id = team_members.user_id").
+		Where("team_members.team_id = ?", teamID)
+	if !filter.IsEmpty() {
+		filterSQL, filterVars, err := filter.ExportSQL(SCIMUserFilterColumns)
+		if err != nil {
+			return nil, 0, err
+		}
+		query = query.Where(filterSQL, filterVars...)
+	}
+	query = query.Session(&gorm.Session{}) // the query reused by count and find
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Select("team_members.*").Order("team_members.id ASC").Offset(startIndex - 1).Limit(count).Find(&teamMembers).Error; err != nil {
+		return nil, 0, err
+	}
+	return teamMembers, total, nil
+}
+
+func (d *TeamMemberStorage) RetrieveByTeamIDAndUserRole(teamID int, userRole int) ([]*TeamMember, error) {
+	var teamMembers []*TeamMember
+	if err := d.db.Where("team_id = ? AND user_role = ?", teamID, userRole).Order("id ASC").Find(&teamMembers).Error; err != nil {
+		return nil, err
+	}
+	return teamMembers, nil
+}
+
+// RetrieveByTeamIDAndEffectiveUserRole retrieve the team members who hold the role at now, same as TeamMember.ExportUserRole,
+// the members whose time-bound role expired hold their base role even before the expirer restored it.
+func (d *TeamMemberStorage) RetrieveByTeamIDAndEffectiveUserRole(teamID int, userRole int, now time.Time) ([]*TeamMember, error) {
+	var teamMembers []*TeamMember
+	if err := d.db.Where("team_id = ?", teamID).
+		Where("(user_role = ? AND NOT (base_user_role <> 0 AND role_valid_until <= ?)) OR (base_user_role = ? AND role_valid_until <= ?)", userRole, now, userRole, now).
+		Order("id ASC").Find(&teamMembers).Error; err != nil {
+		return nil, err
+	}
+	return teamMembers, nil
+}
+
+// RetrieveUserRolesByTeamIDAndStatus retrieve the distinct roles held by the team members in the status.
+func (d *TeamMemberStorage) RetrieveUserRolesByTeamIDAndStatus(teamID int, status int) ([]int, error) {
+	var userRoles []int
+	if err := d.db.Model(&TeamMember{}).Where("team_id = ? AND status = ?", teamID, status).Distinct().Order("user_role ASC").Pluck("user_role", &userRoles).Error; err != nil {
+		return nil, err
+	}
+	return userRoles, nil
+}
+
+func (d *TeamMemberStorage) RetrieveByTeamIDAndUserRolesAndStatus(teamID int, userRoles []int, status int) ([]*TeamMember, error) {
+	var teamMembers []*TeamMember
+	if err := d.db.Where("team_id = ? AND user_role IN ? AND status = ?", teamID, userRoles, status).Order("id ASC").Find(&teamMembers).Error; err != nil {
+		return nil, err
+	}
+	return teamMembers, nil
+}
+
+// CountByTeamIDAndUserRole count team members holding the role, including the base role of time-bound role.
+func (d *TeamMemberStorage) CountByTeamIDAndUserRole(teamID int, userRole int) (int64, error) {
+	var count int64
+	if err := d.db.Model(&TeamMember{}).Where("team_id = ? AND (user_role = ? OR base_user_role = ?)", teamID, userRole, userRole).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// RetrieveTimeBoundRoleExpiredBefore retrieve team members of all teams which time-bound role expired.
+func (d *TeamMemberStorage) RetrieveTimeBoundRoleExpiredBefore(before time.Time, limit int) ([]*TeamMember, error) {
+	var teamMembers []*TeamMember
+	if err := d.db.Where("base_user_role <> 0 AND role_valid_until <= ?", before).Order("role_valid_until ASC").Limit(limit).Find(&teamMembers).Error; err != nil {
+		return nil, err
+	}
+	return teamMembers, nil
+}
+
+// RetrieveTimeBoundRoleExpiringBetween retrieve team members which time-bound role will expire in the window and not notified yet.
+func (d *TeamMemberStorage) RetrieveTimeBoundRoleExpiringBetween(after time.Time, before time.Time, limit int) ([]*TeamMember, error) {
+	var teamMembers []*TeamMember
+	if err := d.db.Where("base_user_role <> 0 AND role_valid_until > ? AND role_valid_until <= ? AND role_expiry_notified_at = ?", after, before, time.Time{}).Order("role_valid_until ASC").Limit(limit).Find(&teamMembers).Error; err != nil {
+		return nil, err
+	}
+	return teamMembers, nil
+}
+
 func (d *TeamMemberStorage) RetrieveByTeamIDAndID(team_id int, id int) (*TeamMember, error) {
 	var teamMember *TeamMember
 	if err := d.db.Where("team_id = ? AND id = ?", team_id, id).First(&teamMember).Error; err != nil {
@@ -111,6 +278,15 @@ func (d *TeamMemberStorage) Update(u *TeamMember) error {
 	return nil
 }
 
+// UpdateRole persist the role columns of the team member, zero values included, e.g. the time-bound role cleared by expiry.
+// Update skips the zero values, so it can not clear them.
+func (d *TeamMemberStorage) UpdateRole(u *TeamMember) error {
+	if err := d.db.Model(&TeamMember{}).Where("id = ?", u.ID).Select("user_role", "base_user_role", "role_valid_until", "role_expiry_notified_at", "updated_at").Updates(u).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
 func (d *TeamMemberStorage) DeleteByID(id int) error {
 	if err := d.db.Delete(&TeamMember{}, id).Error; err != nil {
 		return err
@@ -0,0 +1,78 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// newDryRunTeamMemberStorage build the storage on a postgres dialector which never connects,
+// the search relies on pg_trgm and fulltext, so the statement is checked instead of the result.
+func newDryRunTeamMemberStorage(t *testing.T) (*TeamMemberStorage, *string) {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=127.0.0.1"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("open dry run database failed: %v", err)
+	}
+	statement := new(string)
+	db.Callback().Query().After("gorm:query").Register("test:capture", func(tx *gorm.DB) {
+		*statement = tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...)
+	})
+	return NewTeamMemberStorage(db, zap.NewNop().Sugar()), statement
+}
+
+func TestSearchByTeamIDStatement(t *testing.T) {
+	storage, statement := newDryRunTeamMemberStorage(t)
+	cases := []struct {
+		name    string
+		filter  *TeamMemberSearchFilter
+		want    []string
+		notWant []string
+	}{
+		{
+			"keyword",
+			&TeamMemberSearchFilter{Keyword: "alice", Limit: 20},
+			[]string{
+				"team_members.team_id = 0",
+				"users.nickname % 'alice' OR users.email % 'alice'",
+				"users.nickname ILIKE '%alice%' OR users.email ILIKE '%alice%'",
+				"to_tsvector('english', users.nickname) @@ plainto_tsquery('english', 'alice')",
+				"ORDER BY GREATEST(similarity(users.nickname, 'alice'), similarity(users.email, 'alice')) DESC, team_members.id ASC",
+				"LIMIT 20",
+			},
+			[]string{"team_members.user_role", "team_members.status"},
+		},
+		{
+			"wildcards in keyword are escaped",
+			&TeamMemberSearchFilter{Keyword: `50%_off\`, Limit: 20},
+			[]string{`ILIKE '%50\%\_off\\%'`},
+			nil,
+		},
+		{
+			"role and status",
+			&TeamMemberSearchFilter{Keyword: "alice", UserRole: USER_ROLE_EDITOR, Status: TEAM_MEMBER_STATUS_SUSPEND, Limit: 5},
+			[]string{"team_members.user_role = 3", "team_members.status = 3", "LIMIT 5"},
+			nil,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := storage.SearchByTeamID(TEAM_DEFAULT_ID, c.filter); err != nil {
+				t.Fatalf("search failed: %v", err)
+			}
+			for _, want := range c.want {
+				if !strings.Contains(*statement, want) {
+					t.Errorf("statement has no %q: %s", want, *statement)
+				}
+			}
+			for _, notWant := range c.notWant {
+				if strings.Contains(*statement, notWant) {
+					t.Errorf("statement has %q: %s", notWant, *statement)
+				}
+			}
+		})
+	}
+}
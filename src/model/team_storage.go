@@ -1,6 +1,7 @@
 package model
 
 import (
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -48,6 +49,14 @@ func (d *TeamStorage) RetrieveByUID(uid string) (*Team, error) {
 	return u, nil
 }
 
+func (d *TeamStorage) RetrieveByUIDs(uids []uuid.UUID) ([]*Team, error) {
+	teams := []*Team{}
+	if err := d.db.Where("uid IN ?", uids).Find(&teams).Error; err != nil {
+		return nil, err
+	}
+	return teams, nil
+}
+
 func (d *TeamStorage) RetrieveByIdentifier(identifier string) (*Team, error) {
 	u := &Team{}
 	if err := d.db.Where("identifier = ?", identifier).First(&u).Error; err != nil {
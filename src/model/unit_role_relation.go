@@ -0,0 +1,165 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/idconvertor"
+)
+
+// unit role relation grant or deny attributes of a role on a single unit, e.g. an app or a resource.
+// a relation with user id grants the attributes to that team member only, its role id is 0.
+const UNIT_ROLE_RELATION_EFFECT_ALLOW = 1
+const UNIT_ROLE_RELATION_EFFECT_DENY = 2
+
+// UnitPermissions holds the attributes of a unit role relation.
+// map[AttributeCategory][Attribute]status
+// an empty deny relation denies all attributes on the unit.
+type UnitPermissions map[int]map[int]bool
+
+type UnitRoleRelation struct {
+	ID          int       `json:"id" gorm:"column:id;type:bigserial;primary_key"`
+	UID         uuid.UUID `json:"uid" gorm:"column:uid;type:uuid;not null"`
+	TeamID      int       `json:"teamID" gorm:"column:team_id;type:bigserial;index:unit_role_relations_team_role_unit_id_and_unit_type"`
+	RoleID      int       `json:"roleID" gorm:"column:role_id;type:bigserial;index:unit_role_relations_team_role_unit_id_and_unit_type"`
+	UserID      int       `json:"userID" gorm:"column:user_id;type:bigint"` // 0 for the relation of the whole role
+	UnitID      int       `json:"unitID" gorm:"column:unit_id;type:bigserial;index:unit_role_relations_team_role_unit_id_and_unit_type"`
+	UnitType    int       `json:"unitType" gorm:"column:unit_type;type:smallint;index:unit_role_relations_team_role_unit_id_and_unit_type"`
+	Effect      int       `json:"effect" gorm:"column:effect;type:smallint"`
+	Permissions string    `json:"permissions" gorm:"column:permissions;type:jsonb"`
+	CreatedAt   time.Time `gorm:"column:created_at;type:timestamp"`
+	UpdatedAt   time.Time `gorm:"column:updated_at;type:timestamp"`
+}
+
+type UnitRoleRelationKey struct {
+	UnitType int
+	UnitID   int
+}
+
+type UnitRoleRelationForExport struct {
+	ID          string          `json:"unitRoleRelationID"`
+	UID         uuid.UUID       `json:"uid"`
+	TeamID      string          `json:"teamID"`
+	RoleID      string          `json:"roleID"`
+	UserID      string          `json:"userID,omitempty"`
+	UnitID      string          `json:"unitID"`
+	UnitType    int             `json:"unitType"`
+	Effect      int             `json:"effect"`
+	Permissions UnitPermissions `json:"permissions"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+}
+
+func NewUnitRoleRelation(teamID int, unitType int, unitID int, roleID int) *UnitRoleRelation {
+	unitRoleRelation := &UnitRoleRelation{
+		TeamID:   teamID,
+		RoleID:   roleID,
+		UnitID:   unitID,
+		UnitType: unitType,
+	}
+	unitRoleRelation.InitUID()
+	unitRoleRelation.InitCreatedAt()
+	return unitRoleRelation
+}
+
+// NewUnitRoleRelationForUser create the relation grants the unit to a single team member, e.g. an approved access request.
+func NewUnitRoleRelationForUser(teamID int, unitType int, unitID int, userID int) *UnitRoleRelation {
+	unitRoleRelation := NewUnitRoleRelation(teamID, unitType, unitID, 0)
+	unitRoleRelation.UserID = userID
+	return unitRoleRelation
+}
+
+func (u *UnitRoleRelation) InitUID() {
+	u.UID = uuid.New()
+}
+
+func (u *UnitRoleRelation) InitCreatedAt() {
+	u.CreatedAt = time.Now().UTC()
+}
+
+func (u *UnitRoleRelation) InitUpdatedAt() {
+	u.UpdatedAt = time.Now().UTC()
+}
+
+func (u *UnitRoleRelation) ExportID() int {
+	return u.ID
+}
+
+func (u *UnitRoleRelation) IsDeny() bool {
+	return u.Effect == UNIT_ROLE_RELATION_EFFECT_DENY
+}
+
+func (u *UnitRoleRelation) UpdateByCreateUnitRoleRelationRequest(req *CreateUnitRoleRelationRequest) {
+	u.Effect = req.Effect
+	permissions := req.Permissions
+	if permissions == nil {
+		permissions = UnitPermissions{}
+	}
+	payload, _ := json.Marshal(permissions)
+	u.Permissions = string(payload)
+	u.InitUpdatedAt()
+}
+
+// AllowPermissions merge permissions into the allow relation.
+func (u *UnitRoleRelation) AllowPermissions(permissions UnitPermissions) {
+	merged := u.ExportPermissions()
+	for category, attributes := range permissions {
+		if merged[category] == nil {
+			merged[category] = map[int]bool{}
+		}
+		for attribute, status := range attributes {
+			if status {
+				merged[category][attribute] = true
+			}
+		}
+	}
+	u.Effect = UNIT_ROLE_RELATION_EFFECT_ALLOW
+	payload, _ := json.Marshal(merged)
+	u.Permissions = string(payload)
+	u.InitUpdatedAt()
+}
+
+func (u *UnitRoleRelation) ExportPermissions() UnitPermissions {
+	permissions := UnitPermissions{}
+	json.Unmarshal([]byte(u.Permissions), &permissions)
+	return permissions
+}
+
+// ExportRolePermissions export permissions in role permissions format under the unit type of the relation.
+func (u *UnitRoleRelation) ExportRolePermissions() RolePermissions {
+	rolePermissions := RolePermissions{}
+	for category, attributes := range u.ExportPermissions() {
+		rolePermissions[category] = map[int]map[int]bool{u.UnitType: attributes}
+	}
+	return rolePermissions
+}
+
+func (u *UnitRoleRelation) Export() *UnitRoleRelationForExport {
+	userID := ""
+	if u.UserID != 0 {
+		userID = idconvertor.ConvertIntToString(u.UserID)
+	}
+	return &UnitRoleRelationForExport{
+		ID:          idconvertor.ConvertIntToString(u.ID),
+		UID:         u.UID,
+		TeamID:      idconvertor.ConvertIntToString(u.TeamID),
+		RoleID:      idconvertor.ConvertIntToString(u.RoleID),
+		UserID:      userID,
+		UnitID:      idconvertor.ConvertIntToString(u.UnitID),
+		UnitType:    u.UnitType,
+		Effect:      u.Effect,
+		Permissions: u.ExportPermissions(),
+		CreatedAt:   u.CreatedAt,
+		UpdatedAt:   u.UpdatedAt,
+	}
+}
+
+func GroupUnitRoleRelationsByUnit(unitRoleRelations []*UnitRoleRelation) map[UnitRoleRelationKey][]*UnitRoleRelation {
+	ret := make(map[UnitRoleRelationKey][]*UnitRoleRelation)
+	for _, unitRoleRelation := range unitRoleRelations {
+		key := UnitRoleRelationKey{UnitType: unitRoleRelation.UnitType, UnitID: unitRoleRelation.UnitID}
+		ret[key] = append(ret[key], unitRoleRelation)
+	}
+	return ret
+}
@@ -0,0 +1,117 @@
+package model
+
+import (
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type UnitRoleRelationStorage struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewUnitRoleRelationStorage(db *gorm.DB, logger *zap.SugaredLogger) *UnitRoleRelationStorage {
+	return &UnitRoleRelationStorage{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (d *UnitRoleRelationStorage) Create(u *UnitRoleRelation) (int, error) {
+	if err := d.db.Create(u).Error; err != nil {
+		return 0, err
+	}
+	return u.ID, nil
+}
+
+func (d *UnitRoleRelationStorage) RetrieveByUnit(teamID int, unitType int, unitID int) ([]*UnitRoleRelation, error) {
+	var unitRoleRelations []*UnitRoleRelation
+	if err := d.db.Where("team_id = ? AND unit_type = ? AND unit_id = ?", teamID, unitType, unitID).Order("id ASC").Find(&unitRoleRelations).Error; err != nil {
+		return nil, err
+	}
+	return unitRoleRelations, nil
+}
+
+func (d *UnitRoleRelationStorage) RetrieveByUnitAndRoleID(teamID int, unitType int, unitID int, roleID int) ([]*UnitRoleRelation, error) {
+	var unitRoleRelations []*UnitRoleRelation
+	if err := d.db.Where("team_id = ? AND role_id = ? AND unit_id = ? AND unit_type = ?", teamID, roleID, unitID, unitType).Order("id ASC").Find(&unitRoleRelations).Error; err != nil {
+		return nil, err
+	}
+	return unitRoleRelations, nil
+}
+
+// RetrieveByUnitForTeamMember retrieve the relations of the role and the grants to the user on the unit.
+func (d *UnitRoleRelationStorage) RetrieveByUnitForTeamMember(teamID int, unitType int, unitID int, roleID int, userID int) ([]*UnitRoleRelation, error) {
+	var unitRoleRelations []*UnitRoleRelation
+	if err := d.db.Where("team_id = ? AND unit_id = ? AND unit_type = ? AND (role_id = ? OR (user_id <> 0 AND user_id = ?))", teamID, unitID, unitType, roleID, userID).Order("id ASC").Find(&unitRoleRelations).Error; err != nil {
+		return nil, err
+	}
+	return unitRoleRelations, nil
+}
+
+func (d *UnitRoleRelationStorage) RetrieveByUnitAndUserID(teamID int, unitType int, unitID int, userID int) ([]*UnitRoleRelation, error) {
+	var unitRoleRelations []*UnitRoleRelation
+	if err := d.db.Where("team_id = ? AND user_id = ? AND unit_id = ? AND unit_type = ?", teamID, userID, unitID, unitType).Order("id ASC").Find(&unitRoleRelations).Error; err != nil {
+		return nil, err
+	}
+	return unitRoleRelations, nil
+}
+
+func (d *UnitRoleRelationStorage) RetrieveByUnitAndID(teamID int, unitType int, unitID int, id int) (*UnitRoleRelation, error) {
+	u := &UnitRoleRelation{}
+	if err := d.db.Where("team_id = ? AND unit_type = ? AND unit_id = ? AND id = ?", teamID, unitType, unitID, id).First(u).Error; err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// RetrieveByTeamMemberAndUnitIDs retrieve the relations of the role and the grants to the user on the units.
+func (d *UnitRoleRelationStorage) RetrieveByTeamMemberAndUnitIDs(teamID int, roleID int, userID int, unitIDs []int) ([]*UnitRoleRelation, error) {
+	var unitRoleRelations []*UnitRoleRelation
+	if err := d.db.Where("team_id = ? AND (role_id = ? OR (user_id <> 0 AND user_id = ?)) AND unit_id IN ?", teamID, roleID, userID, unitIDs).Order("id ASC").Find(&unitRoleRelations).Error; err != nil {
+		return nil, err
+	}
+	return unitRoleRelations, nil
+}
+
+func (d *UnitRoleRelationStorage) UpdateByID(u *UnitRoleRelation) error {
+	if err := d.db.Model(&UnitRoleRelation{}).Where("id = ?", u.ID).Select("*").Omit("id", "uid", "created_at").Updates(u).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *UnitRoleRelationStorage) DeleteByTeamIDAndID(teamID int, id int) error {
+	if err := d.db.Where("team_id = ? AND id = ?", teamID, id).Delete(&UnitRoleRelation{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *UnitRoleRelationStorage) DeleteByTeamIDAndRoleID(teamID int, roleID int) error {
+	if err := d.db.Where("team_id = ? AND role_id = ?", teamID, roleID).Delete(&UnitRoleRelation{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *UnitRoleRelationStorage) DeleteByTeamIDAndUserID(teamID int, userID int) error {
+	if err := d.db.Where("team_id = ? AND user_id = ?", teamID, userID).Delete(&UnitRoleRelation{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *UnitRoleRelationStorage) DeleteByUserID(userID int) error {
+	if err := d.db.Where("user_id = ?", userID).Delete(&UnitRoleRelation{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *UnitRoleRelationStorage) DeleteByTeamID(teamID int) error {
+	if err := d.db.Where("team_id = ?", teamID).Delete(&UnitRoleRelation{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
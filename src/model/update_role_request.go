@@ -0,0 +1,11 @@
+package model
+
+// empty field will not be updated.
+type UpdateRoleRequest struct {
+	Name        string          `json:"name" validate:"max=255"`
+	Permissions RolePermissions `json:"permissions"`
+}
+
+func NewUpdateRoleRequest() *UpdateRoleRequest {
+	return &UpdateRoleRequest{}
+}
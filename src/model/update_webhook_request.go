@@ -0,0 +1,18 @@
+package model
+
+// empty field will not be updated.
+type UpdateWebhookRequest struct {
+	Name       string   `json:"name" validate:"max=255"`
+	URL        string   `json:"url" validate:"omitempty,url,max=2048,startswith=http"`
+	EventTypes []string `json:"eventTypes" validate:"omitempty,min=1,dive,required"`
+	Format     int      `json:"format" validate:"omitempty,oneof=1 2"`
+	Status     int      `json:"status" validate:"omitempty,oneof=1 2"`
+}
+
+func NewUpdateWebhookRequest() *UpdateWebhookRequest {
+	return &UpdateWebhookRequest{}
+}
+
+func (req *UpdateWebhookRequest) ExportUnsubscribableEventType() (string, bool) {
+	return exportUnsubscribableEventType(req.EventTypes)
+}
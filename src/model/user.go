@@ -2,6 +2,7 @@ package model
 
 import (
 	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -160,10 +161,37 @@ func (u *User) ExportID() int {
 	return u.ID
 }
 
+// ExportEmailDomain export the domain part of user email.
+// The email of self-host account was verified when the account created, and can not be changed after that.
+func (u *User) ExportEmailDomain() string {
+	at := strings.LastIndex(u.Email, "@")
+	if at < 0 {
+		return ""
+	}
+	return NormalizeEmailDomain(u.Email[at+1:])
+}
+
+func NormalizeEmailDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
+
 func (u *User) ExportEmail() string {
 	return u.Email
 }
 
+func (u *User) ExportUserSSOConfig() *UserSSOConfig {
+	userSSOConfig := NewUserSSOConfig()
+	json.Unmarshal([]byte(u.SSOConfig), userSSOConfig)
+	return userSSOConfig
+}
+
+func (u *User) SetSCIMExternalID(externalID string) {
+	userSSOConfig := u.ExportUserSSOConfig()
+	userSSOConfig.SCIMExternalID = externalID
+	u.SSOConfig = userSSOConfig.Export()
+	u.InitUpdatedAt()
+}
+
 func (u *User) ExportUserCustomization() *UserCustomization {
 	userCustomization := NewUserCustomization()
 	json.Unmarshal([]byte(u.Customization), &userCustomization)
@@ -214,7 +242,8 @@ func BuildLookUpTableForUserExport(users []*User) map[int]*UserForExport {
 }
 
 type UserSSOConfig struct {
-	Github string
+	Github         string
+	SCIMExternalID string // id of user in SCIM identity provider
 }
 
 func NewUserSSOConfig() *UserSSOConfig {
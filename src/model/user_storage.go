@@ -43,6 +43,25 @@ func (d *UserStorage) RetrieveByIDs(ids []int) ([]*User, error) {
 	return users, nil
 }
 
+// RetrieveByIDsByPage feedback nil page info for unlimited pagination.
+func (d *UserStorage) RetrieveByIDsByPage(ids []int, pagination *Pagination) ([]*User, *PageInfo, error) {
+	users := []*User{}
+	query := d.db.Model(&User{}).Where("(users.id) IN ?", ids)
+	if err := pagination.Apply(query, "users").Find(&users).Error; err != nil {
+		return nil, nil, err
+	}
+	if pagination.IsUnlimited() {
+		return users, nil, nil
+	}
+	pageInfo := NewPageInfo()
+	if pagination.HasMore(len(users)) {
+		users = users[:pagination.Limit]
+		lastUser := users[len(users)-1]
+		pageInfo.SetNextCursor(pagination.EncodeCursor(lastUser.ID, lastUser.CreatedAt))
+	}
+	return users, pageInfo, nil
+}
+
 func (d *UserStorage) RetrieveByUID(uid uuid.UUID) (*User, error) {
 	u := &User{}
 	if err := d.db.Where("uid = ?", uid).First(&u).Error; err != nil {
@@ -51,6 +70,14 @@ func (d *UserStorage) RetrieveByUID(uid uuid.UUID) (*User, error) {
 	return u, nil
 }
 
+func (d *UserStorage) RetrieveByUIDs(uids []uuid.UUID) ([]*User, error) {
+	users := []*User{}
+	if err := d.db.Where("uid IN ?", uids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
 func (d *UserStorage) RetrieveByIDAndUID(id int, uid uuid.UUID) (*User, error) {
 	u := &User{}
 	if err := d.db.Where("id = ? and uid = ?", id, uid).First(&u).Error; err != nil {
@@ -67,6 +94,14 @@ func (d *UserStorage) RetrieveByEmail(email string) (*User, error) {
 	return u, nil
 }
 
+func (d *UserStorage) RetrieveByEmails(emails []string) ([]*User, error) {
+	users := []*User{}
+	if err := d.db.Where("email IN ?", emails).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
 func (d *UserStorage) UpdateByID(u *User) error {
 	if err := d.db.Model(&User{}).Where("id = ?", u.ID).UpdateColumns(u).Error; err != nil {
 		return err
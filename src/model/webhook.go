@@ -0,0 +1,195 @@
+package model
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/idconvertor"
+)
+
+const WEBHOOK_STATUS_ENABLED = 1
+const WEBHOOK_STATUS_DISABLED = 2
+
+// json format posts the event as it is, text format posts {"text": "..."} for slack and microsoft teams incoming webhooks.
+const WEBHOOK_FORMAT_JSON = 1
+const WEBHOOK_FORMAT_TEXT = 2
+
+// the secret signs deliveries, it is only exported when created or rotated.
+const WEBHOOK_SECRET_PREFIX = "kozmo_whsec_"
+
+// test ping, it is not a domain event and never retried.
+const WEBHOOK_EVENT_TYPE_PING = "Ping"
+
+// notices to team members, they are delivered by the webhook notifier with the recipients, not by the outbox.
+const (
+	WEBHOOK_EVENT_TYPE_ROLE_GRANT_EXPIRING     = "RoleGrantExpiring"
+	WEBHOOK_EVENT_TYPE_ROLE_GRANT_EXPIRED      = "RoleGrantExpired"
+	WEBHOOK_EVENT_TYPE_ACCESS_REQUEST_CREATED  = "AccessRequestCreated"
+	WEBHOOK_EVENT_TYPE_ACCESS_REQUEST_APPROVED = "AccessRequestApproved"
+	WEBHOOK_EVENT_TYPE_ACCESS_REQUEST_DENIED   = "AccessRequestDenied"
+)
+
+// domain events and notices of a team which can be subscribed by webhooks.
+var WebhookSubscribableEventTypes = map[string]bool{
+	EVENT_TYPE_TEAM_UPDATED:                    true,
+	EVENT_TYPE_TEAM_MEMBER_JOINED:              true,
+	EVENT_TYPE_TEAM_MEMBER_ROLE_CHANGED:        true,
+	EVENT_TYPE_TEAM_MEMBER_STATUS_CHANGED:      true,
+	EVENT_TYPE_TEAM_MEMBER_REMOVED:             true,
+	WEBHOOK_EVENT_TYPE_ROLE_GRANT_EXPIRING:     true,
+	WEBHOOK_EVENT_TYPE_ROLE_GRANT_EXPIRED:      true,
+	WEBHOOK_EVENT_TYPE_ACCESS_REQUEST_CREATED:  true,
+	WEBHOOK_EVENT_TYPE_ACCESS_REQUEST_APPROVED: true,
+	WEBHOOK_EVENT_TYPE_ACCESS_REQUEST_DENIED:   true,
+}
+
+type Webhook struct {
+	ID         int       `json:"id" gorm:"column:id;type:bigserial;primary_key;index:webhooks_ukey"`
+	UID        uuid.UUID `json:"uid" gorm:"column:uid;type:uuid;not null;index:webhooks_ukey"`
+	TeamID     int       `json:"teamID" gorm:"column:team_id;type:bigserial;index:webhooks_team_id"`
+	Name       string    `json:"name" gorm:"column:name;type:varchar;size:255;not null"`
+	URL        string    `json:"url" gorm:"column:url;type:varchar;size:2048;not null"`
+	Secret     string    `json:"secret" gorm:"column:secret;type:varchar;size:255;not null"`
+	EventTypes string    `json:"eventTypes" gorm:"column:event_types;type:jsonb"`
+	Format     int       `json:"format" gorm:"column:format;type:smallint"`
+	Status     int       `json:"status" gorm:"column:status;type:smallint"`
+	CreatedBy  int       `json:"createdBy" gorm:"column:created_by;type:bigserial"`
+	CreatedAt  time.Time `gorm:"column:created_at;type:timestamp"`
+	UpdatedAt  time.Time `gorm:"column:updated_at;type:timestamp"`
+}
+
+type WebhookForExport struct {
+	ID         string    `json:"webhookID"`
+	UID        uuid.UUID `json:"uid"`
+	TeamID     string    `json:"teamID"`
+	Name       string    `json:"name"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"secret,omitempty"` // only exported when created or rotated
+	EventTypes []string  `json:"eventTypes"`
+	Format     int       `json:"format"`
+	Status     int       `json:"status"`
+	CreatedBy  string    `json:"createdBy"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+func NewWebhookByCreateWebhookRequest(teamID int, createdBy int, req *CreateWebhookRequest) *Webhook {
+	webhook := &Webhook{
+		TeamID:    teamID,
+		Name:      req.Name,
+		URL:       req.URL,
+		Format:    req.ExportFormat(),
+		Status:    WEBHOOK_STATUS_ENABLED,
+		CreatedBy: createdBy,
+	}
+	webhook.SetEventTypes(req.EventTypes)
+	webhook.InitUID()
+	webhook.InitSecret()
+	webhook.InitCreatedAt()
+	webhook.InitUpdatedAt()
+	return webhook
+}
+
+func (w *Webhook) InitUID() {
+	w.UID = uuid.New()
+}
+
+func (w *Webhook) InitSecret() {
+	buf := make([]byte, 32)
+	rand.Read(buf)
+	w.Secret = WEBHOOK_SECRET_PREFIX + hex.EncodeToString(buf)
+}
+
+func (w *Webhook) InitCreatedAt() {
+	w.CreatedAt = time.Now().UTC()
+}
+
+func (w *Webhook) InitUpdatedAt() {
+	w.UpdatedAt = time.Now().UTC()
+}
+
+func (w *Webhook) SetEventTypes(eventTypes []string) {
+	eventTypesInJSON, _ := json.Marshal(eventTypes)
+	w.EventTypes = string(eventTypesInJSON)
+}
+
+func (w *Webhook) ExportID() int {
+	return w.ID
+}
+
+func (w *Webhook) ExportTeamID() int {
+	return w.TeamID
+}
+
+func (w *Webhook) ExportEventTypes() []string {
+	eventTypes := make([]string, 0)
+	json.Unmarshal([]byte(w.EventTypes), &eventTypes)
+	return eventTypes
+}
+
+func (w *Webhook) IsEnabled() bool {
+	return w.Status == WEBHOOK_STATUS_ENABLED
+}
+
+func (w *Webhook) IsTextFormat() bool {
+	return w.Format == WEBHOOK_FORMAT_TEXT
+}
+
+func (w *Webhook) DoesSubscribe(eventType string) bool {
+	for _, subscribedEventType := range w.ExportEventTypes() {
+		if subscribedEventType == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Webhook) RotateSecret() {
+	w.InitSecret()
+	w.InitUpdatedAt()
+}
+
+// empty field will not be updated.
+func (w *Webhook) UpdateByUpdateWebhookRequest(req *UpdateWebhookRequest) {
+	if req.Name != "" {
+		w.Name = req.Name
+	}
+	if req.URL != "" {
+		w.URL = req.URL
+	}
+	if req.EventTypes != nil {
+		w.SetEventTypes(req.EventTypes)
+	}
+	if req.Format != 0 {
+		w.Format = req.Format
+	}
+	if req.Status != 0 {
+		w.Status = req.Status
+	}
+	w.InitUpdatedAt()
+}
+
+func (w *Webhook) Export() *WebhookForExport {
+	return &WebhookForExport{
+		ID:         idconvertor.ConvertIntToString(w.ID),
+		UID:        w.UID,
+		TeamID:     idconvertor.ConvertIntToString(w.TeamID),
+		Name:       w.Name,
+		URL:        w.URL,
+		EventTypes: w.ExportEventTypes(),
+		Format:     w.Format,
+		Status:     w.Status,
+		CreatedBy:  idconvertor.ConvertIntToString(w.CreatedBy),
+		CreatedAt:  w.CreatedAt,
+		UpdatedAt:  w.UpdatedAt,
+	}
+}
+
+func (w *Webhook) ExportWithSecret() *WebhookForExport {
+	ret := w.Export()
+	ret.Secret = w.Secret
+	return ret
+}
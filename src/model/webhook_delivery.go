@@ -0,0 +1,180 @@
+package model
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/idconvertor"
+)
+
+const WEBHOOK_DELIVERY_STATUS_PENDING = 1
+const WEBHOOK_DELIVERY_STATUS_SUCCEEDED = 2
+const WEBHOOK_DELIVERY_STATUS_FAILED = 3 // gave up after max attempts
+
+// the response body is logged for troubleshooting, only the head of it is kept.
+const WEBHOOK_DELIVERY_RESPONSE_BODY_MAX_SIZE = 1024
+const WEBHOOK_DELIVERY_LAST_ERROR_MAX_SIZE = 1024
+
+// WebhookDelivery is the delivery of an event to a webhook and the log of its last attempt.
+// the body is rendered when the delivery created, so a redelivery posts the same body.
+type WebhookDelivery struct {
+	ID            int       `json:"id" gorm:"column:id;type:bigserial;primary_key"`
+	UID           uuid.UUID `json:"uid" gorm:"column:uid;type:uuid;not null"`
+	TeamID        int       `json:"teamID" gorm:"column:team_id;type:bigserial"`
+	WebhookID     int       `json:"webhookID" gorm:"column:webhook_id;type:bigserial;index:webhook_deliveries_webhook_id_and_event_uid"`
+	EventUID      uuid.UUID `json:"eventUID" gorm:"column:event_uid;type:uuid;not null;index:webhook_deliveries_webhook_id_and_event_uid"`
+	EventType     string    `json:"eventType" gorm:"column:event_type;type:varchar;size:63;not null"`
+	Body          string    `json:"body" gorm:"column:body;type:text"`
+	Status        int       `json:"status" gorm:"column:status;type:smallint;index:webhook_deliveries_status_and_next_attempt_at"`
+	Attempts      int       `json:"attempts" gorm:"column:attempts;type:integer"`
+	ResponseCode  int       `json:"responseCode" gorm:"column:response_code;type:integer"` // 0 when no response received
+	ResponseBody  string    `json:"responseBody" gorm:"column:response_body;type:text"`
+	LastError     string    `json:"lastError" gorm:"column:last_error;type:text"`
+	RedeliveryOf  int       `json:"redeliveryOf" gorm:"column:redelivery_of;type:bigint"` // 0 for the original delivery
+	NextAttemptAt time.Time `gorm:"column:next_attempt_at;type:timestamp;index:webhook_deliveries_status_and_next_attempt_at"`
+	LastAttemptAt time.Time `gorm:"column:last_attempt_at;type:timestamp"`
+	CreatedAt     time.Time `gorm:"column:created_at;type:timestamp"`
+	UpdatedAt     time.Time `gorm:"column:updated_at;type:timestamp"`
+}
+
+type WebhookDeliveryForExport struct {
+	ID            string     `json:"webhookDeliveryID"`
+	UID           uuid.UUID  `json:"uid"`
+	WebhookID     string     `json:"webhookID"`
+	EventUID      uuid.UUID  `json:"eventUID"`
+	EventType     string     `json:"eventType"`
+	Status        int        `json:"status"`
+	Attempts      int        `json:"attempts"`
+	ResponseCode  int        `json:"responseCode"`
+	ResponseBody  string     `json:"responseBody"`
+	LastError     string     `json:"lastError,omitempty"`
+	RedeliveryOf  string     `json:"redeliveryOf,omitempty"`
+	NextAttemptAt *time.Time `json:"nextAttemptAt,omitempty"` // nil when no more attempts
+	LastAttemptAt time.Time  `json:"lastAttemptAt"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+func NewWebhookDelivery(webhook *Webhook, eventUID uuid.UUID, eventType string, body string) *WebhookDelivery {
+	delivery := &WebhookDelivery{
+		TeamID:    webhook.TeamID,
+		WebhookID: webhook.ID,
+		EventUID:  eventUID,
+		EventType: eventType,
+		Body:      body,
+		Status:    WEBHOOK_DELIVERY_STATUS_PENDING,
+	}
+	delivery.InitUID()
+	delivery.InitCreatedAt()
+	delivery.InitUpdatedAt()
+	delivery.NextAttemptAt = delivery.CreatedAt
+	return delivery
+}
+
+// NewWebhookRedelivery copy the delivery with a new uid, the receiver can still deduplicate by event uid.
+func NewWebhookRedelivery(delivery *WebhookDelivery) *WebhookDelivery {
+	redelivery := &WebhookDelivery{
+		TeamID:       delivery.TeamID,
+		WebhookID:    delivery.WebhookID,
+		EventUID:     delivery.EventUID,
+		EventType:    delivery.EventType,
+		Body:         delivery.Body,
+		Status:       WEBHOOK_DELIVERY_STATUS_PENDING,
+		RedeliveryOf: delivery.ID,
+	}
+	redelivery.InitUID()
+	redelivery.InitCreatedAt()
+	redelivery.InitUpdatedAt()
+	redelivery.NextAttemptAt = redelivery.CreatedAt
+	return redelivery
+}
+
+func (d *WebhookDelivery) InitUID() {
+	d.UID = uuid.New()
+}
+
+func (d *WebhookDelivery) InitCreatedAt() {
+	d.CreatedAt = time.Now().UTC()
+}
+
+func (d *WebhookDelivery) InitUpdatedAt() {
+	d.UpdatedAt = time.Now().UTC()
+}
+
+func (d *WebhookDelivery) ExportID() int {
+	return d.ID
+}
+
+func (d *WebhookDelivery) IsPing() bool {
+	return d.EventType == WEBHOOK_EVENT_TYPE_PING
+}
+
+func (d *WebhookDelivery) IsPending() bool {
+	return d.Status == WEBHOOK_DELIVERY_STATUS_PENDING
+}
+
+// truncateDeliveryText cut the text from the endpoint to at most maxSize bytes of valid UTF-8 without NUL, which a text column accepts.
+func truncateDeliveryText(text string, maxSize int) string {
+	text = strings.ReplaceAll(strings.ToValidUTF8(text, ""), "\x00", "")
+	if len(text) <= maxSize {
+		return text
+	}
+	cut := maxSize
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut]
+}
+
+// RecordAttempt log the response of an attempt, responseCode is 0 when the request failed before any response.
+func (d *WebhookDelivery) RecordAttempt(responseCode int, responseBody string, err error) {
+	d.Attempts++
+	d.ResponseCode = responseCode
+	d.ResponseBody = truncateDeliveryText(responseBody, WEBHOOK_DELIVERY_RESPONSE_BODY_MAX_SIZE)
+	d.LastError = ""
+	if err != nil {
+		d.LastError = truncateDeliveryText(err.Error(), WEBHOOK_DELIVERY_LAST_ERROR_MAX_SIZE)
+	}
+	d.LastAttemptAt = time.Now().UTC()
+	d.InitUpdatedAt()
+}
+
+func (d *WebhookDelivery) MarkSucceeded() {
+	d.Status = WEBHOOK_DELIVERY_STATUS_SUCCEEDED
+	d.NextAttemptAt = time.Time{}
+}
+
+func (d *WebhookDelivery) MarkFailed() {
+	d.Status = WEBHOOK_DELIVERY_STATUS_FAILED
+	d.NextAttemptAt = time.Time{}
+}
+
+func (d *WebhookDelivery) ScheduleRetry(nextAttemptAt time.Time) {
+	d.NextAttemptAt = nextAttemptAt
+}
+
+func (d *WebhookDelivery) Export() *WebhookDeliveryForExport {
+	ret := &WebhookDeliveryForExport{
+		ID:            idconvertor.ConvertIntToString(d.ID),
+		UID:           d.UID,
+		WebhookID:     idconvertor.ConvertIntToString(d.WebhookID),
+		EventUID:      d.EventUID,
+		EventType:     d.EventType,
+		Status:        d.Status,
+		Attempts:      d.Attempts,
+		ResponseCode:  d.ResponseCode,
+		ResponseBody:  d.ResponseBody,
+		LastError:     d.LastError,
+		LastAttemptAt: d.LastAttemptAt,
+		CreatedAt:     d.CreatedAt,
+	}
+	if d.RedeliveryOf != 0 {
+		ret.RedeliveryOf = idconvertor.ConvertIntToString(d.RedeliveryOf)
+	}
+	if d.IsPending() {
+		nextAttemptAt := d.NextAttemptAt
+		ret.NextAttemptAt = &nextAttemptAt
+	}
+	return ret
+}
@@ -0,0 +1,97 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type WebhookDeliveryStorage struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewWebhookDeliveryStorage(db *gorm.DB, logger *zap.SugaredLogger) *WebhookDeliveryStorage {
+	return &WebhookDeliveryStorage{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (d *WebhookDeliveryStorage) Create(u *WebhookDelivery) (int, error) {
+	if err := d.db.Create(u).Error; err != nil {
+		return 0, err
+	}
+	return u.ID, nil
+}
+
+func (d *WebhookDeliveryStorage) RetrieveByTeamIDAndWebhookIDAndID(teamID int, webhookID int, id int) (*WebhookDelivery, error) {
+	u := &WebhookDelivery{}
+	if err := d.db.Where("team_id = ? AND webhook_id = ? AND id = ?", teamID, webhookID, id).First(&u).Error; err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// retrieve the latest deliveries of webhook, newest first.
+func (d *WebhookDeliveryStorage) RetrieveByTeamIDAndWebhookID(teamID int, webhookID int, limit int) ([]*WebhookDelivery, error) {
+	var deliveries []*WebhookDelivery
+	if err := d.db.Where("team_id = ? AND webhook_id = ?", teamID, webhookID).Order("id DESC").Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// retrieve the pending deliveries which should be attempted before given time, oldest first.
+func (d *WebhookDeliveryStorage) RetrieveDueBefore(before time.Time, limit int) ([]*WebhookDelivery, error) {
+	var deliveries []*WebhookDelivery
+	if err := d.db.Where("status = ? AND next_attempt_at <= ?", WEBHOOK_DELIVERY_STATUS_PENDING, before).Order("next_attempt_at, id").Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// the outbox relay publish events at least once, the event which has been delivered to webhook will not be enqueued again.
+func (d *WebhookDeliveryStorage) IsEventEnqueued(webhookID int, eventUID uuid.UUID) (bool, error) {
+	var count int64
+	if err := d.db.Model(&WebhookDelivery{}).Where("webhook_id = ? AND event_uid = ?", webhookID, eventUID).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Claim postpone the next attempt of pending delivery to leaseUntil, it feedback false when another worker claimed it first.
+func (d *WebhookDeliveryStorage) Claim(u *WebhookDelivery, leaseUntil time.Time) (bool, error) {
+	result := d.db.Model(&WebhookDelivery{}).Where("id = ? AND status = ? AND next_attempt_at = ?", u.ID, WEBHOOK_DELIVERY_STATUS_PENDING, u.NextAttemptAt).Update("next_attempt_at", leaseUntil)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	u.NextAttemptAt = leaseUntil
+	return true, nil
+}
+
+func (d *WebhookDeliveryStorage) UpdateByID(u *WebhookDelivery) error {
+	if err := d.db.Model(&WebhookDelivery{}).Where("id = ?", u.ID).Select("*").Omit("id", "created_at").Updates(u).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *WebhookDeliveryStorage) DeleteByTeamIDAndWebhookID(teamID int, webhookID int) error {
+	if err := d.db.Where("team_id = ? AND webhook_id = ?", teamID, webhookID).Delete(&WebhookDelivery{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *WebhookDeliveryStorage) DeleteByTeamID(teamID int) error {
+	if err := d.db.Where("team_id = ?", teamID).Delete(&WebhookDelivery{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
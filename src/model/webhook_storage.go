@@ -0,0 +1,78 @@
+package model
+
+import (
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type WebhookStorage struct {
+	logger *zap.SugaredLogger
+	db     *gorm.DB
+}
+
+func NewWebhookStorage(db *gorm.DB, logger *zap.SugaredLogger) *WebhookStorage {
+	return &WebhookStorage{
+		logger: logger,
+		db:     db,
+	}
+}
+
+func (d *WebhookStorage) Create(u *Webhook) (int, error) {
+	if err := d.db.Create(u).Error; err != nil {
+		return 0, err
+	}
+	return u.ID, nil
+}
+
+func (d *WebhookStorage) RetrieveByID(id int) (*Webhook, error) {
+	u := &Webhook{}
+	if err := d.db.First(u, id).Error; err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (d *WebhookStorage) RetrieveByTeamID(teamID int) ([]*Webhook, error) {
+	var webhooks []*Webhook
+	if err := d.db.Where("team_id = ?", teamID).Order("id").Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (d *WebhookStorage) RetrieveEnabledByTeamID(teamID int) ([]*Webhook, error) {
+	var webhooks []*Webhook
+	if err := d.db.Where("team_id = ? AND status = ?", teamID, WEBHOOK_STATUS_ENABLED).Order("id").Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (d *WebhookStorage) RetrieveByTeamIDAndID(teamID int, id int) (*Webhook, error) {
+	u := &Webhook{}
+	if err := d.db.Where("team_id = ? AND id = ?", teamID, id).First(&u).Error; err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (d *WebhookStorage) UpdateByID(u *Webhook) error {
+	if err := d.db.Model(&Webhook{}).Where("id = ?", u.ID).Select("*").Omit("id", "created_at").Updates(u).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *WebhookStorage) DeleteByTeamIDAndID(teamID int, id int) error {
+	if err := d.db.Where("team_id = ? AND id = ?", teamID, id).Delete(&Webhook{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *WebhookStorage) DeleteByTeamID(teamID int) error {
+	if err := d.db.Where("team_id = ?", teamID).Delete(&Webhook{}).Error; err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package notification
+
+import (
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"go.uber.org/zap"
+)
+
+// notice categories, the categories which can be delivered by webhook are named after the webhook event types.
+const (
+	NOTICE_CATEGORY_ROLE_GRANT_EXPIRING     = model.WEBHOOK_EVENT_TYPE_ROLE_GRANT_EXPIRING
+	NOTICE_CATEGORY_ROLE_GRANT_EXPIRED      = model.WEBHOOK_EVENT_TYPE_ROLE_GRANT_EXPIRED
+	NOTICE_CATEGORY_ACCESS_REQUEST_CREATED  = model.WEBHOOK_EVENT_TYPE_ACCESS_REQUEST_CREATED
+	NOTICE_CATEGORY_ACCESS_REQUEST_APPROVED = model.WEBHOOK_EVENT_TYPE_ACCESS_REQUEST_APPROVED
+	NOTICE_CATEGORY_ACCESS_REQUEST_DENIED   = model.WEBHOOK_EVENT_TYPE_ACCESS_REQUEST_DENIED
+)
+
+type Notice struct {
+	Category         string
+	TeamID           int
+	RecipientUserIDs []int
+	Payload          map[string]interface{}
+}
+
+func NewNotice(category string, teamID int, recipientUserIDs []int, payload map[string]interface{}) *Notice {
+	return &Notice{
+		Category:         category,
+		TeamID:           teamID,
+		RecipientUserIDs: recipientUserIDs,
+		Payload:          payload,
+	}
+}
+
+// Notifier deliver notice to recipients.
+type Notifier interface {
+	Notify(notice *Notice) error
+}
+
+// LogNotifier only write the notice to log, the recipients are not reached.
+// the supervisor delivers notices by webhook.Notifier, LogNotifier is kept for tools and tests.
+type LogNotifier struct {
+	logger *zap.SugaredLogger
+}
+
+func NewLogNotifier(logger *zap.SugaredLogger) *LogNotifier {
+	return &LogNotifier{
+		logger: logger,
+	}
+}
+
+func (n *LogNotifier) Notify(notice *Notice) error {
+	n.logger.Infow("notice",
+		"category", notice.Category,
+		"teamID", notice.TeamID,
+		"recipientUserIDs", notice.RecipientUserIDs,
+		"payload", notice.Payload,
+	)
+	return nil
+}
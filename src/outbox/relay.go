@@ -0,0 +1,211 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/config"
+	"go.uber.org/zap"
+)
+
+// published events are kept for a while for troubleshooting.
+const PUBLISHED_EVENT_RETENTION = 7 * 24 * time.Hour
+
+// a claimed event is not published by other replicas until the lease expired.
+const OUTBOX_EVENT_LEASE = time.Minute
+
+// the n-th retry of a failed event is scheduled after DEFAULT_RETRY_BACKOFF * 2^(n-1), at most MAX_RETRY_BACKOFF.
+const DEFAULT_RETRY_BACKOFF = time.Second
+const MAX_RETRY_BACKOFF = 5 * time.Minute
+
+// namedSink is a sink of relay, the name is recorded in the events published to it so it must be stable.
+type namedSink struct {
+	name string
+	sink Sink
+}
+
+// Relay publish the outbox events to every sink at least once, events of the same aggregate are published in order.
+// a failed event blocks the later events of its aggregate until it is published, events of other aggregates go on.
+// the events are claimed in a short transaction and published outside of it, so replicas can relay at the same time.
+type Relay struct {
+	logger        *zap.SugaredLogger
+	Storage       *model.Storage
+	RelayInterval time.Duration
+	RetryBackoff  time.Duration
+	BatchSize     int
+	sinks         []*namedSink
+}
+
+func NewRelay(storage *model.Storage, sinkName string, sink Sink, relayInterval time.Duration, batchSize int, logger *zap.SugaredLogger) *Relay {
+	return &Relay{
+		logger:        logger,
+		Storage:       storage,
+		RelayInterval: relayInterval,
+		RetryBackoff:  DEFAULT_RETRY_BACKOFF,
+		BatchSize:     batchSize,
+		sinks:         []*namedSink{{name: sinkName, sink: sink}},
+	}
+}
+
+func NewRelayByGlobalConfig(config *config.Config, storage *model.Storage, cache *model.Cache, logger *zap.SugaredLogger) (*Relay, error) {
+	sink, err := NewSinkByGlobalConfig(config, cache)
+	if err != nil {
+		return nil, err
+	}
+	return NewRelay(storage, config.GetOutboxSink(), sink, config.GetOutboxRelayInterval(), config.GetOutboxRelayBatchSize(), logger), nil
+}
+
+func NewSinkByGlobalConfig(config *config.Config, cache *model.Cache) (Sink, error) {
+	switch config.GetOutboxSink() {
+	case SINK_REDIS:
+		return cache.EventStream, nil
+	case SINK_HTTP:
+		if config.GetOutboxHTTPEndpoint() == "" {
+			return nil, errors.New("outbox http sink requires endpoint")
+		}
+		return NewHTTPSink(config.GetOutboxHTTPEndpoint()), nil
+	case SINK_MEMORY:
+		return NewMemorySink(), nil
+	}
+	return nil, errors.New("unknown outbox sink: " + config.GetOutboxSink())
+}
+
+// AddSink publish events to sink besides the current ones, the delivery to each sink is tracked by its name.
+func (r *Relay) AddSink(sinkName string, sink Sink) {
+	r.sinks = append(r.sinks, &namedSink{name: sinkName, sink: sink})
+}
+
+// RelayOnce publish a batch of due events and feedback the count of published events.
+// an event published but not marked because of a crash is published again after its lease expired.
+func (r *Relay) RelayOnce() (int, error) {
+	now := time.Now().UTC()
+	outboxEvents, errInClaim := r.claim(now)
+	if errInClaim != nil {
+		return 0, errInClaim
+	}
+	published := 0
+	blockedAggregates := make(map[string]bool)
+	for _, outboxEvent := range outboxEvents {
+		aggregateKey := outboxEvent.ExportAggregateKey()
+		if blockedAggregates[aggregateKey] {
+			// behind a failed event, it is retrieved again after the failed one published
+			outboxEvent.Release()
+		} else if err := r.publish(outboxEvent); err != nil {
+			blockedAggregates[aggregateKey] = true
+			outboxEvent.MarkFailed(err, time.Now().UTC().Add(r.exportRetryBackoff(outboxEvent.Attempts+1)))
+			r.logger.Errorw("publish outbox event failed", "uid", outboxEvent.UID, "type", outboxEvent.Type, "aggregate", aggregateKey, "attempts", outboxEvent.Attempts, "err", err)
+		} else {
+			outboxEvent.MarkPublished()
+			published++
+		}
+		if err := r.Storage.OutboxEventStorage.Update(outboxEvent); err != nil {
+			return published, err
+		}
+	}
+	return published, nil
+}
+
+// claim lease a batch of due events in one transaction, an aggregate is skipped from the first event claimed by others.
+func (r *Relay) claim(now time.Time) ([]*model.OutboxEvent, error) {
+	claimedEvents := make([]*model.OutboxEvent, 0)
+	err := r.Storage.Transaction(func(txStorage *model.Storage) error {
+		claimedEvents = claimedEvents[:0]
+		outboxEvents, errInRetrieve := txStorage.OutboxEventStorage.RetrieveDue(now, r.BatchSize)
+		if errInRetrieve != nil {
+			return errInRetrieve
+		}
+		skippedAggregates := make(map[string]bool)
+		for _, outboxEvent := range outboxEvents {
+			aggregateKey := outboxEvent.ExportAggregateKey()
+			if skippedAggregates[aggregateKey] {
+				continue
+			}
+			claimed, errInClaim := txStorage.OutboxEventStorage.Claim(outboxEvent, now.Add(OUTBOX_EVENT_LEASE))
+			if errInClaim != nil {
+				return errInClaim
+			}
+			if !claimed {
+				skippedAggregates[aggregateKey] = true
+				continue
+			}
+			claimedEvents = append(claimedEvents, outboxEvent)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claimedEvents, nil
+}
+
+// publish the event to the sinks not published to yet, in the order of sinks added.
+func (r *Relay) publish(outboxEvent *model.OutboxEvent) error {
+	event := outboxEvent.ExportEvent()
+	for _, namedSink := range r.sinks {
+		if outboxEvent.IsPublishedTo(namedSink.name) {
+			continue
+		}
+		if err := namedSink.sink.Publish(event); err != nil {
+			return fmt.Errorf("publish to %s sink failed: %w", namedSink.name, err)
+		}
+		outboxEvent.MarkPublishedTo(namedSink.name)
+	}
+	return nil
+}
+
+func (r *Relay) exportRetryBackoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	backoff := r.RetryBackoff
+	for i := 1; i < attempts && backoff < MAX_RETRY_BACKOFF; i++ {
+		backoff *= 2
+	}
+	if backoff > MAX_RETRY_BACKOFF {
+		return MAX_RETRY_BACKOFF
+	}
+	return backoff
+}
+
+// Relay publish all unpublished events, it stops when a batch is not full or nothing was published.
+func (r *Relay) Relay() {
+	for {
+		published, err := r.RelayOnce()
+		if err != nil {
+			r.logger.Errorw("relay outbox events failed", "err", err)
+			return
+		}
+		if published < r.BatchSize {
+			return
+		}
+	}
+}
+
+// CleanPublishedEvents delete the published events out of retention.
+func (r *Relay) CleanPublishedEvents() {
+	if err := r.Storage.OutboxEventStorage.DeletePublishedBefore(time.Now().UTC().Add(-PUBLISHED_EVENT_RETENTION)); err != nil {
+		r.logger.Errorw("clean published outbox events failed", "err", err)
+	}
+}
+
+// Run relay outbox events periodically, it blocks until ctx is done.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.RelayInterval)
+	defer ticker.Stop()
+	cleanTicker := time.NewTicker(time.Hour)
+	defer cleanTicker.Stop()
+	r.Relay()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Relay()
+		case <-cleanTicker.C:
+			r.CleanPublishedEvents()
+		}
+	}
+}
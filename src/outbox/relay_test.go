@@ -0,0 +1,181 @@
+package outbox
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/internal/testdb"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"go.uber.org/zap"
+)
+
+// flakySink fails the given number of publishes of the failing aggregate, then forwards to MemorySink.
+type flakySink struct {
+	mutex            sync.Mutex
+	failingAggregate string
+	failures         int
+	*MemorySink
+}
+
+func (s *flakySink) Publish(event *model.Event) error {
+	s.mutex.Lock()
+	if event.AggregateID == s.failingAggregate && s.failures > 0 {
+		s.failures--
+		s.mutex.Unlock()
+		return errors.New("sink unavailable")
+	}
+	s.mutex.Unlock()
+	return s.MemorySink.Publish(event)
+}
+
+func newTestRelay(t *testing.T, sink Sink) *Relay {
+	relay := NewRelay(testdb.NewStorage(t), SINK_MEMORY, sink, time.Second, 100, zap.NewNop().Sugar())
+	relay.RetryBackoff = time.Millisecond
+	return relay
+}
+
+func createTestEvents(t *testing.T, storage *model.Storage, aggregateIDs ...string) []*model.Event {
+	t.Helper()
+	events := make([]*model.Event, 0, len(aggregateIDs))
+	for _, aggregateID := range aggregateIDs {
+		event := model.NewEvent(model.EVENT_TYPE_TEAM_UPDATED, model.EVENT_AGGREGATE_TYPE_TEAM, aggregateID, map[string]string{"teamID": aggregateID})
+		if _, err := storage.OutboxEventStorage.Create(event); err != nil {
+			t.Fatalf("create outbox event failed: %v", err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// relayUntilDrained relay until no event left, retries are due after the backoff.
+func relayUntilDrained(t *testing.T, relay *Relay) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := relay.RelayOnce(); err != nil {
+			t.Fatalf("relay failed: %v", err)
+		}
+		remaining, err := relay.Storage.OutboxEventStorage.RetrieveDue(time.Now().UTC().Add(OUTBOX_EVENT_LEASE+MAX_RETRY_BACKOFF), 1000)
+		if err != nil {
+			t.Fatalf("retrieve outbox events failed: %v", err)
+		}
+		if len(remaining) == 0 {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("outbox events not drained")
+}
+
+func publishedUIDs(events []*model.Event, aggregateID string) []string {
+	uids := make([]string, 0)
+	for _, event := range events {
+		if event.AggregateID == aggregateID {
+			uids = append(uids, event.UID.String())
+		}
+	}
+	return uids
+}
+
+func TestRelayKeepsAggregateOrderAcrossRetry(t *testing.T) {
+	sink := &flakySink{failingAggregate: "a", failures: 2, MemorySink: NewMemorySink()}
+	relay := newTestRelay(t, sink)
+	events := createTestEvents(t, relay.Storage, "a", "b", "a", "b", "a")
+
+	// the first event of aggregate a failed, aggregate b goes on
+	published, err := relay.RelayOnce()
+	if err != nil {
+		t.Fatalf("relay failed: %v", err)
+	}
+	if published != 2 {
+		t.Errorf("published = %d, want 2", published)
+	}
+	if got := publishedUIDs(sink.Events(), "a"); len(got) != 0 {
+		t.Errorf("aggregate a published %v while its first event failed", got)
+	}
+
+	// the failed event is not due before its backoff, and blocks the later events of aggregate a
+	due, err := relay.Storage.OutboxEventStorage.RetrieveDue(time.Now().UTC(), 100)
+	if err != nil {
+		t.Fatalf("retrieve outbox events failed: %v", err)
+	}
+	for _, outboxEvent := range due {
+		if outboxEvent.AggregateID == "a" && outboxEvent.Attempts == 0 {
+			t.Errorf("event %s of blocked aggregate is due", outboxEvent.UID)
+		}
+	}
+
+	relayUntilDrained(t, relay)
+
+	for _, aggregateID := range []string{"a", "b"} {
+		want := publishedUIDs(events, aggregateID)
+		got := publishedUIDs(sink.Events(), aggregateID)
+		if len(got) != len(want) {
+			t.Fatalf("aggregate %s published %d events, want %d", aggregateID, len(got), len(want))
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("aggregate %s event %d = %s, want %s", aggregateID, i, got[i], want[i])
+			}
+		}
+	}
+}
+
+func TestRelayTracksDeliveryPerSink(t *testing.T) {
+	primary := NewMemorySink()
+	secondary := &flakySink{failingAggregate: "a", failures: 1, MemorySink: NewMemorySink()}
+	relay := newTestRelay(t, primary)
+	relay.AddSink("secondary", secondary)
+	createTestEvents(t, relay.Storage, "a")
+
+	relayUntilDrained(t, relay)
+
+	// the retry skips the sink published to
+	if got := len(primary.Events()); got != 1 {
+		t.Errorf("primary sink received %d events, want 1", got)
+	}
+	if got := len(secondary.Events()); got != 1 {
+		t.Errorf("secondary sink received %d events, want 1", got)
+	}
+}
+
+func TestRelaySkipsClaimedEvents(t *testing.T) {
+	sink := NewMemorySink()
+	relay := newTestRelay(t, sink)
+	createTestEvents(t, relay.Storage, "a", "a")
+
+	// another replica claimed the first event
+	due, err := relay.Storage.OutboxEventStorage.RetrieveDue(time.Now().UTC(), 1)
+	if err != nil || len(due) != 1 {
+		t.Fatalf("retrieve outbox events failed: %v", err)
+	}
+	claimed, err := relay.Storage.OutboxEventStorage.Claim(due[0], time.Now().UTC().Add(OUTBOX_EVENT_LEASE))
+	if err != nil || !claimed {
+		t.Fatalf("claim outbox event failed: %v", err)
+	}
+
+	published, err := relay.RelayOnce()
+	if err != nil {
+		t.Fatalf("relay failed: %v", err)
+	}
+	if published != 0 || len(sink.Events()) != 0 {
+		t.Errorf("published %d events behind the claimed one", published)
+	}
+}
+
+func TestExportRetryBackoff(t *testing.T) {
+	relay := &Relay{RetryBackoff: time.Second}
+	cases := map[int]time.Duration{
+		1:  time.Second,
+		2:  2 * time.Second,
+		4:  8 * time.Second,
+		64: MAX_RETRY_BACKOFF,
+	}
+	for attempts, want := range cases {
+		if got := relay.exportRetryBackoff(attempts); got != want {
+			t.Errorf("exportRetryBackoff(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package outbox
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+)
+
+// sink kinds
+const (
+	SINK_REDIS  = "redis"
+	SINK_HTTP   = "http"
+	SINK_MEMORY = "memory"
+)
+
+const DEFAULT_HTTP_SINK_TIMEOUT = 10 * time.Second
+
+// http sink headers, the receiver should deduplicate events by uid.
+const (
+	HTTP_SINK_HEADER_EVENT_UID  = "Kozmo-Event-UID"
+	HTTP_SINK_HEADER_EVENT_TYPE = "Kozmo-Event-Type"
+)
+
+// Sink publish domain event to the consumers, an event is published again when Publish failed.
+// model.EventStream is the redis streams sink.
+type Sink interface {
+	Publish(event *model.Event) error
+}
+
+// HTTPSink post the event in JSON to the webhook endpoint, any non 2xx response is a failure.
+type HTTPSink struct {
+	Endpoint string
+	client   *http.Client
+}
+
+func NewHTTPSink(endpoint string) *HTTPSink {
+	return &HTTPSink{
+		Endpoint: endpoint,
+		client:   &http.Client{Timeout: DEFAULT_HTTP_SINK_TIMEOUT},
+	}
+}
+
+func (s *HTTPSink) Publish(event *model.Event) error {
+	body, errInMarshal := json.Marshal(event)
+	if errInMarshal != nil {
+		return errInMarshal
+	}
+	req, errInNewRequest := http.NewRequest(http.MethodPost, s.Endpoint, bytes.NewReader(body))
+	if errInNewRequest != nil {
+		return errInNewRequest
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HTTP_SINK_HEADER_EVENT_UID, event.UID.String())
+	req.Header.Set(HTTP_SINK_HEADER_EVENT_TYPE, event.Type)
+	resp, errInPost := s.client.Do(req)
+	if errInPost != nil {
+		return errInPost
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.New(fmt.Sprintf("webhook endpoint responded status %d", resp.StatusCode))
+	}
+	return nil
+}
+
+// MemorySink keep the published events in memory, for tests and local development.
+type MemorySink struct {
+	mutex  sync.Mutex
+	events []*model.Event
+}
+
+func NewMemorySink() *MemorySink {
+	return &MemorySink{
+		events: make([]*model.Event, 0),
+	}
+}
+
+func (s *MemorySink) Publish(event *model.Event) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.events = append(s.events, event)
+	return nil
+}
+
+// Events feedback a copy of the published events in publish order.
+func (s *MemorySink) Events() []*model.Event {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	events := make([]*model.Event, len(s.events))
+	copy(events, s.events)
+	return events
+}
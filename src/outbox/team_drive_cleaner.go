@@ -0,0 +1,36 @@
+package outbox
+
+import (
+	"encoding/json"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+)
+
+// SINK_TEAM_DRIVE_CLEANER is the name of TeamDriveCleaner in outbox relay.
+const SINK_TEAM_DRIVE_CLEANER = "teamDriveCleaner"
+
+// TeamDriveCleaner is an outbox sink, it removes the drive folder of the team by the TeamDeleted event.
+// the team is deleted before its folder is removed, a failed removal is retried by the relay until it succeeded.
+type TeamDriveCleaner struct {
+	Drive *model.Drive
+}
+
+func NewTeamDriveCleaner(drive *model.Drive) *TeamDriveCleaner {
+	return &TeamDriveCleaner{
+		Drive: drive,
+	}
+}
+
+// Publish remove the team folder, the other events are ignored. removing a removed folder is a no-op.
+func (s *TeamDriveCleaner) Publish(event *model.Event) error {
+	if event.Type != model.EVENT_TYPE_TEAM_DELETED {
+		return nil
+	}
+	payload := &model.TeamDeletedEventPayload{}
+	if err := json.Unmarshal([]byte(event.Payload), payload); err != nil {
+		return err
+	}
+	teamDrive := model.NewTeamDrive(s.Drive)
+	teamDrive.SetTeam(&model.Team{UID: payload.TeamUID})
+	return teamDrive.RemoveTeamFolder()
+}
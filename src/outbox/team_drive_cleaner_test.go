@@ -0,0 +1,66 @@
+package outbox
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/driver/minio"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"go.uber.org/zap"
+)
+
+// flakyDrive fails the given number of folder removals, then removes them from MemoryS3Drive.
+type flakyDrive struct {
+	failures int
+	*minio.MemoryS3Drive
+}
+
+func (d *flakyDrive) RemoveObjectsByPrefix(prefix string) error {
+	if d.failures > 0 {
+		d.failures--
+		return errors.New("drive unavailable")
+	}
+	return d.MemoryS3Drive.RemoveObjectsByPrefix(prefix)
+}
+
+func TestTeamDriveCleanerRetriesFailedRemoval(t *testing.T) {
+	drive := &flakyDrive{failures: 2, MemoryS3Drive: minio.NewMemoryS3Drive("kozmo-drive-test")}
+	deletedTeam := &model.Team{ID: 1, UID: uuid.New(), Identifier: "deleted"}
+	otherTeam := &model.Team{ID: 2, UID: uuid.New(), Identifier: "other"}
+	for _, team := range []*model.Team{deletedTeam, otherTeam} {
+		drive.PutObject(model.TEAM_FOLDER_PREFIX+team.GetUIDInString()+model.TEAM_SPACE_FOLDER+"/a.txt", []byte("a"), "text/plain")
+		drive.PutObject(model.TEAM_FOLDER_PREFIX+team.GetUIDInString()+model.TEAM_SYSTEM_FOLDER+"/icon/b.png", []byte("b"), "image/png")
+	}
+
+	sink := NewMemorySink()
+	relay := newTestRelay(t, sink)
+	relay.AddSink(SINK_TEAM_DRIVE_CLEANER, NewTeamDriveCleaner(model.NewDrive(nil, drive, zap.NewNop().Sugar())))
+	createTestEvents(t, relay.Storage, otherTeam.GetUIDInString())
+	if _, err := relay.Storage.OutboxEventStorage.Create(model.NewTeamDeletedEvent(deletedTeam, nil, 1)); err != nil {
+		t.Fatalf("create outbox event failed: %v", err)
+	}
+	relayUntilDrained(t, relay)
+
+	if drive.failures != 0 {
+		t.Errorf("removal is not retried, %d failures left", drive.failures)
+	}
+	page, err := drive.ListObjects(model.TEAM_FOLDER_PREFIX+deletedTeam.GetUIDInString()+"/", "", 0)
+	if err != nil {
+		t.Fatalf("list objects failed: %v", err)
+	}
+	if len(page.Objects) != 0 {
+		t.Errorf("%d objects of the deleted team are left", len(page.Objects))
+	}
+	page, err = drive.ListObjects(model.TEAM_FOLDER_PREFIX+otherTeam.GetUIDInString()+"/", "", 0)
+	if err != nil {
+		t.Fatalf("list objects failed: %v", err)
+	}
+	if len(page.Objects) != 2 {
+		t.Errorf("objects of the other team = %d, want 2", len(page.Objects))
+	}
+	// the retries of removal do not publish the TeamDeleted event to the other sink again
+	if events := sink.Events(); len(events) != 2 {
+		t.Errorf("published events = %d, want 2", len(events))
+	}
+}
@@ -0,0 +1,17 @@
+package rolegrantexpirer
+
+import (
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/notification"
+)
+
+// NewRoleGrantNotice capture the time-bound role before it is restored.
+func NewRoleGrantNotice(category string, teamMember *model.TeamMember) *notification.Notice {
+	return notification.NewNotice(category, teamMember.TeamID, []int{teamMember.ExportUserID()}, map[string]interface{}{
+		"teamMemberID": teamMember.ExportID(),
+		"userID":       teamMember.ExportUserID(),
+		"userRole":     teamMember.UserRole, // the time-bound role
+		"baseUserRole": teamMember.BaseUserRole,
+		"validUntil":   teamMember.RoleValidUntil,
+	})
+}
@@ -0,0 +1,128 @@
+package rolegrantexpirer
+
+import (
+	"context"
+	"time"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/notification"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/config"
+	"go.uber.org/zap"
+)
+
+const DEFAULT_EXPIRE_BATCH_SIZE = 100
+
+// RoleGrantExpirer persist the downgrade of expired time-bound role and hand the notices for the team member and the admins to the Notifier.
+// the effective role is already the base role once expired, see TeamMember.ExportUserRole.
+type RoleGrantExpirer struct {
+	logger        *zap.SugaredLogger
+	Storage       *model.Storage
+	Notifier      notification.Notifier
+	CheckInterval time.Duration
+	NoticeBefore  time.Duration
+}
+
+func NewRoleGrantExpirer(storage *model.Storage, notifier notification.Notifier, checkInterval time.Duration, noticeBefore time.Duration, logger *zap.SugaredLogger) *RoleGrantExpirer {
+	return &RoleGrantExpirer{
+		logger:        logger,
+		Storage:       storage,
+		Notifier:      notifier,
+		CheckInterval: checkInterval,
+		NoticeBefore:  noticeBefore,
+	}
+}
+
+func NewRoleGrantExpirerByGlobalConfig(config *config.Config, storage *model.Storage, notifier notification.Notifier, logger *zap.SugaredLogger) *RoleGrantExpirer {
+	return NewRoleGrantExpirer(storage, notifier, config.GetRoleGrantExpiryCheckInterval(), config.GetRoleGrantExpiryNoticeBefore(), logger)
+}
+
+// NotifyExpiringRoleGrants notify the time-bound roles which will expire in notice window, each grant is notified once.
+func (e *RoleGrantExpirer) NotifyExpiringRoleGrants() {
+	now := time.Now().UTC()
+	teamMembers, err := e.Storage.TeamMemberStorage.RetrieveTimeBoundRoleExpiringBetween(now, now.Add(e.NoticeBefore), DEFAULT_EXPIRE_BATCH_SIZE)
+	if err != nil {
+		e.logger.Errorw("retrieve expiring time-bound roles failed", "err", err)
+		return
+	}
+	for _, teamMember := range teamMembers {
+		if err := e.notify(notification.NOTICE_CATEGORY_ROLE_GRANT_EXPIRING, teamMember); err != nil {
+			e.logger.Errorw("notify expiring time-bound role failed", "teamMemberID", teamMember.ExportID(), "err", err)
+			continue
+		}
+		teamMember.MarkRoleExpiryNotified()
+		if err := e.Storage.TeamMemberStorage.UpdateRole(teamMember); err != nil {
+			e.logger.Errorw("mark time-bound role expiry notified failed", "teamMemberID", teamMember.ExportID(), "err", err)
+		}
+	}
+}
+
+// ExpireRoleGrants restore the base role of all expired time-bound roles.
+func (e *RoleGrantExpirer) ExpireRoleGrants() {
+	for {
+		teamMembers, err := e.Storage.TeamMemberStorage.RetrieveTimeBoundRoleExpiredBefore(time.Now().UTC(), DEFAULT_EXPIRE_BATCH_SIZE)
+		if err != nil {
+			e.logger.Errorw("retrieve expired time-bound roles failed", "err", err)
+			return
+		}
+		for _, teamMember := range teamMembers {
+			notice := NewRoleGrantNotice(notification.NOTICE_CATEGORY_ROLE_GRANT_EXPIRED, teamMember)
+			previousUserRole := teamMember.UserRole
+			teamMember.ExpireTimeBoundRole()
+			errInExpire := e.Storage.Transaction(func(txStorage *model.Storage) error {
+				if err := txStorage.TeamMemberStorage.UpdateRole(teamMember); err != nil {
+					return err
+				}
+				_, err := txStorage.OutboxEventStorage.Create(model.NewTeamMemberRoleChangedEvent(teamMember, previousUserRole, model.SYSTEM_OPERATOR_USER_ID))
+				return err
+			})
+			if errInExpire != nil {
+				e.logger.Errorw("persist expired time-bound role failed", "teamMemberID", teamMember.ExportID(), "err", errInExpire)
+				return
+			}
+			if err := e.deliver(notice); err != nil {
+				e.logger.Errorw("notify expired time-bound role failed", "teamMemberID", teamMember.ExportID(), "err", err)
+			}
+		}
+		if len(teamMembers) < DEFAULT_EXPIRE_BATCH_SIZE {
+			return
+		}
+	}
+}
+
+func (e *RoleGrantExpirer) notify(category string, teamMember *model.TeamMember) error {
+	return e.deliver(NewRoleGrantNotice(category, teamMember))
+}
+
+// deliver send notice to the team member and the owners and admins of the team.
+func (e *RoleGrantExpirer) deliver(notice *notification.Notice) error {
+	memberUserID := notice.RecipientUserIDs[0]
+	for _, userRole := range []int{model.USER_ROLE_OWNER, model.USER_ROLE_ADMIN} {
+		teamMembers, err := e.Storage.TeamMemberStorage.RetrieveByTeamIDAndUserRole(notice.TeamID, userRole)
+		if err != nil {
+			return err
+		}
+		for _, teamMember := range teamMembers {
+			if teamMember.ExportUserID() != memberUserID && teamMember.IsStatusOK() {
+				notice.RecipientUserIDs = append(notice.RecipientUserIDs, teamMember.ExportUserID())
+			}
+		}
+	}
+	return e.Notifier.Notify(notice)
+}
+
+// Run check time-bound roles periodically, it blocks until ctx is done.
+func (e *RoleGrantExpirer) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.CheckInterval)
+	defer ticker.Stop()
+	e.ExpireRoleGrants()
+	e.NotifyExpiringRoleGrants()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.ExpireRoleGrants()
+			e.NotifyExpiringRoleGrants()
+		}
+	}
+}
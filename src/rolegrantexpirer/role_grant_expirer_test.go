@@ -0,0 +1,142 @@
+package rolegrantexpirer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/internal/testdb"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/notification"
+	"go.uber.org/zap"
+)
+
+type recordingNotifier struct {
+	notices []*notification.Notice
+}
+
+func (n *recordingNotifier) Notify(notice *notification.Notice) error {
+	n.notices = append(n.notices, notice)
+	return nil
+}
+
+func (n *recordingNotifier) count(category string) int {
+	count := 0
+	for _, notice := range n.notices {
+		if notice.Category == category {
+			count++
+		}
+	}
+	return count
+}
+
+func newTestExpirer(t *testing.T) (*RoleGrantExpirer, *recordingNotifier) {
+	notifier := &recordingNotifier{}
+	expirer := NewRoleGrantExpirer(testdb.NewStorage(t), notifier, time.Minute, time.Hour, zap.NewNop().Sugar())
+	return expirer, notifier
+}
+
+func createTimeBoundTeamMember(t *testing.T, storage *model.Storage, userID int, validUntil time.Time) *model.TeamMember {
+	t.Helper()
+	teamMember := model.NewTeamMember()
+	teamMember.TeamID = 1
+	teamMember.UserID = userID
+	teamMember.UserRole = model.USER_ROLE_VIEWER
+	teamMember.Status = model.TEAM_MEMBER_STATUS_OK
+	teamMember.InitCreatedAt()
+	teamMember.InitUpdatedAt()
+	id, err := storage.TeamMemberStorage.Create(teamMember)
+	if err != nil {
+		t.Fatalf("create team member failed: %v", err)
+	}
+	teamMember.SetID(id)
+	teamMember.GrantTimeBoundRole(model.USER_ROLE_ADMIN, validUntil)
+	if err := storage.TeamMemberStorage.Update(teamMember); err != nil {
+		t.Fatalf("grant time-bound role failed: %v", err)
+	}
+	return teamMember
+}
+
+func countOutboxEvents(t *testing.T, storage *model.Storage) int {
+	t.Helper()
+	events, err := storage.OutboxEventStorage.RetrieveDue(time.Now().UTC(), 1000)
+	if err != nil {
+		t.Fatalf("retrieve outbox events failed: %v", err)
+	}
+	return len(events)
+}
+
+func TestExpireRoleGrantsPersistsBaseRole(t *testing.T) {
+	expirer, notifier := newTestExpirer(t)
+	storage := expirer.Storage
+	granted := createTimeBoundTeamMember(t, storage, 1, time.Now().Add(-time.Minute))
+
+	expirer.ExpireRoleGrants()
+
+	teamMember, err := storage.TeamMemberStorage.RetrieveByID(granted.ID)
+	if err != nil {
+		t.Fatalf("retrieve team member failed: %v", err)
+	}
+	if teamMember.UserRole != model.USER_ROLE_VIEWER {
+		t.Errorf("user role = %d, want base role %d", teamMember.UserRole, model.USER_ROLE_VIEWER)
+	}
+	if teamMember.BaseUserRole != 0 || !teamMember.RoleValidUntil.IsZero() || teamMember.IsRoleTimeBound() {
+		t.Errorf("time-bound role not cleared: base role %d, valid until %v", teamMember.BaseUserRole, teamMember.RoleValidUntil)
+	}
+
+	// the downgraded row is not picked up again, so no duplicated events or notices
+	expirer.ExpireRoleGrants()
+	if got := notifier.count(notification.NOTICE_CATEGORY_ROLE_GRANT_EXPIRED); got != 1 {
+		t.Errorf("expired notices = %d, want 1", got)
+	}
+	if got := countOutboxEvents(t, storage); got != 1 {
+		t.Errorf("outbox events = %d, want 1", got)
+	}
+
+	// a later permanent role change is kept by the next tick
+	teamMember.SetUserRole(model.USER_ROLE_EDITOR)
+	if err := storage.TeamMemberStorage.Update(teamMember); err != nil {
+		t.Fatalf("update team member failed: %v", err)
+	}
+	expirer.ExpireRoleGrants()
+	teamMember, err = storage.TeamMemberStorage.RetrieveByID(granted.ID)
+	if err != nil {
+		t.Fatalf("retrieve team member failed: %v", err)
+	}
+	if teamMember.UserRole != model.USER_ROLE_EDITOR {
+		t.Errorf("user role = %d, want %d", teamMember.UserRole, model.USER_ROLE_EDITOR)
+	}
+}
+
+func TestExpireRoleGrantsDrainsFullBatches(t *testing.T) {
+	expirer, notifier := newTestExpirer(t)
+	total := DEFAULT_EXPIRE_BATCH_SIZE + 1
+	for userID := 1; userID <= total; userID++ {
+		createTimeBoundTeamMember(t, expirer.Storage, userID, time.Now().Add(-time.Minute))
+	}
+
+	done := make(chan struct{})
+	go func() {
+		expirer.ExpireRoleGrants()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("expire role grants did not return")
+	}
+	if got := notifier.count(notification.NOTICE_CATEGORY_ROLE_GRANT_EXPIRED); got != total {
+		t.Errorf("expired notices = %d, want %d", got, total)
+	}
+}
+
+func TestNotifyExpiringRoleGrantsOnce(t *testing.T) {
+	expirer, notifier := newTestExpirer(t)
+	createTimeBoundTeamMember(t, expirer.Storage, 1, time.Now().Add(time.Minute))
+
+	expirer.NotifyExpiringRoleGrants()
+	expirer.NotifyExpiringRoleGrants()
+
+	if got := notifier.count(notification.NOTICE_CATEGORY_ROLE_GRANT_EXPIRING); got != 1 {
+		t.Errorf("expiring notices = %d, want 1", got)
+	}
+}
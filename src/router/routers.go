@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/authenticator"
 	"github.com/kozmoai/kozmo-supervisor-backend/src/controller"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
 )
 
 type Router struct {
@@ -25,10 +26,12 @@ func (r *Router) RegisterRouters(engine *gin.Engine) {
 	usersRouter := routerGroup.Group("/users")
 	teamsRouter := routerGroup.Group("/teams")
 	statusRouter := routerGroup.Group("/status")
+	scimRouter := engine.Group(model.SCIM_BASE_PATH)
 
 	// register auth
 	usersRouter.Use(r.Authenticator.JWTAuth())
 	teamsRouter.Use(r.Authenticator.JWTAuth())
+	scimRouter.Use(r.Authenticator.SCIMAuth())
 
 	// auth routers
 	authRouter.POST("/verification", r.Controller.GetVerificationCode)
@@ -50,6 +53,55 @@ func (r *Router) RegisterRouters(engine *gin.Engine) {
 	teamsRouter.GET("/my", r.Controller.GetMyTeams)
 	teamsRouter.PATCH("/:teamID/config", r.Controller.UpdateTeamConfig)
 	teamsRouter.PATCH("/:teamID/permission", r.Controller.UpdateTeamPermission)
+	teamsRouter.DELETE("/:teamID", r.Controller.DeleteTeam)
+	teamsRouter.GET("/:teamID/domains", r.Controller.GetAllDomains)
+	teamsRouter.POST("/:teamID/domains", r.Controller.CreateDomain)
+	teamsRouter.PATCH("/:teamID/domains/:domainID", r.Controller.UpdateDomain)
+	teamsRouter.POST("/:teamID/domains/:domainID/verification", r.Controller.VerifyDomain)
+	teamsRouter.DELETE("/:teamID/domains/:domainID", r.Controller.DeleteDomain)
+	teamsRouter.GET("/:teamID/members", r.Controller.SearchTeamMembers)
+	teamsRouter.PATCH("/:teamID/teamMembers/:targetTeamMemberID/suspend", r.Controller.SuspendTeamMember)
+	teamsRouter.PATCH("/:teamID/teamMembers/:targetTeamMemberID/reactivate", r.Controller.ReactivateTeamMember)
+	teamsRouter.PATCH("/:teamID/teamMembers/:targetTeamMemberID/approve", r.Controller.ApproveTeamMember)
+	teamsRouter.PUT("/:teamID/teamMembers/:targetTeamMemberID/timeBoundRole", r.Controller.GrantTimeBoundRole)
+	teamsRouter.DELETE("/:teamID/teamMembers/:targetTeamMemberID/timeBoundRole", r.Controller.RevokeTimeBoundRole)
+	teamsRouter.GET("/:teamID/scim/tokens", r.Controller.GetAllSCIMTokens)
+	teamsRouter.POST("/:teamID/scim/tokens", r.Controller.CreateSCIMToken)
+	teamsRouter.DELETE("/:teamID/scim/tokens/:scimTokenID", r.Controller.DeleteSCIMToken)
+	teamsRouter.GET("/:teamID/capabilities", r.Controller.GetCapabilities)
+	teamsRouter.GET("/:teamID/roles", r.Controller.GetAllRoles)
+	teamsRouter.POST("/:teamID/roles", r.Controller.CreateRole)
+	teamsRouter.PATCH("/:teamID/roles/:roleID", r.Controller.UpdateRole)
+	teamsRouter.DELETE("/:teamID/roles/:roleID", r.Controller.DeleteRole)
+	teamsRouter.GET("/:teamID/units/:unitType/:unitID/roleRelations", r.Controller.GetUnitRoleRelations)
+	teamsRouter.POST("/:teamID/units/:unitType/:unitID/roleRelations", r.Controller.CreateUnitRoleRelation)
+	teamsRouter.DELETE("/:teamID/units/:unitType/:unitID/roleRelations/:unitRoleRelationID", r.Controller.DeleteUnitRoleRelation)
+	teamsRouter.GET("/:teamID/accessRequests", r.Controller.GetAllAccessRequests)
+	teamsRouter.POST("/:teamID/accessRequests", r.Controller.CreateAccessRequest)
+	teamsRouter.GET("/:teamID/accessRequests/:accessRequestID", r.Controller.GetAccessRequest)
+	teamsRouter.POST("/:teamID/accessRequests/:accessRequestID/approve", r.Controller.ApproveAccessRequest)
+	teamsRouter.POST("/:teamID/accessRequests/:accessRequestID/deny", r.Controller.DenyAccessRequest)
+	teamsRouter.POST("/:teamID/accessRequests/:accessRequestID/cancel", r.Controller.CancelAccessRequest)
+	teamsRouter.GET("/:teamID/webhooks", r.Controller.GetAllWebhooks)
+	teamsRouter.POST("/:teamID/webhooks", r.Controller.CreateWebhook)
+	teamsRouter.PATCH("/:teamID/webhooks/:webhookID", r.Controller.UpdateWebhook)
+	teamsRouter.DELETE("/:teamID/webhooks/:webhookID", r.Controller.DeleteWebhook)
+	teamsRouter.POST("/:teamID/webhooks/:webhookID/secret", r.Controller.RotateWebhookSecret)
+	teamsRouter.POST("/:teamID/webhooks/:webhookID/ping", r.Controller.PingWebhook)
+	teamsRouter.GET("/:teamID/webhooks/:webhookID/deliveries", r.Controller.GetAllWebhookDeliveries)
+	teamsRouter.POST("/:teamID/webhooks/:webhookID/deliveries/:webhookDeliveryID/redeliver", r.Controller.RedeliverWebhookDelivery)
+
+	// scim routers
+	scimRouter.GET("/Users", r.Controller.SCIMGetUsers)
+	scimRouter.POST("/Users", r.Controller.SCIMCreateUser)
+	scimRouter.GET("/Users/:scimUserID", r.Controller.SCIMGetUser)
+	scimRouter.PUT("/Users/:scimUserID", r.Controller.SCIMReplaceUser)
+	scimRouter.PATCH("/Users/:scimUserID", r.Controller.SCIMPatchUser)
+	scimRouter.DELETE("/Users/:scimUserID", r.Controller.SCIMDeleteUser)
+	scimRouter.GET("/Groups", r.Controller.SCIMGetGroups)
+	scimRouter.GET("/Groups/:scimGroupID", r.Controller.SCIMGetGroup)
+	scimRouter.PUT("/Groups/:scimGroupID", r.Controller.SCIMReplaceGroup)
+	scimRouter.PATCH("/Groups/:scimGroupID", r.Controller.SCIMPatchGroup)
 
 	// status router
 	statusRouter.GET("", r.Controller.Status)
@@ -13,6 +13,9 @@ const DEPLOY_MODE_CLOUD = "cloud"
 const DRIVE_TYPE_AWS = "aws"
 const DRIVE_TYPE_MINIO = "minio"
 
+// a failed webhook delivery keeps pending until the last attempt, the attempts are bounded so it is given up in days.
+const WEBHOOK_MAX_ATTEMPTS_LIMIT = 50
+
 var instance *Config
 var once sync.Once
 
@@ -61,6 +64,51 @@ type Config struct {
 	DriveTeamBucketName   string `env:"KOZMO_DRIVE_TEAM_BUCKET_NAME"   envDefault:"kozmo-supervisor-team"`
 	DriveUploadTimeoutRaw string `env:"KOZMO_DRIVE_UPLOAD_TIMEOUT"     envDefault:"300s"`
 	DriveUploadTimeout    time.Duration
+
+	// domain config
+	SystemDomain              string `env:"KOZMO_SYSTEM_DOMAIN"             envDefault:""`
+	DomainReverifyIntervalRaw string `env:"KOZMO_DOMAIN_REVERIFY_INTERVAL"  envDefault:"1h"`
+	DomainReverifyInterval    time.Duration
+
+	// access control policy config, empty policy file means using the built-in policy
+	AccessControlPolicyFile              string `env:"KOZMO_ACCESS_CONTROL_POLICY_FILE"            envDefault:""`
+	AccessControlPolicyReloadIntervalRaw string `env:"KOZMO_ACCESS_CONTROL_POLICY_RELOAD_INTERVAL" envDefault:"0s"`
+	AccessControlPolicyReloadInterval    time.Duration
+
+	// time-bound role config
+	RoleGrantExpiryCheckIntervalRaw string `env:"KOZMO_ROLE_GRANT_EXPIRY_CHECK_INTERVAL" envDefault:"1m"`
+	RoleGrantExpiryCheckInterval    time.Duration
+	RoleGrantExpiryNoticeBeforeRaw  string `env:"KOZMO_ROLE_GRANT_EXPIRY_NOTICE_BEFORE"  envDefault:"24h"`
+	RoleGrantExpiryNoticeBefore     time.Duration
+
+	// internal request token config, the legacy md5 token is rejected by default,
+	// set KOZMO_REQUEST_TOKEN_ALLOW_LEGACY to true only while the callers are migrating to sign with timestamp and nonce
+	RequestTokenSkewWindowRaw string `env:"KOZMO_REQUEST_TOKEN_SKEW_WINDOW" envDefault:"5m"`
+	RequestTokenSkewWindow    time.Duration
+	RequestTokenAllowLegacy   string `env:"KOZMO_REQUEST_TOKEN_ALLOW_LEGACY" envDefault:"false"`
+
+	// internal mutual TLS config, empty cert file means serving the internal port in plaintext
+	// the allowlist format is "<service>=<CN|DNS|URI|EMAIL>:<value>", separated by comma
+	InternalTLSCertFile          string `env:"KOZMO_INTERNAL_TLS_CERT_FILE"       envDefault:""`
+	InternalTLSKeyFile           string `env:"KOZMO_INTERNAL_TLS_KEY_FILE"        envDefault:""`
+	InternalTLSClientCAFile      string `env:"KOZMO_INTERNAL_TLS_CLIENT_CA_FILE"  envDefault:""`
+	InternalTLSAllowedClients    string `env:"KOZMO_INTERNAL_TLS_ALLOWED_CLIENTS" envDefault:""`
+	InternalTLSReloadIntervalRaw string `env:"KOZMO_INTERNAL_TLS_RELOAD_INTERVAL" envDefault:"1m"`
+	InternalTLSReloadInterval    time.Duration
+
+	// domain event outbox config, the sink is one of redis, http and memory
+	OutboxSink             string `env:"KOZMO_OUTBOX_SINK"              envDefault:"redis"`
+	OutboxHTTPEndpoint     string `env:"KOZMO_OUTBOX_HTTP_ENDPOINT"     envDefault:""`
+	OutboxRelayIntervalRaw string `env:"KOZMO_OUTBOX_RELAY_INTERVAL"    envDefault:"1s"`
+	OutboxRelayInterval    time.Duration
+	OutboxRelayBatchSize   int `env:"KOZMO_OUTBOX_RELAY_BATCH_SIZE" envDefault:"100"`
+
+	// team outgoing webhook config, a failed delivery is retried after backoff, backoff * 2, backoff * 4 ... at most an hour
+	WebhookDeliveryIntervalRaw string `env:"KOZMO_WEBHOOK_DELIVERY_INTERVAL" envDefault:"10s"`
+	WebhookDeliveryInterval    time.Duration
+	WebhookRetryBackoffRaw     string `env:"KOZMO_WEBHOOK_RETRY_BACKOFF"     envDefault:"30s"`
+	WebhookRetryBackoff        time.Duration
+	WebhookMaxAttempts         int `env:"KOZMO_WEBHOOK_MAX_ATTEMPTS" envDefault:"8"`
 }
 
 func getConfig() (*Config, error) {
@@ -74,12 +122,64 @@ func getConfig() (*Config, error) {
 	if errInParseDuration != nil {
 		return nil, errInParseDuration
 	}
+	cfg.DomainReverifyInterval, errInParseDuration = time.ParseDuration(cfg.DomainReverifyIntervalRaw)
+	if errInParseDuration != nil {
+		return nil, errInParseDuration
+	}
+	cfg.AccessControlPolicyReloadInterval, errInParseDuration = time.ParseDuration(cfg.AccessControlPolicyReloadIntervalRaw)
+	if errInParseDuration != nil {
+		return nil, errInParseDuration
+	}
+	cfg.RoleGrantExpiryCheckInterval, errInParseDuration = time.ParseDuration(cfg.RoleGrantExpiryCheckIntervalRaw)
+	if errInParseDuration != nil {
+		return nil, errInParseDuration
+	}
+	cfg.RoleGrantExpiryNoticeBefore, errInParseDuration = time.ParseDuration(cfg.RoleGrantExpiryNoticeBeforeRaw)
+	if errInParseDuration != nil {
+		return nil, errInParseDuration
+	}
+	cfg.RequestTokenSkewWindow, errInParseDuration = time.ParseDuration(cfg.RequestTokenSkewWindowRaw)
+	if errInParseDuration != nil {
+		return nil, errInParseDuration
+	}
+	cfg.InternalTLSReloadInterval, errInParseDuration = time.ParseDuration(cfg.InternalTLSReloadIntervalRaw)
+	if errInParseDuration != nil {
+		return nil, errInParseDuration
+	}
+	cfg.OutboxRelayInterval, errInParseDuration = time.ParseDuration(cfg.OutboxRelayIntervalRaw)
+	if errInParseDuration != nil {
+		return nil, errInParseDuration
+	}
+	cfg.WebhookDeliveryInterval, errInParseDuration = time.ParseDuration(cfg.WebhookDeliveryIntervalRaw)
+	if errInParseDuration != nil {
+		return nil, errInParseDuration
+	}
+	cfg.WebhookRetryBackoff, errInParseDuration = time.ParseDuration(cfg.WebhookRetryBackoffRaw)
+	if errInParseDuration != nil {
+		return nil, errInParseDuration
+	}
 
-	// ok
-	fmt.Printf("----------------\n")
-	fmt.Printf("%+v\n", cfg)
-	fmt.Printf("%+v\n", err)
+	// validate data, the intervals drive tickers which do not accept non-positive duration, and a non-positive backoff retries at once
+	positiveIntervals := []struct {
+		name     string
+		interval time.Duration
+	}{
+		{"KOZMO_DOMAIN_REVERIFY_INTERVAL", cfg.DomainReverifyInterval},
+		{"KOZMO_ROLE_GRANT_EXPIRY_CHECK_INTERVAL", cfg.RoleGrantExpiryCheckInterval},
+		{"KOZMO_OUTBOX_RELAY_INTERVAL", cfg.OutboxRelayInterval},
+		{"KOZMO_WEBHOOK_DELIVERY_INTERVAL", cfg.WebhookDeliveryInterval},
+		{"KOZMO_WEBHOOK_RETRY_BACKOFF", cfg.WebhookRetryBackoff},
+	}
+	for _, positiveInterval := range positiveIntervals {
+		if positiveInterval.interval <= 0 {
+			return nil, fmt.Errorf("%s should be positive, got %s", positiveInterval.name, positiveInterval.interval)
+		}
+	}
+	if cfg.WebhookMaxAttempts < 1 || cfg.WebhookMaxAttempts > WEBHOOK_MAX_ATTEMPTS_LIMIT {
+		return nil, fmt.Errorf("KOZMO_WEBHOOK_MAX_ATTEMPTS should be between 1 and %d, got %d", WEBHOOK_MAX_ATTEMPTS_LIMIT, cfg.WebhookMaxAttempts)
+	}
 
+	// ok, the config is not printed since it carries the secrets
 	return cfg, err
 }
 
@@ -104,6 +204,13 @@ func (c *Config) IsServeHTTPS() bool {
 	return false
 }
 
+func (c *Config) IsRequestTokenLegacyAllowed() bool {
+	if c.RequestTokenAllowLegacy == "true" {
+		return true
+	}
+	return false
+}
+
 func (c *Config) GetSecretKey() string {
 	return c.SecretKey
 }
@@ -216,3 +323,83 @@ func (c *Config) GetMINIOTeamBucketName() string {
 func (c *Config) GetMINIOTimeout() time.Duration {
 	return c.DriveUploadTimeout
 }
+
+func (c *Config) GetSystemDomain() string {
+	return c.SystemDomain
+}
+
+func (c *Config) GetDomainReverifyInterval() time.Duration {
+	return c.DomainReverifyInterval
+}
+
+func (c *Config) GetAccessControlPolicyFile() string {
+	return c.AccessControlPolicyFile
+}
+
+func (c *Config) GetAccessControlPolicyReloadInterval() time.Duration {
+	return c.AccessControlPolicyReloadInterval
+}
+
+func (c *Config) GetRoleGrantExpiryCheckInterval() time.Duration {
+	return c.RoleGrantExpiryCheckInterval
+}
+
+func (c *Config) GetRoleGrantExpiryNoticeBefore() time.Duration {
+	return c.RoleGrantExpiryNoticeBefore
+}
+
+func (c *Config) GetRequestTokenSkewWindow() time.Duration {
+	return c.RequestTokenSkewWindow
+}
+
+func (c *Config) IsInternalTLSEnabled() bool {
+	return c.InternalTLSCertFile != ""
+}
+
+func (c *Config) GetInternalTLSCertFile() string {
+	return c.InternalTLSCertFile
+}
+
+func (c *Config) GetInternalTLSKeyFile() string {
+	return c.InternalTLSKeyFile
+}
+
+func (c *Config) GetInternalTLSClientCAFile() string {
+	return c.InternalTLSClientCAFile
+}
+
+func (c *Config) GetInternalTLSAllowedClients() string {
+	return c.InternalTLSAllowedClients
+}
+
+func (c *Config) GetInternalTLSReloadInterval() time.Duration {
+	return c.InternalTLSReloadInterval
+}
+
+func (c *Config) GetOutboxSink() string {
+	return c.OutboxSink
+}
+
+func (c *Config) GetOutboxHTTPEndpoint() string {
+	return c.OutboxHTTPEndpoint
+}
+
+func (c *Config) GetOutboxRelayInterval() time.Duration {
+	return c.OutboxRelayInterval
+}
+
+func (c *Config) GetOutboxRelayBatchSize() int {
+	return c.OutboxRelayBatchSize
+}
+
+func (c *Config) GetWebhookDeliveryInterval() time.Duration {
+	return c.WebhookDeliveryInterval
+}
+
+func (c *Config) GetWebhookRetryBackoff() time.Duration {
+	return c.WebhookRetryBackoff
+}
+
+func (c *Config) GetWebhookMaxAttempts() int {
+	return c.WebhookMaxAttempts
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestGetConfigWebhookMaxAttempts(t *testing.T) {
+	cases := []struct {
+		maxAttempts int
+		wantErr     bool
+	}{
+		{0, true},
+		{1, false},
+		{WEBHOOK_MAX_ATTEMPTS_LIMIT, false},
+		{WEBHOOK_MAX_ATTEMPTS_LIMIT + 1, true},
+	}
+	for _, c := range cases {
+		t.Run(strconv.Itoa(c.maxAttempts), func(t *testing.T) {
+			t.Setenv("KOZMO_WEBHOOK_MAX_ATTEMPTS", strconv.Itoa(c.maxAttempts))
+			cfg, err := getConfig()
+			if (err != nil) != c.wantErr {
+				t.Fatalf("err = %v, want error %v", err, c.wantErr)
+			}
+			if err == nil && cfg.GetWebhookMaxAttempts() != c.maxAttempts {
+				t.Errorf("max attempts = %d, want %d", cfg.GetWebhookMaxAttempts(), c.maxAttempts)
+			}
+		})
+	}
+}
+
+func TestGetConfigDefaults(t *testing.T) {
+	cfg, err := getConfig()
+	if err != nil {
+		t.Fatalf("get config failed: %v", err)
+	}
+	if cfg.IsRequestTokenLegacyAllowed() {
+		t.Error("legacy request token should be rejected by default")
+	}
+}
@@ -0,0 +1,98 @@
+package dnsresolver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+)
+
+var ErrNoSuchRecord = errors.New("no such dns record")
+
+// Resolver looks up the records used by domain ownership verification.
+// use NetResolver in production, and FakeResolver for tests and local development.
+type Resolver interface {
+	LookupTXT(ctx context.Context, host string) ([]string, error)
+	LookupCNAME(ctx context.Context, host string) (string, error)
+}
+
+// NormalizeHost lower cases the host and removes the trailing dot of a fully qualified name.
+func NormalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
+
+type NetResolver struct {
+	resolver *net.Resolver
+}
+
+func NewNetResolver() *NetResolver {
+	return &NetResolver{
+		resolver: net.DefaultResolver,
+	}
+}
+
+func (r *NetResolver) LookupTXT(ctx context.Context, host string) ([]string, error) {
+	return r.resolver.LookupTXT(ctx, host)
+}
+
+func (r *NetResolver) LookupCNAME(ctx context.Context, host string) (string, error) {
+	cname, err := r.resolver.LookupCNAME(ctx, host)
+	if err != nil {
+		return "", err
+	}
+	return NormalizeHost(cname), nil
+}
+
+// FakeResolver is an in-memory resolver, records can be changed at runtime.
+type FakeResolver struct {
+	mutex        sync.RWMutex
+	txtRecords   map[string][]string
+	cnameRecords map[string]string
+}
+
+func NewFakeResolver() *FakeResolver {
+	return &FakeResolver{
+		txtRecords:   make(map[string][]string),
+		cnameRecords: make(map[string]string),
+	}
+}
+
+func (r *FakeResolver) SetTXT(host string, values ...string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.txtRecords[NormalizeHost(host)] = values
+}
+
+func (r *FakeResolver) SetCNAME(host string, target string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.cnameRecords[NormalizeHost(host)] = NormalizeHost(target)
+}
+
+func (r *FakeResolver) Remove(host string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.txtRecords, NormalizeHost(host))
+	delete(r.cnameRecords, NormalizeHost(host))
+}
+
+func (r *FakeResolver) LookupTXT(ctx context.Context, host string) ([]string, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	values, hit := r.txtRecords[NormalizeHost(host)]
+	if !hit {
+		return nil, ErrNoSuchRecord
+	}
+	return values, nil
+}
+
+func (r *FakeResolver) LookupCNAME(ctx context.Context, host string) (string, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	target, hit := r.cnameRecords[NormalizeHost(host)]
+	if !hit {
+		return "", ErrNoSuchRecord
+	}
+	return target, nil
+}
@@ -0,0 +1,95 @@
+package mtls
+
+import (
+	"crypto/x509"
+	"errors"
+	"strings"
+)
+
+// identity kinds of allowlist entry, CN matches the subject common name and the others match the SANs.
+const (
+	IDENTITY_KIND_CN    = "CN"
+	IDENTITY_KIND_DNS   = "DNS"
+	IDENTITY_KIND_URI   = "URI"
+	IDENTITY_KIND_EMAIL = "EMAIL"
+)
+
+var errInvalidAllowlistEntry = errors.New("invalid allowlist entry, the format is \"<service>=<CN|DNS|URI|EMAIL>:<value>\"")
+
+type allowlistEntry struct {
+	Service string
+	Kind    string
+	Value   string
+}
+
+// Allowlist maps client certificate identities to the calling service names.
+type Allowlist struct {
+	entries []*allowlistEntry
+}
+
+// ParseAllowlist parse comma separated entries, e.g. "builder=CN:kozmo-builder-backend,drive=URI:spiffe://kozmo/drive".
+// a service can have multiple entries.
+func ParseAllowlist(raw string) (*Allowlist, error) {
+	allowlist := &Allowlist{}
+	for _, rawEntry := range strings.Split(raw, ",") {
+		rawEntry = strings.TrimSpace(rawEntry)
+		if rawEntry == "" {
+			continue
+		}
+		service, identity, found := strings.Cut(rawEntry, "=")
+		if !found || service == "" {
+			return nil, errInvalidAllowlistEntry
+		}
+		kind, value, found := strings.Cut(identity, ":")
+		if !found || value == "" {
+			return nil, errInvalidAllowlistEntry
+		}
+		kind = strings.ToUpper(kind)
+		switch kind {
+		case IDENTITY_KIND_CN, IDENTITY_KIND_DNS, IDENTITY_KIND_URI, IDENTITY_KIND_EMAIL:
+		default:
+			return nil, errInvalidAllowlistEntry
+		}
+		allowlist.entries = append(allowlist.entries, &allowlistEntry{Service: service, Kind: kind, Value: value})
+	}
+	if len(allowlist.entries) == 0 {
+		return nil, errors.New("empty allowlist, at least one calling service is required")
+	}
+	return allowlist, nil
+}
+
+// Match feedback the service name of the first entry matched by certificate.
+func (a *Allowlist) Match(cert *x509.Certificate) (string, bool) {
+	for _, entry := range a.entries {
+		if entry.matchCertificate(cert) {
+			return entry.Service, true
+		}
+	}
+	return "", false
+}
+
+func (entry *allowlistEntry) matchCertificate(cert *x509.Certificate) bool {
+	switch entry.Kind {
+	case IDENTITY_KIND_CN:
+		return cert.Subject.CommonName == entry.Value
+	case IDENTITY_KIND_DNS:
+		for _, name := range cert.DNSNames {
+			if strings.EqualFold(name, entry.Value) {
+				return true
+			}
+		}
+	case IDENTITY_KIND_URI:
+		for _, uri := range cert.URIs {
+			if uri.String() == entry.Value {
+				return true
+			}
+		}
+	case IDENTITY_KIND_EMAIL:
+		for _, email := range cert.EmailAddresses {
+			if strings.EqualFold(email, entry.Value) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package mtls
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/url"
+	"testing"
+)
+
+func TestParseAllowlist(t *testing.T) {
+	cases := []struct {
+		name    string
+		raw     string
+		wantErr bool
+	}{
+		{"single entry", "builder=CN:kozmo-builder-backend", false},
+		{"multiple entries", " builder=CN:kozmo-builder-backend, drive=uri:spiffe://kozmo/drive,,", false},
+		{"empty", " , ", true},
+		{"missing service", "=CN:kozmo-builder-backend", true},
+		{"missing kind", "builder=kozmo-builder-backend", true},
+		{"missing value", "builder=CN:", true},
+		{"unknown kind", "builder=IP:127.0.0.1", true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := ParseAllowlist(c.raw)
+			if (err != nil) != c.wantErr {
+				t.Errorf("err = %v, want error %v", err, c.wantErr)
+			}
+		})
+	}
+}
+
+func TestAllowlistMatch(t *testing.T) {
+	allowlist, err := ParseAllowlist("builder=CN:kozmo-builder-backend,builder=DNS:builder.kozmo.svc,drive=URI:spiffe://kozmo/drive,ops=EMAIL:ops@kozmo.com")
+	if err != nil {
+		t.Fatalf("parse allowlist failed: %v", err)
+	}
+	spiffeID, _ := url.Parse("spiffe://kozmo/drive")
+	cases := []struct {
+		name    string
+		cert    *x509.Certificate
+		service string
+		hit     bool
+	}{
+		{"common name", &x509.Certificate{Subject: pkix.Name{CommonName: "kozmo-builder-backend"}}, "builder", true},
+		{"dns name case insensitive", &x509.Certificate{DNSNames: []string{"Builder.Kozmo.svc"}}, "builder", true},
+		{"uri", &x509.Certificate{URIs: []*url.URL{spiffeID}}, "drive", true},
+		{"email", &x509.Certificate{EmailAddresses: []string{"OPS@kozmo.com"}}, "ops", true},
+		{"common name is not a dns name", &x509.Certificate{Subject: pkix.Name{CommonName: "builder.kozmo.svc"}}, "", false},
+		{"unknown", &x509.Certificate{Subject: pkix.Name{CommonName: "kozmo-drive-backend"}}, "", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			service, hit := allowlist.Match(c.cert)
+			if service != c.service || hit != c.hit {
+				t.Errorf("match = %q %v, want %q %v", service, hit, c.service, c.hit)
+			}
+		})
+	}
+}
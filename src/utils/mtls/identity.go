@@ -0,0 +1,48 @@
+package mtls
+
+import (
+	"context"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+type clientIdentityContextKey struct{}
+
+// ClientIdentity is the calling service resolved from the client certificate, for auditing.
+type ClientIdentity struct {
+	Service string
+	Subject string
+}
+
+func NewContextWithClientIdentity(ctx context.Context, identity *ClientIdentity) context.Context {
+	return context.WithValue(ctx, clientIdentityContextKey{}, identity)
+}
+
+// ClientIdentityFromContext feedback the calling service identity, it returns nil when the internal listener is not serving mTLS.
+func ClientIdentityFromContext(ctx context.Context) *ClientIdentity {
+	identity, _ := ctx.Value(clientIdentityContextKey{}).(*ClientIdentity)
+	return identity
+}
+
+// IdentityHandler attach the calling service identity of verified client certificate to the request context,
+// and log the identity of every request for auditing.
+func IdentityHandler(allowlist *Allowlist, logger *zap.SugaredLogger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+			logger.Warnw("internal request without verified client certificate", "method", r.Method, "path", r.URL.Path, "remoteAddr", r.RemoteAddr)
+			next.ServeHTTP(w, r)
+			return
+		}
+		cert := r.TLS.VerifiedChains[0][0]
+		service, hit := allowlist.Match(cert)
+		if !hit {
+			logger.Warnw("internal request with client certificate not in the allowlist", "subject", cert.Subject.String(), "method", r.Method, "path", r.URL.Path, "remoteAddr", r.RemoteAddr)
+			next.ServeHTTP(w, r)
+			return
+		}
+		identity := &ClientIdentity{Service: service, Subject: cert.Subject.String()}
+		logger.Infow("internal request", "service", identity.Service, "subject", identity.Subject, "method", r.Method, "path", r.URL.Path, "remoteAddr", r.RemoteAddr)
+		next.ServeHTTP(w, r.WithContext(NewContextWithClientIdentity(r.Context(), identity)))
+	})
+}
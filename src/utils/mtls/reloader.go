@@ -0,0 +1,154 @@
+package mtls
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// CertificateReloader keeps the server certificate and the client CA bundle of the internal listener,
+// the files are reloaded when any of them has been modified, the broken files will be ignored and the certificates in use will be kept.
+type CertificateReloader struct {
+	logger         *zap.SugaredLogger
+	CertFile       string
+	KeyFile        string
+	ClientCAFile   string
+	ReloadInterval time.Duration
+	mutex          sync.RWMutex
+	certificate    *tls.Certificate
+	clientCAs      *x509.CertPool
+	lastModifiedAt map[string]time.Time
+}
+
+func NewCertificateReloader(certFile string, keyFile string, clientCAFile string, reloadInterval time.Duration, logger *zap.SugaredLogger) *CertificateReloader {
+	return &CertificateReloader{
+		logger:         logger,
+		CertFile:       certFile,
+		KeyFile:        keyFile,
+		ClientCAFile:   clientCAFile,
+		ReloadInterval: reloadInterval,
+		lastModifiedAt: map[string]time.Time{},
+	}
+}
+
+// Load load the certificate, key and CA bundle files and swap the ones in use.
+func (r *CertificateReloader) Load() error {
+	modifiedAt, errInStat := r.statFiles()
+	if errInStat != nil {
+		return errInStat
+	}
+	certificate, errInLoadKeyPair := tls.LoadX509KeyPair(r.CertFile, r.KeyFile)
+	if errInLoadKeyPair != nil {
+		return errInLoadKeyPair
+	}
+	caBundle, errInReadCA := os.ReadFile(r.ClientCAFile)
+	if errInReadCA != nil {
+		return errInReadCA
+	}
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(caBundle) {
+		return errors.New("no certificate found in client CA bundle")
+	}
+
+	// swap
+	r.mutex.Lock()
+	r.certificate = &certificate
+	r.clientCAs = clientCAs
+	r.lastModifiedAt = modifiedAt
+	r.mutex.Unlock()
+	r.logger.Infow("internal TLS certificates loaded", "cert", r.CertFile, "clientCA", r.ClientCAFile)
+	return nil
+}
+
+// Reload load the files only when any of them has been modified since last load.
+func (r *CertificateReloader) Reload() {
+	modifiedAt, err := r.statFiles()
+	if err != nil {
+		r.logger.Errorw("stat internal TLS certificate files failed", "err", err)
+		return
+	}
+	r.mutex.RLock()
+	modified := false
+	for path, modTime := range modifiedAt {
+		if !modTime.Equal(r.lastModifiedAt[path]) {
+			modified = true
+		}
+	}
+	r.mutex.RUnlock()
+	if !modified {
+		return
+	}
+	if err := r.Load(); err != nil {
+		// avoid logging the same broken files every tick
+		r.mutex.Lock()
+		r.lastModifiedAt = modifiedAt
+		r.mutex.Unlock()
+		r.logger.Errorw("reload internal TLS certificates failed, keep using the previous certificates", "err", err)
+	}
+}
+
+// Run reload certificate files periodically, it blocks until ctx is done.
+func (r *CertificateReloader) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.ReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Reload()
+		}
+	}
+}
+
+// TLSConfig build the server config which requires a client certificate verified by the CA bundle and matched by allowlist.
+// the certificates are resolved on every handshake, so the reloaded files apply to new connections.
+func (r *CertificateReloader) TLSConfig(allowlist *Allowlist) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: []string{"h2", "http/1.1"},
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			r.mutex.RLock()
+			defer r.mutex.RUnlock()
+			return r.certificate, nil
+		},
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mutex.RLock()
+			defer r.mutex.RUnlock()
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				NextProtos:   []string{"h2", "http/1.1"},
+				Certificates: []tls.Certificate{*r.certificate},
+				ClientCAs:    r.clientCAs,
+				ClientAuth:   tls.RequireAndVerifyClientCert,
+				VerifyConnection: func(state tls.ConnectionState) error {
+					if len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+						return errors.New("client certificate is not verified")
+					}
+					if _, hit := allowlist.Match(state.VerifiedChains[0][0]); !hit {
+						return errors.New("client certificate is not in the allowlist")
+					}
+					return nil
+				},
+			}, nil
+		},
+	}
+}
+
+func (r *CertificateReloader) statFiles() (map[string]time.Time, error) {
+	modifiedAt := make(map[string]time.Time, 3)
+	for _, path := range []string{r.CertFile, r.KeyFile, r.ClientCAFile} {
+		fileInfo, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		modifiedAt[path] = fileInfo.ModTime()
+	}
+	return modifiedAt, nil
+}
@@ -0,0 +1,268 @@
+package mtls
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type testCertificate struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+var testSerialNumber int64
+
+// newTestCertificate issue a certificate by parent, a self-signed CA when parent is nil.
+func newTestCertificate(t *testing.T, commonName string, parent *testCertificate) *testCertificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key failed: %v", err)
+	}
+	testSerialNumber++
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(testSerialNumber),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	signerCert, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	} else {
+		template.KeyUsage = x509.KeyUsageDigitalSignature
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+		template.IPAddresses = []net.IP{net.ParseIP("127.0.0.1")}
+		signerCert, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signerCert, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("create certificate failed: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate failed: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key failed: %v", err)
+	}
+	return &testCertificate{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func (c *testCertificate) keyPair(t *testing.T) tls.Certificate {
+	t.Helper()
+	keyPair, err := tls.X509KeyPair(c.certPEM, c.keyPEM)
+	if err != nil {
+		t.Fatalf("load key pair failed: %v", err)
+	}
+	return keyPair
+}
+
+// writeTestFile write the file with a modification time after the previous one, so Reload always notice the change.
+func writeTestFile(t *testing.T, path string, content []byte, modifiedAt time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		t.Fatalf("write %s failed: %v", path, err)
+	}
+	if err := os.Chtimes(path, modifiedAt, modifiedAt); err != nil {
+		t.Fatalf("touch %s failed: %v", path, err)
+	}
+}
+
+type testLogBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (b *testLogBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.Write(p)
+}
+
+func (b *testLogBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.String()
+}
+
+type testServer struct {
+	logs       *testLogBuffer
+	reloader   *CertificateReloader
+	server     *httptest.Server
+	certFile   string
+	keyFile    string
+	caFile     string
+	modifiedAt time.Time
+}
+
+func newTestServer(t *testing.T, serverCert *testCertificate, clientCA *testCertificate, allowlist *Allowlist) *testServer {
+	t.Helper()
+	dir := t.TempDir()
+	s := &testServer{
+		certFile:   filepath.Join(dir, "server.crt"),
+		keyFile:    filepath.Join(dir, "server.key"),
+		caFile:     filepath.Join(dir, "client-ca.crt"),
+		modifiedAt: time.Now().Add(-time.Minute),
+		logs:       &testLogBuffer{},
+	}
+	s.writeServerCertificate(t, serverCert)
+	s.writeClientCA(t, clientCA.certPEM)
+	s.reloader = NewCertificateReloader(s.certFile, s.keyFile, s.caFile, time.Second, zap.NewNop().Sugar())
+	if err := s.reloader.Load(); err != nil {
+		t.Fatalf("load certificates failed: %v", err)
+	}
+
+	// echo the calling service attached by IdentityHandler
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if identity := ClientIdentityFromContext(r.Context()); identity != nil {
+			io.WriteString(w, identity.Service)
+		}
+	})
+	logger := zap.New(zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(s.logs), zap.InfoLevel)).Sugar()
+	s.server = httptest.NewUnstartedServer(IdentityHandler(allowlist, logger, handler))
+	s.server.TLS = s.reloader.TLSConfig(allowlist)
+	s.server.StartTLS()
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+func (s *testServer) writeServerCertificate(t *testing.T, serverCert *testCertificate) {
+	s.modifiedAt = s.modifiedAt.Add(time.Second)
+	writeTestFile(t, s.certFile, serverCert.certPEM, s.modifiedAt)
+	writeTestFile(t, s.keyFile, serverCert.keyPEM, s.modifiedAt)
+}
+
+func (s *testServer) writeClientCA(t *testing.T, caBundle []byte) {
+	s.modifiedAt = s.modifiedAt.Add(time.Second)
+	writeTestFile(t, s.caFile, caBundle, s.modifiedAt)
+}
+
+// get request the server on a new connection, it feedback the calling service and the server certificate common name.
+func (s *testServer) get(t *testing.T, serverCA *testCertificate, clientCert *testCertificate) (string, string, error) {
+	t.Helper()
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(serverCA.cert)
+	tlsConfig := &tls.Config{RootCAs: rootCAs}
+	if clientCert != nil {
+		tlsConfig.Certificates = []tls.Certificate{clientCert.keyPair(t)}
+	}
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig, DisableKeepAlives: true}}
+	resp, err := client.Get(s.server.URL)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", "", err
+	}
+	return string(body), resp.TLS.PeerCertificates[0].Subject.CommonName, nil
+}
+
+func TestCertificateReloaderHandshake(t *testing.T) {
+	ca := newTestCertificate(t, "kozmo-test-ca", nil)
+	otherCA := newTestCertificate(t, "other-ca", nil)
+	allowlist, err := ParseAllowlist("builder=CN:kozmo-builder-backend")
+	if err != nil {
+		t.Fatalf("parse allowlist failed: %v", err)
+	}
+	s := newTestServer(t, newTestCertificate(t, "kozmo-supervisor-backend-internal", ca), ca, allowlist)
+
+	service, _, err := s.get(t, ca, newTestCertificate(t, "kozmo-builder-backend", ca))
+	if err != nil {
+		t.Fatalf("allowed client rejected: %v", err)
+	}
+	if service != "builder" {
+		t.Errorf("service = %q, want builder", service)
+	}
+	if logs := s.logs.String(); !strings.Contains(logs, `"service":"builder"`) || !strings.Contains(logs, `"subject":"CN=kozmo-builder-backend"`) {
+		t.Errorf("client identity is not logged: %s", logs)
+	}
+	if _, _, err := s.get(t, ca, newTestCertificate(t, "kozmo-drive-backend", ca)); err == nil {
+		t.Error("client not in the allowlist should be rejected")
+	}
+	if _, _, err := s.get(t, ca, newTestCertificate(t, "kozmo-builder-backend", otherCA)); err == nil {
+		t.Error("client issued by unknown CA should be rejected")
+	}
+	if _, _, err := s.get(t, ca, nil); err == nil {
+		t.Error("client without certificate should be rejected")
+	}
+}
+
+func TestCertificateReloaderHotReload(t *testing.T) {
+	ca := newTestCertificate(t, "kozmo-test-ca", nil)
+	rotatedCA := newTestCertificate(t, "kozmo-test-ca-rotated", nil)
+	allowlist, err := ParseAllowlist("builder=CN:kozmo-builder-backend")
+	if err != nil {
+		t.Fatalf("parse allowlist failed: %v", err)
+	}
+	s := newTestServer(t, newTestCertificate(t, "server-1", ca), ca, allowlist)
+	client := newTestCertificate(t, "kozmo-builder-backend", ca)
+	rotatedClient := newTestCertificate(t, "kozmo-builder-backend", rotatedCA)
+
+	if _, _, err := s.get(t, ca, rotatedClient); err == nil {
+		t.Fatal("client of the rotated CA should be rejected before reload")
+	}
+
+	// rotate the server certificate and trust both of the CAs
+	s.writeServerCertificate(t, newTestCertificate(t, "server-2", ca))
+	s.writeClientCA(t, append(append([]byte{}, ca.certPEM...), rotatedCA.certPEM...))
+	s.reloader.Reload()
+	for _, clientCert := range []*testCertificate{client, rotatedClient} {
+		_, serverName, err := s.get(t, ca, clientCert)
+		if err != nil {
+			t.Fatalf("client of %s rejected after reload: %v", clientCert.cert.Issuer.CommonName, err)
+		}
+		if serverName != "server-2" {
+			t.Errorf("server certificate = %s, want server-2", serverName)
+		}
+	}
+
+	// the broken CA bundle is ignored, the certificates in use are kept
+	s.writeClientCA(t, []byte("broken"))
+	s.reloader.Reload()
+	if _, _, err := s.get(t, ca, rotatedClient); err != nil {
+		t.Errorf("client rejected after broken reload: %v", err)
+	}
+
+	// drop the old CA
+	s.writeClientCA(t, rotatedCA.certPEM)
+	s.reloader.Reload()
+	if _, _, err := s.get(t, ca, client); err == nil {
+		t.Error("client of the dropped CA should be rejected")
+	}
+	if _, _, err := s.get(t, ca, rotatedClient); err != nil {
+		t.Errorf("client of the rotated CA rejected: %v", err)
+	}
+}
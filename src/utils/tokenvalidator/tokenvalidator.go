@@ -1,22 +1,55 @@
 package tokenvalidator
 
 import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/base64"
-	"fmt"
+	"errors"
 	"sort"
-
-	"crypto/md5"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/config"
 )
 
+var (
+	errRequestTokenMismatch       = errors.New("request token mismatch.")
+	errLegacyRequestTokenDisabled = errors.New("legacy request token is disabled, please sign request with timestamp and nonce.")
+	errInvalidRequestTimestamp    = errors.New("invalid request timestamp.")
+	errRequestTimestampOutOfRange = errors.New("request timestamp is out of the skew window.")
+	errMissingRequestNonce        = errors.New("missing request nonce.")
+	errRequestNonceReplayed       = errors.New("request nonce has been used.")
+)
+
+// NonceStore records the nonce of signed requests, a recorded nonce is rejected until it expires.
+type NonceStore interface {
+	// MarkNonce record the nonce for ttl, it returns false when the nonce is already recorded.
+	MarkNonce(nonce string, ttl time.Duration) (bool, error)
+}
+
 type RequestTokenValidator struct {
-	Config *config.Config
+	Config     *config.Config
+	nonceStore NonceStore
+}
+
+// SignedRequest carry the inputs of a request token, timestamp is in unix seconds.
+// a request without timestamp is validated by the legacy md5 scheme.
+type SignedRequest struct {
+	Method    string
+	Path      string
+	Params    []string
+	Timestamp string
+	Nonce     string
+	Token     string
 }
 
-func NewRequestTokenValidator() *RequestTokenValidator {
+func NewRequestTokenValidator(nonceStore NonceStore) *RequestTokenValidator {
 	return &RequestTokenValidator{
-		Config: config.GetInstance(),
+		Config:     config.GetInstance(),
+		nonceStore: nonceStore,
 	}
 }
 
@@ -24,6 +57,7 @@ func (r *RequestTokenValidator) GenerateValidateToken(input ...string) string {
 	return r.GenerateValidateTokenBySliceParam(input)
 }
 
+// GenerateValidateTokenBySliceParam generate the legacy md5 token, it carries no timestamp or nonce and is kept for the migration only.
 func (r *RequestTokenValidator) GenerateValidateTokenBySliceParam(input []string) string {
 	var concatr string
 	sort.Strings(input)
@@ -31,9 +65,67 @@ func (r *RequestTokenValidator) GenerateValidateTokenBySliceParam(input []string
 		concatr += str
 	}
 	concatr += r.Config.GetSecretKey()
-	fmt.Printf("[DUMP] GenerateValidateTokenBySliceParam r.Config.GetSecretKey(): %+v\n", r.Config.GetSecretKey())
 	hash := md5.Sum([]byte(concatr))
 	var hashConverted []byte = hash[:]
 
 	return base64.StdEncoding.EncodeToString(hashConverted)
 }
+
+// GenerateSignedToken generate the HMAC-SHA256 token of request.
+// the signed content is method, path, sorted params, timestamp and nonce, joined by line feed.
+func (r *RequestTokenValidator) GenerateSignedToken(method string, path string, params []string, timestamp string, nonce string) string {
+	sortedParams := make([]string, len(params))
+	copy(sortedParams, params)
+	sort.Strings(sortedParams)
+	content := strings.Join([]string{strings.ToUpper(method), path, strings.Join(sortedParams, "\n"), timestamp, nonce}, "\n")
+	mac := hmac.New(sha256.New, []byte(r.Config.GetSecretKey()))
+	mac.Write([]byte(content))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (r *RequestTokenValidator) ValidateSignedRequest(req *SignedRequest) error {
+	// legacy scheme
+	if req.Timestamp == "" {
+		if !r.Config.IsRequestTokenLegacyAllowed() {
+			return errLegacyRequestTokenDisabled
+		}
+		params := make([]string, len(req.Params))
+		copy(params, req.Params)
+		if subtle.ConstantTimeCompare([]byte(req.Token), []byte(r.GenerateValidateTokenBySliceParam(params))) != 1 {
+			return errRequestTokenMismatch
+		}
+		return nil
+	}
+
+	// check timestamp
+	timestamp, errInParseTimestamp := strconv.ParseInt(req.Timestamp, 10, 64)
+	if errInParseTimestamp != nil {
+		return errInvalidRequestTimestamp
+	}
+	skew := time.Since(time.Unix(timestamp, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > r.Config.GetRequestTokenSkewWindow() {
+		return errRequestTimestampOutOfRange
+	}
+	if req.Nonce == "" {
+		return errMissingRequestNonce
+	}
+
+	// check signature
+	tokenShouldBe := r.GenerateSignedToken(req.Method, req.Path, req.Params, req.Timestamp, req.Nonce)
+	if !hmac.Equal([]byte(req.Token), []byte(tokenShouldBe)) {
+		return errRequestTokenMismatch
+	}
+
+	// check replay, the nonce is kept as long as its timestamp can be accepted
+	fresh, errInMarkNonce := r.nonceStore.MarkNonce(req.Nonce, 2*r.Config.GetRequestTokenSkewWindow())
+	if errInMarkNonce != nil {
+		return errInMarkNonce
+	}
+	if !fresh {
+		return errRequestNonceReplayed
+	}
+	return nil
+}
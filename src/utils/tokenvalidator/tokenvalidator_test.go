@@ -0,0 +1,135 @@
+package tokenvalidator
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/config"
+)
+
+type memoryNonceStore struct {
+	nonces map[string]time.Duration
+}
+
+func (s *memoryNonceStore) MarkNonce(nonce string, ttl time.Duration) (bool, error) {
+	if _, hit := s.nonces[nonce]; hit {
+		return false, nil
+	}
+	s.nonces[nonce] = ttl
+	return true, nil
+}
+
+func newTestValidator(allowLegacy string) (*RequestTokenValidator, *memoryNonceStore) {
+	nonceStore := &memoryNonceStore{nonces: make(map[string]time.Duration)}
+	cfg := &config.Config{
+		SecretKey:               "test-secret-key",
+		RequestTokenSkewWindow:  5 * time.Minute,
+		RequestTokenAllowLegacy: allowLegacy,
+	}
+	return &RequestTokenValidator{Config: cfg, nonceStore: nonceStore}, nonceStore
+}
+
+// newTestSignedRequest sign the request issued at the given time.
+func newTestSignedRequest(r *RequestTokenValidator, issuedAt time.Time, nonce string) *SignedRequest {
+	req := &SignedRequest{
+		Method:    "GET",
+		Path:      "/api/v1/access/team/0/unitType/1/unitID/0/attribute/1",
+		Params:    []string{"0", "1", "0", "1"},
+		Timestamp: strconv.FormatInt(issuedAt.Unix(), 10),
+		Nonce:     nonce,
+	}
+	req.Token = r.GenerateSignedToken(req.Method, req.Path, req.Params, req.Timestamp, req.Nonce)
+	return req
+}
+
+func TestGenerateSignedToken(t *testing.T) {
+	r, _ := newTestValidator("false")
+	token := r.GenerateSignedToken("get", "/path", []string{"b", "a"}, "1700000000", "nonce")
+	if token != r.GenerateSignedToken("GET", "/path", []string{"a", "b"}, "1700000000", "nonce") {
+		t.Error("token should not depend on the method case and the params order")
+	}
+	params := []string{"b", "a"}
+	r.GenerateSignedToken("GET", "/path", params, "1700000000", "nonce")
+	if params[0] != "b" {
+		t.Error("the params of caller are sorted in place")
+	}
+	for name, other := range map[string]string{
+		"method":    r.GenerateSignedToken("POST", "/path", []string{"a", "b"}, "1700000000", "nonce"),
+		"path":      r.GenerateSignedToken("GET", "/other", []string{"a", "b"}, "1700000000", "nonce"),
+		"params":    r.GenerateSignedToken("GET", "/path", []string{"a", "c"}, "1700000000", "nonce"),
+		"timestamp": r.GenerateSignedToken("GET", "/path", []string{"a", "b"}, "1700000001", "nonce"),
+		"nonce":     r.GenerateSignedToken("GET", "/path", []string{"a", "b"}, "1700000000", "other"),
+	} {
+		if other == token {
+			t.Errorf("token does not sign the %s", name)
+		}
+	}
+	otherKey, _ := newTestValidator("false")
+	otherKey.Config.SecretKey = "other-secret-key"
+	if otherKey.GenerateSignedToken("GET", "/path", []string{"a", "b"}, "1700000000", "nonce") == token {
+		t.Error("token does not depend on the secret key")
+	}
+}
+
+func TestValidateSignedRequest(t *testing.T) {
+	r, nonceStore := newTestValidator("false")
+	now := time.Now()
+
+	if err := r.ValidateSignedRequest(newTestSignedRequest(r, now, "nonce-1")); err != nil {
+		t.Fatalf("valid request rejected: %v", err)
+	}
+	if ttl := nonceStore.nonces["nonce-1"]; ttl != 10*time.Minute {
+		t.Errorf("nonce ttl = %s, want twice of the skew window", ttl)
+	}
+
+	tampered := newTestSignedRequest(r, now, "nonce-2")
+	tampered.Params = []string{"0", "1", "0", "2"}
+	missingNonce := newTestSignedRequest(r, now, "")
+	invalidTimestamp := newTestSignedRequest(r, now, "nonce-3")
+	invalidTimestamp.Timestamp = "yesterday"
+	cases := []struct {
+		name    string
+		req     *SignedRequest
+		wantErr error
+	}{
+		{"tampered params", tampered, errRequestTokenMismatch},
+		{"missing nonce", missingNonce, errMissingRequestNonce},
+		{"invalid timestamp", invalidTimestamp, errInvalidRequestTimestamp},
+		{"too old", newTestSignedRequest(r, now.Add(-6*time.Minute), "nonce-4"), errRequestTimestampOutOfRange},
+		{"too new", newTestSignedRequest(r, now.Add(6*time.Minute), "nonce-5"), errRequestTimestampOutOfRange},
+		{"skew in window", newTestSignedRequest(r, now.Add(-4*time.Minute), "nonce-6"), nil},
+		{"clock ahead in window", newTestSignedRequest(r, now.Add(4*time.Minute), "nonce-7"), nil},
+		{"nonce reused", newTestSignedRequest(r, now, "nonce-1"), errRequestNonceReplayed},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := r.ValidateSignedRequest(c.req); !errors.Is(err, c.wantErr) {
+				t.Errorf("err = %v, want %v", err, c.wantErr)
+			}
+		})
+	}
+	// the rejected requests do not burn the nonce
+	if _, hit := nonceStore.nonces["nonce-2"]; hit {
+		t.Error("nonce of the tampered request is recorded")
+	}
+}
+
+func TestValidateSignedRequestLegacy(t *testing.T) {
+	params := []string{"0", "1"}
+	r, _ := newTestValidator("false")
+	legacy := &SignedRequest{Params: params, Token: r.GenerateValidateTokenBySliceParam([]string{"0", "1"})}
+	if err := r.ValidateSignedRequest(legacy); !errors.Is(err, errLegacyRequestTokenDisabled) {
+		t.Errorf("legacy token accepted while disabled: %v", err)
+	}
+
+	r, _ = newTestValidator("true")
+	if err := r.ValidateSignedRequest(legacy); err != nil {
+		t.Errorf("legacy token rejected while allowed: %v", err)
+	}
+	legacy.Token = r.GenerateValidateTokenBySliceParam([]string{"0", "2"})
+	if err := r.ValidateSignedRequest(legacy); !errors.Is(err, errRequestTokenMismatch) {
+		t.Errorf("err = %v, want %v", err, errRequestTokenMismatch)
+	}
+}
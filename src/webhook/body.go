@@ -0,0 +1,170 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/notification"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/idconvertor"
+)
+
+// JSONBody is the body of json format delivery.
+type JSONBody struct {
+	UID       uuid.UUID       `json:"uid"`
+	Type      string          `json:"type"`
+	TeamID    string          `json:"teamID"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// NoticeBody is the body of json format notice delivery, the receiver forwards the notice to the recipients.
+type NoticeBody struct {
+	UID        uuid.UUID              `json:"uid"`
+	Type       string                 `json:"type"`
+	TeamID     string                 `json:"teamID"`
+	Recipients []*NoticeRecipient     `json:"recipients"`
+	Payload    map[string]interface{} `json:"payload"`
+	CreatedAt  time.Time              `json:"createdAt"`
+}
+
+type NoticeRecipient struct {
+	UserID   string `json:"userID"`
+	Nickname string `json:"nickname"`
+	Email    string `json:"email"`
+}
+
+// TextBody is the body of text format delivery, which slack and microsoft teams incoming webhooks accept.
+type TextBody struct {
+	Text string `json:"text"`
+}
+
+// teamEventPayload is the common part of subscribable event payloads.
+type teamEventPayload struct {
+	TeamID         string `json:"teamID"`
+	Name           string `json:"name"`
+	UserID         string `json:"userID"`
+	UserRole       int    `json:"userRole"`
+	Status         int    `json:"status"`
+	UpdatedBy      string `json:"updatedBy"`
+	OperatorUserID string `json:"operatorUserID"`
+}
+
+func exportTeamEventPayload(event *model.Event) (*teamEventPayload, error) {
+	payload := &teamEventPayload{}
+	if err := json.Unmarshal([]byte(event.Payload), payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// renderBody render the delivery body of event in webhook format.
+func renderBody(webhook *model.Webhook, event *model.Event, payload *teamEventPayload) (string, error) {
+	var body interface{}
+	if webhook.IsTextFormat() {
+		body = &TextBody{Text: summarize(event, payload)}
+	} else {
+		body = &JSONBody{
+			UID:       event.UID,
+			Type:      event.Type,
+			TeamID:    payload.TeamID,
+			Payload:   json.RawMessage(event.Payload),
+			CreatedAt: event.CreatedAt,
+		}
+	}
+	bodyInJSON, errInMarshal := json.Marshal(body)
+	if errInMarshal != nil {
+		return "", errInMarshal
+	}
+	return string(bodyInJSON), nil
+}
+
+// RenderPingBody render the test ping body, it is not a domain event so it has no payload.
+func RenderPingBody(webhook *model.Webhook, eventUID uuid.UUID) (string, error) {
+	var body interface{}
+	if webhook.IsTextFormat() {
+		body = &TextBody{Text: "Kozmo webhook \"" + webhook.Name + "\" is working."}
+	} else {
+		body = &JSONBody{
+			UID:       eventUID,
+			Type:      model.WEBHOOK_EVENT_TYPE_PING,
+			TeamID:    webhook.Export().TeamID,
+			Payload:   json.RawMessage("{}"),
+			CreatedAt: time.Now().UTC(),
+		}
+	}
+	bodyInJSON, errInMarshal := json.Marshal(body)
+	if errInMarshal != nil {
+		return "", errInMarshal
+	}
+	return string(bodyInJSON), nil
+}
+
+// renderNoticeBody render the delivery body of notice in webhook format.
+func renderNoticeBody(webhook *model.Webhook, eventUID uuid.UUID, notice *notification.Notice, recipients []*model.User) (string, error) {
+	var body interface{}
+	if webhook.IsTextFormat() {
+		emails := make([]string, 0, len(recipients))
+		for _, recipient := range recipients {
+			emails = append(emails, recipient.Email)
+		}
+		body = &TextBody{Text: summarizeNotice(notice) + " Recipients: " + strings.Join(emails, ", ") + "."}
+	} else {
+		noticeRecipients := make([]*NoticeRecipient, 0, len(recipients))
+		for _, recipient := range recipients {
+			noticeRecipients = append(noticeRecipients, &NoticeRecipient{
+				UserID:   idconvertor.ConvertIntToString(recipient.ID),
+				Nickname: recipient.Nickname,
+				Email:    recipient.Email,
+			})
+		}
+		body = &NoticeBody{
+			UID:        eventUID,
+			Type:       notice.Category,
+			TeamID:     idconvertor.ConvertIntToString(notice.TeamID),
+			Recipients: noticeRecipients,
+			Payload:    notice.Payload,
+			CreatedAt:  time.Now().UTC(),
+		}
+	}
+	bodyInJSON, errInMarshal := json.Marshal(body)
+	if errInMarshal != nil {
+		return "", errInMarshal
+	}
+	return string(bodyInJSON), nil
+}
+
+func summarizeNotice(notice *notification.Notice) string {
+	switch notice.Category {
+	case notification.NOTICE_CATEGORY_ROLE_GRANT_EXPIRING:
+		return fmt.Sprintf("Time-bound role %v of user %v expires at %v.", notice.Payload["userRole"], notice.Payload["userID"], notice.Payload["validUntil"])
+	case notification.NOTICE_CATEGORY_ROLE_GRANT_EXPIRED:
+		return fmt.Sprintf("Time-bound role %v of user %v expired, role %v is restored.", notice.Payload["userRole"], notice.Payload["userID"], notice.Payload["baseUserRole"])
+	case notification.NOTICE_CATEGORY_ACCESS_REQUEST_CREATED:
+		return fmt.Sprintf("Access request %v is waiting for review.", notice.Payload["accessRequestID"])
+	case notification.NOTICE_CATEGORY_ACCESS_REQUEST_APPROVED:
+		return fmt.Sprintf("Access request %v was approved.", notice.Payload["accessRequestID"])
+	case notification.NOTICE_CATEGORY_ACCESS_REQUEST_DENIED:
+		return fmt.Sprintf("Access request %v was denied.", notice.Payload["accessRequestID"])
+	}
+	return "Notice " + notice.Category + "."
+}
+
+func summarize(event *model.Event, payload *teamEventPayload) string {
+	switch event.Type {
+	case model.EVENT_TYPE_TEAM_UPDATED:
+		return fmt.Sprintf("Team %s was updated by user %s.", payload.Name, payload.UpdatedBy)
+	case model.EVENT_TYPE_TEAM_MEMBER_JOINED:
+		return fmt.Sprintf("User %s joined the team with role %d.", payload.UserID, payload.UserRole)
+	case model.EVENT_TYPE_TEAM_MEMBER_ROLE_CHANGED:
+		return fmt.Sprintf("Role of user %s was changed to %d by user %s.", payload.UserID, payload.UserRole, payload.OperatorUserID)
+	case model.EVENT_TYPE_TEAM_MEMBER_STATUS_CHANGED:
+		return fmt.Sprintf("Status of user %s was changed to %d by user %s.", payload.UserID, payload.Status, payload.OperatorUserID)
+	case model.EVENT_TYPE_TEAM_MEMBER_REMOVED:
+		return fmt.Sprintf("User %s was removed from the team by user %s.", payload.UserID, payload.OperatorUserID)
+	}
+	return "Event " + event.Type + " occurred."
+}
@@ -0,0 +1,206 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/config"
+	"go.uber.org/zap"
+)
+
+const DEFAULT_DELIVERY_TIMEOUT = 10 * time.Second
+
+// a claimed delivery is not attempted by other workers until the lease expired.
+const DELIVERY_LEASE = time.Minute
+
+const DELIVERY_BATCH_SIZE = 100
+
+// the n-th retry of a failed delivery is scheduled after RetryBackoff * 2^(n-1), at most MAX_RETRY_BACKOFF.
+const MAX_RETRY_BACKOFF = time.Hour
+
+// Deliverer post the pending webhook deliveries and retry the failed ones with exponential backoff.
+// a delivery is failed after MaxAttempts attempts.
+type Deliverer struct {
+	logger           *zap.SugaredLogger
+	Storage          *model.Storage
+	DeliveryInterval time.Duration
+	RetryBackoff     time.Duration
+	MaxAttempts      int
+	client           *http.Client
+}
+
+func NewDeliverer(storage *model.Storage, deliveryInterval time.Duration, retryBackoff time.Duration, maxAttempts int, logger *zap.SugaredLogger) *Deliverer {
+	return &Deliverer{
+		logger:           logger,
+		Storage:          storage,
+		DeliveryInterval: deliveryInterval,
+		RetryBackoff:     retryBackoff,
+		MaxAttempts:      maxAttempts,
+		client:           newDeliveryClient(),
+	}
+}
+
+func NewDelivererByGlobalConfig(config *config.Config, storage *model.Storage, logger *zap.SugaredLogger) *Deliverer {
+	return NewDeliverer(storage, config.GetWebhookDeliveryInterval(), config.GetWebhookRetryBackoff(), config.GetWebhookMaxAttempts(), logger)
+}
+
+// DeliverNow create the delivery claimed and attempt it at once, for ping and redelivery which feedback the result to the user.
+// a failed redelivery is retried later as usual.
+func (d *Deliverer) DeliverNow(webhook *model.Webhook, delivery *model.WebhookDelivery) error {
+	delivery.NextAttemptAt = time.Now().UTC().Add(DELIVERY_LEASE)
+	if _, err := d.Storage.WebhookDeliveryStorage.Create(delivery); err != nil {
+		return err
+	}
+	return d.attempt(webhook, delivery)
+}
+
+// Ping post a test ping to webhook at once and feedback the delivery.
+func (d *Deliverer) Ping(webhook *model.Webhook) (*model.WebhookDelivery, error) {
+	eventUID := uuid.New()
+	body, errInRender := RenderPingBody(webhook, eventUID)
+	if errInRender != nil {
+		return nil, errInRender
+	}
+	delivery := model.NewWebhookDelivery(webhook, eventUID, model.WEBHOOK_EVENT_TYPE_PING, body)
+	if err := d.DeliverNow(webhook, delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// DeliverDue attempt the due deliveries and feedback the count of attempted deliveries.
+func (d *Deliverer) DeliverDue() (int, error) {
+	now := time.Now().UTC()
+	deliveries, errInRetrieve := d.Storage.WebhookDeliveryStorage.RetrieveDueBefore(now, DELIVERY_BATCH_SIZE)
+	if errInRetrieve != nil {
+		return 0, errInRetrieve
+	}
+	webhooks := make(map[int]*model.Webhook)
+	attempted := 0
+	for _, delivery := range deliveries {
+		claimed, errInClaim := d.Storage.WebhookDeliveryStorage.Claim(delivery, now.Add(DELIVERY_LEASE))
+		if errInClaim != nil {
+			return attempted, errInClaim
+		}
+		if !claimed {
+			continue
+		}
+		webhook, hit := webhooks[delivery.WebhookID]
+		if !hit {
+			var errInRetrieveWebhook error
+			webhook, errInRetrieveWebhook = d.Storage.WebhookStorage.RetrieveByID(delivery.WebhookID)
+			// the claimed delivery is attempted again after the lease, the other deliveries go on
+			if errInRetrieveWebhook != nil {
+				d.logger.Errorw("retrieve webhook of delivery failed", "webhookID", delivery.WebhookID, "delivery", delivery.UID, "err", errInRetrieveWebhook)
+				continue
+			}
+			webhooks[delivery.WebhookID] = webhook
+		}
+		// the deliveries of disabled webhook are given up, they can be redelivered after enabled.
+		if !webhook.IsEnabled() {
+			delivery.RecordAttempt(0, "", errors.New("webhook is disabled"))
+			delivery.MarkFailed()
+			if err := d.Storage.WebhookDeliveryStorage.UpdateByID(delivery); err != nil {
+				return attempted, err
+			}
+			continue
+		}
+		if err := d.attempt(webhook, delivery); err != nil {
+			return attempted, err
+		}
+		attempted++
+	}
+	return attempted, nil
+}
+
+// attempt post the delivery once and record the response, it only feedback the error of storage.
+func (d *Deliverer) attempt(webhook *model.Webhook, delivery *model.WebhookDelivery) error {
+	responseCode, responseBody, errInPost := d.post(webhook, delivery)
+	delivery.RecordAttempt(responseCode, responseBody, errInPost)
+	switch {
+	case errInPost == nil:
+		delivery.MarkSucceeded()
+	case delivery.IsPing() || delivery.Attempts >= d.MaxAttempts:
+		delivery.MarkFailed()
+	default:
+		delivery.ScheduleRetry(delivery.LastAttemptAt.Add(d.exportRetryBackoff(delivery.Attempts)))
+	}
+	if errInPost != nil {
+		d.logger.Warnw("deliver webhook failed", "webhookID", webhook.ID, "delivery", delivery.UID, "eventType", delivery.EventType, "attempts", delivery.Attempts, "err", errInPost)
+	}
+	return d.Storage.WebhookDeliveryStorage.UpdateByID(delivery)
+}
+
+func (d *Deliverer) exportRetryBackoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	backoff := d.RetryBackoff
+	for i := 1; i < attempts && backoff < MAX_RETRY_BACKOFF; i++ {
+		backoff *= 2
+	}
+	if backoff > MAX_RETRY_BACKOFF {
+		return MAX_RETRY_BACKOFF
+	}
+	return backoff
+}
+
+// post the delivery body signed by webhook secret, any non 2xx response is a failure.
+func (d *Deliverer) post(webhook *model.Webhook, delivery *model.WebhookDelivery) (int, string, error) {
+	body := []byte(delivery.Body)
+	req, errInNewRequest := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if errInNewRequest != nil {
+		return 0, "", errInNewRequest
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HEADER_SIGNATURE, Sign(webhook.Secret, body, time.Now()))
+	req.Header.Set(HEADER_DELIVERY, delivery.UID.String())
+	req.Header.Set(HEADER_EVENT_UID, delivery.EventUID.String())
+	req.Header.Set(HEADER_EVENT_TYPE, delivery.EventType)
+	resp, errInPost := d.client.Do(req)
+	if errInPost != nil {
+		return 0, "", errInPost
+	}
+	defer resp.Body.Close()
+	responseBody, _ := io.ReadAll(io.LimitReader(resp.Body, model.WEBHOOK_DELIVERY_RESPONSE_BODY_MAX_SIZE))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, string(responseBody), fmt.Errorf("webhook endpoint responded status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, string(responseBody), nil
+}
+
+// Deliver attempt all due deliveries, it stops when a batch is not full.
+func (d *Deliverer) Deliver() {
+	for {
+		attempted, err := d.DeliverDue()
+		if err != nil {
+			d.logger.Errorw("deliver webhooks failed", "err", err)
+			return
+		}
+		if attempted < DELIVERY_BATCH_SIZE {
+			return
+		}
+	}
+}
+
+// Run deliver webhooks periodically, it blocks until ctx is done.
+func (d *Deliverer) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.DeliveryInterval)
+	defer ticker.Stop()
+	d.Deliver()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.Deliver()
+		}
+	}
+}
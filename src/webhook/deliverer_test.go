@@ -0,0 +1,124 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/internal/testdb"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"go.uber.org/zap"
+)
+
+func TestDelivererExportRetryBackoff(t *testing.T) {
+	deliverer := &Deliverer{RetryBackoff: 30 * time.Second}
+	cases := map[int]time.Duration{
+		0:    30 * time.Second,
+		1:    30 * time.Second,
+		2:    time.Minute,
+		4:    4 * time.Minute,
+		8:    MAX_RETRY_BACKOFF,
+		1000: MAX_RETRY_BACKOFF,
+	}
+	for attempts, want := range cases {
+		if got := deliverer.exportRetryBackoff(attempts); got != want {
+			t.Errorf("exportRetryBackoff(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
+
+// newTestDeliverer post to the test server, the delivery client refuses the loopback address.
+func newTestDeliverer(t *testing.T, handler http.HandlerFunc) (*Deliverer, *httptest.Server) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	deliverer := NewDeliverer(testdb.NewStorage(t), time.Second, time.Minute, 3, zap.NewNop().Sugar())
+	deliverer.client = server.Client()
+	return deliverer, server
+}
+
+func enqueueTestDelivery(t *testing.T, storage *model.Storage, webhook *model.Webhook) *model.WebhookDelivery {
+	t.Helper()
+	delivery := model.NewWebhookDelivery(webhook, uuid.New(), model.EVENT_TYPE_TEAM_UPDATED, "{}")
+	id, err := storage.WebhookDeliveryStorage.Create(delivery)
+	if err != nil {
+		t.Fatalf("create delivery failed: %v", err)
+	}
+	delivery.ID = id
+	return delivery
+}
+
+func retrieveTestDelivery(t *testing.T, storage *model.Storage, delivery *model.WebhookDelivery) *model.WebhookDelivery {
+	t.Helper()
+	retrieved, err := storage.WebhookDeliveryStorage.RetrieveByTeamIDAndWebhookIDAndID(delivery.TeamID, delivery.WebhookID, delivery.ID)
+	if err != nil {
+		t.Fatalf("retrieve delivery failed: %v", err)
+	}
+	return retrieved
+}
+
+func TestDelivererRetryWithBackoff(t *testing.T) {
+	deliverer, server := newTestDeliverer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	webhook := createTestWebhook(t, deliverer.Storage, "broken", model.WEBHOOK_FORMAT_JSON, model.WEBHOOK_STATUS_ENABLED, model.EVENT_TYPE_TEAM_UPDATED)
+	webhook.URL = server.URL
+	if err := deliverer.Storage.WebhookStorage.UpdateByID(webhook); err != nil {
+		t.Fatalf("update webhook failed: %v", err)
+	}
+	delivery := enqueueTestDelivery(t, deliverer.Storage, webhook)
+
+	for attempts := 1; attempts <= deliverer.MaxAttempts; attempts++ {
+		if attempted, err := deliverer.DeliverDue(); err != nil || attempted != 1 {
+			t.Fatalf("attempt %d: attempted = %d, err = %v", attempts, attempted, err)
+		}
+		retrieved := retrieveTestDelivery(t, deliverer.Storage, delivery)
+		if retrieved.Attempts != attempts || retrieved.ResponseCode != http.StatusServiceUnavailable || retrieved.LastError != "webhook endpoint responded status 503" {
+			t.Fatalf("attempt %d: delivery = %+v", attempts, retrieved)
+		}
+		if attempts == deliverer.MaxAttempts {
+			if retrieved.Status != model.WEBHOOK_DELIVERY_STATUS_FAILED {
+				t.Errorf("status = %d after the last attempt, want failed", retrieved.Status)
+			}
+			break
+		}
+		if backoff := retrieved.NextAttemptAt.Sub(retrieved.LastAttemptAt); backoff != deliverer.exportRetryBackoff(attempts) {
+			t.Errorf("attempt %d: backoff = %s, want %s", attempts, backoff, deliverer.exportRetryBackoff(attempts))
+		}
+		// make the retry due
+		retrieved.NextAttemptAt = time.Now().UTC().Add(-time.Second)
+		if err := deliverer.Storage.WebhookDeliveryStorage.UpdateByID(retrieved); err != nil {
+			t.Fatalf("update delivery failed: %v", err)
+		}
+	}
+}
+
+func TestDelivererDeliverDueSkipsMissingWebhook(t *testing.T) {
+	deliverer, server := newTestDeliverer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	webhook := createTestWebhook(t, deliverer.Storage, "ok", model.WEBHOOK_FORMAT_JSON, model.WEBHOOK_STATUS_ENABLED, model.EVENT_TYPE_TEAM_UPDATED)
+	webhook.URL = server.URL
+	if err := deliverer.Storage.WebhookStorage.UpdateByID(webhook); err != nil {
+		t.Fatalf("update webhook failed: %v", err)
+	}
+	orphan := enqueueTestDelivery(t, deliverer.Storage, &model.Webhook{ID: webhook.ID + 100, TeamID: testTeamID})
+	delivery := enqueueTestDelivery(t, deliverer.Storage, webhook)
+
+	attempted, err := deliverer.DeliverDue()
+	if err != nil {
+		t.Fatalf("deliver due failed: %v", err)
+	}
+	if attempted != 1 {
+		t.Errorf("attempted = %d, want 1", attempted)
+	}
+	if retrieved := retrieveTestDelivery(t, deliverer.Storage, delivery); retrieved.Status != model.WEBHOOK_DELIVERY_STATUS_SUCCEEDED {
+		t.Errorf("status = %d, want succeeded", retrieved.Status)
+	}
+	// the orphan keeps pending and is attempted after the lease
+	if retrieved := retrieveTestDelivery(t, deliverer.Storage, orphan); retrieved.Status != model.WEBHOOK_DELIVERY_STATUS_PENDING || retrieved.Attempts != 0 || !retrieved.NextAttemptAt.After(time.Now()) {
+		t.Errorf("orphan delivery = %+v", retrieved)
+	}
+}
@@ -0,0 +1,81 @@
+package webhook
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+var ErrForbiddenAddress = errors.New("webhook endpoint resolves to a forbidden address")
+
+// forbiddenNetworks are the ranges not covered by the net.IP predicates but still not public.
+var forbiddenNetworks = mustParseCIDRs(
+	"0.0.0.0/8",     // this network
+	"100.64.0.0/10", // carrier-grade NAT
+	"192.0.0.0/24",  // IETF protocol assignments
+	"198.18.0.0/15", // benchmarking
+	"64:ff9b::/96",  // NAT64, maps to IPv4 addresses
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// IsForbiddenIP reports whether the webhook deliveries must not reach ip, e.g. the loopback, private and link-local addresses.
+func IsForbiddenIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return true
+	}
+	for _, network := range forbiddenNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// controlDial reject the connection after the endpoint host resolved, so a public name pointing to an internal address is refused too.
+func controlDial(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || IsForbiddenIP(ip) {
+		return ErrForbiddenAddress
+	}
+	return nil
+}
+
+// newDeliveryClient returns the client which only reach public addresses and never follow redirects,
+// a redirect response is recorded as the failed attempt.
+func newDeliveryClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: DEFAULT_DELIVERY_TIMEOUT,
+		Control: controlDial,
+	}
+	return &http.Client{
+		Timeout: DEFAULT_DELIVERY_TIMEOUT,
+		Transport: &http.Transport{
+			Proxy:               nil, // the proxy would dial the endpoint on our behalf and bypass the address check
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: DEFAULT_DELIVERY_TIMEOUT,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
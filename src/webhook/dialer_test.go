@@ -0,0 +1,71 @@
+package webhook
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestIsForbiddenIP(t *testing.T) {
+	cases := []struct {
+		ip        string
+		forbidden bool
+	}{
+		{"127.0.0.1", true},
+		{"::1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true},
+		{"fe80::1", true},
+		{"fd00::1", true},
+		{"0.0.0.0", true},
+		{"::", true},
+		{"100.64.0.1", true},
+		{"::ffff:127.0.0.1", true},
+		{"8.8.8.8", false},
+		{"2606:4700:4700::1111", false},
+	}
+	for _, c := range cases {
+		if got := IsForbiddenIP(net.ParseIP(c.ip)); got != c.forbidden {
+			t.Errorf("IsForbiddenIP(%s) = %v, want %v", c.ip, got, c.forbidden)
+		}
+	}
+}
+
+func TestDeliveryClientRefusesLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// a name resolving to loopback is refused as the address literal
+	url := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	for _, target := range []string{server.URL, url} {
+		_, err := newDeliveryClient().Post(target, "application/json", strings.NewReader("{}"))
+		if !errors.Is(err, ErrForbiddenAddress) {
+			t.Errorf("post %s err = %v, want %v", target, err, ErrForbiddenAddress)
+		}
+	}
+}
+
+func TestDeliveryClientDoesNotFollowRedirect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data/", http.StatusFound)
+	}))
+	defer server.Close()
+
+	client := newDeliveryClient()
+	client.Transport = http.DefaultTransport // reach the loopback test server, the redirect policy is under test
+	resp, err := client.Post(server.URL, "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("post failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusFound)
+	}
+}
@@ -0,0 +1,60 @@
+package webhook
+
+import (
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/utils/idconvertor"
+	"go.uber.org/zap"
+)
+
+// SINK_NAME is the name of Dispatcher in outbox relay.
+const SINK_NAME = "webhook"
+
+// Dispatcher is an outbox sink, it enqueues a delivery of event for each enabled webhook of the team subscribed it.
+// the deliveries are posted by Deliverer, so a slow or broken endpoint never blocks the outbox relay.
+type Dispatcher struct {
+	logger  *zap.SugaredLogger
+	Storage *model.Storage
+}
+
+func NewDispatcher(storage *model.Storage, logger *zap.SugaredLogger) *Dispatcher {
+	return &Dispatcher{
+		logger:  logger,
+		Storage: storage,
+	}
+}
+
+// Publish enqueue deliveries of event, an event published again is not enqueued twice for the same webhook.
+func (d *Dispatcher) Publish(event *model.Event) error {
+	if !model.WebhookSubscribableEventTypes[event.Type] {
+		return nil
+	}
+	payload, errInExport := exportTeamEventPayload(event)
+	if errInExport != nil {
+		return errInExport
+	}
+	teamID := idconvertor.ConvertStringToInt(payload.TeamID)
+	webhooks, errInRetrieve := d.Storage.WebhookStorage.RetrieveEnabledByTeamID(teamID)
+	if errInRetrieve != nil {
+		return errInRetrieve
+	}
+	for _, webhook := range webhooks {
+		if !webhook.DoesSubscribe(event.Type) {
+			continue
+		}
+		enqueued, errInCheck := d.Storage.WebhookDeliveryStorage.IsEventEnqueued(webhook.ID, event.UID)
+		if errInCheck != nil {
+			return errInCheck
+		}
+		if enqueued {
+			continue
+		}
+		body, errInRender := renderBody(webhook, event, payload)
+		if errInRender != nil {
+			return errInRender
+		}
+		if _, err := d.Storage.WebhookDeliveryStorage.Create(model.NewWebhookDelivery(webhook, event.UID, event.Type, body)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
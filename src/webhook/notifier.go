@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"github.com/google/uuid"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/notification"
+	"go.uber.org/zap"
+)
+
+// Notifier deliver notices by the enabled webhooks of the team which subscribed the notice category, e.g. a mail relay or a chat channel.
+// the recipients are resolved to their email addresses, so the receiver can reach each of them.
+// the deliveries are posted and retried by Deliverer.
+type Notifier struct {
+	logger  *zap.SugaredLogger
+	Storage *model.Storage
+}
+
+func NewNotifier(storage *model.Storage, logger *zap.SugaredLogger) *Notifier {
+	return &Notifier{
+		logger:  logger,
+		Storage: storage,
+	}
+}
+
+// Notify enqueue a delivery of notice for each subscribed webhook, the notice without subscriber is only logged.
+func (n *Notifier) Notify(notice *notification.Notice) error {
+	n.logger.Infow("notice",
+		"category", notice.Category,
+		"teamID", notice.TeamID,
+		"recipientUserIDs", notice.RecipientUserIDs,
+	)
+	webhooks, errInRetrieveWebhooks := n.Storage.WebhookStorage.RetrieveEnabledByTeamID(notice.TeamID)
+	if errInRetrieveWebhooks != nil {
+		return errInRetrieveWebhooks
+	}
+	subscribedWebhooks := make([]*model.Webhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		if webhook.DoesSubscribe(notice.Category) {
+			subscribedWebhooks = append(subscribedWebhooks, webhook)
+		}
+	}
+	if len(subscribedWebhooks) == 0 {
+		return nil
+	}
+	recipients, errInRetrieveRecipients := n.Storage.UserStorage.RetrieveByIDs(notice.RecipientUserIDs)
+	if errInRetrieveRecipients != nil {
+		return errInRetrieveRecipients
+	}
+
+	// the deliveries of the same notice share the event uid, so the receiver can deduplicate them
+	eventUID := uuid.New()
+	for _, webhook := range subscribedWebhooks {
+		body, errInRender := renderNoticeBody(webhook, eventUID, notice, recipients)
+		if errInRender != nil {
+			return errInRender
+		}
+		if _, err := n.Storage.WebhookDeliveryStorage.Create(model.NewWebhookDelivery(webhook, eventUID, notice.Category, body)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package webhook
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kozmoai/kozmo-supervisor-backend/src/internal/testdb"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/model"
+	"github.com/kozmoai/kozmo-supervisor-backend/src/notification"
+	"go.uber.org/zap"
+)
+
+const testTeamID = 7
+
+func createTestWebhook(t *testing.T, storage *model.Storage, name string, format int, status int, eventTypes ...string) *model.Webhook {
+	t.Helper()
+	webhook := &model.Webhook{TeamID: testTeamID, Name: name, URL: "https://hooks.example.com/" + name, Format: format, Status: status}
+	webhook.SetEventTypes(eventTypes)
+	webhook.InitUID()
+	webhook.InitSecret()
+	id, err := storage.WebhookStorage.Create(webhook)
+	if err != nil {
+		t.Fatalf("create webhook failed: %v", err)
+	}
+	webhook.ID = id
+	return webhook
+}
+
+func TestNotifierEnqueueSubscribedWebhooks(t *testing.T) {
+	storage := testdb.NewStorage(t)
+	for _, user := range []*model.User{
+		{ID: 1, Nickname: "alice", Email: "alice@acme.com"},
+		{ID: 2, Nickname: "bob", Email: "bob@acme.com"},
+	} {
+		if _, err := storage.UserStorage.Create(user); err != nil {
+			t.Fatalf("create user failed: %v", err)
+		}
+	}
+	jsonWebhook := createTestWebhook(t, storage, "relay", model.WEBHOOK_FORMAT_JSON, model.WEBHOOK_STATUS_ENABLED, model.WEBHOOK_EVENT_TYPE_ROLE_GRANT_EXPIRING)
+	textWebhook := createTestWebhook(t, storage, "chat", model.WEBHOOK_FORMAT_TEXT, model.WEBHOOK_STATUS_ENABLED, model.WEBHOOK_EVENT_TYPE_ROLE_GRANT_EXPIRING, model.EVENT_TYPE_TEAM_UPDATED)
+	createTestWebhook(t, storage, "events", model.WEBHOOK_FORMAT_JSON, model.WEBHOOK_STATUS_ENABLED, model.EVENT_TYPE_TEAM_UPDATED)
+	createTestWebhook(t, storage, "disabled", model.WEBHOOK_FORMAT_JSON, model.WEBHOOK_STATUS_DISABLED, model.WEBHOOK_EVENT_TYPE_ROLE_GRANT_EXPIRING)
+
+	notifier := NewNotifier(storage, zap.NewNop().Sugar())
+	notice := notification.NewNotice(notification.NOTICE_CATEGORY_ROLE_GRANT_EXPIRING, testTeamID, []int{1, 2}, map[string]interface{}{
+		"userID":     1,
+		"userRole":   model.USER_ROLE_ADMIN,
+		"validUntil": time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+	if err := notifier.Notify(notice); err != nil {
+		t.Fatalf("notify failed: %v", err)
+	}
+	// a notice nobody subscribed is dropped
+	if err := notifier.Notify(notification.NewNotice(notification.NOTICE_CATEGORY_ROLE_GRANT_EXPIRED, testTeamID, []int{1}, nil)); err != nil {
+		t.Fatalf("notify failed: %v", err)
+	}
+
+	deliveries, err := storage.WebhookDeliveryStorage.RetrieveDueBefore(time.Now().UTC(), DELIVERY_BATCH_SIZE)
+	if err != nil {
+		t.Fatalf("retrieve deliveries failed: %v", err)
+	}
+	if len(deliveries) != 2 {
+		t.Fatalf("deliveries = %d, want 2", len(deliveries))
+	}
+	if deliveries[0].EventUID != deliveries[1].EventUID {
+		t.Error("deliveries of the same notice should share the event uid")
+	}
+	for _, delivery := range deliveries {
+		if delivery.EventType != model.WEBHOOK_EVENT_TYPE_ROLE_GRANT_EXPIRING {
+			t.Errorf("event type = %s", delivery.EventType)
+		}
+		switch delivery.WebhookID {
+		case jsonWebhook.ID:
+			body := &NoticeBody{}
+			if err := json.Unmarshal([]byte(delivery.Body), body); err != nil {
+				t.Fatalf("decode body failed: %v", err)
+			}
+			if len(body.Recipients) != 2 || body.Recipients[0].Email != "alice@acme.com" || body.Recipients[1].Email != "bob@acme.com" {
+				t.Errorf("recipients = %+v", body.Recipients)
+			}
+			if body.Type != model.WEBHOOK_EVENT_TYPE_ROLE_GRANT_EXPIRING || body.Payload["validUntil"] != "2026-01-01T00:00:00Z" {
+				t.Errorf("body = %s", delivery.Body)
+			}
+		case textWebhook.ID:
+			body := &TextBody{}
+			if err := json.Unmarshal([]byte(delivery.Body), body); err != nil {
+				t.Fatalf("decode body failed: %v", err)
+			}
+			if !strings.Contains(body.Text, "expires at 2026-01-01") || !strings.HasSuffix(body.Text, "Recipients: alice@acme.com, bob@acme.com.") {
+				t.Errorf("text = %q", body.Text)
+			}
+		default:
+			t.Errorf("delivery to webhook %d which did not subscribe the notice", delivery.WebhookID)
+		}
+	}
+}
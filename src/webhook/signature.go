@@ -0,0 +1,35 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// delivery headers, the receiver should deduplicate by event uid since an event can be redelivered.
+const (
+	HEADER_SIGNATURE  = "Kozmo-Webhook-Signature"
+	HEADER_DELIVERY   = "Kozmo-Webhook-Delivery"
+	HEADER_EVENT_UID  = "Kozmo-Event-UID"
+	HEADER_EVENT_TYPE = "Kozmo-Event-Type"
+)
+
+const SIGNATURE_VERSION = "v1"
+
+// Sign feedback the signature header value of body in format "t=<unix timestamp>,v1=<hex hmac>".
+// the hmac is HMAC-SHA256 over "<unix timestamp>.<body>" keyed by the webhook secret,
+// the receiver should recompute it and reject stale timestamps to prevent replay.
+func Sign(secret string, body []byte, signedAt time.Time) string {
+	timestamp := strconv.FormatInt(signedAt.Unix(), 10)
+	return "t=" + timestamp + "," + SIGNATURE_VERSION + "=" + computeSignature(secret, timestamp, body)
+}
+
+func computeSignature(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}